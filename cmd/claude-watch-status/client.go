@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/cli"
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/daemon"
+	"github.com/sho7650/claude-watch-status/internal/i18n"
+	"github.com/sho7650/claude-watch-status/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// clientPort is the daemon port of client subcommands (--port)
+var clientPort int
+
+// clientInsecure skips verifying the TLS certificate of the daemon (--insecure)
+var clientInsecure bool
+
+// addClientCommands adds the subcommands that talk to a running daemon
+func addClientCommands(rootCmd *cobra.Command) {
+	// Watch subcommand (the same as the root command)
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Show status changes as they happen (default command)",
+		Long: `Show status changes from the running daemon as a stream, or as a
+dashboard with --dashboard. Without a daemon, or with --standalone,
+session logs are watched directly.`,
+		Args: cobra.NoArgs,
+		RunE: runWatch,
+	}
+	addWatchFlags(watchCmd)
+	rootCmd.AddCommand(watchCmd)
+
+	// Attach subcommand
+	var attachURL string
+	var attachDashboard, attachNotify bool
+	attachCmd := &cobra.Command{
+		Use:   "attach",
+		Short: "Show status changes from a running daemon only",
+		Long: `Show the stream, or the dashboard with --dashboard, on the event stream of
+a running daemon. Unlike watch, attach never falls back to watching session
+logs itself, so it adds no watcher and, unless --notify is given, no
+notifications of its own next to the daemon's. The daemon is found from its
+lock file, --port or the configuration; --url attaches to another address,
+e.g. a daemon reached through a tunnel.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			endpoint := attachURL
+			if endpoint == "" {
+				var err error
+				if endpoint, err = daemonEndpoint(cmd); err != nil {
+					return err
+				}
+			}
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if err := i18n.Set(i18n.Detect(cfg.Language)); err != nil {
+				return err
+			}
+			if err := applyTheme(cfg); err != nil {
+				return err
+			}
+			c, err := cli.Connect(endpoint, config.GetAPIToken(), clientInsecure)
+			if err != nil {
+				return fmt.Errorf("no daemon answers on %s (start one with 'claude-watch-status serve')", endpoint)
+			}
+			remote := cli.NewRemoteMode(c, attachDashboard)
+			remote.SetLayout(dashboardLayout())
+			remote.SetProjectsDir(cfg.ProjectsDir)
+			remote.SetNotificationsEnabled(attachNotify)
+			remote.SetNotifyInterrupted(!noInterrupt)
+			return remote.Run()
+		},
+	}
+	attachCmd.Flags().StringVar(&attachURL, "url", "", "Daemon URL, e.g. http://127.0.0.1:10087 (default: the running daemon)")
+	attachCmd.Flags().BoolVarP(&attachDashboard, "dashboard", "d", false, "Show dashboard view (latest status per project)")
+	addLayoutFlags(attachCmd)
+	attachCmd.Flags().BoolVar(&attachNotify, "notify", false, "Also send desktop notifications from this terminal")
+	attachCmd.Flags().BoolVar(&noInterrupt, "no-interrupt-notify", false, "With --notify, disable notifications for interrupted requests")
+	addClientFlags(attachCmd)
+	rootCmd.AddCommand(attachCmd)
+
+	// Status subcommand
+	var statusJSON bool
+	statusCmd := &cobra.Command{
+		Use:   "status [project...]",
+		Short: "Print the current status of projects",
+		Long: `Print the current status of all projects, or of the given projects,
+from the running daemon.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := connectDaemon(cmd)
+			if err != nil {
+				return err
+			}
+			if info, ok := daemon.Running(config.GetDaemonLockPath()); ok && !statusJSON {
+				fmt.Printf("Daemon running (pid %d) on %s since %s\n\n", info.PID, info.URL(), info.StartedAt.Local().Format("2006-01-02 15:04"))
+			}
+			return cli.RunStatus(os.Stdout, c, args, statusJSON)
+		},
+	}
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "Print the status as JSON")
+	addClientFlags(statusCmd)
+	rootCmd.AddCommand(statusCmd)
+
+	// Wait subcommand
+	var waitOpts cli.WaitOptions
+	waitCmd := &cobra.Command{
+		Use:   "wait <project>",
+		Short: "Block until a project needs attention or finishes its turn",
+		Long: `Block until the project enters one of the --for states, then print its
+status. Returns at once if the project is already in such a state.
+
+States: started, user_input, working, waiting, completed, interrupted,
+error, ended. Exits with an error when --timeout passes first.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := connectDaemon(cmd)
+			if err != nil {
+				return err
+			}
+			waitOpts.Project = args[0]
+			return cli.RunWait(c, waitOpts)
+		},
+	}
+	waitCmd.Flags().StringSliceVar(&waitOpts.Phases, "for", cli.DefaultWaitPhases, "States to wait for")
+	waitCmd.Flags().DurationVar(&waitOpts.Timeout, "timeout", 0, "Give up after this long (0 = wait forever)")
+	addClientFlags(waitCmd)
+	rootCmd.AddCommand(waitCmd)
+
+	// Attention subcommand
+	attentionCmd := &cobra.Command{
+		Use:   "attention",
+		Short: "List projects waiting for you",
+		Long: `List the projects waiting for approval, interrupted or stopped by an
+error. Exits with status 1 when there are none, for use in scripts:
+
+  claude-watch-status attention >/dev/null && say "Claude needs you"`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := connectDaemon(cmd)
+			if err != nil {
+				return err
+			}
+			found, err := cli.RunAttention(os.Stdout, c)
+			if err != nil {
+				return err
+			}
+			if !found {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+	addClientFlags(attentionCmd)
+	rootCmd.AddCommand(attentionCmd)
+
+	// Mute subcommand
+	var muteFor time.Duration
+	var muteOff bool
+	muteCmd := &cobra.Command{
+		Use:   "mute [project]",
+		Short: "Silence the notifications of a project",
+		Long: `Silence the desktop and browser notifications of a project, for a
+duration with --for or until 'mute --off'. The project keeps updating its
+state in every view. Without a project, list the muted projects.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := connectDaemon(cmd)
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				if muteOff || muteFor != 0 {
+					return fmt.Errorf("a project is required")
+				}
+				return cli.RunMutes(os.Stdout, c)
+			}
+			return cli.RunMute(os.Stdout, c, args[0], muteFor, muteOff)
+		},
+	}
+	muteCmd.Flags().DurationVar(&muteFor, "for", 0, "Mute duration (default: until unmuted)")
+	muteCmd.Flags().BoolVar(&muteOff, "off", false, "Unmute the project")
+	addClientFlags(muteCmd)
+	rootCmd.AddCommand(muteCmd)
+
+	// Focus subcommand
+	focusCmd := &cobra.Command{
+		Use:   "focus <project>",
+		Short: "Raise the terminal window of a project's session",
+		Long: `Bring the terminal running a project's Claude Code session to the front,
+for example to answer a prompt waiting for approval. The daemon selects
+the tmux pane and raises iTerm2, Terminal.app (macOS), WezTerm, kitty or
+X11 windows (with wmctrl), using what the hook relay reported about the
+session's terminal.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := connectDaemon(cmd)
+			if err != nil {
+				return err
+			}
+			return cli.RunFocus(os.Stdout, c, args[0])
+		},
+	}
+	addClientFlags(focusCmd)
+	rootCmd.AddCommand(focusCmd)
+}
+
+// addWatchFlags adds the flags of the watch views
+func addWatchFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&dashboardMode, "dashboard", "d", false, "Show dashboard view (latest status per project)")
+	addLayoutFlags(cmd)
+	cmd.Flags().BoolVar(&noInterrupt, "no-interrupt-notify", false, "Disable notifications for interrupted requests")
+	cmd.Flags().BoolVar(&standalone, "standalone", false, "Watch session logs directly instead of using the daemon")
+	cmd.Flags().StringVar(&watchMode, "watch-mode", "auto", "Without a daemon, how session log changes are detected: auto, fsnotify, poll")
+	addClientFlags(cmd)
+}
+
+// addLayoutFlags adds the dashboard layout, color and icon flags
+func addLayoutFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&compactLayout, "compact", false, "Dashboard: icon, name and elapsed time only, for narrow terminals")
+	cmd.Flags().BoolVar(&wideLayout, "wide", false, "Dashboard: add token usage and branch columns")
+	cmd.MarkFlagsMutuallyExclusive("compact", "wide")
+	cmd.Flags().BoolVar(&noColor, "no-color", false, "Print without colors (also NO_COLOR or CLICOLOR=0)")
+	addIconsFlag(cmd)
+}
+
+// dashboardLayout returns the layout selected by --compact and --wide;
+// without them it follows the terminal width
+func dashboardLayout() cli.DashboardLayout {
+	switch {
+	case compactLayout:
+		return cli.LayoutCompact
+	case wideLayout:
+		return cli.LayoutWide
+	default:
+		return cli.LayoutAuto
+	}
+}
+
+// addClientFlags adds the daemon port and TLS flags
+func addClientFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&clientPort, "port", "p", 10087, "Daemon port (default: server_port from config)")
+	cmd.Flags().BoolVar(&clientInsecure, "insecure", false, "Do not verify the daemon's TLS certificate (self-signed)")
+}
+
+// daemonEndpoint returns the daemon URL from the lock file of the running
+// daemon, unless --port names another one, or from --port or the
+// configuration
+func daemonEndpoint(cmd *cobra.Command) (string, error) {
+	info, running := daemon.Running(config.GetDaemonLockPath())
+	if !cmd.Flags().Changed("port") {
+		if running {
+			return info.URL(), nil
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			return "", err
+		}
+		clientPort = cfg.ServerPort
+	}
+	if running && info.Port == clientPort {
+		return info.URL(), nil
+	}
+	return "http://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(clientPort)), nil
+}
+
+// connectDaemon returns a client for the running daemon
+func connectDaemon(cmd *cobra.Command) (*client.Client, error) {
+	endpoint, err := daemonEndpoint(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return cli.Connect(endpoint, config.GetAPIToken(), clientInsecure)
+}
@@ -2,50 +2,113 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/sho7650/claude-watch-status/internal/cli"
 	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/daemon"
+	"github.com/sho7650/claude-watch-status/internal/engine"
 	"github.com/sho7650/claude-watch-status/internal/hooks"
+	"github.com/sho7650/claude-watch-status/internal/i18n"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/notifier"
+	"github.com/sho7650/claude-watch-status/internal/prefs"
+	"github.com/sho7650/claude-watch-status/internal/redact"
 	"github.com/sho7650/claude-watch-status/internal/server"
+	"github.com/sho7650/claude-watch-status/internal/shortcuts"
+	"github.com/sho7650/claude-watch-status/internal/source"
 	"github.com/sho7650/claude-watch-status/internal/state"
+	"github.com/sho7650/claude-watch-status/internal/stats"
 	"github.com/sho7650/claude-watch-status/internal/watcher"
 	"github.com/spf13/cobra"
 )
 
 var (
 	version       = "0.2.0"
+	configPath    string
 	dashboardMode bool
+	compactLayout bool
+	wideLayout    bool
+	noColor       bool
+	iconSet       string
+	noInterrupt   bool
+	standalone    bool
 	serverPort    int
+	apiToken      string
+	bindAddr      string
+	tlsCert       string
+	tlsKey        string
+	serveNotify   bool
+	lowPower      bool
+	noJournal     bool
+	watchMode     string
+	logLevel      string
+	sseKeepalive  time.Duration
+	heartbeat     time.Duration
+	replace       bool
 )
 
+// replaceTimeout is how long serve --replace waits for the running daemon
+// to shut down
+const replaceTimeout = 10 * time.Second
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "claude-watch-status",
 		Short: "Real-time status monitor for Claude Code sessions",
-		Long: `claude-watch-status monitors Claude Code activity in real-time by watching
-the JSONL session logs. It provides visual feedback on what Claude is doing
-across multiple projects simultaneously.`,
+		Long: `claude-watch-status monitors Claude Code activity in real-time. The daemon
+('serve') watches the JSONL session logs and receives hook events; the other
+commands are thin clients of it, so one watcher serves every view. Without a
+running daemon, or with --standalone, the session logs are watched directly.`,
+		Args: cobra.NoArgs,
 		RunE: runWatch,
 	}
 
-	rootCmd.Flags().BoolVarP(&dashboardMode, "dashboard", "d", false, "Show dashboard view (latest status per project)")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file (default: $CWS_CONFIG or the platform config directory)")
+	addWatchFlags(rootCmd)
+	addClientCommands(rootCmd)
 
 	// Serve subcommand
 	serveCmd := &cobra.Command{
 		Use:   "serve",
-		Short: "Start the Web UI server",
-		Long:  `Start the HTTP server with Web UI and API endpoints for real-time status monitoring.`,
-		RunE:  runServe,
+		Short: "Start the daemon (Web UI server)",
+		Long: `Start the daemon: watch session logs, receive hook events and serve the
+Web UI and the API used by the other commands.`,
+		RunE: runServe,
 	}
 	serveCmd.Flags().IntVarP(&serverPort, "port", "p", 10087, "Server port")
+	serveCmd.Flags().StringVar(&bindAddr, "bind", "", "Address to bind to (default: all interfaces)")
+	serveCmd.Flags().StringVar(&tlsCert, "tls-cert", "", "TLS certificate file (enables HTTPS)")
+	serveCmd.Flags().StringVar(&tlsKey, "tls-key", "", "TLS private key file (enables HTTPS)")
+	serveCmd.MarkFlagsRequiredTogether("tls-cert", "tls-key")
+	serveCmd.Flags().BoolVar(&serveNotify, "notify", false, "Send desktop notifications (default: notifications.desktop from config)")
+	serveCmd.Flags().BoolVar(&lowPower, "low-power", false, "On battery, check idle projects less often and debounce session log reads")
+	serveCmd.Flags().StringVar(&watchMode, "watch-mode", "auto", "How session log changes are detected: auto, fsnotify, poll")
+	serveCmd.Flags().BoolVar(&noJournal, "no-journal", false, "Do not journal hook events for replay after a restart")
+	serveCmd.Flags().DurationVar(&sseKeepalive, "sse-keepalive", 15*time.Second, "Interval of keepalive comments on idle event streams (0 disables)")
+	serveCmd.Flags().DurationVar(&heartbeat, "heartbeat", 0, "Republish unchanged project statuses reported by sources at most this often (0 never)")
+	serveCmd.Flags().StringVar(&logLevel, "log-level", "info", "Log level: debug, info, warn, error (SIGUSR1 toggles debug)")
+	serveCmd.Flags().StringVar(&apiToken, "api-token", "", "Require bearer token for the read API (default: $CWS_API_TOKEN)")
+	serveCmd.Flags().BoolVar(&replace, "replace", false, "Stop the running daemon and take its place")
 	rootCmd.AddCommand(serveCmd)
 
 	// Init subcommand
 	var initPort int
-	var initForce, initYes, initCheck, initRemove, initKeepScript bool
+	var initHost string
+	var initTLS, initInsecure bool
+	var initForce, initYes, initCheck, initRemove, initKeepScript, initLocal bool
+	var initTransport, initProject string
 
 	initCmd := &cobra.Command{
 		Use:   "init",
@@ -56,21 +119,415 @@ real-time status detection without polling delays.
 This command:
   - Creates a backup of your current settings
   - Adds CWS hooks to your Claude Code configuration
-  - Creates the hook notification script
+  - Registers 'claude-watch-status hook-relay' (or a script, see
+    --hook-transport) as the hook command
+
+Existing hooks and settings are preserved.
 
-Existing hooks and settings are preserved.`,
+With --project, hooks are installed into <path>/.claude/settings.json
+(or settings.local.json with --local) instead; --check and --remove
+then act on that file only.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runInit(initPort, initForce, initYes, initCheck, initRemove, initKeepScript)
+			if !cmd.Flags().Changed("port") {
+				cfg, err := loadConfig()
+				if err != nil {
+					return err
+				}
+				initPort = cfg.HooksPort
+			}
+			endpoint := hooks.Endpoint{Port: initPort, TLS: initTLS, Insecure: initInsecure}
+			if cmd.Flags().Changed("host") {
+				endpoint.Host = initHost
+			}
+			installer, err := newInitInstaller(endpoint, initProject, initLocal)
+			if err != nil {
+				return err
+			}
+			return runInit(installer, initForce, initYes, initCheck, initRemove, initKeepScript, hooks.Transport(initTransport))
 		},
 	}
 	initCmd.Flags().IntVarP(&initPort, "port", "p", 10087, "Daemon port")
+	initCmd.Flags().StringVar(&initHost, "host", "127.0.0.1", "Daemon host hooks send events to (serve --bind)")
+	initCmd.Flags().BoolVar(&initTLS, "tls", false, "Send hook events over HTTPS (serve --tls-cert)")
+	initCmd.Flags().BoolVar(&initInsecure, "insecure", false, "With --tls, do not verify the daemon's certificate (self-signed)")
 	initCmd.Flags().BoolVarP(&initForce, "force", "f", false, "Overwrite existing CWS configuration")
 	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "Skip confirmation prompts")
 	initCmd.Flags().BoolVar(&initCheck, "check", false, "Check current configuration status")
 	initCmd.Flags().BoolVar(&initRemove, "remove", false, "Remove CWS hooks configuration")
 	initCmd.Flags().BoolVar(&initKeepScript, "keep-script", false, "Keep hook script when removing")
+	initCmd.Flags().StringVar(&initTransport, "hook-transport", "", "Hook transport: binary, sh, powershell (default: binary)")
+	initCmd.Flags().StringVar(&initProject, "project", "", "Install into a project's .claude/settings.json instead of ~/.claude")
+	initCmd.Flags().BoolVar(&initLocal, "local", false, "With --project, use .claude/settings.local.json (not checked in)")
 	rootCmd.AddCommand(initCmd)
 
+	// Config subcommand
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the configuration file",
+	}
+	var configProbe bool
+	configValidateCmd := &cobra.Command{
+		Use:   "validate [path]",
+		Short: "Validate a configuration file and print the effective configuration",
+		Long: `Parse a configuration file without starting the daemon. Reports JSON
+syntax errors, unknown keys and invalid values, then prints the fully
+resolved effective configuration.
+
+With --probe, also sends a HEAD request to the endpoint of every webhook,
+slack, ntfy and pushover notifier and reports the unreachable ones. No
+notification is sent.
+
+If no path is given, --config, $CWS_CONFIG or the default location is used.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigValidate(args, configProbe)
+		},
+	}
+	configValidateCmd.Flags().BoolVar(&configProbe, "probe", false, "Check that notifier endpoints are reachable")
+	configCmd.AddCommand(configValidateCmd)
+
+	var configInitForce, configInitInteractive bool
+	configInitCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Write a commented default configuration file",
+		Long: `Write a fully-commented default configuration file to --config,
+$CWS_CONFIG or the platform config directory
+($XDG_CONFIG_HOME on Linux, ~/Library/Application Support on macOS).`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigInit(configInitForce, configInitInteractive)
+		},
+	}
+	configInitCmd.Flags().BoolVarP(&configInitForce, "force", "f", false, "Overwrite an existing configuration file")
+	configInitCmd.Flags().BoolVarP(&configInitInteractive, "interactive", "i", false, "Prompt for settings")
+	configCmd.AddCommand(configInitCmd)
+
+	var importDryRun bool
+	configImportCmd := &cobra.Command{
+		Use:   "import-projects <mapping.csv|mapping.json>",
+		Short: "Import project aliases, tiers and groups from a CSV or JSON file",
+		Long: `Merge path to alias/tier/group mappings into the "projects" section of the
+configuration file. A CSV file needs a header row with the columns path,
+alias, tier and group (only path is required); a JSON file holds an array
+of objects with the same keys. Without an alias, the directory name is the
+project name.
+
+Every path must be an existing directory, and paths and names must be
+unique; if any mapping is invalid, nothing is written. Tier and group are
+only changed where the file sets them. Comments elsewhere in the
+configuration file are kept.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigImportProjects(args[0], importDryRun)
+		},
+	}
+	configImportCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Only report what would change")
+	configCmd.AddCommand(configImportCmd)
+	rootCmd.AddCommand(configCmd)
+
+	// Hook relay subcommand (registered as the hook command by init)
+	var relayPort int
+	var relayHost string
+	var relayTLS, relayInsecure bool
+	var relayTimeout time.Duration
+	hookRelayCmd := &cobra.Command{
+		Use:   "hook-relay",
+		Short: "Forward a Claude Code hook event from stdin to the daemon",
+		Long: `Read a Claude Code hook event from stdin and POST it to the daemon.
+Registered as the hook command by 'init', so hooks work without sh or curl.
+
+While the daemon is unreachable, events are spooled to the cache directory
+and replayed by the next successful relay. Always exits successfully so
+Claude Code is never blocked.
+
+The address, bind address and TLS of the running daemon on that port are
+taken from its lock file; --host and --tls are for daemons without one.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("port") {
+				if cfg, err := loadConfig(); err == nil {
+					relayPort = cfg.HooksPort
+				}
+			}
+			token := os.Getenv("CWS_TOKEN")
+			if token == "" {
+				token, _ = hooks.LoadToken(config.GetTokenPath())
+			}
+			endpoint := hooks.Endpoint{Host: relayHost, Port: relayPort, TLS: relayTLS}.URL()
+			if info, ok := daemon.Running(config.GetDaemonLockPath()); ok && info.Port == relayPort && !cmd.Flags().Changed("host") {
+				endpoint = info.URL() + "/api/hooks"
+			}
+			relay := hooks.NewRelay(endpoint, token, relayTimeout)
+			if relayInsecure {
+				relay.SetInsecureTLS()
+			}
+			relay.SetSpoolDir(config.GetSpoolDir())
+			relay.SetTerminalCacheDir(config.GetTerminalCacheDir())
+			// Fail silently to not block Claude Code
+			relay.Relay(os.Stdin)
+			return nil
+		},
+	}
+	hookRelayCmd.Flags().IntVarP(&relayPort, "port", "p", 10087, "Daemon port")
+	hookRelayCmd.Flags().StringVar(&relayHost, "host", "127.0.0.1", "Daemon host")
+	hookRelayCmd.Flags().BoolVar(&relayTLS, "tls", false, "Send over HTTPS (default: from the running daemon)")
+	hookRelayCmd.Flags().BoolVar(&relayInsecure, "insecure", false, "Do not verify the daemon's TLS certificate (self-signed)")
+	hookRelayCmd.Flags().DurationVar(&relayTimeout, "timeout", 2*time.Second, "Request timeout")
+	rootCmd.AddCommand(hookRelayCmd)
+
+	// Statusline subcommand
+	var statuslineOpts cli.StatuslineOptions
+	statuslineCmd := &cobra.Command{
+		Use:   "statusline",
+		Short: "Print a compact one-line status for status bars and prompts",
+		Long: `Print a compact one-line status (icon, state, age) from the running daemon,
+for tmux status bars, starship prompts or Claude Code's statusLine setting.
+
+When used as a Claude Code statusLine command, the project is taken from the
+JSON piped on stdin. Otherwise the most recently updated project is shown.
+Prints nothing if the daemon is not running.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if err := applyIcons(); err != nil {
+				return err
+			}
+			if statuslineOpts.Endpoint, err = daemonEndpoint(cmd); err != nil {
+				return err
+			}
+			statuslineOpts.Token = config.GetAPIToken()
+			statuslineOpts.Insecure = clientInsecure
+			statuslineOpts.ProjectNameFor = cfg.ProjectNameFor
+			if statuslineOpts.Project == "" && cli.StdinIsPiped() {
+				statuslineOpts.Dir = cli.DirFromStatuslineInput(os.Stdin)
+			}
+			return cli.RunStatusline(os.Stdout, statuslineOpts)
+		},
+	}
+	statuslineCmd.Flags().StringVar(&statuslineOpts.Project, "project", "", "Project name (default: from stdin or most recent)")
+	statuslineCmd.Flags().StringVar(&statuslineOpts.Format, "format", "plain", "Output format: plain, tmux, starship, json")
+	addClientFlags(statuslineCmd)
+	addIconsFlag(statuslineCmd)
+	rootCmd.AddCommand(statuslineCmd)
+
+	// tmux-sync subcommand
+	var tmuxOpts cli.TmuxSyncOptions
+	tmuxCmd := &cobra.Command{
+		Use:   "tmux-sync",
+		Short: "Prefix tmux window names with project status icons",
+		Long: `Poll the running daemon and prefix the name of every tmux window with the
+status icon of the project its panes are in (matched by directory name),
+so windows waiting for approval stand out. Original names are restored on exit.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyIcons(); err != nil {
+				return err
+			}
+			endpoint, err := daemonEndpoint(cmd)
+			if err != nil {
+				return err
+			}
+			tmuxOpts.Endpoint = endpoint
+			tmuxOpts.Token = config.GetAPIToken()
+			tmuxOpts.Insecure = clientInsecure
+			return cli.NewTmuxSync(tmuxOpts).Run()
+		},
+	}
+	tmuxCmd.Flags().DurationVar(&tmuxOpts.Interval, "interval", 2*time.Second, "Polling interval")
+	addClientFlags(tmuxCmd)
+	addIconsFlag(tmuxCmd)
+	rootCmd.AddCommand(tmuxCmd)
+
+	// Tunnel subcommand
+	var tunnelOpts cli.TunnelOptions
+	tunnelCmd := &cobra.Command{
+		Use:   "tunnel <user@host> [-- ssh options...]",
+		Short: "Open the Web UI of a daemon running on a remote host over SSH",
+		Long: `Forward a local port to the daemon on a remote host with ssh -L, wait until
+its /health endpoint answers through the tunnel and open the Web UI in the
+local browser. The tunnel stays open until Ctrl+C.
+
+Arguments after "--" are passed to ssh (for example -- -i ~/.ssh/devbox -J bastion).
+With --print, only the ssh command is printed.`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if n := cmd.ArgsLenAtDash(); n == 0 || n > 1 || (n < 0 && len(args) > 1) {
+				return fmt.Errorf("expected one destination; pass ssh options after \"--\"")
+			}
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("port") {
+				tunnelOpts.RemotePort = cfg.ServerPort
+			}
+			tunnelOpts.Destination = args[0]
+			tunnelOpts.SSHArgs = args[1:]
+			tunnelOpts.Token = config.GetAPIToken()
+			return cli.RunTunnel(tunnelOpts)
+		},
+	}
+	tunnelCmd.Flags().IntVarP(&tunnelOpts.RemotePort, "port", "p", 10087, "Daemon port on the remote host")
+	tunnelCmd.Flags().IntVarP(&tunnelOpts.LocalPort, "local-port", "l", 0, "Local port (default: same as --port)")
+	tunnelCmd.Flags().BoolVar(&tunnelOpts.Print, "print", false, "Print the ssh command instead of running it")
+	tunnelCmd.Flags().BoolVar(&tunnelOpts.NoBrowser, "no-browser", false, "Do not open the browser")
+	tunnelCmd.Flags().DurationVar(&tunnelOpts.Timeout, "timeout", 15*time.Second, "How long to wait for the remote daemon")
+	rootCmd.AddCommand(tunnelCmd)
+
+	// Tail subcommand
+	var tailOpts cli.TailOptions
+	var tailNoFollow, tailNoColor bool
+	tailCmd := &cobra.Command{
+		Use:   "tail <project|session.jsonl>",
+		Short: "Follow a project's session log as a readable transcript",
+		Long: `Print the last entries of the project's latest session log and follow new
+ones as they are written: user prompts, Claude's replies, tool calls with
+their arguments and tool results, shortened to one line each and with
+secrets redacted. When the project starts a new session, tail switches to
+it. A session log file can be given instead of a project name.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if err := redact.SetPatterns(cfg.Redaction.Patterns); err != nil {
+				return err
+			}
+			tailOpts.ProjectsDir = cfg.ProjectsDir
+			tailOpts.Project = args[0]
+			tailOpts.NameFor = cfg.ProjectNameFor
+			tailOpts.Follow = !tailNoFollow
+			tailOpts.Color = cli.ColorsEnabled(tailNoColor)
+			return cli.RunTail(os.Stdout, tailOpts)
+		},
+	}
+	tailCmd.Flags().IntVarP(&tailOpts.Lines, "lines", "n", 20, "Number of entries to show before following")
+	tailCmd.Flags().IntVar(&tailOpts.Width, "width", 160, "Shorten texts to this many characters")
+	tailCmd.Flags().BoolVar(&tailNoFollow, "no-follow", false, "Exit after printing instead of following")
+	tailCmd.Flags().BoolVar(&tailNoColor, "no-color", false, "Print without colors")
+	rootCmd.AddCommand(tailCmd)
+
+	// Sessions subcommand
+	var sessionsOpts cli.SessionsOptions
+	sessionsCmd := &cobra.Command{
+		Use:   "sessions [project]",
+		Short: "List sessions with their duration, message counts and token usage",
+		Long: `List the sessions of a project, or of all projects, newest first, with
+start time, duration, prompts, replies, tool calls and token usage, read
+from the session logs.
+
+With --export markdown or --export json, print the transcript of the
+project's latest session, or of the session given by --session (an ID or
+its prefix), with secrets redacted, for sharing or archiving.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if err := redact.SetPatterns(cfg.Redaction.Patterns); err != nil {
+				return err
+			}
+			sessionsOpts.ProjectsDir = cfg.ProjectsDir
+			sessionsOpts.NameFor = cfg.ProjectNameFor
+			if len(args) > 0 {
+				sessionsOpts.Project = args[0]
+			}
+			return cli.RunSessions(os.Stdout, sessionsOpts)
+		},
+	}
+	sessionsCmd.Flags().StringVar(&sessionsOpts.Export, "export", "", "Print a session transcript: markdown or json")
+	sessionsCmd.Flags().StringVar(&sessionsOpts.Session, "session", "", "Session ID or prefix to export (default: the project's latest)")
+	sessionsCmd.Flags().IntVarP(&sessionsOpts.Limit, "limit", "n", 20, "Number of sessions to list (0 = all)")
+	sessionsCmd.Flags().BoolVar(&sessionsOpts.JSON, "json", false, "List sessions as JSON")
+	rootCmd.AddCommand(sessionsCmd)
+
+	// Search subcommand
+	var searchOpts cli.SearchOptions
+	var searchNoColor bool
+	searchCmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search session logs for text, tool calls and tool results",
+		Long: `Scan the session logs of all projects, or of --project, for prompts,
+replies, tool calls (name and arguments) and tool results containing the
+query, ignoring case, and print each match with its session and time.
+Sessions are searched newest first. Secrets are redacted before matching.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if err := redact.SetPatterns(cfg.Redaction.Patterns); err != nil {
+				return err
+			}
+			searchOpts.ProjectsDir = cfg.ProjectsDir
+			searchOpts.NameFor = cfg.ProjectNameFor
+			searchOpts.Query = args[0]
+			searchOpts.Color = cli.ColorsEnabled(searchNoColor)
+			return cli.RunSearch(os.Stdout, searchOpts)
+		},
+	}
+	searchCmd.Flags().StringVar(&searchOpts.Project, "project", "", "Only search this project's sessions")
+	searchCmd.Flags().DurationVar(&searchOpts.Since, "since", 0, "Only search entries this recent, e.g. 24h (0 = all)")
+	searchCmd.Flags().IntVarP(&searchOpts.Limit, "limit", "n", 100, "Number of matches to show (0 = all)")
+	searchCmd.Flags().BoolVar(&searchOpts.JSON, "json", false, "Print matches as JSON")
+	searchCmd.Flags().BoolVar(&searchNoColor, "no-color", false, "Print without colors")
+	rootCmd.AddCommand(searchCmd)
+
+	// History subcommand
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "Back up and restore daily activity statistics",
+	}
+	var exportFormat, exportOutput string
+	historyExportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the statistics history to stdout or a file",
+		Long: `Write the daily statistics kept by the daemon as JSON, or as
+zstd-compressed JSON with --format zstd, for backups or moving them to
+another machine with 'history import'.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryExport(exportFormat, exportOutput)
+		},
+	}
+	historyExportCmd.Flags().StringVar(&exportFormat, "format", stats.FormatJSON, "Output format: json, zstd")
+	historyExportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file (default: stdout)")
+	historyCmd.AddCommand(historyExportCmd)
+
+	var importOverwrite bool
+	historyImportCmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Merge an exported statistics history",
+		Long: `Merge a history written by 'history export' (JSON or zstd, detected
+automatically; "-" reads stdin). Days and projects already in the history
+are kept unless --overwrite is given.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryImport(args[0], importOverwrite)
+		},
+	}
+	historyImportCmd.Flags().BoolVar(&importOverwrite, "overwrite", false, "Replace existing records for the same day and project")
+	historyCmd.AddCommand(historyImportCmd)
+	rootCmd.AddCommand(historyCmd)
+
 	// Version subcommand
 	versionCmd := &cobra.Command{
 		Use:   "version",
@@ -87,59 +544,631 @@ Existing hooks and settings are preserved.`,
 }
 
 func runWatch(cmd *cobra.Command, args []string) error {
-	projectsDir := config.GetProjectsDir()
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := redact.SetPatterns(cfg.Redaction.Patterns); err != nil {
+		return err
+	}
+	if err := i18n.Set(i18n.Detect(cfg.Language)); err != nil {
+		return err
+	}
+	if err := applyTheme(cfg); err != nil {
+		return err
+	}
+	projectsDir := cfg.ProjectsDir
+
+	// Use the daemon's watcher when one is running
+	if !standalone {
+		endpoint, err := daemonEndpoint(cmd)
+		if err != nil {
+			return err
+		}
+		c, err := cli.Connect(endpoint, config.GetAPIToken(), clientInsecure)
+		if err == nil {
+			remote := cli.NewRemoteMode(c, dashboardMode)
+			remote.SetLayout(dashboardLayout())
+			remote.SetProjectsDir(projectsDir)
+			remote.SetNotifyInterrupted(!noInterrupt)
+			remote.SetNotificationDigest(cfg.Notifications.DigestWindow())
+			if err := remote.SetNotifiers(cfg.Notifiers); err != nil {
+				return err
+			}
+			return remote.Run()
+		}
+		fmt.Fprintf(os.Stderr, "No daemon on %s, watching session logs directly (start one with 'claude-watch-status serve')\n", endpoint)
+	}
 
 	// Check if projects directory exists
 	if _, err := os.Stat(projectsDir); os.IsNotExist(err) {
 		return fmt.Errorf("projects directory not found: %s\nMake sure Claude Code is installed and has been used at least once", projectsDir)
 	}
 
+	mode, err := watcher.ParseMode(watchMode)
+	if err != nil {
+		return err
+	}
+
 	if dashboardMode {
 		dashboard := cli.NewDashboardMode(projectsDir)
+		dashboard.SetWatchMode(mode)
+		dashboard.SetLayout(dashboardLayout())
+		dashboard.SetNotifyInterrupted(!noInterrupt)
+		dashboard.ApplyConfig(cfg)
+		if err := dashboard.SetNotifiers(cfg.Notifiers); err != nil {
+			return err
+		}
 		return dashboard.Run()
 	}
 
 	stream := cli.NewStreamMode(projectsDir)
+	stream.SetWatchMode(mode)
+	stream.SetNotifyInterrupted(!noInterrupt)
+	stream.ApplyConfig(cfg)
+	if err := stream.SetNotifiers(cfg.Notifiers); err != nil {
+		return err
+	}
 	return stream.Run()
 }
 
 func runServe(cmd *cobra.Command, args []string) error {
-	projectsDir := config.GetProjectsDir()
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := redact.SetPatterns(cfg.Redaction.Patterns); err != nil {
+		return err
+	}
+	if err := i18n.Set(i18n.Detect(cfg.Language)); err != nil {
+		return err
+	}
+
+	level, err := logging.ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	logging.SetLevel(level)
+	projectsDir := cfg.ProjectsDir
+	if !cmd.Flags().Changed("port") {
+		serverPort = cfg.ServerPort
+	}
+	mode, err := watcher.ParseMode(watchMode)
+	if err != nil {
+		return err
+	}
 
 	// Check if projects directory exists
 	if _, err := os.Stat(projectsDir); os.IsNotExist(err) {
 		return fmt.Errorf("projects directory not found: %s\nMake sure Claude Code is installed and has been used at least once", projectsDir)
 	}
 
-	// Create state manager
-	manager := state.NewManager()
+	// One daemon per user: refuse to start next to another, or take over
+	lock, err := lockDaemon()
+	if err != nil {
+		return err
+	}
+	if lock != nil {
+		defer lock.Release()
+	}
+
+	// The configuration file is reloaded on changes; per-project settings
+	// are looked up in the current one
+	live := config.NewLive(configFilePath(), cfg)
+	live.SetValidator(func(cfg *config.Config) error {
+		return notifier.Validate(cfg.Notifiers)
+	})
+
+	// Tell the user when status updates stop, so a frozen dashboard is not trusted
+	health := notifier.New()
+	health.SetEnabled(cfg.Notifications.DaemonHealthEnabled())
+	if err := health.Configure(cfg.Notifiers); err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			health.NotifyDaemonStopped("crashed")
+			panic(r)
+		}
+	}()
+
+	// The engine watches session logs into the state manager; the other
+	// sources feed the same manager
+	eng := engine.New(projectsDir)
+	eng.SetWatchMode(mode)
+	manager := eng.Manager()
+	// Runs after the sources stopped: ends the subscriptions left
+	defer manager.Close()
+	manager.SetTierFunc(live.TierFor)
+	manager.SetGroupFunc(live.GroupFor)
+	manager.SetProjectNameFunc(live.ProjectNameFor)
+	manager.SetRetention(cfg.Retention.HideAfterDuration(), cfg.Retention.DeleteAfterDuration())
+	manager.SetHeartbeat(heartbeat)
+	eng.SetCompletedAfterFunc(func() time.Duration {
+		return live.Get().Idle.CompletedAfterDuration()
+	})
+	eng.JSONL().SetFailureFunc(func(error) {
+		health.NotifyWatcherFailed()
+	})
+	if err := eng.Watch(); err != nil {
+		return fmt.Errorf("failed to start %s source: %w", eng.JSONL().Name(), err)
+	}
+	defer eng.Stop()
+	sourceNames := []string{eng.JSONL().Name()}
+
+	hooksSource := source.NewHooks()
+	var journalPath string
+	if !noJournal {
+		journal, err := source.OpenJournal(config.GetJournalPath())
+		if err != nil {
+			logging.Logger().Warn("hook journal disabled", "error", err)
+		} else {
+			hooksSource.SetJournal(journal)
+			journalPath = journal.Path()
+		}
+	}
+	if err := hooksSource.Start(manager); err != nil {
+		return fmt.Errorf("failed to start %s source: %w", hooksSource.Name(), err)
+	}
+	defer hooksSource.Stop()
+	sourceNames = append(sourceNames, hooksSource.Name())
+
+	// Optional sources for other agent CLIs
+	for name, agent := range cfg.Agents {
+		if !agent.Enabled {
+			continue
+		}
+		p, ok := source.LookupParser(name)
+		if !ok {
+			return fmt.Errorf("unknown agent %q (available: %s)", name, strings.Join(source.Parsers(), ", "))
+		}
+		src := source.NewAgentLog(p, config.ExpandHome(agent.Dir))
+		if err := src.Start(manager); err != nil {
+			logging.Logger().Warn("agent source not started", "agent", name, "error", err)
+			continue
+		}
+		defer src.Stop()
+		sourceNames = append(sourceNames, src.Name())
+	}
+
+	// SIGUSR1 toggles debug logging, SIGUSR2 dumps state
+	handleDebugSignals(manager)
 
-	// Create and start watcher
-	w, err := watcher.New(projectsDir)
+	// Load hook shared-secret token (created by init)
+	hookToken, err := hooks.LoadToken(config.GetTokenPath())
 	if err != nil {
-		return fmt.Errorf("failed to create watcher: %w", err)
+		return fmt.Errorf("failed to load hook token: %w", err)
+	}
+	if hookToken == "" {
+		fmt.Fprintln(os.Stderr, "Warning: no hook token found, /api/hooks accepts unauthenticated events (run 'claude-watch-status init --force')")
+	}
+
+	if apiToken == "" {
+		apiToken = config.GetAPIToken()
+	}
+
+	opts := []server.Option{
+		server.WithHooksSource(hooksSource),
+		server.WithLowPower(lowPower),
+		server.WithSSEKeepalive(sseKeepalive),
+		server.WithHistory(stats.NewHistoryStore(config.GetHistoryPath())),
+		server.WithHookToken(hookToken),
+		server.WithAPIToken(apiToken),
+		server.WithBindAddress(bindAddr),
+		server.WithTLS(tlsCert, tlsKey),
+		server.WithVersion(version),
+		server.WithProjectNameFunc(live.ProjectNameFor),
+		server.WithConfig(live),
+		server.WithDaemonInfo(server.DaemonInfo{
+			ConfigFile:   configFilePath(),
+			ProjectsDir:  projectsDir,
+			Sources:      sourceNames,
+			JournalFile:  journalPath,
+			DaemonHealth: cfg.Notifications.DaemonHealthEnabled(),
+		}),
+	}
+
+	// Mutes and preferences set through the API survive restarts
+	prefsPath := config.GetPrefsPath()
+	if err := prefs.Move(config.GetOldPrefsPath(), prefsPath); err != nil {
+		logging.Logger().Warn("runtime preferences left at their previous location", "path", config.GetOldPrefsPath(), "error", err)
+		prefsPath = config.GetOldPrefsPath()
+	}
+	if store, err := prefs.Open(prefsPath); err != nil {
+		logging.Logger().Warn("runtime preferences will not be kept", "error", err)
+	} else {
+		opts = append(opts, server.WithPrefs(store))
 	}
 
-	if err := w.Start(); err != nil {
-		return fmt.Errorf("failed to start watcher: %w", err)
+	// Desktop notifications are opt-in for the daemon; --notify overrides
+	// the config file. Configured notifiers are used even when desktop
+	// notifications are off.
+	desktop := func(cfg *config.Config) bool {
+		if cmd.Flags().Changed("notify") {
+			return serveNotify
+		}
+		return cfg.Notifications.Desktop
+	}
+	statusNotifier := notifier.New()
+	statusNotifier.SetDesktopEnabled(desktop(cfg))
+	statusNotifier.SetDigest(cfg.Notifications.DigestWindow())
+	unattendedNotifier := notifier.New()
+	unattendedNotifier.SetDesktopEnabled(cfg.Notifications.UnattendedPermissions)
+	for _, n := range []*notifier.Notifier{statusNotifier, unattendedNotifier} {
+		n.SetTierFunc(live.TierFor)
+		n.SetProjectEnabledFunc(live.NotifyEnabledFor)
+		if err := n.Configure(cfg.Notifiers); err != nil {
+			return err
+		}
 	}
-	defer w.Stop()
+	opts = append(opts, server.WithNotifier(statusNotifier), server.WithUnattendedWarning(unattendedNotifier))
 
-	// Process watcher events in background
-	go func() {
-		for event := range w.Events() {
-			manager.Update(event.ProjectName, event.SessionID, event.Path)
+	// Apply what can change without a restart; Configure cannot fail on
+	// notifiers the validator accepted
+	live.OnReload(func(cfg *config.Config) {
+		redact.SetPatterns(cfg.Redaction.Patterns)
+		i18n.Set(i18n.Detect(cfg.Language))
+		manager.SetRetention(cfg.Retention.HideAfterDuration(), cfg.Retention.DeleteAfterDuration())
+		health.SetEnabled(cfg.Notifications.DaemonHealthEnabled())
+		health.Configure(cfg.Notifiers)
+		statusNotifier.SetDesktopEnabled(desktop(cfg))
+		statusNotifier.SetDigest(cfg.Notifications.DigestWindow())
+		statusNotifier.Configure(cfg.Notifiers)
+		unattendedNotifier.SetDesktopEnabled(cfg.Notifications.UnattendedPermissions)
+		unattendedNotifier.Configure(cfg.Notifiers)
+	})
+
+	if len(cfg.Shortcuts) > 0 {
+		bridge, err := shortcuts.New(cfg.Shortcuts)
+		if err != nil {
+			return err
 		}
-	}()
+		if shortcuts.Available() {
+			opts = append(opts, server.WithShortcuts(bridge))
+		} else {
+			logging.Logger().Warn("shortcuts configured but the shortcuts command is not available (macOS 12 or later required)")
+		}
+	}
 
 	// Create and start server
-	srv := server.New(serverPort, manager)
-	return srv.Start()
+	srv := server.New(serverPort, eng, opts...)
+
+	// Stop gracefully on Ctrl+C, so today's statistics are saved; the
+	// sources, the engine and the manager stop after the server
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := srv.Run(ctx); err != nil {
+		health.NotifyDaemonStopped(err.Error())
+		return err
+	}
+	return nil
 }
 
-func runInit(port int, force, yes, check, remove, keepScript bool) error {
-	installer := hooks.NewInstaller(port)
+// lockDaemon records this daemon in the lock file. With --replace, a
+// running daemon is stopped first. A lock file that cannot be written
+// only disables discovery; nil is returned then.
+func lockDaemon() (*daemon.Lock, error) {
+	path := config.GetDaemonLockPath()
+	info := daemon.Info{Port: serverPort, Bind: bindAddr, TLS: tlsCert != "", Version: version, StartedAt: time.Now()}
 
+	lock, err := daemon.Acquire(path, info)
+	var running *daemon.RunningError
+	if errors.As(err, &running) && replace {
+		fmt.Fprintf(os.Stderr, "Stopping the running daemon (pid %d)\n", running.Info.PID)
+		if err := daemon.Stop(path, running.Info, replaceTimeout); err != nil {
+			return nil, err
+		}
+		lock, err = daemon.Acquire(path, info)
+	}
+	if errors.As(err, &running) {
+		return nil, fmt.Errorf("%w; stop it or take over with 'serve --replace'", err)
+	}
+	if err != nil {
+		logging.Logger().Warn("daemon lock disabled, clients will not find this daemon", "path", path, "error", err)
+		return nil, nil
+	}
+	return lock, nil
+}
+
+// loadConfig loads the configuration file from --config or the default location
+func loadConfig() (*config.Config, error) {
+	return config.Load(configFilePath())
+}
+
+// applyTheme sets the colors of the terminal views from the configuration,
+// NO_COLOR, CLICOLOR and --no-color, and their icons from --icons
+func applyTheme(cfg *config.Config) error {
+	theme, err := cli.NewTheme(cfg.Colors, cli.ColorsEnabled(noColor))
+	if err != nil {
+		return err
+	}
+	cli.SetTheme(theme)
+	return applyIcons()
+}
+
+// applyIcons sets the status markers selected by --icons
+func applyIcons() error {
+	set, err := cli.ParseIconSet(iconSet)
+	if err != nil {
+		return err
+	}
+	cli.SetIcons(set)
+	return nil
+}
+
+// addIconsFlag adds the --icons flag
+func addIconsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&iconSet, "icons", "emoji", "Status markers: emoji, ascii ([RUN], [WAIT], [DONE]) or nerdfont")
+}
+
+// sortedAgentNames returns the configured agent names in sorted order
+func sortedAgentNames(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.Agents))
+	for name := range cfg.Agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// configFilePath returns the configuration file path from --config or the default location
+func configFilePath() string {
+	if configPath != "" {
+		return configPath
+	}
+	return config.GetConfigPath()
+}
+
+func runConfigInit(force, interactive bool) error {
+	path := configFilePath()
+
+	if _, err := os.Stat(path); err == nil && !force {
+		fmt.Printf("Config file already exists: %s\n", path)
+		fmt.Println("Use --force to overwrite, or 'config validate' to check it.")
+		return nil
+	}
+
+	cfg := config.DefaultConfig()
+
+	if interactive {
+		reader := bufio.NewReader(os.Stdin)
+		cfg.ProjectsDir = prompt(reader, "Claude projects directory", cfg.ProjectsDir)
+		if port, err := strconv.Atoi(prompt(reader, "Server port", strconv.Itoa(cfg.ServerPort))); err == nil {
+			cfg.ServerPort = port
+		}
+		if port, err := strconv.Atoi(prompt(reader, "Hooks port", strconv.Itoa(cfg.ServerPort))); err == nil {
+			cfg.HooksPort = port
+		}
+		if errs := cfg.Validate(); len(errs) > 0 {
+			return fmt.Errorf("invalid settings: %w", errors.Join(errs...))
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(config.GenerateTemplate(cfg)), 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Config file written: %s\n", path)
+	return nil
+}
+
+// prompt asks for a value on stdin, returning def if the answer is empty
+func prompt(reader *bufio.Reader, label, def string) string {
+	fmt.Printf("%s [%s]: ", label, def)
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(response)
+	if response == "" {
+		return def
+	}
+	return response
+}
+
+func runConfigValidate(args []string, probe bool) error {
+	path := configFilePath()
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	fmt.Printf("Config file: %s\n", path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		fmt.Println("Status: ⚠️  Not found (using defaults)")
+		data = []byte("{}")
+	}
+
+	cfg, err := config.Parse(data, true)
+	if err != nil {
+		fmt.Println("Status: ❌ Invalid")
+		fmt.Printf("  ❌ %v\n", err)
+		return fmt.Errorf("config validation failed")
+	}
+
+	errs := cfg.Validate()
+	for _, name := range sortedAgentNames(cfg) {
+		if _, ok := source.LookupParser(name); !ok {
+			errs = append(errs, fmt.Errorf("unknown agent %q (available: %s)", name, strings.Join(source.Parsers(), ", ")))
+		}
+	}
+	if _, err := shortcuts.New(cfg.Shortcuts); err != nil {
+		errs = append(errs, err)
+	}
+	if err := notifier.Validate(cfg.Notifiers); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := cli.NewTheme(cfg.Colors, false); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		fmt.Println("Status: ❌ Invalid")
+		for _, e := range errs {
+			fmt.Printf("  ❌ %v\n", e)
+		}
+		return fmt.Errorf("config validation failed")
+	}
+	fmt.Println("Status: ✅ Valid")
+
+	unreachable := 0
+	if probe {
+		fmt.Println()
+		fmt.Println("Notifier endpoints:")
+		results := notifier.Probe(context.Background(), cfg.Notifiers, 5*time.Second)
+		if len(results) == 0 {
+			fmt.Println("  (none to probe)")
+		}
+		for _, r := range results {
+			if r.Err != nil {
+				unreachable++
+				fmt.Printf("  ❌ notifiers[%d] %s %s: unreachable: %v\n", r.Index, r.Type, r.Host, r.Err)
+				continue
+			}
+			fmt.Printf("  ✅ notifiers[%d] %s %s: answered %d\n", r.Index, r.Type, r.Host, r.Status)
+		}
+	}
+
+	effective, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	fmt.Println("Effective configuration:")
+	fmt.Println(string(effective))
+
+	if unreachable > 0 {
+		return fmt.Errorf("%d notifier endpoint(s) unreachable", unreachable)
+	}
+	return nil
+}
+
+// runConfigImportProjects merges project mappings into the config file
+func runConfigImportProjects(mappingPath string, dryRun bool) error {
+	mappings, err := config.ReadProjectMappings(mappingPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", mappingPath, err)
+	}
+
+	path := configFilePath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		data = []byte(config.GenerateTemplate(config.DefaultConfig()))
+	}
+	cfg, err := config.Parse(data, false)
+	if err != nil {
+		return fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	result, errs := cfg.MergeProjects(mappings)
+	if len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Printf("  ❌ %v\n", e)
+		}
+		return fmt.Errorf("%d errors in %s, config not changed", len(errs), mappingPath)
+	}
+	if errs := cfg.Validate(); len(errs) > 0 {
+		return fmt.Errorf("imported config would be invalid: %w", errors.Join(errs...))
+	}
+
+	for _, name := range result.Added {
+		fmt.Printf("  + %s\n", name)
+	}
+	for _, name := range result.Updated {
+		fmt.Printf("  ~ %s\n", name)
+	}
+	if dryRun {
+		fmt.Printf("Would add %d and update %d projects in %s\n", len(result.Added), len(result.Updated), path)
+		return nil
+	}
+	if len(result.Added)+len(result.Updated) == 0 {
+		fmt.Printf("No changes to %s\n", path)
+		return nil
+	}
+
+	updated, err := config.SetProjects(data, cfg.Projects)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, updated, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Added %d and updated %d projects in %s\n", len(result.Added), len(result.Updated), path)
+	return nil
+}
+
+// runHistoryExport writes the statistics history in the given format
+func runHistoryExport(format, output string) error {
+	hist, err := stats.NewHistoryStore(config.GetHistoryPath()).Load()
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		return stats.WriteHistory(os.Stdout, hist, format)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := stats.WriteHistory(f, hist, format); err != nil {
+		f.Close()
+		os.Remove(output)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d days to %s\n", len(hist.Days), output)
+	return nil
+}
+
+// runHistoryImport merges an exported history into the statistics history
+func runHistoryImport(path string, overwrite bool) error {
+	in := os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	imported, err := stats.ReadHistory(in)
+	if err != nil {
+		return fmt.Errorf("invalid history export: %w", err)
+	}
+	n, err := stats.NewHistoryStore(config.GetHistoryPath()).Import(imported, overwrite)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d records from %d days\n", n, len(imported.Days))
+	return nil
+}
+
+// newInitInstaller returns the installer for ~/.claude, or for a project
+// when projectDir is set
+func newInitInstaller(endpoint hooks.Endpoint, projectDir string, local bool) (*hooks.Installer, error) {
+	if projectDir == "" {
+		if local {
+			return nil, fmt.Errorf("--local requires --project")
+		}
+		return hooks.NewInstaller(endpoint), nil
+	}
+	return hooks.NewProjectInstaller(endpoint, projectDir, local)
+}
+
+func runInit(installer *hooks.Installer, force, yes, check, remove, keepScript bool, transport hooks.Transport) error {
 	// Check mode
 	if check {
 		return runInitCheck(installer)
@@ -151,7 +1180,7 @@ func runInit(port int, force, yes, check, remove, keepScript bool) error {
 	}
 
 	// Install mode
-	return runInitInstall(installer, force, yes)
+	return runInitInstall(installer, force, yes, transport)
 }
 
 func runInitCheck(installer *hooks.Installer) error {
@@ -162,10 +1191,15 @@ func runInitCheck(installer *hooks.Installer) error {
 
 	fmt.Println("Claude Watch Status - Hooks Configuration Check")
 	fmt.Println()
+	if result.ProjectDir != "" {
+		fmt.Printf("Project: %s\n", result.ProjectDir)
+	}
 	fmt.Printf("Settings file: %s\n", result.SettingsPath)
 
 	if result.Installed {
 		fmt.Println("Status: ✅ Installed")
+		fmt.Printf("Transport: %s\n", result.Transport)
+		fmt.Printf("Command: %s\n", result.Command)
 	} else {
 		fmt.Println("Status: ❌ Not installed")
 	}
@@ -179,14 +1213,25 @@ func runInitCheck(installer *hooks.Installer) error {
 		fmt.Printf("  ❌ %s\n", event)
 	}
 
-	fmt.Println()
-	fmt.Printf("Hook script: %s\n", result.ScriptPath)
-	if result.ScriptExists {
-		if result.ScriptExecutable {
-			fmt.Println("Status: ✅ Exists (executable)")
+	// The binary transport runs this executable and needs no script
+	if result.Transport != hooks.TransportBinary {
+		fmt.Println()
+		fmt.Printf("Hook script: %s\n", result.ScriptPath)
+		if result.ScriptExists {
+			if result.ScriptExecutable {
+				fmt.Println("Status: ✅ Exists (executable)")
+			} else {
+				fmt.Println("Status: ⚠️  Exists (not executable)")
+			}
 		} else {
-			fmt.Println("Status: ⚠️  Exists (not executable)")
+			fmt.Println("Status: ❌ Not found")
 		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Hook token: %s\n", result.TokenPath)
+	if result.TokenExists {
+		fmt.Println("Status: ✅ Exists")
 	} else {
 		fmt.Println("Status: ❌ Not found")
 	}
@@ -222,7 +1267,7 @@ func runInitRemove(installer *hooks.Installer, yes, keepScript bool) error {
 	return nil
 }
 
-func runInitInstall(installer *hooks.Installer, force, yes bool) error {
+func runInitInstall(installer *hooks.Installer, force, yes bool, transport hooks.Transport) error {
 	// Check current status
 	result, err := installer.Check()
 	if err != nil {
@@ -252,7 +1297,8 @@ func runInitInstall(installer *hooks.Installer, force, yes bool) error {
 	}
 
 	opts := hooks.InstallOptions{
-		Force: force,
+		Force:     force,
+		Transport: transport,
 	}
 
 	if err := installer.Install(opts); err != nil {
@@ -269,3 +1315,19 @@ func runInitInstall(installer *hooks.Installer, force, yes bool) error {
 
 	return nil
 }
+
+// dumpState writes the current status of every project to the log
+func dumpState(manager *state.Manager) {
+	statuses := manager.GetAll()
+	logging.Logger().Info("state dump", "projects", len(statuses))
+	for _, status := range statuses {
+		logging.Logger().Info("state dump",
+			"project", status.Name,
+			"state", status.State,
+			"source", status.Source,
+			"session", status.SessionID,
+			"updated_at", status.UpdatedAt.Format(time.RFC3339),
+			"estimated", status.IsEstimated,
+		)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+import "github.com/sho7650/claude-watch-status/internal/state"
+
+// handleDebugSignals is a no-op on platforms without SIGUSR1/SIGUSR2;
+// use POST /api/loglevel instead
+func handleDebugSignals(manager *state.Manager) {}
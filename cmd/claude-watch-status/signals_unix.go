@@ -0,0 +1,31 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// handleDebugSignals installs SIGUSR1 (toggle debug logging) and
+// SIGUSR2 (dump current state to the log) handlers
+func handleDebugSignals(manager *state.Manager) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for sig := range sigCh {
+			switch sig {
+			case syscall.SIGUSR1:
+				level := logging.ToggleDebug()
+				logging.Logger().Info("log level changed", "level", level.String(), "via", "SIGUSR1")
+			case syscall.SIGUSR2:
+				dumpState(manager)
+			}
+		}
+	}()
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/i18n"
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// tierBadge returns a colored badge for non-normal project tiers
+func tierBadge(tier string) string {
+	switch config.Tier(tier) {
+	case config.TierCritical:
+		return " " + paint("31", "[critical]")
+	case config.TierBackground:
+		return " " + dim("[background]")
+	default:
+		return ""
+	}
+}
+
+// modelBadge names the model family of a session, colored by cost
+func modelBadge(env *state.Environment) string {
+	family := env.ModelFamily()
+	switch family {
+	case "":
+		return ""
+	case "opus":
+		return " " + paint("35", "[opus]")
+	case "haiku":
+		return " " + paint("32", "[haiku]")
+	default:
+		return " " + paint("34", "["+family+"]")
+	}
+}
+
+// permissionBadge warns about sessions that run tools without approval,
+// and marks sessions in plan mode or accepting edits
+func permissionBadge(env *state.Environment) string {
+	if env == nil {
+		return ""
+	}
+	switch env.PermissionMode {
+	case state.PermissionBypass:
+		return " " + paint("33", "[⚠️ unattended-permissions]")
+	case state.PermissionPlan:
+		return " " + paint("36", "[🛡️ plan]")
+	case state.PermissionAcceptEdits:
+		return " " + paint("34", "[📝 accept-edits]")
+	default:
+		return ""
+	}
+}
+
+// detailSuffix returns a dimmed tool detail (file, command, URL) to
+// follow a state
+func detailSuffix(detail string) string {
+	if detail == "" {
+		return ""
+	}
+	return " " + dim(detail)
+}
+
+// statusDetail returns what a project waiting for approval asks
+// permission for, or else what its current tool works on
+func statusDetail(status state.ProjectStatus) string {
+	if status.Request != "" {
+		return status.Request
+	}
+	return status.Detail
+}
+
+// subagentLabel names a subagent by its task description, type or ID
+func subagentLabel(sub state.SubagentStatus) string {
+	switch {
+	case sub.Description != "":
+		return sub.Description
+	case sub.Type != "":
+		return sub.Type
+	default:
+		return sub.ID
+	}
+}
+
+// focusHint tells how to raise the terminal of a project waiting for
+// approval, when the hook relay reported it
+func focusHint(status *state.ProjectStatus) string {
+	if state.PhaseOf(status.State) != state.PhaseWaiting || status.Environment == nil || !status.Environment.Terminal.Focusable() {
+		return ""
+	}
+	name := status.Name
+	if strings.ContainsAny(name, " '\"$`\\") {
+		name = "'" + strings.ReplaceAll(name, "'", `'\''`) + "'"
+	}
+	return " " + dim("→ "+i18n.T("cli.focus_hint", name))
+}
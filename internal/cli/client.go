@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/state"
+	"github.com/sho7650/claude-watch-status/pkg/client"
+	"github.com/sho7650/claude-watch-status/pkg/protocol"
+)
+
+// ErrDaemonUnreachable is returned by client commands when no daemon answers
+var ErrDaemonUnreachable = errors.New("daemon is not running (start it with 'claude-watch-status serve')")
+
+// attentionPhases are the phases in which a project waits for the user
+var attentionPhases = []state.Phase{state.PhaseWaiting, state.PhaseInterrupted, state.PhaseError}
+
+// DefaultWaitPhases are the phases wait stops at by default: the session
+// needs the user or has finished its turn
+var DefaultWaitPhases = []string{"waiting", "completed", "interrupted", "error", "ended"}
+
+// NewClient returns a client for the daemon at endpoint; insecure skips
+// verifying its TLS certificate
+func NewClient(endpoint, token string, insecure bool) *client.Client {
+	c := client.New(endpoint, token)
+	if insecure {
+		c.SetInsecureTLS()
+	}
+	return c
+}
+
+// Connect returns a client for the daemon, or ErrDaemonUnreachable;
+// insecure skips verifying its TLS certificate
+func Connect(endpoint, token string, insecure bool) (*client.Client, error) {
+	c := NewClient(endpoint, token, insecure)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.Health(ctx); err != nil {
+		return nil, ErrDaemonUnreachable
+	}
+	return c, nil
+}
+
+// RunStatus prints the status of the given projects, or of all projects
+func RunStatus(w io.Writer, c *client.Client, projects []string, asJSON bool) error {
+	snapshot, err := c.Status(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(projects) > 0 {
+		selected := snapshot.Projects[:0]
+		for _, p := range snapshot.Projects {
+			if slices.Contains(projects, p.Name) {
+				selected = append(selected, p)
+			}
+		}
+		if len(selected) == 0 {
+			return fmt.Errorf("unknown project %q", strings.Join(projects, ", "))
+		}
+		snapshot.Projects = selected
+	}
+	slices.SortFunc(snapshot.Projects, func(a, b protocol.ProjectStatus) int { return strings.Compare(a.Name, b.Name) })
+
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snapshot)
+	}
+	if len(snapshot.Projects) == 0 {
+		fmt.Fprintln(w, "No projects.")
+		return nil
+	}
+	for _, p := range snapshot.Projects {
+		status := StatusFromProtocol(p, "")
+		printStatus(&status)
+	}
+	return nil
+}
+
+// RunAttention prints the projects waiting for the user: for approval,
+// after an interruption or an error. Reports whether there were any.
+func RunAttention(w io.Writer, c *client.Client) (bool, error) {
+	snapshot, err := c.Status(context.Background())
+	if err != nil {
+		return false, err
+	}
+	slices.SortFunc(snapshot.Projects, func(a, b protocol.ProjectStatus) int { return strings.Compare(a.Name, b.Name) })
+
+	found := false
+	for _, p := range snapshot.Projects {
+		if !slices.Contains(attentionPhases, state.PhaseOf(p.State)) {
+			continue
+		}
+		found = true
+		status := StatusFromProtocol(p, "")
+		printStatus(&status)
+	}
+	return found, nil
+}
+
+// WaitOptions configures RunWait
+type WaitOptions struct {
+	Project string
+	Phases  []string      // phase names (waiting, completed, ...); empty = DefaultWaitPhases
+	Timeout time.Duration // 0 = wait forever
+}
+
+// errWaitDone stops the stream once the awaited state is reached
+var errWaitDone = errors.New("wait done")
+
+// RunWait blocks until a project enters one of the given phases, then
+// prints its status. A project already in such a phase returns at once.
+func RunWait(c *client.Client, opts WaitOptions) error {
+	names := opts.Phases
+	if len(names) == 0 {
+		names = DefaultWaitPhases
+	}
+	phases, err := parsePhases(names)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	var reached *state.ProjectStatus
+	check := func(p protocol.ProjectStatus, cause string) error {
+		if p.Name != opts.Project || !slices.Contains(phases, state.PhaseOf(p.State)) {
+			return nil
+		}
+		status := StatusFromProtocol(p, cause)
+		reached = &status
+		return errWaitDone
+	}
+	err = Follow(ctx, c, client.StreamOptions{Projects: []string{opts.Project}}, func(ev client.Event) error {
+		if ev.Snapshot != nil {
+			for _, p := range ev.Snapshot.Projects {
+				if err := check(p, ""); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if ev.Update == nil {
+			return nil
+		}
+		return check(ev.Update.ProjectStatus, ev.Update.Cause)
+	})
+	if reached != nil {
+		printStatus(reached)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s waiting for %s to be %s", opts.Timeout, opts.Project, strings.Join(names, ", "))
+	}
+	return fmt.Errorf("interrupted")
+}
+
+// parsePhases converts phase names to phases
+func parsePhases(names []string) ([]state.Phase, error) {
+	phases := make([]state.Phase, 0, len(names))
+	for _, name := range names {
+		phase, ok := state.ParsePhase(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown state %q (want started, user_input, working, waiting, completed, interrupted, error or ended)", name)
+		}
+		phases = append(phases, phase)
+	}
+	return phases, nil
+}
+
+// RunMute silences the notifications of a project for d (0 = until
+// unmuted), or restores them if off is set
+func RunMute(w io.Writer, c *client.Client, project string, d time.Duration, off bool) error {
+	ctx := context.Background()
+	if off {
+		if err := c.Unmute(ctx, project); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "🔔 %s unmuted\n", project)
+		return nil
+	}
+	mute, err := c.Mute(ctx, project, d)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "🔕 %s muted %s\n", project, muteUntil(*mute))
+	return nil
+}
+
+// RunFocus raises the terminal window of a project's session
+func RunFocus(w io.Writer, c *client.Client, project string) error {
+	status, err := c.Focus(context.Background(), project)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Raised the terminal of %s (%s)\n", project, status.State)
+	return nil
+}
+
+// RunMutes lists the muted projects
+func RunMutes(w io.Writer, c *client.Client) error {
+	mutes, err := c.Mutes(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(mutes) == 0 {
+		fmt.Fprintln(w, "No muted projects.")
+		return nil
+	}
+	for _, mute := range mutes {
+		fmt.Fprintf(w, "🔕 %-15s %s\n", mute.Project, muteUntil(mute))
+	}
+	return nil
+}
+
+func muteUntil(mute protocol.Mute) string {
+	if mute.Until.IsZero() {
+		return "until unmuted"
+	}
+	return "until " + mute.Until.Local().Format("15:04:05")
+}
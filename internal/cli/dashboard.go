@@ -2,140 +2,161 @@ package cli
 
 import (
 	"fmt"
-	"os"
-	"os/signal"
-	"sort"
-	"syscall"
 	"time"
 
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/engine"
 	"github.com/sho7650/claude-watch-status/internal/notifier"
 	"github.com/sho7650/claude-watch-status/internal/state"
 	"github.com/sho7650/claude-watch-status/internal/watcher"
 )
 
+// dashboardRefresh is how often the dashboard redraws without status
+// changes, counting up the time active states last
+const dashboardRefresh = time.Second
+
 // DashboardMode runs the CLI in dashboard mode
 type DashboardMode struct {
-	projectsDir string
-	notifier    *notifier.Notifier
-	manager     *state.Manager
-	notified    map[string]bool
+	engine   *engine.Engine
+	notifier *notifier.Notifier
+	view     *dashboardView
 }
 
 // NewDashboardMode creates a new DashboardMode
 func NewDashboardMode(projectsDir string) *DashboardMode {
-	return &DashboardMode{
-		projectsDir: projectsDir,
-		notifier:    notifier.New(),
-		manager:     state.NewManager(),
-		notified:    make(map[string]bool),
+	d := &DashboardMode{
+		engine:   engine.New(projectsDir),
+		notifier: notifier.New(),
+		view:     newDashboardView(projectsDir),
 	}
+	d.engine.SetNotifier(d.notifier)
+	return d
 }
 
-// Run starts the dashboard mode
-func (d *DashboardMode) Run() error {
-	// Clear screen and print header
-	fmt.Print("\033[2J\033[H") // Clear screen and move to top-left
-	fmt.Println("Claude Code Status (Ctrl+C to stop)")
-	fmt.Println("────────────────────────────────────────")
-
-	w, err := watcher.New(d.projectsDir)
-	if err != nil {
-		return fmt.Errorf("failed to create watcher: %w", err)
-	}
-
-	if err := w.Start(); err != nil {
-		return fmt.Errorf("failed to start watcher: %w", err)
-	}
-	defer w.Stop()
+// SetWatchMode selects how log changes are detected
+func (d *DashboardMode) SetWatchMode(mode watcher.Mode) {
+	d.engine.SetWatchMode(mode)
+}
 
-	// Set up signal handling
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+// SetLayout selects how much the dashboard rows show
+func (d *DashboardMode) SetLayout(layout DashboardLayout) {
+	d.view.layout = layout
+}
 
-	// Start idle detection goroutine
-	idleTicker := time.NewTicker(5 * time.Second)
-	defer idleTicker.Stop()
+// SetNotifiers adds the notification backends configured next to
+// desktop notifications
+func (d *DashboardMode) SetNotifiers(cfgs []config.NotifierConfig) error {
+	return d.notifier.Configure(cfgs)
+}
 
-	for {
-		select {
-		case <-sigCh:
-			fmt.Println()
-			fmt.Println("Stopped.")
-			return nil
+// SetNotifyInterrupted enables or disables notifications for interruptions
+func (d *DashboardMode) SetNotifyInterrupted(enabled bool) {
+	d.notifier.SetInterruptedEnabled(enabled)
+}
 
-		case event := <-w.Events():
-			d.handleEvent(event)
+// ApplyConfig applies per-project settings (tiers, names, notification
+// enable flags), the retention and the notification digest from the
+// configuration file
+func (d *DashboardMode) ApplyConfig(cfg *config.Config) {
+	d.engine.ApplyConfig(cfg)
+	d.notifier.SetTierFunc(cfg.TierFor)
+	d.notifier.SetProjectEnabledFunc(cfg.NotifyEnabledFor)
+	d.notifier.SetDigest(cfg.Notifications.DigestWindow())
+}
 
-		case err := <-w.Errors():
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+// Run starts the dashboard mode
+func (d *DashboardMode) Run() error {
+	drawDashboardHeader()
 
-		case <-idleTicker.C:
-			d.checkIdleProjects()
-		}
+	if err := d.engine.Watch(); err != nil {
+		return err
 	}
-}
+	defer d.engine.Stop()
 
-func (d *DashboardMode) handleEvent(event watcher.Event) {
-	status, err := d.manager.Update(event.ProjectName, event.SessionID, event.Path)
-	if err != nil || status == nil {
-		return
+	// Show existing sessions instead of waiting for writes
+	manager := d.engine.Manager()
+	eventCh := manager.Subscribe()
+	defer manager.Unsubscribe(eventCh)
+	if len(manager.GetAll()) > 0 {
+		d.redraw()
 	}
+	d.engine.Start()
+
+	// Redraw periodically too, so elapsed times count up and projects
+	// past their retention disappear
+	ticker := time.NewTicker(dashboardRefresh)
+	defer ticker.Stop()
 
-	d.redraw()
+	followLocal(eventCh, ticker.C, func(state.StatusEvent) { d.redraw() }, d.redraw)
+	return nil
 }
 
 func (d *DashboardMode) redraw() {
-	statuses := d.manager.GetAll()
-
-	// Sort by project name for consistent ordering
-	sort.Slice(statuses, func(i, j int) bool {
-		return statuses[i].Name < statuses[j].Name
-	})
-
-	// Move cursor to line 3 (after header)
-	fmt.Print("\033[3;1H")
+	d.view.draw(d.engine.Manager().GetVisible())
+}
 
-	for _, status := range statuses {
-		ts := status.UpdatedAt.Format("15:04:05")
-		// Add uncertainty indicator if state is estimated
-		icon := status.Icon
-		if status.IsEstimated {
-			icon = status.Icon + "❓"
-		}
-		// Format: [project     ] icon [timestamp] state
-		fmt.Printf("[%-12s] %s \033[90m[%s]\033[0m %-20s\033[K\n",
-			status.Name, icon, ts, status.State)
-	}
+// stateClock remembers when each project entered its current state.
+// Status times move on with every log entry repeating the state, so they
+// cannot tell how long a state has lasted.
+type stateClock struct {
+	seen map[string]clockEntry
+}
 
-	// Clear any remaining lines
-	fmt.Print("\033[J")
+// clockEntry is the state a project was last drawn with
+type clockEntry struct {
+	state     string
+	sessionID string
+	since     time.Time
 }
 
-func (d *DashboardMode) checkIdleProjects() {
-	events := d.manager.CheckIdleProjects(5 * time.Second)
+// newStateClock creates an empty stateClock
+func newStateClock() *stateClock {
+	return &stateClock{seen: make(map[string]clockEntry)}
+}
 
-	for _, event := range events {
-		// Create a unique key for this idle event
-		key := fmt.Sprintf("%s:%s:%s", event.Project.FilePath, event.Project.FileTime, event.Type)
-		if d.notified[key] {
-			continue
-		}
-		d.notified[key] = true
+// since returns when an active state began; ok is false for states that
+// are over, like completed. A state counts from the update that entered
+// it, a running state from when the tool started.
+func (c *stateClock) since(status state.ProjectStatus) (since time.Time, ok bool) {
+	e, seen := c.seen[status.Name]
+	if !seen || e.state != status.State || e.sessionID != status.SessionID {
+		e = clockEntry{state: status.State, sessionID: status.SessionID, since: status.UpdatedAt}
+		c.seen[status.Name] = e
+	}
 
-		// Update the manager's state
-		d.manager.MarkIdle(event.Project.Name, event.Project.Icon, event.Project.State, event.Project.IsEstimated)
+	switch state.PhaseOf(status.State) {
+	case state.PhaseUserInput, state.PhaseWorking, state.PhaseWaiting:
+	default:
+		return time.Time{}, false
+	}
+	if !status.ToolStartedAt.IsZero() {
+		return status.ToolStartedAt, true
+	}
+	return e.since, true
+}
 
-		// Send notification
-		switch event.Type {
-		case "idle_approval":
-			d.notifier.NotifyWaitingApproval(event.Project.Name)
-		case "idle_completed":
-			d.notifier.NotifyCompleted(event.Project.Name)
+// prune forgets projects no longer shown
+func (c *stateClock) prune(statuses []state.ProjectStatus) {
+	shown := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		shown[status.Name] = true
+	}
+	for name := range c.seen {
+		if !shown[name] {
+			delete(c.seen, name)
 		}
 	}
+}
 
-	// Always redraw to update timestamps
-	if len(d.manager.GetAll()) > 0 {
-		d.redraw()
+// formatElapsed formats a running time to the second, as in "1m23s"
+func formatElapsed(d time.Duration) string {
+	d = max(d, 0).Truncate(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
 	}
 }
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// IconSet selects the status markers of the terminal views
+type IconSet int
+
+const (
+	// IconsEmoji shows the status icons as they are: 🔧, ⏸️, ✅, ...
+	IconsEmoji IconSet = iota
+	// IconsASCII shows markers such as [RUN], [WAIT] and [DONE], for
+	// terminals without emoji fonts
+	IconsASCII
+	// IconsNerdFont shows Nerd Font glyphs, which take one column
+	IconsNerdFont
+)
+
+// ParseIconSet returns the icon set named by --icons
+func ParseIconSet(name string) (IconSet, error) {
+	switch name {
+	case "", "emoji":
+		return IconsEmoji, nil
+	case "ascii":
+		return IconsASCII, nil
+	case "nerdfont":
+		return IconsNerdFont, nil
+	default:
+		return IconsEmoji, fmt.Errorf("unknown icon set %q (want ascii, emoji or nerdfont)", name)
+	}
+}
+
+// iconNames names the status icons; markers are looked up by name
+var iconNames = map[string]string{
+	"👤":  "user",
+	"⏳":  "processing",
+	"🤔":  "thinking",
+	"🔧":  "tool",
+	"⏸️": "waiting",
+	"✅":  "done",
+	"⚠️": "warning",
+	"🛑":  "stopped",
+	"🔄":  "continuing",
+	"💤":  "idle",
+	"🤖":  "agent",
+	"🗑":  "removed",
+}
+
+// phaseIcons names the marker of states with an icon not listed in
+// iconNames, such as the ❓ of estimated states
+var phaseIcons = map[state.Phase]string{
+	state.PhaseStarted:     "agent",
+	state.PhaseUserInput:   "user",
+	state.PhaseWorking:     "processing",
+	state.PhaseWaiting:     "waiting",
+	state.PhaseCompleted:   "done",
+	state.PhaseInterrupted: "stopped",
+	state.PhaseError:       "warning",
+	state.PhaseEnded:       "idle",
+}
+
+// asciiIcons are the markers of IconsASCII, padded to one width so
+// columns stay aligned
+var asciiIcons = map[string]string{
+	"user":       "[USER]",
+	"processing": "[PROC]",
+	"thinking":   "[THNK]",
+	"tool":       "[RUN] ",
+	"waiting":    "[WAIT]",
+	"done":       "[DONE]",
+	"warning":    "[WARN]",
+	"stopped":    "[STOP]",
+	"continuing": "[CONT]",
+	"idle":       "[IDLE]",
+	"agent":      "[AGNT]",
+	"removed":    "[GONE]",
+	"":           "[----]",
+}
+
+// nerdFontIcons are the markers of IconsNerdFont (Font Awesome glyphs)
+var nerdFontIcons = map[string]string{
+	"user":       "\uf007", // user
+	"processing": "\uf252", // hourglass-half
+	"thinking":   "\uf0eb", // lightbulb
+	"tool":       "\uf0ad", // wrench
+	"waiting":    "\uf04c", // pause
+	"done":       "\uf00c", // check
+	"warning":    "\uf071", // exclamation-triangle
+	"stopped":    "\uf04d", // stop
+	"continuing": "\uf021", // refresh
+	"idle":       "\uf186", // moon
+	"agent":      "\uf0e8", // sitemap
+	"removed":    "\uf1f8", // trash
+	"":           "\uf128", // question
+}
+
+// icons selects the markers of the terminal views; see SetIcons
+var icons = IconsEmoji
+
+// SetIcons sets the status markers of the stream, dashboard, statusline
+// and tmux window names
+func SetIcons(set IconSet) {
+	icons = set
+}
+
+// marker returns the marker of a state in the selected icon set. An
+// estimated state is marked with a question mark.
+func marker(icon, stateText string, estimated bool) string {
+	if icons == IconsEmoji {
+		if estimated {
+			return icon + "❓"
+		}
+		return icon
+	}
+
+	name, ok := iconNames[icon]
+	if !ok {
+		name = phaseIcons[state.PhaseOf(stateText)]
+	}
+	if icons == IconsNerdFont {
+		m := nerdFontIcons[name]
+		if estimated {
+			m += nerdFontIcons[""]
+		}
+		return m
+	}
+	m := asciiIcons[name]
+	if estimated {
+		m += "?"
+	}
+	return m
+}
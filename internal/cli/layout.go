@@ -0,0 +1,289 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sho7650/claude-watch-status/internal/i18n"
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// DashboardLayout selects how much a dashboard row shows
+type DashboardLayout int
+
+const (
+	// LayoutAuto picks a layout by the terminal width
+	LayoutAuto DashboardLayout = iota
+	// LayoutCompact shows the icon, name and elapsed time, for narrow
+	// terminals
+	LayoutCompact
+	// LayoutNormal adds the update time, state, badges and detail
+	LayoutNormal
+	// LayoutWide adds token usage and the branch
+	LayoutWide
+)
+
+// Terminal widths LayoutAuto switches layouts at
+const (
+	compactBelow = 60
+	wideFrom     = 160
+)
+
+// Name column widths; names longer than the limit are shortened
+const (
+	minNameWidth     = 12
+	maxNameWidth     = 24
+	maxWideNameWidth = 32
+)
+
+// dashboardView draws the dashboard rows
+type dashboardView struct {
+	layout DashboardLayout
+	clock  *stateClock
+	tokens *tokenCounter
+	width  func() int // terminal columns, 0 = unknown
+}
+
+// newDashboardView creates a view; the token usage of the wide layout is
+// read from session logs under projectsDir
+func newDashboardView(projectsDir string) *dashboardView {
+	return &dashboardView{
+		clock:  newStateClock(),
+		tokens: newTokenCounter(projectsDir),
+		width:  terminalWidth,
+	}
+}
+
+// drawDashboardHeader clears the screen and prints the dashboard header
+func drawDashboardHeader() {
+	fmt.Print("\033[2J\033[H") // Clear screen and move to top-left
+	fmt.Println(i18n.T("cli.dashboard"))
+	fmt.Println("────────────────────────────────────────")
+}
+
+// draw prints one line per project below the header. Rows are cut to
+// the terminal width, so they never wrap.
+func (v *dashboardView) draw(statuses []state.ProjectStatus) {
+	// Sort by project name for consistent ordering
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	v.clock.prune(statuses)
+
+	width := v.width()
+	layout := v.layout
+	if layout == LayoutAuto {
+		layout = autoLayout(width)
+	}
+	if layout == LayoutWide {
+		v.tokens.prune(statuses)
+	}
+
+	longest := 0
+	for _, status := range statuses {
+		longest = max(longest, len([]rune(status.Name)))
+	}
+	nameWidth := min(max(longest, minNameWidth), maxNameWidth)
+	switch {
+	case layout == LayoutWide:
+		nameWidth = min(max(longest, minNameWidth), maxWideNameWidth)
+	case layout == LayoutCompact && width > 0:
+		nameWidth = max(min(longest, width-12), 4)
+	}
+
+	// Move cursor to line 3 (after header)
+	fmt.Print("\033[3;1H")
+
+	now := time.Now()
+	for _, status := range statuses {
+		var lines []string
+		switch layout {
+		case LayoutCompact:
+			lines = []string{v.compactRow(status, nameWidth, now)}
+		default:
+			lines = append([]string{v.row(status, layout, nameWidth, width, now)}, subagentRows(status)...)
+		}
+		for _, line := range lines {
+			if width > 0 {
+				line = cutToWidth(line, width)
+			}
+			fmt.Print(line + "\033[K\n")
+		}
+	}
+
+	// Clear any remaining lines
+	fmt.Print("\033[J")
+}
+
+// autoLayout returns the layout fitting a terminal width
+func autoLayout(width int) DashboardLayout {
+	switch {
+	case width <= 0:
+		return LayoutNormal
+	case width < compactBelow:
+		return LayoutCompact
+	case width >= wideFrom:
+		return LayoutWide
+	default:
+		return LayoutNormal
+	}
+}
+
+// compactRow formats a project as its icon, name, elapsed time and the
+// icons of its subagents
+func (v *dashboardView) compactRow(status state.ProjectStatus, nameWidth int, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s", statusIcon(status), paintState(status.State, fmt.Sprintf("%-*s", nameWidth, truncate(status.Name, nameWidth))))
+	if since, ok := v.clock.since(status); ok {
+		b.WriteString(" " + formatElapsed(now.Sub(since)))
+	}
+	if len(status.Subagents) > 0 {
+		b.WriteString(" ")
+		for _, sub := range status.Subagents {
+			b.WriteString(marker(sub.Icon, sub.State, false))
+		}
+	}
+	return b.String()
+}
+
+// row formats a project in the normal or wide layout. The detail is
+// shortened to what fits the terminal.
+func (v *dashboardView) row(status state.ProjectStatus, layout DashboardLayout, nameWidth, width int, now time.Time) string {
+	elapsed := ""
+	if since, ok := v.clock.since(status); ok {
+		elapsed = i18n.T("cli.elapsed", formatElapsed(now.Sub(since)))
+	}
+
+	// Format: [project     ] icon [timestamp] state elapsed [tokens] [model] [tier] [unattended-permissions] [branch] detail
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%-*s] %s %s %s %s",
+		nameWidth, truncate(status.Name, nameWidth), statusIcon(status), dim("["+status.UpdatedAt.Format("15:04:05")+"]"),
+		paintState(status.State, padRight(i18n.State(status.State), 20)), padRight(elapsed, elapsedWidth()))
+	if layout == LayoutWide {
+		tokens := "-"
+		if u, ok := v.tokens.usage(status, now); ok {
+			tokens = formatTokens(u.TotalInput()) + "/" + formatTokens(u.Output)
+		}
+		fmt.Fprintf(&b, " %13s", tokens)
+	}
+	b.WriteString(modelBadge(status.Environment) + tierBadge(status.Tier) + permissionBadge(status.Environment))
+	if layout == LayoutWide && status.Branch != "" {
+		b.WriteString(" " + dim("⎇ "+status.Branch))
+	}
+
+	detail := statusDetail(status)
+	if room := width - displayWidth(b.String()) - 1; width > 0 && detail != "" {
+		if room < 8 {
+			detail = ""
+		} else {
+			detail = truncate(detail, room)
+		}
+	}
+	return b.String() + detailSuffix(detail)
+}
+
+// subagentRows formats the subagents of a project, one per line
+func subagentRows(status state.ProjectStatus) []string {
+	rows := make([]string, 0, len(status.Subagents))
+	for _, sub := range status.Subagents {
+		rows = append(rows, fmt.Sprintf("  ↳ %-10s %s %s", subagentLabel(sub), marker(sub.Icon, sub.State, false), paintState(sub.State, i18n.State(sub.State))))
+	}
+	return rows
+}
+
+// statusIcon returns the marker of a project, see marker
+func statusIcon(status state.ProjectStatus) string {
+	return marker(status.Icon, status.State, status.IsEstimated)
+}
+
+// terminalWidth returns the column count of the terminal, from stdout or
+// $COLUMNS; 0 if unknown
+func terminalWidth() int {
+	if w, _ := stdoutTTY(); w > 0 {
+		return w
+	}
+	w, _ := strconv.Atoi(os.Getenv("COLUMNS"))
+	return max(w, 0)
+}
+
+// ansiEscape matches the color sequences of dashboard rows
+var ansiEscape = regexp.MustCompile(`\033\[[0-9;]*[A-Za-z]`)
+
+// displayWidth estimates the terminal columns s takes: color sequences
+// take none, emoji and East Asian wide characters two
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range ansiEscape.ReplaceAllString(s, "") {
+		w += runeWidth(r)
+	}
+	return w
+}
+
+// elapsedWidth is the width of the elapsed time column, fitting up to
+// "59m59s" in the selected language
+func elapsedWidth() int {
+	return displayWidth(i18n.T("cli.elapsed", "00m00s"))
+}
+
+// padRight pads s with spaces to width columns, counting wide characters
+// twice
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-displayWidth(s), 0))
+}
+
+// runeWidth estimates the terminal columns of a character
+func runeWidth(r rune) int {
+	switch {
+	case r == 0xFE0F || r == 0x200D: // emoji presentation selector, joiner
+		return 0
+	case r >= 0x1F000,
+		r >= 0x2600 && r < 0x2800,
+		r >= 0x23E9 && r <= 0x23FA, // ⏩ ... ⏺, including ⏳ and ⏸
+		r >= 0x1100 && r <= 0x115F,
+		r >= 0x2E80 && r <= 0xA4CF,
+		r >= 0xAC00 && r <= 0xD7A3,
+		r >= 0xF900 && r <= 0xFAFF,
+		r >= 0xFF00 && r <= 0xFF60,
+		r >= 0xFFE0 && r <= 0xFFE6:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// cutToWidth shortens a row to width columns, keeping color sequences so
+// colors are reset
+func cutToWidth(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	var b strings.Builder
+	w := 0
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "\033[") {
+			if loc := ansiEscape.FindStringIndex(s[i:]); loc != nil && loc[0] == 0 {
+				b.WriteString(s[i : i+loc[1]])
+				i += loc[1]
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w += runeWidth(r)
+		if w > width-1 { // room for the ellipsis
+			break
+		}
+		b.WriteRune(r)
+		i += size
+	}
+	b.WriteString("…")
+	if theme.enabled {
+		b.WriteString("\033[0m")
+	}
+	return b.String()
+}
@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/i18n"
+	"github.com/sho7650/claude-watch-status/internal/notifier"
+	"github.com/sho7650/claude-watch-status/internal/state"
+	"github.com/sho7650/claude-watch-status/pkg/client"
+	"github.com/sho7650/claude-watch-status/pkg/protocol"
+)
+
+// reconnectDelay is how long remote modes wait before reconnecting to the
+// daemon after the event stream dropped
+const reconnectDelay = 2 * time.Second
+
+// RemoteMode runs the stream or dashboard view on the daemon's event
+// stream instead of watching session logs itself
+type RemoteMode struct {
+	client    *client.Client
+	notifier  *notifier.Notifier
+	dashboard bool
+
+	mu       sync.Mutex // guards statuses and view between events and redraws
+	statuses map[string]state.ProjectStatus
+	view     *dashboardView
+}
+
+// NewRemoteMode creates a RemoteMode; dashboard selects the dashboard view
+func NewRemoteMode(c *client.Client, dashboard bool) *RemoteMode {
+	return &RemoteMode{
+		client:    c,
+		notifier:  notifier.New(),
+		dashboard: dashboard,
+		statuses:  make(map[string]state.ProjectStatus),
+		view:      newDashboardView(config.GetProjectsDir()),
+	}
+}
+
+// SetLayout selects how much the dashboard rows show
+func (r *RemoteMode) SetLayout(layout DashboardLayout) {
+	r.view.layout = layout
+}
+
+// SetProjectsDir sets where the session logs the wide layout reads token
+// usage from are; by default $CLAUDE_PROJECTS_DIR or ~/.claude/projects
+func (r *RemoteMode) SetProjectsDir(dir string) {
+	r.view.tokens = newTokenCounter(dir)
+}
+
+// SetNotifiers adds the notification backends configured next to
+// desktop notifications
+func (r *RemoteMode) SetNotifiers(cfgs []config.NotifierConfig) error {
+	return r.notifier.Configure(cfgs)
+}
+
+// SetNotificationsEnabled enables or disables notifications from this
+// view, e.g. when the daemon already sends them
+func (r *RemoteMode) SetNotificationsEnabled(enabled bool) {
+	r.notifier.SetEnabled(enabled)
+}
+
+// SetNotificationDigest batches notifications arriving within window of
+// each other into one summary; 0 turns batching off
+func (r *RemoteMode) SetNotificationDigest(window time.Duration) {
+	r.notifier.SetDigest(window)
+}
+
+// SetNotifyInterrupted enables or disables notifications for interruptions
+func (r *RemoteMode) SetNotifyInterrupted(enabled bool) {
+	r.notifier.SetInterruptedEnabled(enabled)
+}
+
+// Run shows status changes until interrupted, reconnecting when the
+// daemon restarts. Desktop notifications follow the daemon's notify
+// hints, so muted projects and notification preferences apply.
+func (r *RemoteMode) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if r.dashboard {
+		drawDashboardHeader()
+		go r.refresh(ctx)
+	} else {
+		fmt.Println(i18n.T("cli.watching_remote", r.client.Endpoint()))
+		fmt.Println("---")
+	}
+
+	err := Follow(ctx, r.client, client.StreamOptions{}, func(ev client.Event) error {
+		r.handleEvent(ev)
+		return nil
+	})
+	fmt.Println()
+	fmt.Println(i18n.T("cli.stopped"))
+	return err
+}
+
+// refresh redraws the dashboard until ctx is done, so elapsed times
+// count up between events
+func (r *RemoteMode) refresh(ctx context.Context) {
+	ticker := time.NewTicker(dashboardRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			if len(r.statuses) > 0 {
+				r.redraw()
+			}
+			r.mu.Unlock()
+		}
+	}
+}
+
+func (r *RemoteMode) handleEvent(ev client.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if ev.Snapshot != nil {
+		// A snapshot after a resync or reconnect repeats unchanged projects
+		prev := r.statuses
+		r.statuses = make(map[string]state.ProjectStatus, len(ev.Snapshot.Projects))
+		for _, p := range ev.Snapshot.Projects {
+			status := StatusFromProtocol(p, "")
+			r.statuses[status.Name] = status
+			if old, seen := prev[status.Name]; !r.dashboard && (!seen || old.Seq != status.Seq || old.State != status.State) {
+				printStatus(&status)
+			}
+		}
+		if r.dashboard {
+			r.redraw()
+		}
+		return
+	}
+
+	if ev.Removed != nil {
+		if !ev.Removed.ProjectRemoved {
+			return
+		}
+		delete(r.statuses, ev.Removed.Project)
+		if r.dashboard {
+			r.redraw()
+		} else {
+			printRemoved(ev.Removed.Project)
+		}
+		return
+	}
+
+	update := ev.Update
+	if update == nil {
+		return
+	}
+	status := StatusFromProtocol(update.ProjectStatus, update.Cause)
+	r.statuses[status.Name] = status
+	if r.dashboard {
+		r.redraw()
+	} else if update.Cause != state.EventAcknowledged && update.Cause != state.EventMuted {
+		printStatus(&status)
+	}
+
+	if !update.Notify {
+		return
+	}
+	switch state.PhaseOf(status.State) {
+	case state.PhaseWaiting:
+		r.notifier.NotifyWaitingApproval(status)
+	case state.PhaseCompleted:
+		r.notifier.NotifyCompleted(status)
+	case state.PhaseInterrupted:
+		r.notifier.NotifyInterrupted(status)
+	}
+}
+
+// redraw draws the dashboard. Caller must hold r.mu.
+func (r *RemoteMode) redraw() {
+	statuses := make([]state.ProjectStatus, 0, len(r.statuses))
+	for _, status := range r.statuses {
+		statuses = append(statuses, status)
+	}
+	r.view.draw(statuses)
+}
+
+// Follow streams daemon events to fn until ctx is done or fn fails,
+// reconnecting after connection losses and resuming from the last event
+// received
+func Follow(ctx context.Context, c *client.Client, opts client.StreamOptions, fn func(client.Event) error) error {
+	lost := false
+	for {
+		var fnErr error
+		err := c.Stream(ctx, opts, func(ev client.Event) error {
+			if lost {
+				fmt.Fprintln(os.Stderr, i18n.T("cli.reconnected"))
+				lost = false
+			}
+			if ev.ID != 0 {
+				opts.LastEventID = ev.ID
+			}
+			fnErr = fn(ev)
+			return fnErr
+		})
+		if fnErr != nil {
+			return fnErr
+		}
+		if err == nil || ctx.Err() != nil {
+			return nil
+		}
+		if err == client.ErrUnauthorized {
+			return err
+		}
+		if !lost {
+			fmt.Fprintln(os.Stderr, i18n.T("cli.reconnecting", err))
+			lost = true
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// StatusFromProtocol converts a status received from the daemon. cause is
+// the update's cause; changes made by the daemon's idle detection are
+// estimates.
+func StatusFromProtocol(p protocol.ProjectStatus, cause string) state.ProjectStatus {
+	status := state.ProjectStatus{
+		Name:          p.Name,
+		Icon:          p.Icon,
+		State:         p.State,
+		Detail:        p.Detail,
+		Request:       p.Request,
+		UpdatedAt:     p.UpdatedAt,
+		ReceivedAt:    p.ReceivedAt,
+		SessionID:     p.SessionID,
+		Source:        p.Source,
+		Tier:          p.Tier,
+		Seq:           p.Seq,
+		Acknowledged:  p.Acknowledged,
+		Muted:         p.Muted,
+		MutedUntil:    p.MutedUntil,
+		ProjectPath:   p.ProjectPath,
+		Path:          p.Path,
+		Branch:        p.Branch,
+		ToolStartedAt: p.ToolStartedAt,
+		IsEstimated:   cause == "idle_approval" || cause == "idle_completed",
+	}
+	for _, sub := range p.Subagents {
+		status.Subagents = append(status.Subagents, state.SubagentStatus{
+			ID:          sub.ID,
+			Description: sub.Description,
+			Type:        sub.Type,
+			Icon:        sub.Icon,
+			State:       sub.State,
+			UpdatedAt:   sub.UpdatedAt,
+		})
+	}
+	if p.Environment != nil {
+		status.Environment = &state.Environment{
+			Model:          p.Environment.Model,
+			PermissionMode: p.Environment.PermissionMode,
+			MCPServers:     p.Environment.MCPServers,
+		}
+		if t := p.Environment.Terminal; t != nil {
+			terminal := state.Terminal(*t)
+			status.Environment.Terminal = &terminal
+		}
+	}
+	return status
+}
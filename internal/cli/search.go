@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+	"github.com/sho7650/claude-watch-status/internal/transcript"
+)
+
+// SearchOptions configures RunSearch
+type SearchOptions struct {
+	ProjectsDir string
+	Query       string
+	Project     string                  // "" = all projects
+	NameFor     func(dir string) string // configured project names, may be nil
+	Since       time.Duration           // only entries this recent, 0 = all
+	Limit       int                     // matches shown, 0 = all
+	JSON        bool
+	Color       bool
+}
+
+// RunSearch prints the session log entries containing a query, grouped
+// by session, newest session first
+func RunSearch(w io.Writer, opts SearchOptions) error {
+	q := transcript.Query{Text: opts.Query, Project: opts.Project, Limit: opts.Limit}
+	if opts.Since > 0 {
+		q.Since = time.Now().Add(-opts.Since)
+	}
+	result, err := transcript.Search(opts.ProjectsDir, opts.NameFor, q)
+	if err != nil {
+		return err
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	if len(result.Matches) == 0 {
+		fmt.Fprintln(w, "No matches.")
+		return nil
+	}
+
+	t := &tailer{w: w, opts: TailOptions{Color: opts.Color}}
+	var session string
+	for _, m := range result.Matches {
+		if m.SessionID != session {
+			if session != "" {
+				fmt.Fprintln(w)
+			}
+			session = m.SessionID
+			fmt.Fprintln(w, t.color("1", m.Project)+" "+t.color("90", "session "+m.SessionID))
+		}
+
+		label := t.color("36", "claude")
+		switch {
+		case m.Type == string(parser.ContentTypeToolUse):
+			label = t.color("33", "tool")
+		case m.Type == string(parser.ContentTypeToolResult):
+			label = t.color("90", "result")
+			if m.Tool != "" {
+				label = t.color("90", m.Tool+" result")
+			}
+		case m.Role == string(parser.EntryTypeUser):
+			label = t.color("32", "user")
+		}
+		if m.Subagent {
+			label = t.color("90", "[subagent]") + " " + label
+		}
+		snippet := transcript.Highlight(m.Snippet, opts.Query, func(s string) string { return t.color("1;31", s) })
+		fmt.Fprintf(w, "  %s %s  %s\n", t.color("90", m.Time.Local().Format("2006-01-02 15:04:05")), label, snippet)
+	}
+	if result.Truncated {
+		fmt.Fprintln(w, t.color("90", fmt.Sprintf("\nShowing the first %d matches; use --limit to see more.", opts.Limit)))
+	}
+	return nil
+}
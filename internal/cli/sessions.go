@@ -0,0 +1,371 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+	"github.com/sho7650/claude-watch-status/internal/redact"
+	"github.com/sho7650/claude-watch-status/internal/transcript"
+	"github.com/sho7650/claude-watch-status/internal/watcher"
+)
+
+// maxExportResult bounds the tool result text kept per result in exports
+const maxExportResult = 4000
+
+// SessionsOptions configures RunSessions
+type SessionsOptions struct {
+	ProjectsDir string
+	Project     string                  // "" = all projects
+	NameFor     func(dir string) string // configured project names, may be nil
+	Session     string                  // session ID or prefix to export
+	Export      string                  // "", "markdown" or "json"
+	Limit       int                     // sessions listed, 0 = all
+	JSON        bool                    // list as JSON
+}
+
+// TokenUsage is the token usage of a session, summed over its API requests
+type TokenUsage struct {
+	Input         int `json:"input"`
+	Output        int `json:"output"`
+	CacheCreation int `json:"cache_creation"`
+	CacheRead     int `json:"cache_read"`
+}
+
+// add counts the usage of an API request
+func (t *TokenUsage) add(u *parser.Usage) {
+	t.Input += u.InputTokens
+	t.Output += u.OutputTokens
+	t.CacheCreation += u.CacheCreationInputTokens
+	t.CacheRead += u.CacheReadInputTokens
+}
+
+// TotalInput returns all input tokens, cached or not
+func (t TokenUsage) TotalInput() int {
+	return t.Input + t.CacheCreation + t.CacheRead
+}
+
+// SessionInfo summarizes a session log
+type SessionInfo struct {
+	Project   string     `json:"project"`
+	SessionID string     `json:"session_id"`
+	Path      string     `json:"path"`
+	Title     string     `json:"title,omitempty"` // summary or first prompt
+	Started   time.Time  `json:"started"`
+	Ended     time.Time  `json:"ended"` // last entry
+	Prompts   int        `json:"prompts"`
+	Replies   int        `json:"replies"` // assistant messages
+	ToolCalls int        `json:"tool_calls"`
+	Tokens    TokenUsage `json:"tokens"`
+}
+
+// TranscriptEntry is one part of a message in an exported session
+type TranscriptEntry struct {
+	Time     time.Time `json:"time,omitempty"`
+	Role     string    `json:"role"` // user or assistant
+	Type     string    `json:"type"` // text, tool_use or tool_result
+	Text     string    `json:"text,omitempty"`
+	Tool     string    `json:"tool,omitempty"`
+	Input    string    `json:"input,omitempty"` // tool_use arguments as JSON
+	IsError  bool      `json:"is_error,omitempty"`
+	Subagent bool      `json:"subagent,omitempty"`
+}
+
+// Transcript is an exported session
+type Transcript struct {
+	Session SessionInfo       `json:"session"`
+	Entries []TranscriptEntry `json:"entries"`
+}
+
+// RunSessions lists the sessions of a project, or of all projects, newest
+// first, or exports one session's transcript
+func RunSessions(w io.Writer, opts SessionsOptions) error {
+	sessions, err := ListSessions(opts.ProjectsDir, opts.Project, opts.NameFor)
+	if err != nil {
+		return err
+	}
+	if opts.Export != "" {
+		return exportSession(w, sessions, opts)
+	}
+	if opts.Limit > 0 && len(sessions) > opts.Limit {
+		sessions = sessions[:opts.Limit]
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sessions)
+	}
+	if len(sessions) == 0 {
+		fmt.Fprintln(w, "No sessions.")
+		return nil
+	}
+	fmt.Fprintf(w, "%-20s %-8s %-16s %-8s %5s %5s %5s %15s  %s\n",
+		"PROJECT", "SESSION", "STARTED", "DURATION", "PRMPT", "REPLY", "TOOLS", "TOKENS IN/OUT", "TITLE")
+	for _, s := range sessions {
+		fmt.Fprintf(w, "%-20s %-8s %-16s %-8s %5d %5d %5d %15s  %s\n",
+			truncate(s.Project, 20), truncate(s.SessionID, 8), s.Started.Local().Format("2006-01-02 15:04"),
+			formatAge(s.Ended.Sub(s.Started)), s.Prompts, s.Replies, s.ToolCalls,
+			formatTokens(s.Tokens.TotalInput())+"/"+formatTokens(s.Tokens.Output),
+			truncate(s.Title, 50))
+	}
+	return nil
+}
+
+// ListSessions summarizes the session logs of a project, or of all
+// projects if project is "", newest first
+func ListSessions(projectsDir, project string, nameFor func(dir string) string) ([]SessionInfo, error) {
+	dirs, err := watcher.ListProjectDirs(projectsDir, nameFor)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []SessionInfo
+	for _, d := range dirs {
+		if project != "" && d.Project != project {
+			continue
+		}
+		entries, err := os.ReadDir(d.Dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
+				continue
+			}
+			info, err := summarizeSession(d.Project, filepath.Join(d.Dir, entry.Name()))
+			if err != nil || info.Started.IsZero() {
+				continue
+			}
+			sessions = append(sessions, info)
+		}
+	}
+	if project != "" && len(sessions) == 0 {
+		return nil, fmt.Errorf("no sessions found for project %q in %s", project, projectsDir)
+	}
+	slices.SortFunc(sessions, func(a, b SessionInfo) int { return b.Ended.Compare(a.Ended) })
+	return sessions, nil
+}
+
+// summarizeSession reads a session log into a SessionInfo
+func summarizeSession(project, path string) (SessionInfo, error) {
+	info := SessionInfo{
+		Project:   project,
+		SessionID: strings.TrimSuffix(filepath.Base(path), ".jsonl"),
+		Path:      path,
+	}
+	var firstPrompt string
+	// Claude Code writes each content block of an assistant message as an
+	// entry of its own, repeating the message's usage
+	seen := make(map[string]bool)
+
+	err := transcript.Each(path, func(entry transcript.Entry) {
+		if entry.Type == parser.EntryTypeSummary {
+			info.Title = entry.Summary
+			return
+		}
+		if t, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
+			if info.Started.IsZero() {
+				info.Started = t
+			}
+			info.Ended = t
+		}
+
+		items := transcript.Contents(entry.Message.Content)
+		switch entry.Type {
+		case parser.EntryTypeUser:
+			if entry.IsSidechain {
+				return
+			}
+			for _, c := range items {
+				if c.Type == string(parser.ContentTypeText) && strings.TrimSpace(c.Text) != "" {
+					info.Prompts++
+					if firstPrompt == "" {
+						firstPrompt = c.Text
+					}
+					break
+				}
+			}
+		case parser.EntryTypeAssistant:
+			for _, c := range items {
+				if c.Type == string(parser.ContentTypeToolUse) {
+					info.ToolCalls++
+				}
+			}
+			if id := entry.Message.ID; id != "" {
+				if seen[id] {
+					return
+				}
+				seen[id] = true
+			}
+			if !entry.IsSidechain {
+				info.Replies++
+			}
+			if u := entry.Message.Usage; u != nil {
+				info.Tokens.add(u)
+			}
+		}
+	})
+	if info.Title == "" {
+		info.Title = firstPrompt
+	}
+	info.Title = redact.String(strings.Join(strings.Fields(info.Title), " "))
+	return info, err
+}
+
+// exportSession writes the transcript of the selected session
+func exportSession(w io.Writer, sessions []SessionInfo, opts SessionsOptions) error {
+	if opts.Session == "" && opts.Project == "" {
+		return fmt.Errorf("name a project or a session (--session) to export")
+	}
+	var selected []SessionInfo
+	for _, s := range sessions {
+		if strings.HasPrefix(s.SessionID, opts.Session) {
+			selected = append(selected, s)
+		}
+	}
+	switch {
+	case len(selected) == 0:
+		return fmt.Errorf("no session %q", opts.Session)
+	case len(selected) > 1 && opts.Session != "":
+		return fmt.Errorf("session %q is ambiguous (%d sessions match)", opts.Session, len(selected))
+	}
+	// Without --session, the project's latest session
+	transcript, err := readTranscript(selected[0])
+	if err != nil {
+		return err
+	}
+
+	switch opts.Export {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(transcript)
+	case "markdown", "md":
+		return writeMarkdown(w, transcript)
+	default:
+		return fmt.Errorf("unknown export format %q (want markdown or json)", opts.Export)
+	}
+}
+
+// readTranscript reads the messages of a session, with secrets redacted
+func readTranscript(info SessionInfo) (Transcript, error) {
+	t := Transcript{Session: info}
+	tools := make(map[string]string)
+	err := transcript.Each(info.Path, func(entry transcript.Entry) {
+		role := string(entry.Type)
+		if entry.Type != parser.EntryTypeUser && entry.Type != parser.EntryTypeAssistant {
+			return
+		}
+		ts, _ := time.Parse(time.RFC3339Nano, entry.Timestamp)
+		for _, c := range transcript.Contents(entry.Message.Content) {
+			e := TranscriptEntry{Time: ts, Role: role, Type: c.Type, Subagent: entry.IsSidechain}
+			switch c.Type {
+			case string(parser.ContentTypeText):
+				e.Text = redact.String(strings.TrimSpace(c.Text))
+				if e.Text == "" {
+					continue
+				}
+			case string(parser.ContentTypeToolUse):
+				tools[c.ID] = c.Name
+				e.Tool = c.Name
+				var buf bytes.Buffer
+				if json.Indent(&buf, c.Input, "", "  ") == nil {
+					e.Input = redact.String(buf.String())
+				}
+			case string(parser.ContentTypeToolResult):
+				e.Tool = tools[c.ToolUseID]
+				e.Text = truncate(redact.String(strings.TrimSpace(c.ResultText())), maxExportResult)
+				e.IsError = c.IsError
+			default:
+				continue
+			}
+			t.Entries = append(t.Entries, e)
+		}
+	})
+	return t, err
+}
+
+// writeMarkdown writes a transcript as a Markdown document
+func writeMarkdown(w io.Writer, t Transcript) error {
+	s := t.Session
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s — session %s\n\n", s.Project, s.SessionID)
+	if s.Title != "" {
+		fmt.Fprintf(&b, "> %s\n\n", s.Title)
+	}
+	fmt.Fprintf(&b, "- Started: %s\n", s.Started.Local().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- Ended: %s\n", s.Ended.Local().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- Prompts: %d, replies: %d, tool calls: %d\n", s.Prompts, s.Replies, s.ToolCalls)
+	fmt.Fprintf(&b, "- Tokens: %d input, %d output, %d cache write, %d cache read\n",
+		s.Tokens.Input, s.Tokens.Output, s.Tokens.CacheCreation, s.Tokens.CacheRead)
+
+	var lastRole string
+	for _, e := range t.Entries {
+		role := e.Role
+		if e.Type == string(parser.ContentTypeToolResult) {
+			role = "assistant" // results belong to the assistant's tool calls
+		}
+		if role != lastRole {
+			who := "👤 User"
+			if role == "assistant" {
+				who = "🤖 Claude"
+			}
+			if e.Subagent {
+				who += " (subagent)"
+			}
+			fmt.Fprintf(&b, "\n## %s — %s\n", who, e.Time.Local().Format("15:04:05"))
+			lastRole = role
+		}
+
+		switch e.Type {
+		case string(parser.ContentTypeText):
+			fmt.Fprintf(&b, "\n%s\n", e.Text)
+		case string(parser.ContentTypeToolUse):
+			fmt.Fprintf(&b, "\n**Tool:** `%s`\n", e.Tool)
+			if e.Input != "" && e.Input != "{}" {
+				fmt.Fprintf(&b, "\n```json\n%s\n```\n", e.Input)
+			}
+		case string(parser.ContentTypeToolResult):
+			label := "Result"
+			if e.IsError {
+				label = "Error"
+			}
+			fmt.Fprintf(&b, "\n<details><summary>%s</summary>\n\n```\n%s\n```\n\n</details>\n", label, fence(e.Text))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// fence keeps text from closing the code block it is shown in
+func fence(text string) string {
+	return strings.ReplaceAll(text, "```", "ˋˋˋ")
+}
+
+// truncate shortens s to max characters
+func truncate(s string, max int) string {
+	if r := []rune(s); len(r) > max {
+		return string(r[:max-1]) + "…"
+	}
+	return s
+}
+
+// formatTokens formats a token count compactly: 950, 12.3k, 1.2M
+func formatTokens(n int) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprintf("%d", n)
+	case n < 1000000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	}
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// StatuslineOptions configures the statusline output
+type StatuslineOptions struct {
+	Endpoint string // daemon base URL, e.g. http://127.0.0.1:10087
+	Token    string // bearer token for the read API
+	Insecure bool   // skip verifying the daemon's TLS certificate
+	Project  string // project name; empty = the project of Dir, or the most recently updated
+	Dir      string // directory of the project, as piped by Claude Code
+	Format   string // "plain", "tmux", "starship" or "json"
+
+	// ProjectNameFor returns the configured project name of a directory,
+	// "" if none; nil = no configured names
+	ProjectNameFor func(dir string) string
+}
+
+// Limits on reading the JSON Claude Code pipes to statusLine commands:
+// stdin of other callers may be a pipe that never closes, e.g. under
+// tmux or cron
+const (
+	maxStatuslineInput  = 64 << 10
+	statuslineInputWait = 200 * time.Millisecond
+)
+
+// statuslineJSON is the json output format
+type statuslineJSON struct {
+	Name       string `json:"name"`
+	Icon       string `json:"icon"`
+	State      string `json:"state"`
+	AgeSeconds int    `json:"age_seconds"`
+}
+
+// RunStatusline prints a one-line status from the running daemon
+func RunStatusline(w io.Writer, opts StatuslineOptions) error {
+	statuses, err := fetchStatuses(opts.Endpoint, opts.Token, opts.Insecure)
+	if err != nil {
+		// Keep status bars quiet when the daemon is not running
+		if opts.Format == "json" {
+			fmt.Fprintln(w, "{}")
+		}
+		return nil
+	}
+
+	project := opts.Project
+	if project == "" && opts.Dir != "" {
+		project = projectForDir(statuses, opts.Dir, opts.ProjectNameFor)
+	}
+	status := pickStatus(statuses, project)
+	if status == nil {
+		if opts.Format == "json" {
+			fmt.Fprintln(w, "{}")
+		}
+		return nil
+	}
+
+	age := time.Since(status.UpdatedAt)
+
+	switch opts.Format {
+	case "json":
+		return json.NewEncoder(w).Encode(statuslineJSON{
+			Name:       status.Name,
+			Icon:       status.Icon,
+			State:      status.State,
+			AgeSeconds: int(age.Seconds()),
+		})
+	case "tmux":
+		fmt.Fprintf(w, "#[fg=%s]%s %s %s#[default]\n", tmuxColor(status.State), marker(status.Icon, status.State, false), status.State, formatAge(age))
+	case "starship", "plain", "":
+		fmt.Fprintf(w, "%s %s %s\n", marker(status.Icon, status.State, false), status.State, formatAge(age))
+	default:
+		return fmt.Errorf("unknown format %q (want plain, tmux, starship or json)", opts.Format)
+	}
+	return nil
+}
+
+// DirFromStatuslineInput extracts the project directory from the JSON
+// that Claude Code pipes to statusLine commands
+// ({"workspace":{"project_dir":...,"current_dir":...}}). It gives up
+// after statuslineInputWait or maxStatuslineInput bytes, returning "".
+func DirFromStatuslineInput(r io.Reader) string {
+	type statuslineInput struct {
+		CWD       string `json:"cwd"`
+		Workspace struct {
+			ProjectDir string `json:"project_dir"`
+			CurrentDir string `json:"current_dir"`
+		} `json:"workspace"`
+	}
+	// Buffered, so a late result does not block the reader; a read that
+	// never returns ends with the process
+	done := make(chan statuslineInput, 1)
+	go func() {
+		var input statuslineInput
+		json.NewDecoder(io.LimitReader(r, maxStatuslineInput)).Decode(&input)
+		done <- input
+	}()
+
+	var input statuslineInput
+	select {
+	case input = <-done:
+	case <-time.After(statuslineInputWait):
+		return ""
+	}
+	for _, dir := range []string{input.Workspace.ProjectDir, input.Workspace.CurrentDir, input.CWD} {
+		if dir != "" {
+			return filepath.Clean(dir)
+		}
+	}
+	return ""
+}
+
+// projectForDir returns the name the daemon shows the project in dir
+// under: the project whose directory is nearest above dir, as projects
+// of the same name in other directories are named differently, else the
+// configured name of dir, else the directory name
+func projectForDir(statuses []state.ProjectStatus, dir string, nameFor func(dir string) string) string {
+	var name, best string
+	for _, status := range statuses {
+		path := status.ProjectPath
+		if path == "" || len(path) <= len(best) {
+			continue
+		}
+		if rel, err := filepath.Rel(path, dir); err == nil && filepath.IsLocal(rel) {
+			name, best = status.Name, path
+		}
+	}
+	if name != "" {
+		return name
+	}
+	if nameFor != nil {
+		if configured := nameFor(dir); configured != "" {
+			return configured
+		}
+	}
+	return filepath.Base(dir)
+}
+
+// StdinIsPiped reports whether stdin is a pipe or file rather than a terminal
+func StdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// fetchStatuses returns the status of all projects from the daemon
+func fetchStatuses(endpoint, token string, insecure bool) ([]state.ProjectStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	snapshot, err := NewClient(endpoint, token, insecure).Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]state.ProjectStatus, 0, len(snapshot.Projects))
+	for _, p := range snapshot.Projects {
+		statuses = append(statuses, StatusFromProtocol(p, ""))
+	}
+	return statuses, nil
+}
+
+// pickStatus returns the named project, or the most recently updated one
+func pickStatus(statuses []state.ProjectStatus, project string) *state.ProjectStatus {
+	if project != "" {
+		for i := range statuses {
+			if statuses[i].Name == project {
+				return &statuses[i]
+			}
+		}
+		return nil
+	}
+	if len(statuses) == 0 {
+		return nil
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].UpdatedAt.After(statuses[j].UpdatedAt)
+	})
+	return &statuses[0]
+}
+
+// formatAge formats a duration compactly: 12s, 3m, 2h, 1d
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// tmuxColor returns a tmux color name for a state
+func tmuxColor(stateText string) string {
+	switch state.PhaseOf(stateText) {
+	case state.PhaseWaiting:
+		return "yellow"
+	case state.PhaseCompleted:
+		return "green"
+	case state.PhaseInterrupted, state.PhaseError:
+		return "red"
+	case state.PhaseWorking:
+		return "cyan"
+	default:
+		return "default"
+	}
+}
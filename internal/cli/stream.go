@@ -4,9 +4,13 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sort"
 	"syscall"
 	"time"
 
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/engine"
+	"github.com/sho7650/claude-watch-status/internal/i18n"
 	"github.com/sho7650/claude-watch-status/internal/notifier"
 	"github.com/sho7650/claude-watch-status/internal/state"
 	"github.com/sho7650/claude-watch-status/internal/watcher"
@@ -14,103 +18,156 @@ import (
 
 // StreamMode runs the CLI in stream mode
 type StreamMode struct {
-	projectsDir string
-	notifier    *notifier.Notifier
-	manager     *state.Manager
-	notified    map[string]bool // Track notified files to prevent duplicates
+	engine   *engine.Engine
+	notifier *notifier.Notifier
+	last     map[string]state.ProjectStatus // last status printed per project
 }
 
 // NewStreamMode creates a new StreamMode
 func NewStreamMode(projectsDir string) *StreamMode {
-	return &StreamMode{
-		projectsDir: projectsDir,
-		notifier:    notifier.New(),
-		manager:     state.NewManager(),
-		notified:    make(map[string]bool),
+	s := &StreamMode{
+		engine:   engine.New(projectsDir),
+		notifier: notifier.New(),
+		last:     make(map[string]state.ProjectStatus),
 	}
+	s.engine.SetNotifier(s.notifier)
+	return s
+}
+
+// SetWatchMode selects how log changes are detected
+func (s *StreamMode) SetWatchMode(mode watcher.Mode) {
+	s.engine.SetWatchMode(mode)
+}
+
+// SetNotifiers adds the notification backends configured next to
+// desktop notifications
+func (s *StreamMode) SetNotifiers(cfgs []config.NotifierConfig) error {
+	return s.notifier.Configure(cfgs)
+}
+
+// SetNotifyInterrupted enables or disables notifications for interruptions
+func (s *StreamMode) SetNotifyInterrupted(enabled bool) {
+	s.notifier.SetInterruptedEnabled(enabled)
+}
+
+// ApplyConfig applies per-project settings (tiers, names, notification
+// enable flags) and the notification digest from the configuration file
+func (s *StreamMode) ApplyConfig(cfg *config.Config) {
+	s.engine.ApplyConfig(cfg)
+	s.notifier.SetTierFunc(cfg.TierFor)
+	s.notifier.SetProjectEnabledFunc(cfg.NotifyEnabledFor)
+	s.notifier.SetDigest(cfg.Notifications.DigestWindow())
 }
 
 // Run starts the stream mode
 func (s *StreamMode) Run() error {
-	fmt.Println("Watching Claude Code activity... (Ctrl+C to stop)")
+	fmt.Println(i18n.T("cli.watching"))
 	fmt.Println("---")
 
-	w, err := watcher.New(s.projectsDir)
-	if err != nil {
-		return fmt.Errorf("failed to create watcher: %w", err)
+	if err := s.engine.Watch(); err != nil {
+		return err
+	}
+	defer s.engine.Stop()
+
+	manager := s.engine.Manager()
+	eventCh, statuses, _ := manager.SubscribeWithSnapshot()
+	defer manager.Unsubscribe(eventCh)
+	s.seed(statuses)
+	s.engine.Start()
+
+	followLocal(eventCh, nil, s.handleEvent, nil)
+	return nil
+}
+
+// seed prints the states of existing sessions, oldest first
+func (s *StreamMode) seed(statuses []state.ProjectStatus) {
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].UpdatedAt.Before(statuses[j].UpdatedAt) })
+	for i := range statuses {
+		s.last[statuses[i].Name] = statuses[i]
+		printStatus(&statuses[i])
 	}
+}
 
-	if err := w.Start(); err != nil {
-		return fmt.Errorf("failed to start watcher: %w", err)
+func (s *StreamMode) handleEvent(event state.StatusEvent) {
+	status := event.Project
+	switch event.Type {
+	case state.EventSessionRemoved:
+		if event.ProjectRemoved {
+			delete(s.last, status.Name)
+			printRemoved(status.Name)
+		}
+		return
+	case state.EventAcknowledged, state.EventMuted:
+		return
+	case state.EventResync:
+		// Print the projects that changed in the events missed
+		for _, update := range event.Updates() {
+			if prev, seen := s.last[update.Project.Name]; !seen || prev.Seq != update.Project.Seq {
+				s.handleEvent(update)
+			}
+		}
+		return
 	}
-	defer w.Stop()
 
-	// Set up signal handling
+	// Subagent changes leave the parent's state and time as they were
+	prev, seen := s.last[status.Name]
+	s.last[status.Name] = status
+	if seen && len(status.Subagents) > 0 && prev.State == status.State && prev.UpdatedAt.Equal(status.UpdatedAt) {
+		printSubagent(&status)
+		return
+	}
+	printStatus(&status)
+}
+
+// followLocal calls fn for every status event of a local engine, and
+// onTick on every tick of tick (nil = never), until interrupted
+func followLocal(eventCh chan state.StatusEvent, tick <-chan time.Time, fn func(state.StatusEvent), onTick func()) {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-
-	// Start idle detection goroutine
-	idleTicker := time.NewTicker(5 * time.Second)
-	defer idleTicker.Stop()
+	defer signal.Stop(sigCh)
 
 	for {
 		select {
 		case <-sigCh:
 			fmt.Println()
-			fmt.Println("Stopped.")
-			return nil
-
-		case event := <-w.Events():
-			s.handleEvent(event)
-
-		case err := <-w.Errors():
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-
-		case <-idleTicker.C:
-			s.checkIdleProjects()
+			fmt.Println(i18n.T("cli.stopped"))
+			return
+		case event := <-eventCh:
+			fn(event)
+		case <-tick:
+			onTick()
 		}
 	}
 }
 
-func (s *StreamMode) handleEvent(event watcher.Event) {
-	status, err := s.manager.Update(event.ProjectName, event.SessionID, event.Path)
-	if err != nil || status == nil {
-		return
-	}
-
-	s.printStatus(status)
-}
-
-func (s *StreamMode) printStatus(status *state.ProjectStatus) {
+// printStatus prints a status line
+func printStatus(status *state.ProjectStatus) {
 	ts := status.UpdatedAt.Format("15:04:05")
-	// Format: icon [timestamp] project     state
-	fmt.Printf("%s \033[90m[%s]\033[0m %-15s \033[36m%s\033[0m\n",
-		status.Icon, ts, status.Name, status.State)
+	icon := marker(status.Icon, status.State, status.IsEstimated)
+	// Format: icon [timestamp] project     state detail [model] [tier] [unattended-permissions] → focus hint
+	fmt.Printf("%s %s %-15s %s%s%s%s%s%s\n",
+		icon, dim("["+ts+"]"), status.Name, paintState(status.State, i18n.State(status.State)), detailSuffix(statusDetail(*status)), modelBadge(status.Environment), tierBadge(status.Tier), permissionBadge(status.Environment), focusHint(status))
 }
 
-func (s *StreamMode) checkIdleProjects() {
-	events := s.manager.CheckIdleProjects(5 * time.Second)
-
-	for _, event := range events {
-		// Create a unique key for this idle event
-		key := fmt.Sprintf("%s:%s:%s", event.Project.FilePath, event.Project.FileTime, event.Type)
-		if s.notified[key] {
-			continue
-		}
-		s.notified[key] = true
-
-		// Update the manager's state
-		s.manager.MarkIdle(event.Project.Name, event.Project.Icon, event.Project.State, event.Project.IsEstimated)
-
-		// Print the status
-		s.printStatus(&event.Project)
+// printRemoved prints that a project went away with its session logs
+func printRemoved(projectName string) {
+	ts := time.Now().Format("15:04:05")
+	fmt.Printf("%s %s %-15s %s\n", marker("🗑", "", false), dim("["+ts+"]"), projectName, dim(i18n.T("cli.removed")))
+}
 
-		// Send notification
-		switch event.Type {
-		case "idle_approval":
-			s.notifier.NotifyWaitingApproval(event.Project.Name)
-		case "idle_completed":
-			s.notifier.NotifyCompleted(event.Project.Name)
+// printSubagent prints the most recently updated subagent of a project
+func printSubagent(status *state.ProjectStatus) {
+	var latest *state.SubagentStatus
+	for i := range status.Subagents {
+		if latest == nil || status.Subagents[i].UpdatedAt.After(latest.UpdatedAt) {
+			latest = &status.Subagents[i]
 		}
 	}
+	if latest == nil {
+		return
+	}
+	ts := latest.UpdatedAt.Format("15:04:05")
+	// Format:   ↳ icon [timestamp] project/subagent  state
+	fmt.Printf("  ↳ %s %s %-15s %s\n",
+		marker(latest.Icon, latest.State, false), dim("["+ts+"]"), status.Name+"/"+subagentLabel(*latest), paintState(latest.State, i18n.State(latest.State)))
 }
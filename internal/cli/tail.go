@@ -0,0 +1,304 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+	"github.com/sho7650/claude-watch-status/internal/redact"
+	"github.com/sho7650/claude-watch-status/internal/transcript"
+	"github.com/sho7650/claude-watch-status/internal/watcher"
+)
+
+// TailOptions configures RunTail
+type TailOptions struct {
+	ProjectsDir string
+	Project     string                  // project name, or the path of a session log
+	NameFor     func(dir string) string // configured project names, may be nil
+	Lines       int                     // entries shown before following
+	Follow      bool
+	Width       int // longest text snippet, in characters
+	Color       bool
+}
+
+// tailInterval is how often tail checks the log for new entries
+const tailInterval = 500 * time.Millisecond
+
+// tailer prints the entries of a session log as a readable transcript
+type tailer struct {
+	w     io.Writer
+	opts  TailOptions
+	tools map[string]string // tool_use ID -> tool name, to label results
+}
+
+// RunTail prints the last entries of a project's latest session log and,
+// with Follow, new entries as they are written, switching to a newer
+// session when one starts, until interrupted
+func RunTail(w io.Writer, opts TailOptions) error {
+	dirs, path, err := tailLog(opts)
+	if err != nil {
+		return err
+	}
+
+	t := &tailer{w: w, opts: opts, tools: make(map[string]string)}
+	t.header(path)
+	offset, err := t.printLast(path)
+	if err != nil || !opts.Follow {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(tailInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if latest := latestLog(dirs); latest != "" && latest != path {
+			// Finish the old session before switching
+			if _, err := t.printFrom(path, offset); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			path, offset = latest, 0
+			t.header(path)
+		}
+		offset, err = t.printFrom(path, offset)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+}
+
+// tailLog returns the directories to look for newer sessions in and the
+// session log to start with
+func tailLog(opts TailOptions) ([]string, string, error) {
+	if strings.HasSuffix(opts.Project, ".jsonl") {
+		if _, err := os.Stat(opts.Project); err != nil {
+			return nil, "", err
+		}
+		return nil, opts.Project, nil
+	}
+
+	dirs, err := watcher.ProjectDirs(opts.ProjectsDir, opts.Project, opts.NameFor)
+	if err != nil {
+		return nil, "", err
+	}
+	path := latestLog(dirs)
+	if path == "" {
+		return nil, "", fmt.Errorf("no session log found for project %q in %s", opts.Project, opts.ProjectsDir)
+	}
+	return dirs, path, nil
+}
+
+// latestLog returns the most recently modified session log in dirs
+func latestLog(dirs []string) string {
+	var latest string
+	var latestTime time.Time
+	for _, dir := range dirs {
+		path, err := watcher.GetLatestJSONL(dir)
+		if err != nil || path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(latestTime) {
+			latest, latestTime = path, info.ModTime()
+		}
+	}
+	return latest
+}
+
+// header announces the session log being shown
+func (t *tailer) header(path string) {
+	session := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+	fmt.Fprintln(t.w, t.color("90", "── session "+session+" ──"))
+}
+
+// printLast prints the last Lines entries of a log and returns the offset
+// after the last complete line
+func (t *tailer) printLast(path string) (int64, error) {
+	lines, offset, err := readLines(path, 0)
+	if err != nil {
+		return 0, err
+	}
+	// Earlier tool calls label results shown below
+	if len(lines) > t.opts.Lines {
+		for _, line := range lines[:len(lines)-t.opts.Lines] {
+			t.remember(line)
+		}
+		lines = lines[len(lines)-t.opts.Lines:]
+	}
+	for _, line := range lines {
+		t.print(line)
+	}
+	return offset, nil
+}
+
+// printFrom prints the complete lines after offset and returns the new
+// offset. A truncated log is read from the start.
+func (t *tailer) printFrom(path string, offset int64) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return offset, err
+	}
+	if info.Size() < offset {
+		offset = 0
+	}
+	if info.Size() == offset {
+		return offset, nil
+	}
+	lines, offset, err := readLines(path, offset)
+	for _, line := range lines {
+		t.print(line)
+	}
+	return offset, err
+}
+
+// readLines reads the complete lines of a file after offset. A line still
+// being written is left for the next read.
+func readLines(path string, offset int64) ([][]byte, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, offset, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+
+	var lines [][]byte
+	r := bufio.NewReaderSize(f, 64*1024)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return lines, offset, err
+		}
+		offset += int64(len(line))
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+}
+
+// remember records the tool calls of a line that is not printed
+func (t *tailer) remember(line []byte) {
+	entry, ok := transcript.Decode(line)
+	if !ok || entry.Type != parser.EntryTypeAssistant {
+		return
+	}
+	for _, c := range transcript.Contents(entry.Message.Content) {
+		if c.Type == string(parser.ContentTypeToolUse) {
+			t.tools[c.ID] = c.Name
+		}
+	}
+}
+
+// print writes one log entry as transcript lines
+func (t *tailer) print(line []byte) {
+	entry, ok := transcript.Decode(line)
+	if !ok {
+		return
+	}
+	prefix := t.color("90", entryTime(entry.Timestamp)) + " "
+	if entry.IsSidechain {
+		prefix += t.color("90", "[subagent]") + " "
+	}
+
+	switch entry.Type {
+	case parser.EntryTypeSummary:
+		fmt.Fprintln(t.w, prefix+t.color("90", "summary: "+t.snippet(entry.Summary)))
+	case parser.EntryTypeUser:
+		for _, c := range transcript.Contents(entry.Message.Content) {
+			switch c.Type {
+			case string(parser.ContentTypeText):
+				if text := t.snippet(c.Text); text != "" {
+					fmt.Fprintln(t.w, prefix+t.color("32", "user")+"    "+text)
+				}
+			case string(parser.ContentTypeToolResult):
+				label := "result"
+				if name := t.tools[c.ToolUseID]; name != "" {
+					label = name + " result"
+				}
+				code := "90"
+				if c.IsError {
+					code, label = "31", label+" (error)"
+				}
+				fmt.Fprintln(t.w, prefix+t.color(code, "  ↳ "+label+": "+t.snippet(c.ResultText())))
+			}
+		}
+	case parser.EntryTypeAssistant:
+		for _, c := range transcript.Contents(entry.Message.Content) {
+			switch c.Type {
+			case string(parser.ContentTypeText):
+				if text := t.snippet(c.Text); text != "" {
+					fmt.Fprintln(t.w, prefix+t.color("36", "claude")+"  "+text)
+				}
+			case string(parser.ContentTypeToolUse):
+				t.tools[c.ID] = c.Name
+				fmt.Fprintln(t.w, prefix+t.color("33", "tool")+"    "+t.color("1", c.Name)+" "+t.toolArgs(c))
+			}
+		}
+	}
+}
+
+// toolArgs describes the arguments of a tool call: its detail (file,
+// command, URL, ...) if known, else its input as compact JSON
+func (t *tailer) toolArgs(c parser.Content) string {
+	var input map[string]interface{}
+	if err := json.Unmarshal(c.Input, &input); err == nil {
+		if detail := parser.ToolDetail(c.Name, input); detail != "" {
+			return detail
+		}
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, c.Input); err != nil {
+		return ""
+	}
+	return t.snippet(buf.String())
+}
+
+// snippet returns text on one line, redacted and shortened to Width
+func (t *tailer) snippet(text string) string {
+	text = redact.String(strings.Join(strings.Fields(text), " "))
+	if r := []rune(text); t.opts.Width > 1 && len(r) > t.opts.Width {
+		text = string(r[:t.opts.Width-1]) + "…"
+	}
+	return text
+}
+
+// color wraps s in an ANSI color code unless colors are off
+func (t *tailer) color(code, s string) string {
+	if !t.opts.Color {
+		return s
+	}
+	return "\033[" + code + "m" + s + "\033[0m"
+}
+
+// entryTime formats an entry's timestamp as local time of day
+func entryTime(ts string) string {
+	parsed, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return "--:--:--"
+	}
+	return parsed.Local().Format("15:04:05")
+}
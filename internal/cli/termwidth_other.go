@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package cli
+
+// stdoutTTY reports stdout as no terminal: terminals are not detected on
+// this platform
+func stdoutTTY() (width int, ok bool) {
+	return 0, false
+}
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// Theme colors the terminal views: states by phase, and dimmed
+// timestamps and details
+type Theme struct {
+	enabled bool
+	phases  map[state.Phase]string // SGR codes
+}
+
+// themes are the built-in state colors by phase. Besides hue, the light
+// and colorblind themes set waiting and errors apart by weight.
+var themes = map[string]map[state.Phase]string{
+	"dark": {
+		state.PhaseUnknown:     "36",
+		state.PhaseStarted:     "36",
+		state.PhaseUserInput:   "36",
+		state.PhaseWorking:     "36",
+		state.PhaseWaiting:     "33",
+		state.PhaseCompleted:   "32",
+		state.PhaseInterrupted: "31",
+		state.PhaseError:       "1;31",
+		state.PhaseEnded:       "90",
+	},
+	"light": {
+		state.PhaseUnknown:     "34",
+		state.PhaseStarted:     "34",
+		state.PhaseUserInput:   "34",
+		state.PhaseWorking:     "34",
+		state.PhaseWaiting:     "1;38;5;130",
+		state.PhaseCompleted:   "38;5;28",
+		state.PhaseInterrupted: "38;5;160",
+		state.PhaseError:       "1;38;5;160",
+		state.PhaseEnded:       "90",
+	},
+	// Okabe-Ito colors: sky blue, orange, bluish green and vermillion
+	"colorblind": {
+		state.PhaseUnknown:     "38;5;74",
+		state.PhaseStarted:     "38;5;74",
+		state.PhaseUserInput:   "38;5;74",
+		state.PhaseWorking:     "38;5;74",
+		state.PhaseWaiting:     "1;38;5;214",
+		state.PhaseCompleted:   "38;5;36",
+		state.PhaseInterrupted: "38;5;166",
+		state.PhaseError:       "1;4;38;5;166",
+		state.PhaseEnded:       "90",
+	},
+}
+
+// colorNames maps the color names of the config file to SGR codes
+var colorNames = map[string]string{
+	"default": "39", "bold": "1", "dim": "2", "italic": "3", "underline": "4",
+	"black": "30", "red": "31", "green": "32", "yellow": "33",
+	"blue": "34", "magenta": "35", "cyan": "36", "white": "37", "gray": "90",
+	"bright-red": "91", "bright-green": "92", "bright-yellow": "93", "bright-blue": "94",
+	"bright-magenta": "95", "bright-cyan": "96", "bright-white": "97",
+}
+
+// sgrCodes matches raw SGR codes such as "1;38;5;208"
+var sgrCodes = regexp.MustCompile(`^[0-9]{1,3}(;[0-9]{1,3})*$`)
+
+// theme colors the output of the terminal views; see SetTheme
+var theme = &Theme{enabled: true, phases: themes["dark"]}
+
+// NewTheme creates the theme configured in cfg; enabled = false prints
+// without colors
+func NewTheme(cfg config.ColorsConfig, enabled bool) (*Theme, error) {
+	name := cfg.Theme
+	if name == "" {
+		name = "dark"
+	}
+	base, ok := themes[name]
+	if !ok {
+		return nil, fmt.Errorf("colors: unknown theme %q (want dark, light or colorblind)", cfg.Theme)
+	}
+
+	t := &Theme{enabled: enabled, phases: make(map[state.Phase]string, len(base))}
+	for phase, code := range base {
+		t.phases[phase] = code
+	}
+	names := make([]string, 0, len(cfg.States))
+	for name := range cfg.States {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		phase, ok := state.ParsePhase(name)
+		if !ok {
+			return nil, fmt.Errorf("colors: unknown state %q (want started, user_input, working, waiting, completed, interrupted, error or ended)", name)
+		}
+		code, err := parseColor(cfg.States[name])
+		if err != nil {
+			return nil, fmt.Errorf("colors: state %q: %w", name, err)
+		}
+		t.phases[phase] = code
+	}
+	return t, nil
+}
+
+// parseColor converts color names and SGR codes, separated by spaces, to
+// SGR codes
+func parseColor(s string) (string, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty color")
+	}
+	codes := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if code, ok := colorNames[strings.ToLower(f)]; ok {
+			codes = append(codes, code)
+			continue
+		}
+		if !sgrCodes.MatchString(f) {
+			return "", fmt.Errorf("unknown color %q (want a name such as yellow or bright-blue, or SGR codes such as 1;38;5;208)", f)
+		}
+		codes = append(codes, f)
+	}
+	return strings.Join(codes, ";"), nil
+}
+
+// SetTheme sets the colors of the stream and dashboard views
+func SetTheme(t *Theme) {
+	theme = t
+}
+
+// ColorsEnabled reports whether terminal output should be colored: not
+// with --no-color (noColor) or NO_COLOR set, nor with CLICOLOR=0 or when
+// stdout is not a terminal unless CLICOLOR_FORCE is set
+func ColorsEnabled(noColor bool) bool {
+	switch {
+	case noColor, os.Getenv("NO_COLOR") != "":
+		return false
+	case os.Getenv("CLICOLOR_FORCE") != "" && os.Getenv("CLICOLOR_FORCE") != "0":
+		return true
+	case os.Getenv("CLICOLOR") == "0":
+		return false
+	default:
+		_, tty := stdoutTTY()
+		return tty
+	}
+}
+
+// paint wraps s in SGR codes unless colors are off
+func paint(code, s string) string {
+	if !theme.enabled || code == "" {
+		return s
+	}
+	return "\033[" + code + "m" + s + "\033[0m"
+}
+
+// dim grays out secondary text: timestamps, details
+func dim(s string) string {
+	return paint("90", s)
+}
+
+// paintState colors text in the theme's color for a state
+func paintState(stateText, s string) string {
+	return paint(theme.phases[state.PhaseOf(stateText)], s)
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// TmuxSyncOptions configures tmux window syncing
+type TmuxSyncOptions struct {
+	Endpoint string        // daemon base URL, e.g. http://127.0.0.1:10087
+	Token    string        // bearer token for the read API
+	Insecure bool          // skip verifying the daemon's TLS certificate
+	Interval time.Duration // how often to poll the daemon
+}
+
+// TmuxSync prefixes tmux window names with the status icon of the
+// project whose directory one of the window's panes is in
+type TmuxSync struct {
+	opts     TmuxSyncOptions
+	original map[string]string // window ID -> name before we renamed it
+	applied  map[string]string // window ID -> name we last set
+}
+
+// tmuxPane is a pane as reported by tmux list-panes
+type tmuxPane struct {
+	windowID   string
+	windowName string
+	path       string
+}
+
+// NewTmuxSync creates a new TmuxSync
+func NewTmuxSync(opts TmuxSyncOptions) *TmuxSync {
+	if opts.Interval <= 0 {
+		opts.Interval = 2 * time.Second
+	}
+	return &TmuxSync{
+		opts:     opts,
+		original: make(map[string]string),
+		applied:  make(map[string]string),
+	}
+}
+
+// Run syncs window names until interrupted, then restores the original names
+func (t *TmuxSync) Run() error {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		return fmt.Errorf("tmux not found in PATH")
+	}
+	if _, err := listTmuxPanes(); err != nil {
+		return fmt.Errorf("no tmux server running: %w", err)
+	}
+
+	fmt.Println("Syncing tmux window names... (Ctrl+C to stop)")
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	ticker := time.NewTicker(t.opts.Interval)
+	defer ticker.Stop()
+
+	t.sync()
+	for {
+		select {
+		case <-sigCh:
+			t.restore()
+			fmt.Println()
+			fmt.Println("Stopped.")
+			return nil
+
+		case <-ticker.C:
+			t.sync()
+		}
+	}
+}
+
+// sync renames every window with a pane in a known project directory
+func (t *TmuxSync) sync() {
+	panes, err := listTmuxPanes()
+	if err != nil {
+		return
+	}
+
+	// Without the daemon, keep the last names rather than flickering
+	statuses, err := fetchStatuses(t.opts.Endpoint, t.opts.Token, t.opts.Insecure)
+	if err != nil {
+		return
+	}
+	byName := make(map[string]state.ProjectStatus, len(statuses))
+	for _, status := range statuses {
+		byName[status.Name] = status
+	}
+
+	matched := make(map[string]bool)
+	for _, pane := range panes {
+		if matched[pane.windowID] {
+			continue
+		}
+		status, ok := byName[filepath.Base(pane.path)]
+		if !ok {
+			continue
+		}
+		matched[pane.windowID] = true
+
+		// The user (or automatic-rename) changed the name since our last sync
+		if applied, ok := t.applied[pane.windowID]; !ok || applied != pane.windowName {
+			t.original[pane.windowID] = pane.windowName
+		}
+
+		name := strings.TrimSpace(marker(status.Icon, status.State, false)) + " " + t.original[pane.windowID]
+		if name == pane.windowName {
+			t.applied[pane.windowID] = name
+			continue
+		}
+		if err := exec.Command("tmux", "rename-window", "-t", pane.windowID, name).Run(); err == nil {
+			t.applied[pane.windowID] = name
+		}
+	}
+
+	// Windows whose project disappeared get their name back
+	for id := range t.applied {
+		if !matched[id] {
+			t.restoreWindow(id)
+		}
+	}
+}
+
+// restore puts back the original names of all renamed windows
+func (t *TmuxSync) restore() {
+	for id := range t.applied {
+		t.restoreWindow(id)
+	}
+}
+
+func (t *TmuxSync) restoreWindow(id string) {
+	exec.Command("tmux", "rename-window", "-t", id, t.original[id]).Run()
+	delete(t.applied, id)
+	delete(t.original, id)
+}
+
+// listTmuxPanes lists all panes across all tmux sessions
+func listTmuxPanes() ([]tmuxPane, error) {
+	out, err := exec.Command("tmux", "list-panes", "-a", "-F", "#{window_id}\t#{window_name}\t#{pane_current_path}").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var panes []tmuxPane
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		panes = append(panes, tmuxPane{windowID: fields[0], windowName: fields[1], path: fields[2]})
+	}
+	return panes, nil
+}
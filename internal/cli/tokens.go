@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+	"github.com/sho7650/claude-watch-status/internal/state"
+	"github.com/sho7650/claude-watch-status/internal/transcript"
+)
+
+// logLookupInterval is how often the log of a session not found among the
+// session logs is looked for again
+const logLookupInterval = 30 * time.Second
+
+// tokenCounter sums the token usage of the sessions shown, reading only
+// what was appended to their logs since the last count
+type tokenCounter struct {
+	projectsDir string
+	sessions    map[string]*sessionTokens // by session ID
+}
+
+// sessionTokens is the usage counted so far from a session log
+type sessionTokens struct {
+	path     string // "" = not found yet
+	lookedUp time.Time
+	offset   int64
+	seen     map[string]bool // assistant messages, repeated by every content block
+	usage    TokenUsage
+}
+
+// newTokenCounter creates a tokenCounter finding session logs under
+// projectsDir
+func newTokenCounter(projectsDir string) *tokenCounter {
+	return &tokenCounter{projectsDir: projectsDir, sessions: make(map[string]*sessionTokens)}
+}
+
+// usage returns the token usage of a project's session. ok is false if
+// its log is not available here, e.g. when attached to a daemon on another
+// machine.
+func (c *tokenCounter) usage(status state.ProjectStatus, now time.Time) (TokenUsage, bool) {
+	if status.SessionID == "" {
+		return TokenUsage{}, false
+	}
+	s, ok := c.sessions[status.SessionID]
+	if !ok {
+		s = &sessionTokens{seen: make(map[string]bool)}
+		c.sessions[status.SessionID] = s
+	}
+	if s.path == "" {
+		if now.Sub(s.lookedUp) < logLookupInterval {
+			return TokenUsage{}, false
+		}
+		s.lookedUp = now
+		if s.path = c.find(status); s.path == "" {
+			return TokenUsage{}, false
+		}
+	}
+	s.read()
+	return s.usage, true
+}
+
+// find returns the log of a project's session, "" if there is none
+func (c *tokenCounter) find(status state.ProjectStatus) string {
+	name := status.SessionID + ".jsonl"
+	if status.FilePath != "" && filepath.Base(status.FilePath) == name {
+		return status.FilePath
+	}
+	if c.projectsDir == "" {
+		return ""
+	}
+	matches, _ := filepath.Glob(filepath.Join(c.projectsDir, "*", name))
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[0]
+}
+
+// read counts the entries appended to the log. A truncated log is counted
+// again from the start.
+func (s *sessionTokens) read() {
+	info, err := os.Stat(s.path)
+	if err != nil || info.Size() == s.offset {
+		return
+	}
+	if info.Size() < s.offset {
+		*s = sessionTokens{path: s.path, seen: make(map[string]bool)}
+	}
+	lines, offset, _ := readLines(s.path, s.offset)
+	s.offset = offset
+	for _, line := range lines {
+		entry, ok := transcript.Decode(line)
+		if !ok || entry.Type != parser.EntryTypeAssistant || entry.Message.Usage == nil {
+			continue
+		}
+		if id := entry.Message.ID; id != "" {
+			if s.seen[id] {
+				continue
+			}
+			s.seen[id] = true
+		}
+		s.usage.add(entry.Message.Usage)
+	}
+}
+
+// prune forgets sessions no longer shown
+func (c *tokenCounter) prune(statuses []state.ProjectStatus) {
+	shown := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		shown[status.SessionID] = true
+	}
+	for id := range c.sessions {
+		if !shown[id] {
+			delete(c.sessions, id)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// TunnelOptions configures an SSH tunnel to a remote daemon
+type TunnelOptions struct {
+	Destination string        // ssh destination, e.g. user@devbox
+	RemotePort  int           // daemon port on the remote host
+	LocalPort   int           // local end of the forward; 0 = RemotePort
+	SSHArgs     []string      // extra ssh arguments (-i key, -J jump, ...)
+	Token       string        // API token appended to the browser URL
+	Print       bool          // only print the ssh command
+	NoBrowser   bool          // do not open the browser
+	Timeout     time.Duration // how long to wait for the remote daemon
+}
+
+// tunnelPollInterval is how often the forwarded health endpoint is probed
+const tunnelPollInterval = 500 * time.Millisecond
+
+// SSHCommand returns the ssh arguments that forward the local port to the
+// daemon on the remote host's loopback interface
+func (o TunnelOptions) SSHCommand() []string {
+	args := []string{"ssh", "-N",
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ServerAliveInterval=30",
+		"-L", fmt.Sprintf("127.0.0.1:%d:127.0.0.1:%d", o.localPort(), o.RemotePort),
+	}
+	args = append(args, o.SSHArgs...)
+	return append(args, o.Destination)
+}
+
+// URL returns the Web UI URL on the local end of the tunnel
+func (o TunnelOptions) URL() string {
+	u := "http://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(o.localPort())) + "/"
+	if o.Token != "" {
+		u += "?token=" + url.QueryEscape(o.Token)
+	}
+	return u
+}
+
+func (o TunnelOptions) localPort() int {
+	if o.LocalPort == 0 {
+		return o.RemotePort
+	}
+	return o.LocalPort
+}
+
+// RunTunnel forwards a local port to a remote daemon over SSH, waits until
+// the daemon answers through the tunnel and opens the Web UI. The tunnel
+// stays up until interrupted or ssh exits.
+func RunTunnel(opts TunnelOptions) error {
+	command := opts.SSHCommand()
+	if opts.Print {
+		fmt.Println(shellJoin(command))
+		return nil
+	}
+
+	if _, err := exec.LookPath("ssh"); err != nil {
+		return fmt.Errorf("ssh not found in PATH; run this on a machine with OpenSSH, or forward the port yourself:\n  %s", shellJoin(command))
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(opts.localPort())))
+	if err != nil {
+		return fmt.Errorf("local port %d is in use (a daemon running here?); choose another with --local-port", opts.localPort())
+	}
+	ln.Close()
+
+	fmt.Printf("Opening tunnel: %s\n", shellJoin(command))
+
+	// ssh runs in the foreground so it can ask for passwords and host keys
+	ssh := exec.Command(command[0], command[1:]...)
+	ssh.Stdin, ssh.Stdout, ssh.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := ssh.Start(); err != nil {
+		return fmt.Errorf("failed to start ssh: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- ssh.Wait() }()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	ready := make(chan error, 1)
+	go func() { ready <- waitForDaemon(opts.URL(), opts.Timeout) }()
+
+	for {
+		select {
+		case err := <-ready:
+			if err != nil {
+				ssh.Process.Kill()
+				<-exited
+				return fmt.Errorf("tunnel is up but the daemon did not answer: %w (is 'claude-watch-status serve' running on %s?)", err, opts.Destination)
+			}
+			fmt.Printf("Connected to the daemon on %s: %s (Ctrl+C to close)\n", opts.Destination, strings.SplitN(opts.URL(), "?", 2)[0])
+			if !opts.NoBrowser {
+				if err := openBrowser(opts.URL()); err != nil {
+					fmt.Fprintf(os.Stderr, "Could not open a browser (%v); open the URL above\n", err)
+				}
+			}
+
+		case err := <-exited:
+			if err != nil {
+				return fmt.Errorf("ssh exited: %w", err)
+			}
+			return nil
+
+		case <-sigCh:
+			ssh.Process.Signal(os.Interrupt)
+			select {
+			case <-exited:
+			case <-time.After(2 * time.Second):
+				ssh.Process.Kill()
+				<-exited
+			}
+			fmt.Println()
+			fmt.Println("Tunnel closed.")
+			return nil
+		}
+	}
+}
+
+// waitForDaemon polls the daemon's health endpoint through the tunnel
+func waitForDaemon(base string, timeout time.Duration) error {
+	healthURL := strings.SplitN(base, "?", 2)[0] + "health"
+	client := &http.Client{Timeout: 2 * time.Second}
+	deadline := time.Now().Add(timeout)
+
+	var lastErr error
+	for time.Now().Before(deadline) {
+		resp, err := client.Get(healthURL)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+			err = fmt.Errorf("health check returned %s", resp.Status)
+		}
+		lastErr = err
+		time.Sleep(tunnelPollInterval)
+	}
+	if lastErr == nil {
+		lastErr = errors.New("timed out")
+	}
+	return lastErr
+}
+
+// openBrowser opens url in the default browser
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// shellJoin quotes arguments for display as a shell command
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\$`*?[]{}()<>|&;#~") {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
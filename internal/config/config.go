@@ -1,24 +1,289 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/i18n"
+	"github.com/sho7650/claude-watch-status/internal/redact"
+)
+
+// Tier represents the importance of a project for alert routing
+type Tier string
+
+const (
+	TierCritical   Tier = "critical"
+	TierNormal     Tier = "normal"
+	TierBackground Tier = "background"
 )
 
+// Valid reports whether the tier is one of the known tiers
+func (t Tier) Valid() bool {
+	switch t {
+	case TierCritical, TierNormal, TierBackground:
+		return true
+	}
+	return false
+}
+
 // Config holds the application configuration
 type Config struct {
-	ProjectsDir string
-	ServerPort  int
-	HooksPort   int
+	ProjectsDir string                   `json:"projects_dir,omitempty"`
+	ServerPort  int                      `json:"server_port,omitempty"`
+	HooksPort   int                      `json:"hooks_port,omitempty"`
+	Projects    map[string]ProjectConfig `json:"projects,omitempty"`
+
+	Notifications NotificationsConfig `json:"notifications"`
+
+	// Agents enables watching other agent CLIs' session logs, keyed by parser name
+	Agents map[string]AgentConfig `json:"agents,omitempty"`
+
+	Redaction RedactionConfig `json:"redaction"`
+
+	// Shortcuts run macOS Shortcuts on status transitions (serve only)
+	Shortcuts []ShortcutConfig `json:"shortcuts,omitempty"`
+
+	// Notifiers adds notification backends next to desktop notifications
+	Notifiers []NotifierConfig `json:"notifiers,omitempty"`
+
+	Retention RetentionConfig `json:"retention"`
+
+	Idle IdleConfig `json:"idle"`
+
+	Colors ColorsConfig `json:"colors"`
+
+	// Language of messages, notifications and the Web UI: "en" or "ja";
+	// empty = from the locale (LC_ALL, LC_MESSAGES, LANG)
+	Language string `json:"language,omitempty"`
+}
+
+// ColorsConfig sets the colors of terminal output
+type ColorsConfig struct {
+	// Theme is "dark" (default), "light" or "colorblind"
+	Theme string `json:"theme,omitempty"`
+	// States overrides the color of states by phase (user_input, working,
+	// waiting, ...): a color name such as "yellow" or "bright-blue", or
+	// ANSI SGR codes such as "1;38;5;208"
+	States map[string]string `json:"states,omitempty"`
+}
+
+// Idle detection limits
+const (
+	DefaultCompletedAfter = 5 * time.Second
+	MaxCompletedAfter     = 10 * time.Minute // idle detection gives up after this
+)
+
+// IdleConfig tunes idle detection of session logs without a definite
+// end of turn
+type IdleConfig struct {
+	// CompletedAfter is how long a reply must stay unchanged before the
+	// turn counts as completed; default 5s
+	CompletedAfter string `json:"completed_after,omitempty"`
+}
+
+// CompletedAfterDuration returns the idle time after which a turn counts
+// as completed
+func (i IdleConfig) CompletedAfterDuration() time.Duration {
+	d, err := time.ParseDuration(i.CompletedAfter)
+	if err != nil || d <= 0 {
+		return DefaultCompletedAfter
+	}
+	return d
+}
+
+// validate checks the idle thresholds
+func (i IdleConfig) validate() []error {
+	if i.CompletedAfter == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(i.CompletedAfter)
+	if err != nil || d <= 0 || d > MaxCompletedAfter {
+		return []error{fmt.Errorf("idle.completed_after: invalid duration %q (want e.g. \"10s\", at most %s)", i.CompletedAfter, MaxCompletedAfter)}
+	}
+	return nil
+}
+
+// Default retention of projects without activity
+const (
+	DefaultHideAfter   = 24 * time.Hour
+	DefaultDeleteAfter = 7 * 24 * time.Hour
+)
+
+// RetentionConfig sets how long projects without activity are shown and
+// kept. Values are Go durations ("36h") or days ("7d"); "0" disables.
+type RetentionConfig struct {
+	HideAfter   string `json:"hide_after,omitempty"`   // hidden from the dashboard and API; default 24h
+	DeleteAfter string `json:"delete_after,omitempty"` // forgotten; default 7d
+}
+
+// HideAfterDuration returns the hide TTL, 0 if disabled
+func (r RetentionConfig) HideAfterDuration() time.Duration {
+	d, _ := parseRetention(r.HideAfter, DefaultHideAfter)
+	return d
+}
+
+// DeleteAfterDuration returns the delete TTL, 0 if disabled
+func (r RetentionConfig) DeleteAfterDuration() time.Duration {
+	d, _ := parseRetention(r.DeleteAfter, DefaultDeleteAfter)
+	return d
+}
+
+// validate checks the retention durations
+func (r RetentionConfig) validate() []error {
+	var errs []error
+	hide, err := parseRetention(r.HideAfter, DefaultHideAfter)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("retention.hide_after: %w", err))
+	}
+	del, err := parseRetention(r.DeleteAfter, DefaultDeleteAfter)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("retention.delete_after: %w", err))
+	}
+	if len(errs) == 0 && hide > 0 && del > 0 && del < hide {
+		errs = append(errs, fmt.Errorf("retention.delete_after (%s) is shorter than hide_after (%s)", del, hide))
+	}
+	return errs
+}
+
+// parseRetention parses a retention duration, def if empty
+func parseRetention(s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q (e.g. \"36h\" or \"7d\")", s)
+	}
+	return d, nil
+}
+
+// ShortcutConfig runs a macOS Shortcut when a project enters one of the
+// given states
+type ShortcutConfig struct {
+	Name     string   `json:"name"`               // as shown in the Shortcuts app
+	On       []string `json:"on"`                 // started, user_input, working, waiting, completed, interrupted, error, ended
+	Projects []string `json:"projects,omitempty"` // empty = all projects
+}
+
+// NotifierConfig adds a notification backend. Type selects a registered
+// backend; the other settings are read by the backends that use them.
+// Token and User may name environment variables as $VAR.
+type NotifierConfig struct {
+	Type     string            `json:"type"`               // desktop, sound, webhook, slack, exec, ntfy, pushover
+	On       []string          `json:"on,omitempty"`       // events; empty = all
+	Projects []string          `json:"projects,omitempty"` // empty = all projects
+	URL      string            `json:"url,omitempty"`      // webhook, slack; ntfy server, default https://ntfy.sh
+	Topic    string            `json:"topic,omitempty"`    // ntfy topic
+	Token    string            `json:"token,omitempty"`    // ntfy access token (optional) or Pushover application token
+	User     string            `json:"user,omitempty"`     // Pushover user or group key
+	Headers  map[string]string `json:"headers,omitempty"`  // webhook
+	Command  []string          `json:"command,omitempty"`  // exec: program and arguments
+	File     string            `json:"file,omitempty"`     // sound: audio file; empty = beep
+	Sounds   map[string]string `json:"sounds,omitempty"`   // sound, desktop: audio file or system sound name per event
+	Options  map[string]string `json:"options,omitempty"`  // settings of other backends
+}
+
+// RedactionConfig holds secret redaction settings
+type RedactionConfig struct {
+	// Patterns are regular expressions masked in tool details and prompt
+	// snippets, in addition to the built-in credential formats
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// AgentConfig enables an additional agent CLI source
+type AgentConfig struct {
+	Enabled bool   `json:"enabled"`
+	Dir     string `json:"dir,omitempty"` // empty = the agent's default log directory
+}
+
+// NotificationsConfig holds notification settings
+type NotificationsConfig struct {
+	// Desktop enables desktop notifications from the serve daemon
+	// (the CLI modes always notify)
+	Desktop bool `json:"desktop"`
+
+	// DaemonHealth notifies when the serve daemon stops unexpectedly or its
+	// file watcher fails, independently of Desktop (nil = enabled)
+	DaemonHealth *bool `json:"daemon_health,omitempty"`
+
+	// UnattendedPermissions notifies when a session starts running tools
+	// without approval prompts (--dangerously-skip-permissions),
+	// independently of Desktop
+	UnattendedPermissions bool `json:"unattended_permissions"`
+
+	// Digest batches project notifications: after one is sent, those
+	// arriving within this window are sent together as one summary when it
+	// ends, e.g. "1m"; empty = off
+	Digest string `json:"digest,omitempty"`
+
+	// QuietHours suppresses notifications at set times
+	QuietHours QuietHoursConfig `json:"quiet_hours"`
+}
+
+// MaxDigest is the longest notification digest window
+const MaxDigest = time.Hour
+
+// DigestWindow returns the notification digest window, 0 if off
+func (n NotificationsConfig) DigestWindow() time.Duration {
+	d, err := time.ParseDuration(n.Digest)
+	if err != nil || d < 0 || d > MaxDigest {
+		return 0
+	}
+	return d
+}
+
+// validate checks the digest window and quiet hours
+func (n NotificationsConfig) validate() []error {
+	errs := n.QuietHours.validate()
+	if n.Digest == "" {
+		return errs
+	}
+	d, err := time.ParseDuration(n.Digest)
+	if err != nil || d < 0 || d > MaxDigest {
+		errs = append(errs, fmt.Errorf("notifications.digest: invalid duration %q (want e.g. \"1m\", at most %s)", n.Digest, MaxDigest))
+	}
+	return errs
+}
+
+// DaemonHealthEnabled reports whether daemon health notifications are enabled
+func (n NotificationsConfig) DaemonHealthEnabled() bool {
+	return n.DaemonHealth == nil || *n.DaemonHealth
+}
+
+// ProjectConfig holds per-project settings, keyed by project name
+type ProjectConfig struct {
+	// Path pins the project to a directory: sessions working in it (or
+	// below it) are reported under this project's name instead of the
+	// directory name, e.g. to tell apart two repositories named "api"
+	Path   string `json:"path,omitempty"`
+	Tier   Tier   `json:"tier,omitempty"`
+	Group  string `json:"group,omitempty"`  // free-form label, reported by the project API
+	Notify *bool  `json:"notify,omitempty"` // nil = enabled
 }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
-	homeDir, _ := os.UserHomeDir()
 	return &Config{
-		ProjectsDir: filepath.Join(homeDir, ".claude", "projects"),
+		ProjectsDir: GetProjectsDir(),
 		ServerPort:  10087,
 		HooksPort:   10087,
+		Projects:    make(map[string]ProjectConfig),
 	}
 }
 
@@ -31,6 +296,166 @@ func NewConfig(opts ...Option) *Config {
 	return cfg
 }
 
+// Load reads the configuration file at path on top of the defaults.
+// A missing file is not an error and yields the default configuration.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultConfig(), nil
+		}
+		return nil, err
+	}
+	return parseFile(path, data)
+}
+
+// parseFile decodes and validates the data of the configuration file at
+// path, as Load does
+func parseFile(path string, data []byte) (*Config, error) {
+	cfg, err := Parse(data, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	if dir := os.Getenv("CLAUDE_PROJECTS_DIR"); dir != "" {
+		cfg.ProjectsDir = dir
+	}
+	if err := errors.Join(cfg.Validate()...); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse decodes configuration data on top of the defaults.
+// The data may contain // line comments. In strict mode, unknown keys are rejected.
+func Parse(data []byte, strict bool) (*Config, error) {
+	cfg := DefaultConfig()
+
+	dec := json.NewDecoder(bytes.NewReader(stripComments(data)))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Projects == nil {
+		cfg.Projects = make(map[string]ProjectConfig)
+	}
+	return cfg, nil
+}
+
+// Validate checks the configuration for semantic errors
+func (c *Config) Validate() []error {
+	var errs []error
+
+	if c.ServerPort < 1 || c.ServerPort > 65535 {
+		errs = append(errs, fmt.Errorf("server_port %d is out of range", c.ServerPort))
+	}
+	if c.HooksPort < 1 || c.HooksPort > 65535 {
+		errs = append(errs, fmt.Errorf("hooks_port %d is out of range", c.HooksPort))
+	}
+
+	for _, name := range sortedKeys(c.Projects) {
+		p := c.Projects[name]
+		if p.Tier != "" && !p.Tier.Valid() {
+			errs = append(errs, fmt.Errorf("project %q has unknown tier %q (want critical, normal or background)", name, p.Tier))
+		}
+	}
+	errs = append(errs, c.validateProjectPaths()...)
+	errs = append(errs, c.Retention.validate()...)
+	errs = append(errs, c.Idle.validate()...)
+	errs = append(errs, c.Notifications.validate()...)
+
+	if err := redact.Compile(c.Redaction.Patterns); err != nil {
+		errs = append(errs, fmt.Errorf("redaction: %w", err))
+	}
+	if err := i18n.Check(c.Language); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TierFor returns the configured tier for a project (normal if unset)
+func (c *Config) TierFor(projectName string) Tier {
+	if p, ok := c.Projects[projectName]; ok && p.Tier != "" {
+		return p.Tier
+	}
+	return TierNormal
+}
+
+// GroupFor returns the configured group of a project ("" if unset)
+func (c *Config) GroupFor(projectName string) string {
+	return c.Projects[projectName].Group
+}
+
+// ProjectNameFor returns the name of the project whose path contains dir,
+// the innermost one if paths are nested, or "" if none does
+func (c *Config) ProjectNameFor(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	dir = filepath.Clean(dir)
+
+	var name, best string
+	for projectName, p := range c.Projects {
+		if p.Path == "" {
+			continue
+		}
+		path := filepath.Clean(ExpandHome(p.Path))
+		if !pathContains(path, dir) || len(path) <= len(best) {
+			continue
+		}
+		name, best = projectName, path
+	}
+	return name
+}
+
+// pathContains reports whether dir is root or inside it
+func pathContains(root, dir string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// validateProjectPaths checks that project paths are absolute and unique
+func (c *Config) validateProjectPaths() []error {
+	var errs []error
+	owner := make(map[string]string)
+	for _, name := range sortedKeys(c.Projects) {
+		p := c.Projects[name]
+		if p.Path == "" {
+			continue
+		}
+		path := filepath.Clean(ExpandHome(p.Path))
+		if !filepath.IsAbs(path) {
+			errs = append(errs, fmt.Errorf("project %q has relative path %q (want an absolute path or ~/...)", name, p.Path))
+			continue
+		}
+		if other, ok := owner[path]; ok {
+			errs = append(errs, fmt.Errorf("projects %q and %q have the same path %s", other, name, path))
+			continue
+		}
+		owner[path] = name
+	}
+	return errs
+}
+
+// NotifyEnabledFor reports whether notifications are enabled for a project
+func (c *Config) NotifyEnabledFor(projectName string) bool {
+	if p, ok := c.Projects[projectName]; ok && p.Notify != nil {
+		return *p.Notify
+	}
+	return true
+}
+
 // Option is a function that modifies the configuration
 type Option func(*Config)
 
@@ -61,6 +486,21 @@ func GetProjectsDir() string {
 	return filepath.Join(homeDir, ".claude", "projects")
 }
 
+// GetConfigPath returns the path to the configuration file, checking env var first.
+// The default is in the platform config directory:
+// $XDG_CONFIG_HOME (~/.config) on Linux, ~/Library/Application Support on macOS.
+func GetConfigPath() string {
+	if path := os.Getenv("CWS_CONFIG"); path != "" {
+		return path
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		homeDir, _ := os.UserHomeDir()
+		configDir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configDir, "claude-watch-status", "config.json")
+}
+
 // GetClaudeDir returns the Claude configuration directory
 func GetClaudeDir() string {
 	homeDir, _ := os.UserHomeDir()
@@ -76,3 +516,90 @@ func GetSettingsPath() string {
 func GetHooksDir() string {
 	return filepath.Join(GetClaudeDir(), "hooks")
 }
+
+// GetTokenPath returns the path to the hook shared-secret token
+func GetTokenPath() string {
+	return filepath.Join(GetHooksDir(), "cws-token")
+}
+
+// GetDaemonLockPath returns the lock file recording the running daemon's
+// PID and address
+func GetDaemonLockPath() string {
+	return filepath.Join(GetClaudeDir(), "cws", "daemon.json")
+}
+
+// GetSpoolDir returns the directory where hook-relay keeps events while the
+// daemon is unreachable ($XDG_CACHE_HOME on Linux, ~/Library/Caches on macOS)
+func GetSpoolDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		homeDir, _ := os.UserHomeDir()
+		cacheDir = filepath.Join(homeDir, ".cache")
+	}
+	return filepath.Join(cacheDir, "claude-watch-status", "hook-spool")
+}
+
+// GetNameCachePath returns the file caching resolved project names across
+// restarts, in the same cache directory as the hook spool
+func GetNameCachePath() string {
+	return filepath.Join(filepath.Dir(GetSpoolDir()), "project-names.json")
+}
+
+// GetTerminalCacheDir returns the directory where the hook relay caches
+// the controlling terminal of each session
+func GetTerminalCacheDir() string {
+	return filepath.Join(filepath.Dir(GetSpoolDir()), "terminals")
+}
+
+// GetJournalPath returns the write-ahead journal of hook events, which the
+// daemon replays on startup
+func GetJournalPath() string {
+	return filepath.Join(filepath.Dir(GetSpoolDir()), "hooks.journal")
+}
+
+// GetDataDir returns the directory for persistent data: $XDG_DATA_HOME
+// (~/.local/share) on Linux, the config directory on macOS and Windows
+func GetDataDir() string {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		if dir, err := os.UserConfigDir(); err == nil {
+			return filepath.Join(dir, "claude-watch-status")
+		}
+	}
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		homeDir, _ := os.UserHomeDir()
+		dataDir = filepath.Join(homeDir, ".local", "share")
+	}
+	return filepath.Join(dataDir, "claude-watch-status")
+}
+
+// GetHistoryPath returns the file keeping daily activity statistics
+func GetHistoryPath() string {
+	return filepath.Join(GetDataDir(), "history.json")
+}
+
+// GetPrefsPath returns the file keeping preferences changed at runtime,
+// such as mutes set through the API, next to the daemon lock file
+func GetPrefsPath() string {
+	return filepath.Join(GetClaudeDir(), "cws", "prefs.json")
+}
+
+// GetOldPrefsPath returns where earlier builds kept the preferences file,
+// which the daemon moves to GetPrefsPath
+func GetOldPrefsPath() string {
+	return filepath.Join(GetDataDir(), "prefs.json")
+}
+
+// GetAPIToken returns the bearer token for the read API from the environment
+func GetAPIToken() string {
+	return os.Getenv("CWS_API_TOKEN")
+}
+
+// ExpandHome replaces a leading "~/" in path with the user's home directory
+func ExpandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		homeDir, _ := os.UserHomeDir()
+		return filepath.Join(homeDir, path[1:])
+	}
+	return path
+}
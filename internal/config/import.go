@@ -0,0 +1,356 @@
+package config
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectMapping maps a project directory to its settings, as read by
+// config import-projects
+type ProjectMapping struct {
+	Path  string `json:"path"`
+	Alias string `json:"alias,omitempty"` // project name; default: the directory name
+	Tier  Tier   `json:"tier,omitempty"`
+	Group string `json:"group,omitempty"`
+
+	Pos string `json:"-"` // "line 3" (CSV) or "entry 3" (JSON), for errors
+}
+
+// Name returns the project name the mapping defines
+func (m ProjectMapping) Name() string {
+	if m.Alias != "" {
+		return m.Alias
+	}
+	return filepath.Base(filepath.Clean(ExpandHome(m.Path)))
+}
+
+// ReadProjectMappings reads project mappings from a CSV file with a
+// header row (path, alias, tier, group; only path is required) or a JSON
+// array of objects with the same keys. The format follows the extension.
+func ReadProjectMappings(path string) ([]ProjectMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return parseMappingsCSV(bytes.NewReader(data))
+	case ".json":
+		return parseMappingsJSON(data)
+	default:
+		return nil, fmt.Errorf("unknown mapping format %q (want .csv or .json)", filepath.Ext(path))
+	}
+}
+
+func parseMappingsCSV(r io.Reader) ([]ProjectMapping, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("missing header row: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "path", "alias", "tier", "group":
+			columns[name] = i
+		default:
+			return nil, fmt.Errorf("unknown column %q (want path, alias, tier, group)", name)
+		}
+	}
+	if _, ok := columns["path"]; !ok {
+		return nil, errors.New("missing path column")
+	}
+
+	var mappings []ProjectMapping
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return mappings, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		mappings = append(mappings, ProjectMapping{
+			Path:  field("path"),
+			Alias: field("alias"),
+			Tier:  Tier(field("tier")),
+			Group: field("group"),
+			Pos:   fmt.Sprintf("line %d", line),
+		})
+	}
+}
+
+func parseMappingsJSON(data []byte) ([]ProjectMapping, error) {
+	dec := json.NewDecoder(bytes.NewReader(stripComments(data)))
+	dec.DisallowUnknownFields()
+	var mappings []ProjectMapping
+	if err := dec.Decode(&mappings); err != nil {
+		return nil, err
+	}
+	for i := range mappings {
+		mappings[i].Pos = fmt.Sprintf("entry %d", i+1)
+	}
+	return mappings, nil
+}
+
+// ImportResult lists the projects changed by MergeProjects
+type ImportResult struct {
+	Added   []string
+	Updated []string
+}
+
+// MergeProjects merges mappings into the project settings. Tier and group
+// are only changed if the mapping sets them; other settings are kept. A
+// project already pinned to the same path is renamed to the new alias.
+// Nothing is changed if any mapping is invalid: a missing or unknown
+// path, an unknown tier, or a path or name used twice.
+func (c *Config) MergeProjects(mappings []ProjectMapping) (ImportResult, []error) {
+	var errs []error
+	fail := func(m ProjectMapping, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", m.Pos, fmt.Sprintf(format, args...)))
+	}
+
+	// Existing projects by path, so re-imports update them in place
+	byPath := make(map[string]string)
+	for name, p := range c.Projects {
+		if p.Path != "" {
+			byPath[filepath.Clean(ExpandHome(p.Path))] = name
+		}
+	}
+
+	seenPaths := make(map[string]string)
+	seenNames := make(map[string]string)
+	for _, m := range mappings {
+		if m.Path == "" {
+			fail(m, "missing path")
+			continue
+		}
+		path := filepath.Clean(ExpandHome(m.Path))
+		if !filepath.IsAbs(path) {
+			fail(m, "path %q is not absolute", m.Path)
+			continue
+		}
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			fail(m, "unknown path %s (no such directory)", path)
+			continue
+		}
+		if m.Tier != "" && !m.Tier.Valid() {
+			fail(m, "unknown tier %q (want critical, normal or background)", m.Tier)
+		}
+		if pos, ok := seenPaths[path]; ok {
+			fail(m, "duplicate path %s (also on %s)", path, pos)
+		}
+		seenPaths[path] = m.Pos
+		name := m.Name()
+		if pos, ok := seenNames[name]; ok {
+			fail(m, "duplicate name %q (also on %s)", name, pos)
+		}
+		seenNames[name] = m.Pos
+		if p, ok := c.Projects[name]; ok && p.Path != "" && filepath.Clean(ExpandHome(p.Path)) != path {
+			fail(m, "name %q is already used for %s", name, p.Path)
+		}
+	}
+	if len(errs) > 0 {
+		return ImportResult{}, errs
+	}
+
+	var result ImportResult
+	for _, m := range mappings {
+		path := filepath.Clean(ExpandHome(m.Path))
+		name := m.Name()
+
+		p, exists := c.Projects[name]
+		renamed := false
+		if old, ok := byPath[path]; ok && old != name {
+			// Renamed: keep the settings of the old name
+			if !exists {
+				p = c.Projects[old]
+			}
+			delete(c.Projects, old)
+			renamed = true
+		}
+		before := p
+		p.Path = m.Path
+		if m.Tier != "" {
+			p.Tier = m.Tier
+		}
+		if m.Group != "" {
+			p.Group = m.Group
+		}
+		c.Projects[name] = p
+
+		switch {
+		case !exists && !renamed:
+			result.Added = append(result.Added, name)
+		case renamed || p != before:
+			result.Updated = append(result.Updated, name)
+		}
+	}
+	return result, nil
+}
+
+// SetProjects returns config file data with the "projects" object
+// replaced by projects, keeping comments and the rest of the file as
+// written. The key is added if the file has none.
+func SetProjects(data []byte, projects map[string]ProjectConfig) ([]byte, error) {
+	return setMember(data, []string{"projects"}, projects, "  ")
+}
+
+// setMember returns JSON-with-comments object data with the member at
+// path (a key, then keys of nested objects) set to v, keeping comments
+// and the rest of the object as written. Missing members are added at
+// the end; indent is the indentation of the object's members.
+func setMember(data []byte, path []string, v any, indent string) ([]byte, error) {
+	start, end, found, closing := findTopLevelValue(data, path[0])
+	if closing < 0 {
+		return nil, errors.New("config file is not a JSON object")
+	}
+	var out bytes.Buffer
+	if found && len(path) > 1 && data[start] == '{' {
+		value, err := setMember(data[start:end], path[1:], v, indent+"  ")
+		if err != nil {
+			return nil, err
+		}
+		out.Write(data[:start])
+		out.Write(value)
+		out.Write(data[end:])
+		return out.Bytes(), nil
+	}
+	for i := len(path) - 1; i > 0; i-- {
+		v = map[string]any{path[i]: v}
+	}
+	value, err := json.MarshalIndent(v, indent, "  ")
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		out.Write(data[:start])
+		out.Write(value)
+		out.Write(data[end:])
+		return out.Bytes(), nil
+	}
+
+	// Append after the last member, before comments closing the object
+	last := lastSignificant(data[:closing])
+	out.Write(data[:last+1])
+	if data[last] != '{' {
+		out.WriteByte(',')
+	}
+	out.WriteString("\n")
+	if len(indent) == 2 {
+		out.WriteString("\n") // top-level sections are separated by blank lines
+	}
+	out.WriteString(indent + "\"" + path[0] + "\": ")
+	out.Write(value)
+	if last+1 == closing {
+		out.WriteString("\n" + indent[2:])
+	}
+	out.Write(data[last+1:])
+	return out.Bytes(), nil
+}
+
+// findTopLevelValue locates the value of key in the top-level object of
+// JSON-with-comments data: [start, end) if found, and the position of
+// the object's closing brace (-1 if there is none)
+func findTopLevelValue(data []byte, key string) (start, end int, found bool, closing int) {
+	depth := 0
+	inString, escaped := false, false
+	stringStart := -1
+	var lastString string
+	expectValue := false
+	closing = -1
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+				lastString = string(data[stringStart+1 : i])
+			}
+			continue
+		}
+		if c == '/' && i+1 < len(data) && data[i+1] == '/' {
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			continue
+		}
+		if expectValue && !isSpace(c) {
+			expectValue = false
+			start = i
+			found = true
+		}
+		switch c {
+		case '"':
+			inString, stringStart = true, i
+		case ':':
+			if depth == 1 && lastString == key && !found {
+				expectValue = true
+			}
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 1 && found && end == 0 {
+				end = i + 1
+			}
+			if depth == 0 {
+				if found && end == 0 {
+					end = lastSignificant(data[:i]) + 1
+				}
+				closing = i
+				return
+			}
+		case ',':
+			if depth == 1 && found && end == 0 {
+				end = lastSignificant(data[:i]) + 1
+			}
+		}
+	}
+	return
+}
+
+// lastSignificant returns the position of the last byte of data that is
+// not whitespace or part of a // comment
+func lastSignificant(data []byte) int {
+	lines := bytes.Split(data, []byte("\n"))
+	offset := len(data)
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := lines[i]
+		offset -= len(line)
+		code := stripComments(line)
+		if trimmed := bytes.TrimRight(code, " \t\r"); len(trimmed) > 0 {
+			return offset + len(trimmed) - 1
+		}
+		offset-- // the newline
+	}
+	return -1
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+)
+
+// restartSections are the config sections a reload cannot apply to a
+// running daemon
+var restartSections = map[string]bool{
+	"projects_dir": true,
+	"server_port":  true,
+	"hooks_port":   true,
+	"agents":       true,
+	"shortcuts":    true,
+}
+
+// Live is the configuration of a running daemon, which Reload replaces
+// without a restart. Its lookups always use the current configuration,
+// so they can be handed out once at startup.
+type Live struct {
+	path      string
+	cur       atomic.Pointer[Config]
+	mu        sync.Mutex // serializes reloads
+	validate  func(cfg *Config) error
+	listeners []func(cfg *Config)
+}
+
+// ReloadResult lists the config sections a reload changed
+type ReloadResult struct {
+	Changed         []string `json:"changed"`
+	RestartRequired []string `json:"restart_required,omitempty"` // changed, but only applied on restart
+}
+
+// NewLive creates a Live configuration loaded from path as cfg
+func NewLive(path string, cfg *Config) *Live {
+	l := &Live{path: path}
+	l.cur.Store(cfg)
+	return l
+}
+
+// Path returns the configuration file
+func (l *Live) Path() string {
+	return l.path
+}
+
+// Get returns the current configuration, which must not be modified
+func (l *Live) Get() *Config {
+	return l.cur.Load()
+}
+
+// SetValidator adds a check a reloaded configuration must pass on top of
+// Validate, e.g. of sections other packages interpret
+func (l *Live) SetValidator(fn func(cfg *Config) error) {
+	l.validate = fn
+}
+
+// OnReload registers fn to apply a reloaded configuration
+func (l *Live) OnReload(fn func(cfg *Config)) {
+	l.listeners = append(l.listeners, fn)
+}
+
+// Check reports whether data would be accepted by Reload as the new
+// content of the configuration file, without applying it
+func (l *Live) Check(data []byte) error {
+	cfg, err := parseFile(l.path, data)
+	if err != nil {
+		return err
+	}
+	if l.validate != nil {
+		return l.validate(cfg)
+	}
+	return nil
+}
+
+// Reload reads the configuration file again and applies it. An invalid
+// file is reported and leaves the current configuration in place.
+func (l *Live) Reload() (ReloadResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cfg, err := Load(l.path)
+	if err != nil {
+		return ReloadResult{}, err
+	}
+	if l.validate != nil {
+		if err := l.validate(cfg); err != nil {
+			return ReloadResult{}, err
+		}
+	}
+
+	result := ReloadResult{Changed: changedSections(l.cur.Load(), cfg)}
+	for _, section := range result.Changed {
+		if restartSections[section] {
+			result.RestartRequired = append(result.RestartRequired, section)
+		}
+	}
+	l.cur.Store(cfg)
+	for _, fn := range l.listeners {
+		fn(cfg)
+	}
+	return result, nil
+}
+
+// changedSections returns the top-level keys whose values differ, sorted
+func changedSections(old, cfg *Config) []string {
+	before, after := sections(old), sections(cfg)
+	keys := make(map[string]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+	changed := []string{}
+	for _, key := range sortedKeys(keys) {
+		if !bytes.Equal(before[key], after[key]) {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
+
+// sections returns the JSON encoding of each top-level key of cfg
+func sections(cfg *Config) map[string]json.RawMessage {
+	data, _ := json.Marshal(cfg)
+	var m map[string]json.RawMessage
+	json.Unmarshal(data, &m)
+	return m
+}
+
+// TierFor returns the configured tier for a project (normal if unset)
+func (l *Live) TierFor(projectName string) Tier {
+	return l.Get().TierFor(projectName)
+}
+
+// GroupFor returns the configured group of a project ("" if unset)
+func (l *Live) GroupFor(projectName string) string {
+	return l.Get().GroupFor(projectName)
+}
+
+// ProjectNameFor returns the configured name of the project containing dir
+func (l *Live) ProjectNameFor(dir string) string {
+	return l.Get().ProjectNameFor(dir)
+}
+
+// NotifyEnabledFor reports whether notifications are enabled for a project
+func (l *Live) NotifyEnabledFor(projectName string) bool {
+	return l.Get().NotifyEnabledFor(projectName)
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QuietHoursConfig suppresses notifications at set times, e.g. at night
+// and on weekends. Statuses, the journal and statistics are still
+// recorded.
+type QuietHoursConfig struct {
+	// Periods are the quiet times; notifications are suppressed during
+	// any of them
+	Periods []QuietPeriod `json:"periods,omitempty"`
+
+	// AllowWaitingApproval still notifies when a project waits for approval
+	AllowWaitingApproval bool `json:"allow_waiting_approval,omitempty"`
+}
+
+// QuietPeriod is a daily time range in local time, such as 22:00-08:00,
+// on some weekdays. Without From and To the whole day is quiet; without
+// Days every day is. A range past midnight belongs to the day it starts.
+type QuietPeriod struct {
+	From string   `json:"from,omitempty"` // "22:00"
+	To   string   `json:"to,omitempty"`   // "08:00"
+	Days []string `json:"days,omitempty"` // mon, tue, wed, thu, fri, sat, sun
+}
+
+// weekdays are the names of QuietPeriod.Days
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Silences reports whether notifications are suppressed at t;
+// waitingApproval tells whether the notification is about a project
+// waiting for approval
+func (q QuietHoursConfig) Silences(t time.Time, waitingApproval bool) bool {
+	if waitingApproval && q.AllowWaitingApproval {
+		return false
+	}
+	for _, p := range q.Periods {
+		if p.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether t falls in the period; invalid periods, which
+// validation rejects, contain nothing
+func (p QuietPeriod) contains(t time.Time) bool {
+	from, to, err := p.minutes()
+	if err != nil {
+		return false
+	}
+	now := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	switch {
+	case from == to: // the whole day
+	case from < to:
+		if now < from || now >= to {
+			return false
+		}
+	case now >= to && now < from:
+		return false
+	case now < to:
+		// The early part of a range past midnight belongs to the day before
+		day = (day + 6) % 7
+	}
+	return p.onDay(day)
+}
+
+// onDay reports whether the period applies on a weekday
+func (p QuietPeriod) onDay(day time.Weekday) bool {
+	if len(p.Days) == 0 {
+		return true
+	}
+	for _, name := range p.Days {
+		if weekdays[strings.ToLower(name)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// minutes returns From and To as minutes after midnight
+func (p QuietPeriod) minutes() (from, to int, err error) {
+	if (p.From == "") != (p.To == "") {
+		return 0, 0, fmt.Errorf("from and to must be set together")
+	}
+	if p.From == "" {
+		return 0, 0, nil
+	}
+	if from, err = clockMinutes(p.From); err != nil {
+		return 0, 0, err
+	}
+	if to, err = clockMinutes(p.To); err != nil {
+		return 0, 0, err
+	}
+	return from, to, nil
+}
+
+// clockMinutes parses a time of day such as "22:00"
+func clockMinutes(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (want e.g. \"22:00\")", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// validate checks the periods
+func (q QuietHoursConfig) validate() []error {
+	var errs []error
+	for i, p := range q.Periods {
+		if _, _, err := p.minutes(); err != nil {
+			errs = append(errs, fmt.Errorf("notifications.quiet_hours.periods[%d]: %w", i, err))
+		}
+		for _, name := range p.Days {
+			if _, ok := weekdays[strings.ToLower(name)]; !ok {
+				errs = append(errs, fmt.Errorf("notifications.quiet_hours.periods[%d]: unknown day %q (want mon, tue, wed, thu, fri, sat or sun)", i, name))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Settings are the parts of the configuration the daemon's API changes
+// at runtime: notification toggles, the idle threshold and muted projects
+type Settings struct {
+	Desktop               bool     `json:"desktop"`
+	DaemonHealth          bool     `json:"daemon_health"`
+	UnattendedPermissions bool     `json:"unattended_permissions"`
+	IdleCompletedAfter    string   `json:"idle_completed_after"`
+	MutedProjects         []string `json:"muted_projects"` // projects with "notify": false
+}
+
+// SettingsUpdate changes some settings; nil fields are left as they are
+type SettingsUpdate struct {
+	Desktop               *bool     `json:"desktop,omitempty"`
+	DaemonHealth          *bool     `json:"daemon_health,omitempty"`
+	UnattendedPermissions *bool     `json:"unattended_permissions,omitempty"`
+	IdleCompletedAfter    *string   `json:"idle_completed_after,omitempty"` // "" = default
+	MutedProjects         *[]string `json:"muted_projects,omitempty"`       // replaces the list
+}
+
+// Settings returns the runtime settings of the configuration
+func (c *Config) Settings() Settings {
+	muted := []string{}
+	for _, name := range sortedKeys(c.Projects) {
+		if !c.NotifyEnabledFor(name) {
+			muted = append(muted, name)
+		}
+	}
+	return Settings{
+		Desktop:               c.Notifications.Desktop,
+		DaemonHealth:          c.Notifications.DaemonHealthEnabled(),
+		UnattendedPermissions: c.Notifications.UnattendedPermissions,
+		IdleCompletedAfter:    c.Idle.CompletedAfterDuration().String(),
+		MutedProjects:         muted,
+	}
+}
+
+// UpdateSettings returns config file data with u applied. Only the
+// changed values are rewritten; comments and the rest of the file are
+// kept as written.
+func UpdateSettings(data []byte, u SettingsUpdate) ([]byte, error) {
+	cfg, err := Parse(data, false)
+	if err != nil {
+		return nil, err
+	}
+
+	type change struct {
+		path  []string
+		value any
+	}
+	var changes []change
+	if u.Desktop != nil {
+		cfg.Notifications.Desktop = *u.Desktop
+		changes = append(changes, change{[]string{"notifications", "desktop"}, *u.Desktop})
+	}
+	if u.DaemonHealth != nil {
+		cfg.Notifications.DaemonHealth = u.DaemonHealth
+		changes = append(changes, change{[]string{"notifications", "daemon_health"}, *u.DaemonHealth})
+	}
+	if u.UnattendedPermissions != nil {
+		cfg.Notifications.UnattendedPermissions = *u.UnattendedPermissions
+		changes = append(changes, change{[]string{"notifications", "unattended_permissions"}, *u.UnattendedPermissions})
+	}
+	if u.IdleCompletedAfter != nil {
+		cfg.Idle.CompletedAfter = *u.IdleCompletedAfter
+		changes = append(changes, change{[]string{"idle", "completed_after"}, cfg.Idle.CompletedAfter})
+	}
+	if u.MutedProjects != nil {
+		for _, name := range sortedKeys(cfg.Projects) {
+			if !cfg.NotifyEnabledFor(name) && !slices.Contains(*u.MutedProjects, name) {
+				cfg.setNotify(name, true)
+				changes = append(changes, change{[]string{"projects", name, "notify"}, true})
+			}
+		}
+		for _, name := range *u.MutedProjects {
+			if cfg.NotifyEnabledFor(name) {
+				cfg.setNotify(name, false)
+				changes = append(changes, change{[]string{"projects", name, "notify"}, false})
+			}
+		}
+	}
+	if err := errors.Join(cfg.Validate()...); err != nil {
+		return nil, err
+	}
+
+	for _, c := range changes {
+		if data, err = setMember(data, c.path, c.value, "  "); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// setNotify enables or disables notifications of a project
+func (c *Config) setNotify(projectName string, enabled bool) {
+	p := c.Projects[projectName]
+	p.Notify = &enabled
+	c.Projects[projectName] = p
+}
+
+// WriteFile replaces the configuration file at path with data atomically:
+// a crash leaves either the old or the new file, never a truncated one.
+// The file keeps its mode, and a symlinked file is replaced at its target.
+func WriteFile(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".config-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
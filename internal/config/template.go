@@ -0,0 +1,190 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GenerateTemplate generates a commented configuration file with the given values
+func GenerateTemplate(cfg *Config) string {
+	projectsDir, _ := json.Marshal(cfg.ProjectsDir)
+
+	return fmt.Sprintf(`// Claude Watch Status - Configuration
+// Generated by: claude-watch-status config init
+//
+// Lines starting with // are comments. Check this file with:
+//   claude-watch-status config validate
+{
+  // Directory containing Claude Code session logs
+  // (the CLAUDE_PROJECTS_DIR environment variable takes precedence)
+  "projects_dir": %s,
+
+  // Port for the Web UI and API server (serve --port takes precedence)
+  "server_port": %d,
+
+  // Port the hook script sends events to (init --port takes precedence)
+  "hooks_port": %d,
+
+  "notifications": {
+    // Send desktop notifications from the serve daemon (serve --notify)
+    "desktop": %t,
+
+    // Notify when the daemon stops unexpectedly or stops watching session
+    // logs, so a frozen dashboard is not mistaken for a quiet one
+    "daemon_health": %t,
+
+    // Batch notifications: those arriving within this long after one was
+    // sent are summed up in a single "3 completed, 1 waiting approval"
+    // "digest": "1m",
+
+    // Quiet hours: no notifications at these times (local time; a range
+    // past midnight belongs to the day it starts; without from/to the
+    // whole day). Statuses are still recorded.
+    // "quiet_hours": {
+    //   "periods": [
+    //     { "from": "22:00", "to": "08:00" },
+    //     { "days": ["sat", "sun"] }
+    //   ],
+    //   "allow_waiting_approval": true
+    // },
+
+    // Warn when a session runs tools without approval prompts
+    // (--dangerously-skip-permissions or permissions.defaultMode
+    // "bypassPermissions"), even if "desktop" is off
+    "unattended_permissions": %t
+  },
+
+  // Other agent CLIs to monitor alongside Claude Code (serve only)
+  "agents": {
+    // "codex": { "enabled": true, "dir": "~/.codex/sessions" }
+  },
+
+  // Secret redaction: regular expressions masked as *** in tool details and
+  // prompt snippets, on top of the built-in credential formats (API keys,
+  // tokens, passwords in URLs, TOKEN=... assignments, private keys)
+  "redaction": {
+    "patterns": [
+      // "customer-[0-9]+", "(?i)internal\\.example\\.com"
+    ]
+  },
+
+  // More notification backends, next to desktop notifications. A "desktop"
+  // entry replaces the built-in one, e.g. to limit it to some events.
+  //   type:     desktop (sounds), sound (file, sounds), webhook (url,
+  //             headers), slack (url), exec (command: program and
+  //             arguments; gets CWS_EVENT, CWS_PROJECT, CWS_STATE,
+  //             CWS_TOOL, ... in the environment), ntfy (topic, optional
+  //             url of the server and token), pushover (token of the
+  //             application, user or group key); token and user may
+  //             name environment variables ("$PUSHOVER_TOKEN")
+  //   on:       waiting_approval, completed, interrupted, session_start,
+  //             session_end, unattended_permissions, daemon_stopped,
+  //             watcher_failed (default: all)
+  //   projects: limit to these projects (default: all)
+  //   sounds:   a sound per event: an audio file or a system sound name
+  //             ("Glass" on macOS, "complete" on Linux, "Asterisk" on Windows)
+  "notifiers": [
+    // { "type": "slack", "url": "https://hooks.slack.com/services/...", "on": ["waiting_approval"] },
+    // { "type": "exec", "command": ["tmux", "display-message", "Claude needs you"], "on": ["waiting_approval"] },
+    // { "type": "ntfy", "topic": "my-claude-alerts", "on": ["waiting_approval"] },
+    // { "type": "sound", "file": "/System/Library/Sounds/Glass.aiff", "projects": ["deploy"] },
+    // { "type": "desktop", "sounds": { "waiting_approval": "Sosumi", "completed": "Glass" } }
+  ],
+
+  // macOS Shortcuts to run when a project enters a state (serve only).
+  // The Shortcut receives a JSON dictionary with project, state, phase,
+  // icon, detail, session_id, tier and time as its input.
+  //   on: started, user_input, working, waiting, completed, interrupted,
+  //       error, ended
+  "shortcuts": [
+    // { "name": "Flash Hue Lights", "on": ["waiting"] },
+    // { "name": "Text Me", "on": ["completed", "error"], "projects": ["deploy"] }
+  ],
+
+  // Projects without activity are hidden from the dashboard and the API
+  // after hide_after, and forgotten after delete_after. Durations such as
+  // "36h" or days such as "7d"; "0" keeps projects forever.
+  "retention": {
+    // "hide_after": "24h",
+    // "delete_after": "7d"
+  },
+
+  // Idle detection: how long a reply must stay unchanged before the turn
+  // counts as completed when the session log has no definite end of turn
+  "idle": {
+    // "completed_after": "5s"
+  },
+
+  // Colors of the terminal views (stream, dashboard); NO_COLOR, CLICOLOR
+  // and --no-color turn them off
+  //   theme:  "dark" (default), "light" for light terminal backgrounds,
+  //           "colorblind" for colors told apart without red and green
+  //   states: color by phase (started, user_input, working, waiting,
+  //           completed, interrupted, error, ended): a name (red, yellow,
+  //           bright-blue, gray, bold, ...) or SGR codes ("1;38;5;208")
+  "colors": {
+    // "theme": "light",
+    // "states": { "waiting": "bold bright-magenta" }
+  },
+
+  // Language of terminal messages, notifications and the Web UI: "en" or
+  // "ja"; without it the locale (LC_ALL, LC_MESSAGES, LANG) decides
+  // "language": "ja",
+
+  // Per-project settings, keyed by project name
+  //   tier:   "critical"   - louder waiting-approval alerts
+  //           "normal"     - default
+  //           "background" - no desktop notifications, dashboard only
+  //   notify: false        - disable notifications for this project
+  //   path:   "~/work/a/api" - report sessions in this directory under
+  //           this name instead of the directory name (an alias)
+  //   group:  "backend"    - label reported by the project API
+  // Import many at once with: claude-watch-status config import-projects
+  "projects": {
+    // "my-important-project": { "tier": "critical" },
+    // "scratch": { "tier": "background" },
+    // "noisy": { "notify": false },
+    // "acme-api": { "path": "~/work/acme/api", "group": "acme" }
+  }
+}
+`, projectsDir, cfg.ServerPort, cfg.HooksPort, cfg.Notifications.Desktop, cfg.Notifications.DaemonHealthEnabled(), cfg.Notifications.UnattendedPermissions)
+}
+
+// stripComments removes // line comments outside of JSON strings
+func stripComments(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString, escaped := false, false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		if inString {
+			out = append(out, c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		if c == '/' && i+1 < len(data) && data[i+1] == '/' {
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+			continue
+		}
+
+		if c == '"' {
+			inString = true
+		}
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,139 @@
+// Package daemon keeps a single daemon running per user: serve records
+// its PID and address in a lock file, which client commands read to find
+// the daemon and a second serve reads to refuse to start or to take over.
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Info describes a running daemon, as recorded in its lock file
+type Info struct {
+	PID       int       `json:"pid"`
+	Port      int       `json:"port"`
+	Bind      string    `json:"bind,omitempty"` // "" = all interfaces
+	TLS       bool      `json:"tls,omitempty"`
+	Version   string    `json:"version,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// URL returns the address local clients reach the daemon at
+func (i Info) URL() string {
+	host := i.Bind
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	scheme := "http"
+	if i.TLS {
+		scheme = "https"
+	}
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(i.Port))
+}
+
+// RunningError reports that another daemon holds the lock
+type RunningError struct {
+	Info Info
+}
+
+func (e *RunningError) Error() string {
+	return fmt.Sprintf("a daemon is already running (pid %d, %s)", e.Info.PID, e.Info.URL())
+}
+
+// Lock is the lock file of the running daemon
+type Lock struct {
+	path string
+	pid  int
+}
+
+// Acquire creates the lock file at path for this process. It returns a
+// *RunningError if a live daemon holds it; a lock file left by a daemon
+// that died is replaced.
+func Acquire(path string, info Info) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	info.PID = os.Getpid()
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			_, err = f.Write(append(data, '\n'))
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, err
+			}
+			return &Lock{path: path, pid: info.PID}, nil
+		}
+		if !errors.Is(err, os.ErrExist) || attempt > 0 {
+			return nil, err
+		}
+		if running, ok := Running(path); ok {
+			return nil, &RunningError{Info: running}
+		}
+		// Stale: the daemon that wrote it is gone
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+}
+
+// Release removes the lock file, unless another daemon took it over
+func (l *Lock) Release() error {
+	if info, err := read(l.path); err != nil || info.PID != l.pid {
+		return nil
+	}
+	return os.Remove(l.path)
+}
+
+// Running returns the daemon recorded in the lock file at path, if its
+// process is still alive
+func Running(path string) (Info, bool) {
+	info, err := read(path)
+	if err != nil || info.PID <= 0 || !processAlive(info.PID) {
+		return Info{}, false
+	}
+	return info, true
+}
+
+// Stop asks a running daemon to shut down and waits until its lock file
+// is released, up to timeout
+func Stop(path string, info Info, timeout time.Duration) error {
+	if err := terminate(info.PID); err != nil {
+		return fmt.Errorf("stopping daemon (pid %d): %w", info.PID, err)
+	}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, ok := Running(path); !ok {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("daemon (pid %d) did not stop within %s", info.PID, timeout)
+}
+
+// read parses a lock file
+func read(path string) (Info, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Info{}, err
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return Info{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return info, nil
+}
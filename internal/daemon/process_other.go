@@ -0,0 +1,26 @@
+//go:build !unix
+
+package daemon
+
+import "os"
+
+// processAlive reports whether a process with the given PID exists.
+// FindProcess fails on Windows when there is none.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// terminate ends a process. Windows has no SIGTERM, so the daemon cannot
+// save its state first.
+func terminate(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
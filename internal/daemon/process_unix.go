@@ -0,0 +1,19 @@
+//go:build unix
+
+package daemon
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether a process with the given PID exists
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// terminate asks a process to exit, so the daemon shuts down gracefully
+func terminate(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
@@ -0,0 +1,273 @@
+// Package engine is the watching core shared by every frontend: the
+// session log source feeding a state manager, the idle checker and the
+// notification fan-out. The stream and dashboard views and the daemon
+// each run one engine and show its state by subscribing to its manager.
+package engine
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/notifier"
+	"github.com/sho7650/claude-watch-status/internal/source"
+	"github.com/sho7650/claude-watch-status/internal/state"
+	"github.com/sho7650/claude-watch-status/internal/watcher"
+)
+
+// IdleCheckInterval is how often idle projects are checked while they are
+// active
+const IdleCheckInterval = state.FastTickInterval
+
+// Engine watches session logs and keeps the project states
+type Engine struct {
+	manager  *state.Manager
+	jsonl    *source.JSONLSource
+	notifier *notifier.Notifier                           // nil = no notifications
+	silenced func(project string, phase state.Phase) bool // nil = nothing silenced
+	interval func() time.Duration                         // nil = IdleCheckInterval
+	complete func() time.Duration                         // nil = config.DefaultCompletedAfter
+	tick     tickState
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// tickState is the idle checker's current scheduling
+type tickState struct {
+	mu       sync.RWMutex
+	mode     state.TickMode
+	interval time.Duration
+}
+
+// New creates an Engine watching the session logs in projectsDir
+func New(projectsDir string) *Engine {
+	return &Engine{
+		manager: state.NewManager(),
+		jsonl:   source.NewJSONL(projectsDir),
+		done:    make(chan struct{}),
+	}
+}
+
+// Manager returns the state manager; other sources deliver their events
+// to it, and frontends subscribe to it
+func (e *Engine) Manager() *state.Manager {
+	return e.manager
+}
+
+// JSONL returns the session log source, to pause, resume and debounce it
+func (e *Engine) JSONL() *source.JSONLSource {
+	return e.jsonl
+}
+
+// SetWatchMode selects how log changes are detected
+func (e *Engine) SetWatchMode(mode watcher.Mode) {
+	e.jsonl.SetWatchMode(mode)
+}
+
+// ApplyConfig applies per-project settings (tiers, groups, names), the
+// retention, the idle threshold and the quiet hours from the
+// configuration file
+func (e *Engine) ApplyConfig(cfg *config.Config) {
+	e.manager.SetTierFunc(cfg.TierFor)
+	e.manager.SetGroupFunc(cfg.GroupFor)
+	e.manager.SetProjectNameFunc(cfg.ProjectNameFor)
+	e.manager.SetRetention(cfg.Retention.HideAfterDuration(), cfg.Retention.DeleteAfterDuration())
+	e.complete = cfg.Idle.CompletedAfterDuration
+	quiet := cfg.Notifications.QuietHours
+	e.silenced = func(_ string, phase state.Phase) bool {
+		return quiet.Silences(time.Now(), phase == state.PhaseWaiting)
+	}
+}
+
+// SetCompletedAfterFunc sets how long a reply must stay unchanged before
+// the turn counts as completed
+func (e *Engine) SetCompletedAfterFunc(fn func() time.Duration) {
+	e.complete = fn
+}
+
+// SetNotifier sends notifications for status changes through n
+func (e *Engine) SetNotifier(n *notifier.Notifier) {
+	e.notifier = n
+}
+
+// SetSilenceFunc suppresses the notifications fn reports for a project
+// entering a phase, e.g. of muted projects
+func (e *Engine) SetSilenceFunc(fn func(project string, phase state.Phase) bool) {
+	e.silenced = fn
+}
+
+// SetIdleIntervalFunc sets the shortest interval between idle checks,
+// which low-power mode lengthens
+func (e *Engine) SetIdleIntervalFunc(fn func() time.Duration) {
+	e.interval = fn
+}
+
+// Watch starts watching session logs, seeding the states of existing
+// sessions
+func (e *Engine) Watch() error {
+	return e.jsonl.Start(e.manager)
+}
+
+// Start starts the idle checker and the notifications. Only status
+// changes from then on are notified, so states seeded or replayed before
+// Start are not.
+func (e *Engine) Start() {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.runIdleChecker()
+	}()
+	if e.notifier != nil {
+		eventCh := e.manager.Subscribe()
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			defer e.manager.Unsubscribe(eventCh)
+			e.runNotifier(eventCh)
+		}()
+	}
+}
+
+// Stop stops watching, checking and notifying
+func (e *Engine) Stop() error {
+	var err error
+	e.stopOnce.Do(func() {
+		close(e.done)
+		err = e.jsonl.Stop()
+		e.wg.Wait()
+	})
+	return err
+}
+
+// TickMode returns the idle checker's current tick mode and interval
+func (e *Engine) TickMode() (state.TickMode, time.Duration) {
+	e.tick.mu.RLock()
+	defer e.tick.mu.RUnlock()
+	return e.tick.mode, e.tick.interval
+}
+
+// nextTick returns the idle check mode and interval for the current activity.
+// Low-power mode only ever lengthens the interval.
+func (e *Engine) nextTick() (state.TickMode, time.Duration) {
+	var since time.Duration = math.MaxInt64
+	if last := e.manager.LastActivity(); !last.IsZero() {
+		since = time.Since(last)
+	}
+	mode, interval := state.TickModeFor(since)
+	floor := IdleCheckInterval
+	if e.interval != nil {
+		floor = e.interval()
+	}
+	if interval < floor {
+		interval = floor
+	}
+	return mode, interval
+}
+
+// setTick records the current tick mode
+func (e *Engine) setTick(mode state.TickMode, interval time.Duration) {
+	e.tick.mu.Lock()
+	changed := e.tick.mode != mode
+	e.tick.mode, e.tick.interval = mode, interval
+	e.tick.mu.Unlock()
+
+	if changed {
+		logging.Logger().Debug("idle tick mode changed", "mode", mode, "interval", interval)
+	}
+}
+
+// runIdleChecker periodically transitions idle projects to "waiting approval"
+// or "completed". MarkIdle publishes the change to subscribers.
+// Checks back off while no project is active and return to fast ticks on
+// the next status change. They are skipped, along with the session log
+// re-reads they cause, while dormant or paused.
+func (e *Engine) runIdleChecker() {
+	mode, interval := e.nextTick()
+	e.setTick(mode, interval)
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	// Project name to the idle event last applied to it
+	notified := make(map[string]string)
+
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-e.manager.Activity():
+			if current, _ := e.TickMode(); current != state.TickFast {
+				mode, interval := e.nextTick()
+				e.setTick(mode, interval)
+				timer.Reset(interval)
+			}
+		case <-timer.C:
+			mode, interval := e.nextTick()
+			e.setTick(mode, interval)
+			timer.Reset(interval)
+			if mode == state.TickDormant || e.jsonl.Paused() {
+				continue
+			}
+			e.markIdle(notified)
+		}
+	}
+}
+
+// markIdle applies the idle events of one check, each once. notified holds
+// the last event applied per project; it is kept to the projects the
+// manager still tracks, so it does not grow with every idle turn.
+func (e *Engine) markIdle(notified map[string]string) {
+	for _, event := range e.manager.CheckIdleProjects(e.completedAfter()) {
+		key := idleEventKey(event)
+		if notified[event.Project.Name] == key {
+			continue
+		}
+		notified[event.Project.Name] = key
+
+		e.manager.MarkIdle(event.Project.Name, event.Project.Seq, event.Project.Icon, event.Project.State, event.Project.IsEstimated)
+	}
+	for name := range notified {
+		if e.manager.Get(name) == nil {
+			delete(notified, name)
+		}
+	}
+}
+
+// completedAfter returns the idle time after which a turn counts as completed
+func (e *Engine) completedAfter() time.Duration {
+	if e.complete == nil {
+		return config.DefaultCompletedAfter
+	}
+	return e.complete()
+}
+
+// idleEventKey identifies an idle event so it is applied only once per underlying event
+func idleEventKey(event state.StatusEvent) string {
+	return fmt.Sprintf("%s:%s:%d:%s", event.Project.Name, event.Project.FilePath, event.Project.EventTime.UnixNano(), event.Type)
+}
+
+// runNotifier sends notifications for status changes.
+// Only transitions into a new state are notified, so repeated
+// updates with the same state do not produce duplicates.
+func (e *Engine) runNotifier(eventCh chan state.StatusEvent) {
+	state.ForEachTransition(e.done, eventCh, func(project state.ProjectStatus) {
+		if e.silenced != nil && e.silenced(project.Name, state.PhaseOf(project.State)) {
+			return
+		}
+		switch state.PhaseOf(project.State) {
+		case state.PhaseWaiting:
+			e.notifier.NotifyWaitingApproval(project)
+		case state.PhaseCompleted:
+			e.notifier.NotifyCompleted(project)
+		case state.PhaseInterrupted:
+			e.notifier.NotifyInterrupted(project)
+		case state.PhaseStarted:
+			e.notifier.NotifySessionStart(project)
+		case state.PhaseEnded:
+			e.notifier.NotifySessionEnd(project)
+		}
+	})
+}
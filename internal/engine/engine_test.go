@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeReply writes a session log whose last entry is a reply that has
+// been idle long enough to count as completed
+func writeReply(t *testing.T, path string, at time.Time) {
+	t.Helper()
+	line := fmt.Sprintf(`{"type":"assistant","timestamp":%q,"message":{"role":"assistant","content":[{"type":"text","text":"done"}],"stop_reason":null}}`+"\n",
+		at.UTC().Format(time.RFC3339Nano))
+	if err := os.WriteFile(path, []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMarkIdleBounded(t *testing.T) {
+	dir := t.TempDir()
+	e := New(dir)
+	e.SetCompletedAfterFunc(func() time.Duration { return time.Second })
+	m := e.Manager()
+
+	notified := make(map[string]string)
+	start := time.Now().Add(-time.Hour)
+	for i := range 300 {
+		// Three long-lived projects with a new idle turn every cycle
+		name := fmt.Sprintf("proj%d", i%3)
+		path := filepath.Join(dir, name+".jsonl")
+		writeReply(t, path, start.Add(time.Duration(i)*time.Second))
+		if _, err := m.Update(name, name, path); err != nil {
+			t.Fatal(err)
+		}
+
+		// And one that goes idle, then is removed
+		tmp := filepath.Join(dir, fmt.Sprintf("tmp%d.jsonl", i))
+		writeReply(t, tmp, start)
+		if _, err := m.Update(fmt.Sprintf("tmp%d", i), "tmp", tmp); err != nil {
+			t.Fatal(err)
+		}
+
+		e.markIdle(notified)
+		if got := m.Get(name); got == nil || got.State != "completed" {
+			t.Fatalf("cycle %d: %s = %+v, want completed", i, name, got)
+		}
+		m.RemoveLog(tmp)
+		e.markIdle(notified)
+
+		if len(notified) > 3 {
+			t.Fatalf("cycle %d: %d notified entries, want at most 3: %v", i, len(notified), notified)
+		}
+	}
+}
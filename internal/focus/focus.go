@@ -0,0 +1,157 @@
+// Package focus raises the terminal window of a session, so a prompt
+// waiting for approval can be answered from the dashboard: the tmux pane
+// is selected, and iTerm2, Terminal.app, WezTerm, kitty or wmctrl (X11)
+// bring the session's window to the front.
+package focus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// runTimeout bounds each command raising a terminal
+const runTimeout = 5 * time.Second
+
+// ErrUnknownTerminal is returned for sessions whose terminal cannot be
+// raised: none was reported, or not one this package knows
+var ErrUnknownTerminal = errors.New("the session's terminal is not known")
+
+// run runs a command, returning its output as part of the error
+var run = func(ctx context.Context, name string, args ...string) error {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil && len(out) > 0 {
+		return fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return err
+}
+
+// Raise brings the terminal of a session to the front. Inside tmux the
+// pane is selected first; the terminal application around it is raised
+// when it is known too.
+func Raise(ctx context.Context, t *state.Terminal) error {
+	if !t.Focusable() {
+		return ErrUnknownTerminal
+	}
+	ctx, cancel := context.WithTimeout(ctx, runTimeout)
+	defer cancel()
+
+	raised := false
+	if t.TmuxPane != "" {
+		if err := raiseTmux(ctx, t); err != nil {
+			return err
+		}
+		raised = true
+	}
+
+	var err error
+	switch {
+	case t.ITermSession != "":
+		err = osascript(ctx, itermScript(t.ITermSession))
+	case t.WezTermPane != "":
+		err = run(ctx, "wezterm", "cli", "activate-pane", "--pane-id", t.WezTermPane)
+	case t.KittyWindow != "":
+		err = run(ctx, "kitty", "@", "focus-window", "--match", "id:"+t.KittyWindow)
+	case t.XWindow != "":
+		err = raiseXWindow(ctx, t.XWindow)
+	case t.Program == "Apple_Terminal" && t.TTY != "":
+		err = osascript(ctx, terminalAppScript(t.TTY))
+	default:
+		return nil
+	}
+	if err != nil && raised {
+		// The pane is selected; the window around it could not be raised
+		return nil
+	}
+	return err
+}
+
+// raiseTmux selects the window and pane of a session in every client
+// attached to its tmux server
+func raiseTmux(ctx context.Context, t *state.Terminal) error {
+	tmux := func(args ...string) error {
+		if t.TmuxSocket != "" {
+			args = append([]string{"-S", t.TmuxSocket}, args...)
+		}
+		return run(ctx, "tmux", args...)
+	}
+	if err := tmux("select-window", "-t", t.TmuxPane); err != nil {
+		return err
+	}
+	if err := tmux("select-pane", "-t", t.TmuxPane); err != nil {
+		return err
+	}
+	// Clients attached to another session follow; failing is fine when
+	// no client is attached
+	tmux("switch-client", "-t", t.TmuxPane)
+	return nil
+}
+
+// raiseXWindow activates an X11 window by its decimal ID, switching to
+// its desktop
+func raiseXWindow(ctx context.Context, id string) error {
+	wid, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid X11 window ID %q", id)
+	}
+	return run(ctx, "wmctrl", "-i", "-a", fmt.Sprintf("0x%08x", wid))
+}
+
+// osascript runs an AppleScript, on macOS only
+func osascript(ctx context.Context, script string) error {
+	if runtime.GOOS != "darwin" {
+		return fmt.Errorf("raising iTerm2 and Terminal.app windows needs macOS, not %s", runtime.GOOS)
+	}
+	return run(ctx, "osascript", "-e", script)
+}
+
+// appleScriptString quotes s for AppleScript. Values are validated when
+// hook events are received; quotes and backslashes are dropped all the same.
+func appleScriptString(s string) string {
+	s = strings.NewReplacer(`"`, "", `\`, "").Replace(s)
+	return `"` + s + `"`
+}
+
+// itermScript selects an iTerm2 session by its unique ID
+func itermScript(id string) string {
+	return `tell application "iTerm2"
+	repeat with w in windows
+		repeat with t in tabs of w
+			repeat with s in sessions of t
+				if unique id of s is ` + appleScriptString(id) + ` then
+					select w
+					select t
+					select s
+					activate
+					return
+				end if
+			end repeat
+		end repeat
+	end repeat
+	error "session not found"
+end tell`
+}
+
+// terminalAppScript selects the Terminal.app tab of a tty
+func terminalAppScript(tty string) string {
+	return `tell application "Terminal"
+	repeat with w in windows
+		repeat with t in tabs of w
+			if tty of t is ` + appleScriptString(tty) + ` then
+				set selected of t to true
+				set index of w to 1
+				activate
+				return
+			end if
+		end repeat
+	end repeat
+	error "tab not found"
+end tell`
+}
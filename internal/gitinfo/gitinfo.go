@@ -0,0 +1,73 @@
+// Package gitinfo reads the current git branch of a working directory
+// from the repository files, without running git, so worktrees of the
+// same repository can be told apart.
+package gitinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// shortHashLen is the length of the commit hash shown for a detached HEAD
+const shortHashLen = 7
+
+// Branch returns the branch checked out in the repository containing
+// dir, the short commit hash if HEAD is detached, or "" if dir is not in
+// a git repository. Linked worktrees, whose .git is a file pointing to
+// their own HEAD, report their own branch.
+func Branch(dir string) string {
+	gitDir := findGitDir(dir)
+	if gitDir == "" {
+		return ""
+	}
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	ref := strings.TrimSpace(string(head))
+	if name, ok := strings.CutPrefix(ref, "ref: "); ok {
+		return strings.TrimPrefix(name, "refs/heads/")
+	}
+	if len(ref) > shortHashLen {
+		return ref[:shortHashLen]
+	}
+	return ref
+}
+
+// findGitDir returns the git directory of the repository containing dir,
+// searching dir and its parents
+func findGitDir(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	for dir = filepath.Clean(dir); ; dir = filepath.Dir(dir) {
+		path := filepath.Join(dir, ".git")
+		if info, err := os.Stat(path); err == nil {
+			if info.IsDir() {
+				return path
+			}
+			return linkedGitDir(path)
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return ""
+		}
+	}
+}
+
+// linkedGitDir reads a .git file ("gitdir: <path>") of a worktree or
+// submodule
+func linkedGitDir(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return ""
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	return gitDir
+}
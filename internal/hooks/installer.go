@@ -1,25 +1,58 @@
 package hooks
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 // Installer handles the installation and removal of CWS hooks
 type Installer struct {
 	claudeDir    string
+	projectDir   string // set for project-level installs
 	settingsPath string
 	backupPath   string
 	hooksDir     string
 	scriptPath   string
-	port         int
+	psScriptPath string
+	tokenPath    string
+	endpoint     Endpoint
+}
+
+// Endpoint is the daemon address hooks send events to
+type Endpoint struct {
+	Host     string // "" = 127.0.0.1
+	Port     int
+	TLS      bool // send over HTTPS
+	Insecure bool // with TLS, do not verify the daemon's certificate (self-signed)
+}
+
+func (e Endpoint) host() string {
+	if e.Host == "" {
+		return "127.0.0.1"
+	}
+	return e.Host
+}
+
+func (e Endpoint) scheme() string {
+	if e.TLS {
+		return "https"
+	}
+	return "http"
+}
+
+// URL returns the URL hook events are posted to
+func (e Endpoint) URL() string {
+	return e.scheme() + "://" + net.JoinHostPort(e.host(), strconv.Itoa(e.Port)) + "/api/hooks"
 }
 
 // NewInstaller creates a new Installer
-func NewInstaller(port int) *Installer {
+func NewInstaller(endpoint Endpoint) *Installer {
 	homeDir, _ := os.UserHomeDir()
 	claudeDir := filepath.Join(homeDir, ".claude")
 
@@ -29,19 +62,68 @@ func NewInstaller(port int) *Installer {
 		backupPath:   filepath.Join(claudeDir, "settings.json.cws-backup"),
 		hooksDir:     filepath.Join(claudeDir, "hooks"),
 		scriptPath:   filepath.Join(claudeDir, "hooks", "cws-notify.sh"),
-		port:         port,
+		psScriptPath: filepath.Join(claudeDir, "hooks", "cws-notify.ps1"),
+		tokenPath:    filepath.Join(claudeDir, "hooks", "cws-token"),
+		endpoint:     endpoint,
+	}
+}
+
+// NewProjectInstaller creates an Installer for a project's
+// .claude/settings.json, or .claude/settings.local.json if local is set.
+// Hook scripts and the token stay in ~/.claude/hooks, shared with the
+// user-level installation, so no secret is written into the project.
+func NewProjectInstaller(endpoint Endpoint, projectDir string, local bool) (*Installer, error) {
+	abs, err := filepath.Abs(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("project directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("project directory: %s is not a directory", abs)
+	}
+
+	name := "settings.json"
+	if local {
+		name = "settings.local.json"
 	}
+
+	i := NewInstaller(endpoint)
+	i.projectDir = abs
+	i.settingsPath = filepath.Join(abs, ".claude", name)
+	i.backupPath = i.settingsPath + ".cws-backup"
+	return i, nil
+}
+
+// DefaultTransport returns the default hook transport. The binary relay
+// needs neither a shell nor curl and spools events while the daemon is down.
+func DefaultTransport() Transport {
+	return TransportBinary
 }
 
 // Install installs the CWS hooks configuration
 func (i *Installer) Install(opts InstallOptions) error {
+	transport := opts.Transport
+	if transport == "" {
+		transport = DefaultTransport()
+	}
+	if !transport.Valid() {
+		return fmt.Errorf("unknown hook transport %q (want sh, powershell or binary)", transport)
+	}
+
 	// 1. Check prerequisites
 	if err := i.checkPrerequisites(); err != nil {
 		return err
 	}
+	command, err := i.hookCommand(transport)
+	if err != nil {
+		return err
+	}
 
 	// 2. Check port availability
-	if err := checkPortAvailable(i.port); err != nil {
+	if err := checkPortAvailable(i.endpoint.Port); err != nil {
 		return fmt.Errorf("cannot install hooks: %w\nMake sure the CWS server is not running, or use a different port with --port", err)
 	}
 
@@ -58,7 +140,7 @@ func (i *Installer) Install(opts InstallOptions) error {
 
 	// 5. Remove existing CWS hooks if force mode
 	if opts.Force && HasCWSHooks(settings) {
-		settings = RemoveCWSHooks(settings)
+		RemoveCWSHooks(settings)
 	}
 
 	// 6. Create backup
@@ -66,23 +148,37 @@ func (i *Installer) Install(opts InstallOptions) error {
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
 
-	// 7. Create hook script
-	if err := i.createHookScript(); err != nil {
-		i.restoreFromBackup()
-		return fmt.Errorf("failed to create hook script: %w (restored from backup)", err)
+	// 7. Generate shared-secret token
+	token, err := i.installToken()
+	if err != nil {
+		return err
+	}
+
+	// 8. Create hook script (replacing one for another transport, unless
+	// the scripts are shared with other installations)
+	if i.projectDir == "" {
+		if err := i.removeHookScript(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove old hook script: %v\n", err)
+		}
+	}
+	if transport != TransportBinary {
+		if err := i.createHookScript(transport, token); err != nil {
+			i.restoreFromBackup()
+			return fmt.Errorf("failed to create hook script: %w (restored from backup)", err)
+		}
 	}
 
-	// 8. Merge CWS hooks into settings
-	settings = MergeCWSHooks(settings, i.scriptPath)
+	// 9. Merge CWS hooks into settings
+	MergeCWSHooks(settings, command)
 
-	// 9. Save settings
+	// 10. Save settings
 	if err := i.saveSettings(settings); err != nil {
 		i.restoreFromBackup()
 		return fmt.Errorf("failed to save settings: %w (restored from backup)", err)
 	}
 
-	// 10. Verify installation
-	if err := i.verifyInstallation(); err != nil {
+	// 11. Verify installation
+	if err := i.verifyInstallation(transport); err != nil {
 		i.restoreFromBackup()
 		return fmt.Errorf("verification failed: %w (restored from backup)", err)
 	}
@@ -104,15 +200,19 @@ func (i *Installer) Remove(opts InstallOptions) error {
 	}
 
 	// 3. Remove CWS hooks from settings
-	settings = RemoveCWSHooks(settings)
+	RemoveCWSHooks(settings)
 
 	// 4. Save settings
 	if err := i.saveSettings(settings); err != nil {
 		return fmt.Errorf("failed to save settings: %w", err)
 	}
 
-	// 5. Remove hook script (unless --keep-script)
-	if !opts.KeepScript {
+	// 5. Remove hook script and token (unless --keep-script). Project-level
+	// installs share them with the user-level installation and keep them.
+	if !opts.KeepScript && i.projectDir == "" {
+		if err := os.Remove(i.tokenPath); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove token: %v\n", err)
+		}
 		if err := i.removeHookScript(); err != nil {
 			// Non-fatal, just warn
 			fmt.Fprintf(os.Stderr, "Warning: failed to remove hook script: %v\n", err)
@@ -129,8 +229,10 @@ func (i *Installer) Remove(opts InstallOptions) error {
 func (i *Installer) Check() (*CheckResult, error) {
 	result := &CheckResult{
 		SettingsPath:   i.settingsPath,
+		ProjectDir:     i.projectDir,
 		ScriptPath:     i.scriptPath,
-		DaemonEndpoint: fmt.Sprintf("http://127.0.0.1:%d/api/hooks", i.port),
+		TokenPath:      i.tokenPath,
+		DaemonEndpoint: i.endpoint.URL(),
 	}
 
 	// Check settings
@@ -144,6 +246,9 @@ func (i *Installer) Check() (*CheckResult, error) {
 
 	// Check for CWS hooks
 	result.Installed = HasCWSHooks(settings)
+	result.Command = cwsHookCommand(settings)
+	result.Transport = transportOf(result.Command)
+	result.ScriptPath = i.scriptPathFor(result.Transport)
 
 	// Check configured events
 	for _, event := range CWSHookEvents {
@@ -155,11 +260,16 @@ func (i *Installer) Check() (*CheckResult, error) {
 	}
 
 	// Check hook script
-	if info, err := os.Stat(i.scriptPath); err == nil {
+	if info, err := os.Stat(result.ScriptPath); err == nil {
 		result.ScriptExists = true
 		result.ScriptExecutable = info.Mode()&0111 != 0
 	}
 
+	// Check token
+	if token, err := LoadToken(i.tokenPath); err == nil && token != "" {
+		result.TokenExists = true
+	}
+
 	return result, nil
 }
 
@@ -168,9 +278,36 @@ func (i *Installer) checkPrerequisites() error {
 	if _, err := os.Stat(i.claudeDir); os.IsNotExist(err) {
 		return fmt.Errorf("Claude Code not installed: %s does not exist", i.claudeDir)
 	}
+
+	// Create the project's .claude directory if needed
+	if i.projectDir != "" {
+		if err := os.MkdirAll(filepath.Dir(i.settingsPath), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(i.settingsPath), err)
+		}
+	}
 	return nil
 }
 
+// installToken returns the shared-secret token to install. User-level
+// installs rotate it; project-level installs reuse an existing token so
+// scripts already installed elsewhere keep working.
+func (i *Installer) installToken() (string, error) {
+	if i.projectDir != "" {
+		if token, err := LoadToken(i.tokenPath); err == nil && token != "" {
+			return token, nil
+		}
+	}
+
+	token, err := GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	if err := SaveToken(i.tokenPath, token); err != nil {
+		return "", fmt.Errorf("failed to save token: %w", err)
+	}
+	return token, nil
+}
+
 // checkPortAvailable checks if the specified port is available for use
 func checkPortAvailable(port int) error {
 	ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
@@ -181,33 +318,44 @@ func checkPortAvailable(port int) error {
 	return nil
 }
 
-func (i *Installer) loadSettings() (map[string]interface{}, error) {
+func (i *Installer) loadSettings() (*Settings, error) {
 	data, err := os.ReadFile(i.settingsPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Return empty settings if file doesn't exist
-			return make(map[string]interface{}), nil
+			return &Settings{}, nil
 		}
 		return nil, err
 	}
 
-	var settings map[string]interface{}
+	var settings Settings
 	if err := json.Unmarshal(data, &settings); err != nil {
-		return nil, fmt.Errorf("invalid settings.json: %w", err)
+		return nil, fmt.Errorf("invalid %s: %w", filepath.Base(i.settingsPath), err)
 	}
 
-	return settings, nil
+	return &settings, nil
 }
 
-func (i *Installer) saveSettings(settings map[string]interface{}) error {
-	data, err := json.MarshalIndent(settings, "", "  ")
+func (i *Installer) saveSettings(settings *Settings) error {
+	data, err := formatSettings(settings)
 	if err != nil {
 		return err
 	}
-
 	return os.WriteFile(i.settingsPath, data, 0644)
 }
 
+// formatSettings encodes settings as written to settings.json
+func formatSettings(settings *Settings) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(settings); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func (i *Installer) createBackup() error {
 	// Only backup if settings file exists
 	if _, err := os.Stat(i.settingsPath); os.IsNotExist(err) {
@@ -235,17 +383,70 @@ func (i *Installer) restoreFromBackup() error {
 	return os.WriteFile(i.settingsPath, data, 0644)
 }
 
-func (i *Installer) createHookScript() error {
+// scriptPathFor returns the hook script path for a transport ("" for binary)
+func (i *Installer) scriptPathFor(transport Transport) string {
+	switch transport {
+	case TransportShell:
+		return i.scriptPath
+	case TransportPowerShell:
+		return i.psScriptPath
+	default:
+		return ""
+	}
+}
+
+// hookCommand returns the command registered in settings.json for a transport
+func (i *Installer) hookCommand(transport Transport) (string, error) {
+	switch transport {
+	case TransportPowerShell:
+		return fmt.Sprintf(`powershell -NoProfile -ExecutionPolicy Bypass -File "%s"`, i.psScriptPath), nil
+	case TransportBinary:
+		exe, err := os.Executable()
+		if err != nil {
+			return "", fmt.Errorf("cannot locate claude-watch-status binary: %w", err)
+		}
+		command := fmt.Sprintf(`"%s" hook-relay --port %d`, exe, i.endpoint.Port)
+		if i.endpoint.Host != "" {
+			command += " --host " + i.endpoint.Host
+		}
+		if i.endpoint.TLS {
+			command += " --tls"
+		}
+		if i.endpoint.Insecure {
+			command += " --insecure"
+		}
+		return command, nil
+	default:
+		return i.scriptPath, nil
+	}
+}
+
+// transportOf infers the transport from a registered hook command
+func transportOf(command string) Transport {
+	switch {
+	case strings.Contains(command, "hook-relay"):
+		return TransportBinary
+	case strings.Contains(command, ".ps1"):
+		return TransportPowerShell
+	default:
+		return TransportShell
+	}
+}
+
+func (i *Installer) createHookScript(transport Transport, token string) error {
 	// Create hooks directory
 	if err := os.MkdirAll(i.hooksDir, 0755); err != nil {
 		return err
 	}
 
 	// Generate script content
-	script := GenerateHookScript(i.port)
+	script := GenerateHookScript(i.endpoint, token)
+	if transport == TransportPowerShell {
+		script = GeneratePowerShellScript(i.endpoint, token)
+	}
 
 	// Write script
-	if err := os.WriteFile(i.scriptPath, []byte(script), 0755); err != nil {
+	if err := os.WriteFile(i.scriptPathFor(transport), []byte(script), 0755); err != nil {
 		return err
 	}
 
@@ -253,9 +454,11 @@ func (i *Installer) createHookScript() error {
 }
 
 func (i *Installer) removeHookScript() error {
-	// Remove script
-	if err := os.Remove(i.scriptPath); err != nil && !os.IsNotExist(err) {
-		return err
+	// Remove scripts of all transports
+	for _, path := range []string{i.scriptPath, i.psScriptPath} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
 	}
 
 	// Try to remove hooks directory if empty
@@ -267,7 +470,7 @@ func (i *Installer) removeHookScript() error {
 	return nil
 }
 
-func (i *Installer) verifyInstallation() error {
+func (i *Installer) verifyInstallation(transport Transport) error {
 	// Reload and verify settings
 	settings, err := i.loadSettings()
 	if err != nil {
@@ -278,13 +481,17 @@ func (i *Installer) verifyInstallation() error {
 		return fmt.Errorf("CWS hooks not found in settings after installation")
 	}
 
-	// Verify script exists and is executable
-	info, err := os.Stat(i.scriptPath)
+	if transport == TransportBinary {
+		return nil
+	}
+
+	// Verify script exists and is executable (sh only; PowerShell runs it via -File)
+	info, err := os.Stat(i.scriptPathFor(transport))
 	if err != nil {
 		return fmt.Errorf("hook script not found: %w", err)
 	}
 
-	if info.Mode()&0111 == 0 {
+	if transport == TransportShell && info.Mode()&0111 == 0 {
 		return fmt.Errorf("hook script is not executable")
 	}
 
@@ -1,179 +1,109 @@
 package hooks
 
 import (
-	"encoding/json"
 	"strings"
 )
 
-// MergeCWSHooks merges CWS hooks into existing settings
-func MergeCWSHooks(settings map[string]interface{}, scriptPath string) map[string]interface{} {
-	result := deepCopy(settings)
-
-	// Initialize hooks map if not present
-	if result["hooks"] == nil {
-		result["hooks"] = make(map[string]interface{})
+// MergeCWSHooks adds CWS hooks running command to settings, after any
+// existing hooks for each event
+func MergeCWSHooks(settings *Settings, command string) {
+	if settings.Hooks == nil {
+		settings.Hooks = make(map[string][]HookEntry)
 	}
 
-	hooks := result["hooks"].(map[string]interface{})
-
-	// Add CWS hooks for each event
 	for _, event := range CWSHookEvents {
-		cwsEntry := createCWSHookEntry(event, scriptPath)
-
-		if hooks[event] == nil {
-			// Event doesn't exist - create new array
-			hooks[event] = []interface{}{cwsEntry}
-		} else {
-			// Event exists - append to array
-			existingEntries, ok := hooks[event].([]interface{})
-			if !ok {
-				// Handle case where it might be a different type
-				hooks[event] = []interface{}{cwsEntry}
-				continue
-			}
-			hooks[event] = append(existingEntries, cwsEntry)
+		if _, ok := settings.Hooks[event]; !ok {
+			settings.hookOrder = append(settings.hookOrder, event)
 		}
+		settings.Hooks[event] = append(settings.Hooks[event], createCWSHookEntry(event, command))
 	}
-
-	return result
 }
 
-// RemoveCWSHooks removes all CWS-managed hooks from settings
-func RemoveCWSHooks(settings map[string]interface{}) map[string]interface{} {
-	result := deepCopy(settings)
-
-	hooks, ok := result["hooks"].(map[string]interface{})
-	if !ok {
-		return result
-	}
-
-	// Process each event
-	for event, entries := range hooks {
-		entryList, ok := entries.([]interface{})
-		if !ok {
-			continue
-		}
-
-		filtered := make([]interface{}, 0)
-
-		for _, entry := range entryList {
-			if !isCWSManagedEntry(entry) {
+// RemoveCWSHooks removes all CWS-managed hooks from settings. Events left
+// without hooks are removed.
+func RemoveCWSHooks(settings *Settings) {
+	for event, entries := range settings.Hooks {
+		filtered := make([]HookEntry, 0, len(entries))
+		for _, entry := range entries {
+			if !entry.isCWSManaged() {
 				filtered = append(filtered, entry)
 			}
 		}
 
 		if len(filtered) > 0 {
-			hooks[event] = filtered
+			settings.Hooks[event] = filtered
 		} else {
-			delete(hooks, event)
+			delete(settings.Hooks, event)
 		}
 	}
 
-	// Remove hooks object if empty
-	if len(hooks) == 0 {
-		delete(result, "hooks")
+	if len(settings.Hooks) == 0 {
+		settings.Hooks = nil
 	}
-
-	return result
 }
 
 // HasCWSHooks checks if settings contain any CWS-managed hooks
-func HasCWSHooks(settings map[string]interface{}) bool {
-	hooks, ok := settings["hooks"].(map[string]interface{})
-	if !ok {
-		return false
-	}
-
-	for _, entries := range hooks {
-		entryList, ok := entries.([]interface{})
-		if !ok {
-			continue
-		}
-
-		for _, entry := range entryList {
-			if isCWSManagedEntry(entry) {
-				return true
-			}
+func HasCWSHooks(settings *Settings) bool {
+	for event := range settings.Hooks {
+		if hasCWSHookForEvent(settings, event) {
+			return true
 		}
 	}
-
 	return false
 }
 
 // hasCWSHookForEvent checks if a specific event has CWS hooks
-func hasCWSHookForEvent(settings map[string]interface{}, event string) bool {
-	hooks, ok := settings["hooks"].(map[string]interface{})
-	if !ok {
-		return false
-	}
-
-	entries, ok := hooks[event].([]interface{})
-	if !ok {
-		return false
-	}
-
-	for _, entry := range entries {
-		if isCWSManagedEntry(entry) {
+func hasCWSHookForEvent(settings *Settings, event string) bool {
+	for _, entry := range settings.Hooks[event] {
+		if entry.isCWSManaged() {
 			return true
 		}
 	}
-
 	return false
 }
 
-// createCWSHookEntry creates a hook entry for a given event
-func createCWSHookEntry(event, scriptPath string) map[string]interface{} {
-	hookConfig := map[string]interface{}{
-		"type":    "command",
-		"command": scriptPath + "  " + CWSMarker,
+// cwsHookCommand returns the command of the first CWS-managed hook, without the marker
+func cwsHookCommand(settings *Settings) string {
+	for _, event := range CWSHookEvents {
+		for _, entry := range settings.Hooks[event] {
+			for _, hook := range entry.Hooks {
+				if hook.isCWSManaged() {
+					return strings.TrimSpace(strings.TrimSuffix(hook.Command, CWSMarker))
+				}
+			}
+		}
 	}
+	return ""
+}
 
-	entry := map[string]interface{}{
-		"hooks": []interface{}{hookConfig},
+// createCWSHookEntry creates a hook entry for a given event
+func createCWSHookEntry(event, command string) HookEntry {
+	entry := HookEntry{
+		Hooks: []HookConfig{{
+			Type:    "command",
+			Command: command + "  " + CWSMarker,
+		}},
 	}
 
-	// Add matcher for PreToolUse and PostToolUse
-	if event == "PreToolUse" || event == "PostToolUse" {
-		entry["matcher"] = "*"
+	// Add matcher for the tool events
+	if event == "PreToolUse" || event == "PostToolUse" || event == "PermissionRequest" {
+		entry.Matcher = "*"
 	}
 
 	return entry
 }
 
-// isCWSManagedEntry checks if a hook entry is managed by CWS
-func isCWSManagedEntry(entry interface{}) bool {
-	entryMap, ok := entry.(map[string]interface{})
-	if !ok {
-		return false
-	}
-
-	hooksList, ok := entryMap["hooks"].([]interface{})
-	if !ok {
-		return false
-	}
-
-	for _, hook := range hooksList {
-		hookMap, ok := hook.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		cmd, ok := hookMap["command"].(string)
-		if ok && strings.Contains(cmd, CWSMarker) {
+// isCWSManaged checks if a hook entry is managed by CWS
+func (e HookEntry) isCWSManaged() bool {
+	for _, hook := range e.Hooks {
+		if hook.isCWSManaged() {
 			return true
 		}
 	}
-
 	return false
 }
 
-// deepCopy creates a deep copy of a map
-func deepCopy(src map[string]interface{}) map[string]interface{} {
-	data, _ := json.Marshal(src)
-	var dst map[string]interface{}
-	json.Unmarshal(data, &dst)
-	if dst == nil {
-		dst = make(map[string]interface{})
-	}
-	return dst
+// isCWSManaged checks if a hook runs a CWS-managed command
+func (h HookConfig) isCWSManaged() bool {
+	return strings.Contains(h.Command, CWSMarker)
 }
@@ -0,0 +1,47 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// DefaultPermissionMode returns the permissions.defaultMode that applies
+// to sessions started in dir, following Claude Code's precedence:
+// .claude/settings.local.json, then .claude/settings.json in dir, then
+// ~/.claude/settings.json. Returns "" if none of them sets it.
+func DefaultPermissionMode(dir string) string {
+	homeDir, _ := os.UserHomeDir()
+	var paths []string
+	if dir != "" {
+		paths = append(paths,
+			filepath.Join(dir, ".claude", "settings.local.json"),
+			filepath.Join(dir, ".claude", "settings.json"))
+	}
+	paths = append(paths, filepath.Join(homeDir, ".claude", "settings.json"))
+
+	for _, path := range paths {
+		if mode := settingsPermissionMode(path); mode != "" {
+			return mode
+		}
+	}
+	return ""
+}
+
+// settingsPermissionMode reads permissions.defaultMode from a settings
+// file; missing or unreadable files yield ""
+func settingsPermissionMode(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var settings struct {
+		Permissions struct {
+			DefaultMode string `json:"defaultMode"`
+		} `json:"permissions"`
+	}
+	if json.Unmarshal(data, &settings) != nil {
+		return ""
+	}
+	return settings.Permissions.DefaultMode
+}
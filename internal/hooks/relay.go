@@ -0,0 +1,222 @@
+package hooks
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EventTimeHeader carries when a spooled hook event originally happened,
+// so the daemon does not mistake a replayed event for a new one
+const EventTimeHeader = "X-CWS-Event-Time"
+
+// Spool limits: events older than maxSpoolAge are dropped rather than
+// replayed, and at most maxSpoolEvents are kept. A single relay run replays
+// at most maxReplayPerRun events so Claude Code is never held up for long.
+const (
+	maxSpoolAge     = time.Hour
+	maxSpoolEvents  = 1000
+	maxReplayPerRun = 50
+)
+
+// errRejected is returned for events the daemon refuses for good (too
+// large or malformed); they are dropped rather than spooled. Events over
+// the daemon's rate limit are spooled like undelivered ones.
+var errRejected = errors.New("event rejected")
+
+// Relay forwards hook events to the daemon. While the daemon is
+// unreachable, events are spooled to disk and replayed in order by the
+// next successful relay.
+type Relay struct {
+	endpoint string
+	token    string
+	timeout  time.Duration
+	spoolDir string
+	ttyDir   string
+	insecure bool
+}
+
+// spooledEvent is the on-disk form of an undelivered hook event
+type spooledEvent struct {
+	Time     time.Time       `json:"time"`
+	Body     json.RawMessage `json:"body"`
+	Terminal string          `json:"terminal,omitempty"`
+}
+
+// NewRelay creates a Relay posting to the daemon's hooks endpoint
+func NewRelay(endpoint, token string, timeout time.Duration) *Relay {
+	return &Relay{
+		endpoint: endpoint,
+		token:    token,
+		timeout:  timeout,
+	}
+}
+
+// SetSpoolDir enables spooling undelivered events to dir
+func (r *Relay) SetSpoolDir(dir string) {
+	r.spoolDir = dir
+}
+
+// SetTerminalCacheDir caches the controlling terminal of each session in
+// dir, so ps runs once per session rather than for every event
+func (r *Relay) SetTerminalCacheDir(dir string) {
+	r.ttyDir = dir
+}
+
+// SetInsecureTLS skips verifying the daemon's TLS certificate, for
+// daemons serving HTTPS with a self-signed one
+func (r *Relay) SetInsecureTLS() {
+	r.insecure = true
+}
+
+// Relay reads a hook event from in and delivers it, after replaying any
+// spooled events. If the daemon is unreachable the event is spooled.
+func (r *Relay) Relay(in io.Reader) error {
+	body, err := io.ReadAll(in)
+	if err != nil {
+		return fmt.Errorf("failed to read hook data: %w", err)
+	}
+	now := time.Now()
+	terminal := DetectTerminal(func() string {
+		var event struct {
+			SessionID string `json:"session_id"`
+		}
+		json.Unmarshal(body, &event)
+		return cachedTTY(r.ttyDir, event.SessionID)
+	})
+
+	if err := r.replay(); err != nil {
+		return r.spool(now, body, terminal, err)
+	}
+	if err := r.send(body, time.Time{}, terminal); err != nil {
+		return r.spool(now, body, terminal, err)
+	}
+	return nil
+}
+
+// send posts one event; a non-zero eventTime marks it as replayed
+func (r *Relay) send(body []byte, eventTime time.Time, terminal string) error {
+	req, err := http.NewRequest(http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.token != "" {
+		req.Header.Set(TokenHeader, r.token)
+	}
+	if !eventTime.IsZero() {
+		req.Header.Set(EventTimeHeader, eventTime.Format(time.RFC3339Nano))
+	}
+	if terminal != "" {
+		req.Header.Set(TerminalHeader, terminal)
+	}
+
+	client := &http.Client{Timeout: r.timeout}
+	if r.insecure {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		client.Transport = transport
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		return fmt.Errorf("%w: daemon returned %s", errRejected, resp.Status)
+	default:
+		return fmt.Errorf("daemon returned %s", resp.Status)
+	}
+	return nil
+}
+
+// spool saves an undelivered event and returns the delivery error
+func (r *Relay) spool(t time.Time, body []byte, terminal string, sendErr error) error {
+	if r.spoolDir == "" || !json.Valid(body) || errors.Is(sendErr, errRejected) {
+		return sendErr
+	}
+	if err := os.MkdirAll(r.spoolDir, 0700); err != nil {
+		return err
+	}
+	if files, _ := r.spooledFiles(); len(files) >= maxSpoolEvents {
+		return fmt.Errorf("spool full, event dropped: %w", sendErr)
+	}
+
+	data, err := json.Marshal(spooledEvent{Time: t, Body: body, Terminal: terminal})
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%020d-%d.json", t.UnixNano(), os.Getpid())
+	if err := os.WriteFile(filepath.Join(r.spoolDir, name), data, 0600); err != nil {
+		return err
+	}
+	return fmt.Errorf("event spooled: %w", sendErr)
+}
+
+// replay delivers spooled events oldest first. Each file is removed before
+// sending so concurrent relays never deliver the same event twice; an event
+// that fails to send is spooled again.
+func (r *Relay) replay() error {
+	if r.spoolDir == "" {
+		return nil
+	}
+	files, err := r.spooledFiles()
+	if err != nil || len(files) == 0 {
+		return nil
+	}
+	if len(files) > maxReplayPerRun {
+		files = files[:maxReplayPerRun]
+	}
+
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			continue // claimed by another relay
+		}
+
+		var event spooledEvent
+		if err := json.Unmarshal(data, &event); err != nil || time.Since(event.Time) > maxSpoolAge {
+			continue
+		}
+		if err := r.send(event.Body, event.Time, event.Terminal); err != nil {
+			if errors.Is(err, errRejected) {
+				continue
+			}
+			r.spool(event.Time, event.Body, event.Terminal, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// spooledFiles lists spooled event files, oldest first
+func (r *Relay) spooledFiles() ([]string, error) {
+	entries, err := os.ReadDir(r.spoolDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			files = append(files, filepath.Join(r.spoolDir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
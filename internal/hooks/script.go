@@ -2,31 +2,105 @@ package hooks
 
 import "fmt"
 
-// GenerateHookScript generates the hook notification script content
-func GenerateHookScript(port int) string {
-	return fmt.Sprintf(`#!/bin/bash
+// GenerateHookScript generates the hook notification script content.
+// The script is plain POSIX sh so it runs in minimal containers; it uses
+// curl, or wget if curl is not installed. The token is sent with every
+// request so the daemon can reject events that did not originate from
+// this script. endpoint sets the defaults of CWS_HOST, CWS_PORT,
+// CWS_SCHEME and CWS_INSECURE.
+func GenerateHookScript(endpoint Endpoint, token string) string {
+	return fmt.Sprintf(`#!/bin/sh
 # Claude Watch Status - Hook Notification Script
 # Generated by: claude-watch-status init
 # DO NOT EDIT - This file is managed by claude-watch-status
 
-set -e
-
-CWS_HOST="${CWS_HOST:-127.0.0.1}"
+CWS_HOST="${CWS_HOST:-%s}"
 CWS_PORT="${CWS_PORT:-%d}"
+CWS_SCHEME="${CWS_SCHEME:-%s}"
+CWS_INSECURE="${CWS_INSECURE-%s}"
 CWS_TIMEOUT="${CWS_TIMEOUT:-2}"
+CWS_TOKEN="${CWS_TOKEN:-%s}"
+case "$CWS_HOST" in
+  *:*) CWS_URL="${CWS_SCHEME}://[${CWS_HOST}]:${CWS_PORT}/api/hooks" ;;
+  *) CWS_URL="${CWS_SCHEME}://${CWS_HOST}:${CWS_PORT}/api/hooks" ;;
+esac
 
 # Read hook data from stdin
 HOOK_DATA=$(cat)
 
 # Send to daemon (fail silently to not block Claude Code)
-curl -X POST "http://${CWS_HOST}:${CWS_PORT}/api/hooks" \
-  -H "Content-Type: application/json" \
-  -d "$HOOK_DATA" \
-  --max-time "$CWS_TIMEOUT" \
-  --connect-timeout 1 \
-  --silent \
-  --show-error 2>/dev/null || true
+if command -v curl >/dev/null 2>&1; then
+  curl -X POST "$CWS_URL" \
+    -H "Content-Type: application/json" \
+    -H "%s: ${CWS_TOKEN}" \
+    -d "$HOOK_DATA" \
+    --max-time "$CWS_TIMEOUT" \
+    --connect-timeout 1 \
+    ${CWS_INSECURE:+--insecure} \
+    --silent \
+    --output /dev/null 2>/dev/null
+elif command -v wget >/dev/null 2>&1; then
+  wget -q -O /dev/null \
+    --header "Content-Type: application/json" \
+    --header "%s: ${CWS_TOKEN}" \
+    --post-data "$HOOK_DATA" \
+    --timeout "$CWS_TIMEOUT" \
+    ${CWS_INSECURE:+--no-check-certificate} \
+    "$CWS_URL" 2>/dev/null
+fi
 
 exit 0
-`, port)
+`, endpoint.host(), endpoint.Port, endpoint.scheme(), insecureFlag(endpoint), token, TokenHeader, TokenHeader)
+}
+
+// GeneratePowerShellScript generates the hook notification script for
+// Windows, where neither sh nor curl can be relied on
+func GeneratePowerShellScript(endpoint Endpoint, token string) string {
+	return fmt.Sprintf(`# Claude Watch Status - Hook Notification Script
+# Generated by: claude-watch-status init
+# DO NOT EDIT - This file is managed by claude-watch-status
+
+$CwsHost = if ($env:CWS_HOST) { $env:CWS_HOST } else { "%s" }
+$CwsPort = if ($env:CWS_PORT) { $env:CWS_PORT } else { "%d" }
+$CwsScheme = if ($env:CWS_SCHEME) { $env:CWS_SCHEME } else { "%s" }
+$CwsInsecure = if ($null -ne $env:CWS_INSECURE) { $env:CWS_INSECURE } else { "%s" }
+$CwsTimeout = if ($env:CWS_TIMEOUT) { $env:CWS_TIMEOUT } else { "2" }
+$CwsToken = if ($env:CWS_TOKEN) { $env:CWS_TOKEN } else { "%s" }
+
+$UriHost = if ($CwsHost.Contains(":")) { "[$CwsHost]" } else { $CwsHost }
+
+# Read hook data from stdin
+$HookData = [Console]::In.ReadToEnd()
+
+# Send to daemon (fail silently to not block Claude Code)
+try {
+  $Request = @{
+    Method      = "Post"
+    Uri         = "${CwsScheme}://${UriHost}:${CwsPort}/api/hooks"
+    ContentType = "application/json"
+    Headers     = @{ "%s" = $CwsToken }
+    Body        = $HookData
+    TimeoutSec  = $CwsTimeout
+  }
+  if ($CwsInsecure) {
+    if ($PSVersionTable.PSVersion.Major -ge 6) {
+      $Request.SkipCertificateCheck = $true
+    } else {
+      [System.Net.ServicePointManager]::ServerCertificateValidationCallback = { $true }
+    }
+  }
+  Invoke-RestMethod @Request | Out-Null
+} catch {}
+
+exit 0
+`, endpoint.host(), endpoint.Port, endpoint.scheme(), insecureFlag(endpoint), token, TokenHeader)
+}
+
+// insecureFlag is the default of CWS_INSECURE in hook scripts: non-empty
+// to skip verifying the daemon's certificate
+func insecureFlag(endpoint Endpoint) string {
+	if endpoint.Insecure {
+		return "1"
+	}
+	return ""
 }
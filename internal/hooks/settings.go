@@ -0,0 +1,239 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// UnmarshalJSON decodes settings, keeping unknown keys and key order
+func (s *Settings) UnmarshalJSON(data []byte) error {
+	order, fields, err := decodeObject(data)
+	if err != nil {
+		return err
+	}
+	s.order = order
+	s.Hooks = nil
+	s.hookOrder = nil
+
+	if raw, ok := fields["hooks"]; ok {
+		delete(fields, "hooks")
+		hookOrder, events, err := decodeObject(raw)
+		if err != nil {
+			return fmt.Errorf("hooks: %w", err)
+		}
+		s.hookOrder = hookOrder
+		if len(events) > 0 {
+			s.Hooks = make(map[string][]HookEntry, len(events))
+		}
+		for event, raw := range events {
+			var entries []HookEntry
+			if err := json.Unmarshal(raw, &entries); err != nil {
+				return fmt.Errorf("hooks.%s: %w", event, err)
+			}
+			s.Hooks[event] = entries
+		}
+	}
+
+	s.Other = fields
+	return nil
+}
+
+// MarshalJSON encodes settings in their original key order. New keys and
+// events follow the existing ones; "hooks" is omitted when empty.
+func (s Settings) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]json.RawMessage, len(s.Other)+1)
+	for key, raw := range s.Other {
+		fields[key] = raw
+	}
+
+	if len(s.Hooks) > 0 {
+		events := make(map[string]json.RawMessage, len(s.Hooks))
+		for event, entries := range s.Hooks {
+			raw, err := marshalJSON(entries)
+			if err != nil {
+				return nil, err
+			}
+			events[event] = raw
+		}
+		raw, err := encodeObject(s.hookOrder, events)
+		if err != nil {
+			return nil, err
+		}
+		fields["hooks"] = raw
+	}
+
+	return encodeObject(s.order, fields)
+}
+
+// UnmarshalJSON decodes a hook entry, keeping unknown keys and key order
+func (e *HookEntry) UnmarshalJSON(data []byte) error {
+	type plain HookEntry
+	var p plain
+	order, other, err := decodeKnown(data, &p, "matcher", "hooks")
+	if err != nil {
+		return err
+	}
+	*e = HookEntry(p)
+	e.Other, e.order = other, order
+	return nil
+}
+
+// MarshalJSON encodes a hook entry in its original key order
+func (e HookEntry) MarshalJSON() ([]byte, error) {
+	type plain HookEntry
+	return encodeKnown(plain(e), e.order, e.Other, map[string]json.RawMessage{"matcher": json.RawMessage(`""`)})
+}
+
+// UnmarshalJSON decodes a hook, keeping unknown keys and key order
+func (h *HookConfig) UnmarshalJSON(data []byte) error {
+	type plain HookConfig
+	var p plain
+	order, other, err := decodeKnown(data, &p, "type", "command", "timeout")
+	if err != nil {
+		return err
+	}
+	*h = HookConfig(p)
+	h.Other, h.order = other, order
+	return nil
+}
+
+// MarshalJSON encodes a hook in its original key order
+func (h HookConfig) MarshalJSON() ([]byte, error) {
+	type plain HookConfig
+	return encodeKnown(plain(h), h.order, h.Other, map[string]json.RawMessage{"timeout": json.RawMessage(`0`)})
+}
+
+// decodeKnown decodes a JSON object into v and returns its key order and
+// the fields other than the known keys
+func decodeKnown(data []byte, v interface{}, known ...string) ([]string, map[string]json.RawMessage, error) {
+	order, fields, err := decodeObject(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, nil, err
+	}
+	for _, key := range known {
+		delete(fields, key)
+	}
+	if len(fields) == 0 {
+		fields = nil
+	}
+	return order, fields, nil
+}
+
+// encodeKnown encodes v, adds the other fields and writes them in order;
+// known keys not in order follow in the order of v's fields. Known keys
+// that v omits when empty are written with their zero value if they were
+// present when read.
+func encodeKnown(v interface{}, order []string, other, zeros map[string]json.RawMessage) ([]byte, error) {
+	raw, err := marshalJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	fieldOrder, fields, err := decodeObject(raw)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range order {
+		if _, ok := fields[key]; !ok && zeros[key] != nil {
+			fields[key] = zeros[key]
+		}
+	}
+	for key, value := range other {
+		if _, known := fields[key]; !known {
+			fields[key] = value
+		}
+	}
+	return encodeObject(append(order[:len(order):len(order)], fieldOrder...), fields)
+}
+
+// decodeObject decodes a JSON object into raw field values and the order
+// of its keys. null decodes as an empty object.
+func decodeObject(data []byte) ([]string, map[string]json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if tok == nil {
+		return nil, fields, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("expected a JSON object")
+	}
+
+	var order []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := tok.(string)
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, nil, err
+		}
+		if _, dup := fields[key]; !dup {
+			order = append(order, key)
+		}
+		fields[key] = raw
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, err
+	}
+	return order, fields, nil
+}
+
+// encodeObject writes fields as a JSON object: keys listed in order first,
+// then any remaining keys sorted
+func encodeObject(order []string, fields map[string]json.RawMessage) ([]byte, error) {
+	keys := make([]string, 0, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for _, key := range order {
+		if _, ok := fields[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+	var rest []string
+	for key := range fields {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	keys = append(keys, rest...)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for n, key := range keys {
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := marshalJSON(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(fields[key])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshalJSON encodes v without escaping <, > and & (common in commands)
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
@@ -0,0 +1,95 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+const goldenCommand = `"/usr/local/bin/claude-watch-status" hook-relay --port 10087`
+
+// readSettings decodes a settings file from testdata
+func readSettings(t *testing.T, name string) (*Settings, []byte) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var settings Settings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatalf("decoding %s: %v", name, err)
+	}
+	return &settings, data
+}
+
+// checkGolden compares got with a golden file, or rewrites it with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs:\n--- got\n%s\n--- want\n%s", name, got, want)
+	}
+}
+
+// Settings written back unchanged keep unknown keys at every level and
+// the order of keys and hook events
+func TestSettingsRoundTrip(t *testing.T) {
+	settings, original := readSettings(t, "settings.json")
+	got, err := formatSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, original) {
+		t.Errorf("round trip changed settings.json:\n%s", got)
+	}
+}
+
+// CWS hooks follow the existing hooks of each event, and new events the
+// existing ones
+func TestMergeCWSHooksGolden(t *testing.T) {
+	settings, _ := readSettings(t, "settings.json")
+	MergeCWSHooks(settings, goldenCommand)
+	got, err := formatSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "settings.merged.json", got)
+
+	if !HasCWSHooks(settings) {
+		t.Error("HasCWSHooks() = false after merging")
+	}
+	if command := cwsHookCommand(settings); command != goldenCommand {
+		t.Errorf("cwsHookCommand() = %q, want %q", command, goldenCommand)
+	}
+}
+
+// Removing the CWS hooks restores the settings as they were before
+func TestRemoveCWSHooksRestoresSettings(t *testing.T) {
+	settings, _ := readSettings(t, "settings.merged.json")
+	RemoveCWSHooks(settings)
+	got, err := formatSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, original := readSettings(t, "settings.json")
+	if !bytes.Equal(got, original) {
+		t.Errorf("removing CWS hooks did not restore settings.json:\n%s", got)
+	}
+	if HasCWSHooks(settings) {
+		t.Error("HasCWSHooks() = true after removing")
+	}
+}
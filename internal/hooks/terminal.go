@@ -0,0 +1,137 @@
+package hooks
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/pkg/protocol"
+)
+
+// TerminalHeader is the HTTP header describing the terminal of a hook event
+const TerminalHeader = protocol.HookTerminalHeader
+
+// DetectTerminal describes the terminal the relay runs in, which is the
+// terminal of the Claude Code session running the hook, for the
+// TerminalHeader. It returns "" outside a terminal. lookupTTY returns
+// the controlling terminal, such as controllingTTY; it is only called
+// when the environment tells nothing better, as it may run ps.
+func DetectTerminal(lookupTTY func() string) string {
+	v := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+
+	program := os.Getenv("TERM_PROGRAM")
+	if program == "" && os.Getenv("KITTY_WINDOW_ID") != "" {
+		program = "kitty"
+	}
+	set("program", program)
+	set("tmux_pane", os.Getenv("TMUX_PANE"))
+	if tmux := os.Getenv("TMUX"); tmux != "" && os.Getenv("TMUX_PANE") != "" {
+		socket, _, _ := strings.Cut(tmux, ",")
+		set("tmux_socket", socket)
+	}
+	if session := os.Getenv("ITERM_SESSION_ID"); session != "" {
+		// "w0t1p0:UUID": the UUID is the session's unique ID
+		_, id, _ := strings.Cut(session, ":")
+		set("iterm_session", id)
+	}
+	set("wezterm_pane", os.Getenv("WEZTERM_PANE"))
+	set("kitty_window", os.Getenv("KITTY_WINDOW_ID"))
+	if os.Getenv("DISPLAY") != "" {
+		// Set by xterm, Konsole, Alacritty and others under X11
+		set("x_window", os.Getenv("WINDOWID"))
+	}
+
+	// The tty identifies Terminal.app tabs, which have no ID of their own
+	if len(v) == 0 || program == "Apple_Terminal" {
+		set("tty", lookupTTY())
+	}
+	if len(v) == 0 {
+		return ""
+	}
+	return v.Encode()
+}
+
+// controllingTTY returns the controlling terminal of the relay, such as
+// "/dev/ttys003", or "" without one
+func controllingTTY() string {
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "ps", "-o", "tty=", "-p", strconv.Itoa(os.Getpid())).Output()
+	if err != nil {
+		return ""
+	}
+	tty := strings.TrimSpace(string(out))
+	if tty == "" || tty == "?" || tty == "??" {
+		return ""
+	}
+	if !strings.HasPrefix(tty, "/dev/") {
+		tty = "/dev/" + tty
+	}
+	return tty
+}
+
+// maxTTYCacheAge is how long the terminal of a session stays cached;
+// older entries are removed when a new session is cached
+const maxTTYCacheAge = 24 * time.Hour
+
+// cachedTTY returns the controlling terminal of a session, running ps
+// only for the first hook event of the session; later events of the
+// session read it from a file in dir, "" included
+func cachedTTY(dir, sessionID string) string {
+	if dir == "" || !validCacheName(sessionID) {
+		return controllingTTY()
+	}
+	path := filepath.Join(dir, sessionID)
+	if data, err := os.ReadFile(path); err == nil {
+		return string(data)
+	}
+
+	tty := controllingTTY()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return tty
+	}
+	pruneTTYCache(dir)
+	os.WriteFile(path, []byte(tty), 0600)
+	return tty
+}
+
+// validCacheName reports whether a session ID can name a cache file
+func validCacheName(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// pruneTTYCache removes the cached terminals of sessions over
+// maxTTYCacheAge old
+func pruneTTYCache(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > maxTTYCacheAge {
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package hooks
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sho7650/claude-watch-status/pkg/protocol"
+)
+
+// TokenHeader is the HTTP header carrying the shared secret from the hook script
+const TokenHeader = protocol.HookTokenHeader
+
+// GenerateToken creates a new random shared-secret token
+func GenerateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// LoadToken reads the shared-secret token from path.
+// Returns an empty string without error if the file does not exist.
+func LoadToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SaveToken writes the shared-secret token to path, readable only by the owner
+func SaveToken(path, token string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(token+"\n"), 0600)
+}
+
+// TokensEqual compares two tokens in constant time
+func TokensEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
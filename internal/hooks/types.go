@@ -1,5 +1,7 @@
 package hooks
 
+import "encoding/json"
+
 // CWSMarker is the identifier used to mark CWS-managed hook entries
 const CWSMarker = "# cws-managed"
 
@@ -11,14 +13,42 @@ var CWSHookEvents = []string{
 	"PreToolUse",
 	"PostToolUse",
 	"Stop",
+	"SubagentStop",
 	"SessionStart",
 	"SessionEnd",
+	"UserPromptSubmit",
+	"Notification",
+	"PermissionRequest",
+}
+
+// Transport is how hook events get from Claude Code to the daemon
+type Transport string
+
+const (
+	// TransportShell runs a POSIX sh script using curl or wget
+	TransportShell Transport = "sh"
+	// TransportPowerShell runs a PowerShell script (Windows)
+	TransportPowerShell Transport = "powershell"
+	// TransportBinary runs "claude-watch-status hook-relay"
+	TransportBinary Transport = "binary"
+)
+
+// Valid reports whether the transport is one of the known transports
+func (t Transport) Valid() bool {
+	switch t {
+	case TransportShell, TransportPowerShell, TransportBinary:
+		return true
+	}
+	return false
 }
 
 // HookEntry represents a hook entry in settings.json
 type HookEntry struct {
 	Matcher string       `json:"matcher,omitempty"`
 	Hooks   []HookConfig `json:"hooks"`
+
+	Other map[string]json.RawMessage `json:"-"` // unknown keys, kept verbatim
+	order []string                   // key order as read
 }
 
 // HookConfig represents a single hook configuration
@@ -26,14 +56,20 @@ type HookConfig struct {
 	Type    string `json:"type"`
 	Command string `json:"command"`
 	Timeout int    `json:"timeout,omitempty"`
+
+	Other map[string]json.RawMessage `json:"-"` // unknown keys, kept verbatim
+	order []string                   // key order as read
 }
 
-// Settings represents the Claude Code settings.json structure
+// Settings represents the Claude Code settings.json structure. Only hooks
+// are interpreted; all other keys round-trip unchanged, in their original
+// order.
 type Settings struct {
-	Hooks   map[string][]HookEntry `json:"hooks,omitempty"`
-	Env     map[string]interface{} `json:"env,omitempty"`
-	Schema  string                 `json:"$schema,omitempty"`
-	Other   map[string]interface{} `json:"-"` // Catch-all for unknown fields
+	Hooks map[string][]HookEntry // hook entries by event name
+
+	Other     map[string]json.RawMessage // top-level keys other than "hooks"
+	order     []string                   // top-level key order as read
+	hookOrder []string                   // event order as read
 }
 
 // InstallOptions contains options for the init command
@@ -42,15 +78,21 @@ type InstallOptions struct {
 	Force      bool
 	Yes        bool
 	KeepScript bool
+	Transport  Transport // empty = DefaultTransport()
 }
 
 // CheckResult represents the result of a configuration check
 type CheckResult struct {
-	Installed       bool
-	SettingsPath    string
-	ScriptPath      string
-	ScriptExists    bool
+	Installed        bool
+	SettingsPath     string
+	ProjectDir       string // set for project-level installs
+	ScriptPath       string
+	ScriptExists     bool
 	ScriptExecutable bool
+	Transport        Transport // transport of the installed hooks
+	Command          string    // hook command registered in settings
+	TokenPath        string
+	TokenExists      bool
 	ConfiguredEvents []string
 	MissingEvents    []string
 	DaemonEndpoint   string
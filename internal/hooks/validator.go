@@ -13,7 +13,7 @@ func ValidateSettingsFile(path string) error {
 		return fmt.Errorf("cannot read settings file: %w", err)
 	}
 
-	var settings map[string]interface{}
+	var settings Settings
 	if err := json.Unmarshal(data, &settings); err != nil {
 		return fmt.Errorf("invalid JSON: %w", err)
 	}
@@ -48,8 +48,8 @@ func ValidateCWSConfiguration(settingsPath, scriptPath string) []error {
 	} else {
 		// Check for CWS hooks in settings
 		data, _ := os.ReadFile(settingsPath)
-		var settings map[string]interface{}
-		json.Unmarshal(data, &settings)
+		settings := &Settings{}
+		json.Unmarshal(data, settings)
 
 		if !HasCWSHooks(settings) {
 			errors = append(errors, fmt.Errorf("settings: CWS hooks not found"))
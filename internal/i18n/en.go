@@ -0,0 +1,120 @@
+package i18n
+
+// en holds the English messages; every key has one
+var en = map[string]string{
+	// States
+	"state.user input":        "user input",
+	"state.processing prompt": "processing prompt",
+	"state.processing":        "processing",
+	"state.thinking":          "thinking",
+	"state.responding":        "responding",
+	"state.calling tool":      "calling tool",
+	"state.running tool":      "running tool",
+	"state.running_tool":      "running: %s",
+	"state.waiting approval":  "waiting approval",
+	"state.completed":         "completed",
+	"state.max tokens":        "max tokens",
+	"state.tool error":        "tool error",
+	"state.interrupted":       "interrupted",
+	"state.continuing":        "continuing",
+	"state.inactive":          "inactive",
+	"state.session started":   "session started",
+	"state.session ended":     "session ended",
+	"state.started":           "started",
+
+	// Notifications
+	"notify.state":                         "%s: %s",
+	"notify.unattended":                    "⚠️ %s: session running with unattended permissions",
+	"notify.daemon_stopped":                "CWS daemon stopped — status updates paused (%s)",
+	"notify.watcher_failed":                "CWS stopped watching session logs — status updates paused",
+	"notify.waiting_request":               "%s: waiting approval for %s",
+	"notify.digest":                        "%s: %s",
+	"notify.digest_separator":              ", ",
+	"notify.digest_more":                   "%s more",
+	"notify.digest.waiting_approval":       "%s waiting approval",
+	"notify.digest.completed":              "%s completed",
+	"notify.digest.interrupted":            "%s interrupted",
+	"notify.digest.session_start":          "%s sessions started",
+	"notify.digest.session_end":            "%s sessions ended",
+	"notify.digest.unattended_permissions": "%s running unattended",
+
+	// Terminal views
+	"cli.watching":        "Watching Claude Code activity... (Ctrl+C to stop)",
+	"cli.watching_remote": "Watching Claude Code activity via %s... (Ctrl+C to stop)",
+	"cli.dashboard":       "Claude Code Status (Ctrl+C to stop)",
+	"cli.stopped":         "Stopped.",
+	"cli.removed":         "removed",
+	"cli.elapsed":         "for %s",
+	"cli.reconnected":     "Reconnected to the daemon.",
+	"cli.reconnecting":    "Lost connection to the daemon (%s), reconnecting...",
+	"cli.focus_hint":      "respond: claude-watch-status focus %s",
+
+	// Web UI
+	"web.title":                 "Claude Code Status",
+	"web.contrast":              "High contrast",
+	"web.contrast_on":           "High contrast on",
+	"web.contrast_off":          "High contrast off",
+	"web.motion":                "Reduce motion",
+	"web.motion_on":             "Reduced motion on",
+	"web.motion_off":            "Reduced motion off",
+	"web.notifications":         "Browser notifications",
+	"web.notifications_on":      "Browser notifications on",
+	"web.notifications_off":     "Browser notifications off",
+	"web.pause":                 "Pause all notifications for an hour",
+	"web.paused_until":          "Notifications paused until %s; resume",
+	"web.paused":                "Notifications paused; resume",
+	"web.connecting":            "Connecting...",
+	"web.connected":             "Connected",
+	"web.disconnected":          "Disconnected - Reconnecting...",
+	"web.projects":              "Projects",
+	"web.no_projects":           "No active projects",
+	"web.no_projects_hint":      "Start a Claude Code session to see status updates",
+	"web.keyboard_hint":         "Use the arrow keys, Home and End to move between projects, A to acknowledge a project's state and F to raise the terminal of a project waiting for approval.",
+	"web.search_title":          "Search session logs",
+	"web.search_placeholder":    "Text, tool or result…",
+	"web.search_text":           "Search text",
+	"web.search_range":          "Time range",
+	"web.search_24h":            "Last 24 hours",
+	"web.search_7d":             "Last 7 days",
+	"web.search_all":            "All time",
+	"web.search":                "Search",
+	"web.searching":             "Searching…",
+	"web.search_failed":         "Search failed: %s",
+	"web.no_matches":            "No matches",
+	"web.match":                 "%s match",
+	"web.matches":               "%s matches",
+	"web.role_user":             "user",
+	"web.role_claude":           "claude",
+	"web.role_tool":             "tool",
+	"web.tool_result":           "%s result",
+	"web.result":                "result",
+	"web.subagent_match":        " (subagent)",
+	"web.settings_title":        "Daemon settings",
+	"web.setting_desktop":       "Desktop notifications",
+	"web.setting_daemon_health": "Daemon health alerts",
+	"web.setting_unattended":    "Unattended permission alerts",
+	"web.setting_idle":          "Completed after idle for",
+	"web.setting_idle_hint":     "e.g. 5s, 30s, 1m",
+	"web.setting_muted":         "Muted projects (one per line)",
+	"web.save":                  "Save",
+	"web.saving":                "Saving…",
+	"web.saved":                 "Saved",
+	"web.not_saved":             "Not saved: %s",
+	"web.acknowledge":           "Acknowledge (A)",
+	"web.focus_title":           "Respond in the terminal (F): claude-watch-status focus %s",
+	"web.focus_failed":          "Could not raise the terminal: %s",
+	"web.unattended":            "⚠️ unattended-permissions",
+	"web.unattended_title":      "Tools run without approval prompts (--dangerously-skip-permissions)",
+	"web.label_subagent":        ", subagent %s: %s",
+	"web.label_unattended":      ", unattended permissions",
+	"web.label_plan":            ", plan mode",
+	"web.label_accept_edits":    ", accepting edits",
+	"web.plan":                  "🛡️ plan",
+	"web.plan_title":            "Plan mode: Claude plans without editing files or running commands",
+	"web.accept_edits":          "📝 accept edits",
+	"web.accept_edits_title":    "File edits are accepted without approval prompts; other tools still ask",
+	"web.label_acknowledged":    ", acknowledged",
+	"web.label_elapsed":         " for %s",
+	"web.label_updated":         ", updated %s, via %s",
+	"web.footer":                "claude-watch-status • Real-time status monitor for Claude Code",
+}
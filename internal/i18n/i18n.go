@@ -0,0 +1,132 @@
+// Package i18n translates the text shown to people: CLI messages,
+// notifications, state names and the Web UI labels. The language is
+// configured or taken from the locale (LC_ALL, LC_MESSAGES, LANG);
+// messages missing in a language fall back to English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Default is the language used when none is configured or detected
+const Default = "en"
+
+// catalogs holds the messages of every language by key. Messages take
+// their arguments as %s, which the Web UI substitutes as well.
+var catalogs = map[string]map[string]string{
+	"en": en,
+	"ja": ja,
+}
+
+// current is the language set by Set
+var current atomic.Pointer[string]
+
+// Languages returns the supported languages, sorted
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Check returns an error if a configured language is not supported; ""
+// (detect from the locale) is accepted
+func Check(lang string) error {
+	if _, ok := catalogs[lang]; lang != "" && !ok {
+		return fmt.Errorf("unknown language %q (want %s)", lang, strings.Join(Languages(), " or "))
+	}
+	return nil
+}
+
+// Detect returns the configured language, or without one the language of
+// the locale; English if the locale's language is not supported
+func Detect(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return localeLanguage(v)
+		}
+	}
+	return Default
+}
+
+// localeLanguage returns the supported language of a locale such as
+// "ja_JP.UTF-8"
+func localeLanguage(locale string) string {
+	lang, _, _ := strings.Cut(locale, ".")
+	lang, _, _ = strings.Cut(lang, "@")
+	lang, _, _ = strings.Cut(lang, "_")
+	lang = strings.ToLower(lang)
+	if _, ok := catalogs[lang]; ok {
+		return lang
+	}
+	return Default
+}
+
+// Set selects the language of T and State; see Detect
+func Set(lang string) error {
+	if err := Check(lang); err != nil {
+		return err
+	}
+	if lang == "" {
+		lang = Default
+	}
+	current.Store(&lang)
+	return nil
+}
+
+// Lang returns the selected language
+func Lang() string {
+	if lang := current.Load(); lang != nil {
+		return *lang
+	}
+	return Default
+}
+
+// T returns the message for key in the selected language, formatted with
+// args. Unknown keys are returned as they are.
+func T(key string, args ...any) string {
+	msg, ok := catalogs[Lang()][key]
+	if !ok {
+		if msg, ok = en[key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// State translates a state text such as "waiting approval" or
+// "running: Bash"; states without a translation are returned as they are
+func State(text string) string {
+	if tool, ok := strings.CutPrefix(text, "running: "); ok {
+		return T("state.running_tool", tool)
+	}
+	if _, ok := en["state."+text]; !ok {
+		return text
+	}
+	return T("state." + text)
+}
+
+// Messages returns every message of a language, English where it has no
+// translation, for the Web UI
+func Messages(lang string) map[string]string {
+	msgs := make(map[string]string, len(en))
+	for key, msg := range en {
+		msgs[key] = msg
+	}
+	for key, msg := range catalogs[lang] {
+		msgs[key] = msg
+	}
+	return msgs
+}
@@ -0,0 +1,120 @@
+package i18n
+
+// ja holds the Japanese messages
+var ja = map[string]string{
+	// States
+	"state.user input":        "ユーザー入力",
+	"state.processing prompt": "プロンプト処理中",
+	"state.processing":        "処理中",
+	"state.thinking":          "思考中",
+	"state.responding":        "応答中",
+	"state.calling tool":      "ツール呼び出し中",
+	"state.running tool":      "ツール実行中",
+	"state.running_tool":      "実行中: %s",
+	"state.waiting approval":  "承認待ち",
+	"state.completed":         "完了",
+	"state.max tokens":        "トークン上限",
+	"state.tool error":        "ツールエラー",
+	"state.interrupted":       "中断",
+	"state.continuing":        "継続中",
+	"state.inactive":          "非アクティブ",
+	"state.session started":   "セッション開始",
+	"state.session ended":     "セッション終了",
+	"state.started":           "開始",
+
+	// Notifications
+	"notify.state":                         "%s: %s",
+	"notify.unattended":                    "⚠️ %s: 承認なしでツールを実行するセッションです",
+	"notify.daemon_stopped":                "CWS デーモンが停止しました — ステータス更新は止まっています (%s)",
+	"notify.watcher_failed":                "CWS がセッションログを監視できなくなりました — ステータス更新は止まっています",
+	"notify.waiting_request":               "%s: %s の承認待ち",
+	"notify.digest":                        "%s: %s",
+	"notify.digest_separator":              "、",
+	"notify.digest_more":                   "ほか %s 件",
+	"notify.digest.waiting_approval":       "承認待ち %s 件",
+	"notify.digest.completed":              "完了 %s 件",
+	"notify.digest.interrupted":            "中断 %s 件",
+	"notify.digest.session_start":          "セッション開始 %s 件",
+	"notify.digest.session_end":            "セッション終了 %s 件",
+	"notify.digest.unattended_permissions": "承認なし実行 %s 件",
+
+	// Terminal views
+	"cli.watching":        "Claude Code の動作を監視しています... (Ctrl+C で終了)",
+	"cli.watching_remote": "%s 経由で Claude Code の動作を監視しています... (Ctrl+C で終了)",
+	"cli.dashboard":       "Claude Code ステータス (Ctrl+C で終了)",
+	"cli.stopped":         "終了しました。",
+	"cli.removed":         "削除",
+	"cli.elapsed":         "%s 経過",
+	"cli.reconnected":     "デーモンに再接続しました。",
+	"cli.reconnecting":    "デーモンとの接続が切れました (%s)。再接続しています...",
+	"cli.focus_hint":      "応答: claude-watch-status focus %s",
+
+	// Web UI
+	"web.title":                 "Claude Code ステータス",
+	"web.contrast":              "ハイコントラスト",
+	"web.contrast_on":           "ハイコントラスト: オン",
+	"web.contrast_off":          "ハイコントラスト: オフ",
+	"web.motion":                "動きを減らす",
+	"web.motion_on":             "動きを減らす: オン",
+	"web.motion_off":            "動きを減らす: オフ",
+	"web.notifications":         "ブラウザ通知",
+	"web.notifications_on":      "ブラウザ通知: オン",
+	"web.notifications_off":     "ブラウザ通知: オフ",
+	"web.pause":                 "すべての通知を 1 時間止める",
+	"web.paused_until":          "%s まで通知を停止中。クリックで再開",
+	"web.paused":                "通知を停止中。クリックで再開",
+	"web.connecting":            "接続中...",
+	"web.connected":             "接続済み",
+	"web.disconnected":          "切断 - 再接続中...",
+	"web.projects":              "プロジェクト",
+	"web.no_projects":           "アクティブなプロジェクトはありません",
+	"web.no_projects_hint":      "Claude Code のセッションを始めるとステータスが表示されます",
+	"web.keyboard_hint":         "矢印キー、Home、End でプロジェクト間を移動し、A でプロジェクトの状態を確認済みに、F で承認待ちのプロジェクトのターミナルを前面に出します。",
+	"web.search_title":          "セッションログの検索",
+	"web.search_placeholder":    "テキスト、ツール、結果…",
+	"web.search_text":           "検索テキスト",
+	"web.search_range":          "期間",
+	"web.search_24h":            "過去 24 時間",
+	"web.search_7d":             "過去 7 日間",
+	"web.search_all":            "すべて",
+	"web.search":                "検索",
+	"web.searching":             "検索中…",
+	"web.search_failed":         "検索に失敗しました: %s",
+	"web.no_matches":            "一致なし",
+	"web.match":                 "%s 件一致",
+	"web.matches":               "%s 件一致",
+	"web.role_user":             "ユーザー",
+	"web.role_claude":           "claude",
+	"web.role_tool":             "ツール",
+	"web.tool_result":           "%s の結果",
+	"web.result":                "結果",
+	"web.subagent_match":        " (サブエージェント)",
+	"web.settings_title":        "デーモンの設定",
+	"web.setting_desktop":       "デスクトップ通知",
+	"web.setting_daemon_health": "デーモン停止の通知",
+	"web.setting_unattended":    "承認なし実行の警告",
+	"web.setting_idle":          "完了とみなすまでの待ち時間",
+	"web.setting_idle_hint":     "例: 5s、30s、1m",
+	"web.setting_muted":         "ミュートするプロジェクト (1 行に 1 つ)",
+	"web.save":                  "保存",
+	"web.saving":                "保存中…",
+	"web.saved":                 "保存しました",
+	"web.not_saved":             "保存できませんでした: %s",
+	"web.acknowledge":           "確認済みにする (A)",
+	"web.focus_title":           "ターミナルで応答 (F): claude-watch-status focus %s",
+	"web.focus_failed":          "ターミナルを前面に出せませんでした: %s",
+	"web.unattended":            "⚠️ 承認なし実行",
+	"web.unattended_title":      "ツールを承認なしで実行しています (--dangerously-skip-permissions)",
+	"web.label_subagent":        "、サブエージェント %s: %s",
+	"web.label_unattended":      "、承認なし実行",
+	"web.label_plan":            "、プランモード",
+	"web.label_accept_edits":    "、編集を自動承認",
+	"web.plan":                  "🛡️ プラン",
+	"web.plan_title":            "プランモード: ファイルの編集やコマンドの実行をせずに計画します",
+	"web.accept_edits":          "📝 編集自動承認",
+	"web.accept_edits_title":    "ファイルの編集を承認なしで受け入れます。ほかのツールは承認を求めます",
+	"web.label_acknowledged":    "、確認済み",
+	"web.label_elapsed":         " (%s 経過)",
+	"web.label_updated":         "、%s 更新、%s 経由",
+	"web.footer":                "claude-watch-status • Claude Code のリアルタイム ステータスモニター",
+}
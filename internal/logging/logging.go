@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+var (
+	level  = new(slog.LevelVar)
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+)
+
+// Logger returns the shared logger
+func Logger() *slog.Logger {
+	return logger
+}
+
+// Level returns the current log level
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetLevel changes the log level at runtime
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// ParseLevel parses a level name: debug, info, warn or error
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q (want debug, info, warn or error)", s)
+	}
+	return l, nil
+}
+
+// ToggleDebug switches between debug and info level and returns the new level
+func ToggleDebug() slog.Level {
+	if level.Level() <= slog.LevelDebug {
+		level.Set(slog.LevelInfo)
+	} else {
+		level.Set(slog.LevelDebug)
+	}
+	return level.Level()
+}
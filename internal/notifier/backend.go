@@ -0,0 +1,160 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sho7650/claude-watch-status/internal/config"
+)
+
+// Event identifies what a notification is about
+type Event string
+
+const (
+	EventWaitingApproval       Event = "waiting_approval"
+	EventCompleted             Event = "completed"
+	EventInterrupted           Event = "interrupted"
+	EventSessionStart          Event = "session_start"
+	EventSessionEnd            Event = "session_end"
+	EventUnattendedPermissions Event = "unattended_permissions"
+	EventDaemonStopped         Event = "daemon_stopped"
+	EventWatcherFailed         Event = "watcher_failed"
+)
+
+// events lists all events, in the order they are documented
+var events = []Event{
+	EventWaitingApproval, EventCompleted, EventInterrupted, EventSessionStart,
+	EventSessionEnd, EventUnattendedPermissions, EventDaemonStopped, EventWatcherFailed,
+}
+
+// Notification is a message handed to every backend
+type Notification struct {
+	Event     Event
+	Project   string // empty for notifications about the daemon itself
+	State     string // the project's state text, e.g. "waiting approval"
+	Tool      string // the tool waiting for approval or running, if known
+	SessionID string
+	Title     string
+	Message   string
+	Sound     bool // ask for an audible alert
+	Urgent    bool // a critical project is waiting; desktop adds a beep
+}
+
+// Backend delivers notifications: to the desktop, a chat, a command, ...
+type Backend interface {
+	// Name identifies the backend in logs ("desktop", "slack", ...)
+	Name() string
+	// Send delivers a notification, giving up when ctx is done
+	Send(ctx context.Context, n Notification) error
+}
+
+// Factory creates a backend from its config entry. It returns an error
+// naming the problem if required settings are missing or invalid.
+type Factory func(cfg config.NotifierConfig) (Backend, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a backend type available to the "notifiers" config
+// section. It panics if a type with the same name is already registered.
+func Register(typ string, f Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, dup := registry[typ]; dup {
+		panic("notifier: Register called twice for " + typ)
+	}
+	registry[typ] = f
+}
+
+// Backends returns the names of all registered backend types, sorted
+func Backends() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewBackend creates a backend of a registered type
+func NewBackend(cfg config.NotifierConfig) (Backend, error) {
+	registryMu.RLock()
+	f, ok := registry[cfg.Type]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q (available: %s)", cfg.Type, strings.Join(Backends(), ", "))
+	}
+	return f(cfg)
+}
+
+// ParseEvent returns the event with the given name
+func ParseEvent(name string) (Event, bool) {
+	for _, e := range events {
+		if string(e) == name {
+			return e, true
+		}
+	}
+	return "", false
+}
+
+// route is a backend and the notifications it receives
+type route struct {
+	backend  Backend
+	events   []Event  // empty = all events
+	projects []string // empty = all projects
+}
+
+// defaultEvents are the events of backend types that, without "on", do
+// not receive every notification
+var defaultEvents = map[string][]Event{
+	// Runs a program each time: only when the user is needed or done
+	"exec": {EventWaitingApproval, EventCompleted},
+}
+
+// newRoute creates the backend configured by cfg
+func newRoute(cfg config.NotifierConfig) (route, error) {
+	b, err := NewBackend(cfg)
+	if err != nil {
+		return route{}, err
+	}
+	r := route{backend: b, projects: cfg.Projects}
+	if len(cfg.On) == 0 {
+		r.events = defaultEvents[cfg.Type]
+	}
+	for _, name := range cfg.On {
+		e, ok := ParseEvent(name)
+		if !ok {
+			return route{}, unknownEventError(name)
+		}
+		r.events = append(r.events, e)
+	}
+	return r, nil
+}
+
+// unknownEventError is the error for an event name that ParseEvent does not know
+func unknownEventError(name string) error {
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = string(e)
+	}
+	return fmt.Errorf("unknown event %q (want %s)", name, strings.Join(names, ", "))
+}
+
+// matches reports whether the route receives a notification. Daemon
+// notifications have no project and pass any project filter.
+func (r route) matches(n Notification) bool {
+	if len(r.events) > 0 && !slices.Contains(r.events, n.Event) {
+		return false
+	}
+	return n.Project == "" || len(r.projects) == 0 || slices.Contains(r.projects, n.Project)
+}
@@ -0,0 +1,62 @@
+package notifier
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/gen2brain/beeep"
+	"github.com/sho7650/claude-watch-status/internal/config"
+)
+
+// appName is shown as the sender of notifications; Windows toast
+// notifications also use it as the application ID
+const appName = "Claude Watch Status"
+
+// desktopType is the "notifiers" type of the desktop backend
+const desktopType = "desktop"
+
+func init() {
+	beeep.AppName = appName
+	Register(desktopType, func(cfg config.NotifierConfig) (Backend, error) {
+		sounds, err := parseSounds(cfg.Sounds)
+		if err != nil {
+			return nil, err
+		}
+		return desktopBackend{sounds: sounds}, nil
+	})
+}
+
+// desktopBackend shows native desktop notifications. Events with a sound
+// in "sounds" show a silent notification and play the sound instead of
+// the system alert, so they can be told apart by ear.
+type desktopBackend struct {
+	sounds map[Event]string
+}
+
+func (desktopBackend) Name() string {
+	return desktopType
+}
+
+func (d desktopBackend) Send(ctx context.Context, n Notification) error {
+	if !n.Sound {
+		return beeep.Notify(n.Title, n.Message, "")
+	}
+	if sound := d.sounds[n.Event]; sound != "" {
+		if err := beeep.Notify(n.Title, n.Message, ""); err != nil {
+			return err
+		}
+		return playSound(ctx, sound)
+	}
+
+	// beeep.Alert includes sound on supported platforms
+	var err error
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		err = beeep.Alert(n.Title, n.Message, "")
+	} else {
+		err = beeep.Notify(n.Title, n.Message, "")
+	}
+	if err != nil || !n.Urgent {
+		return err
+	}
+	return beeep.Beep(beeep.DefaultFreq, beeep.DefaultDuration)
+}
@@ -0,0 +1,130 @@
+package notifier
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/i18n"
+)
+
+// maxDigestProjects is how many project names a digest lists
+const maxDigestProjects = 5
+
+// SetDigest batches project notifications: after one is sent, those
+// arriving within window are held and sent as one summary when it ends.
+// Urgent notifications and those about the daemon are sent at once. 0
+// turns batching off.
+func (n *Notifier) SetDigest(window time.Duration) {
+	n.digestMu.Lock()
+	n.digestWindow = window
+	n.digestMu.Unlock()
+	if window <= 0 {
+		n.flushDigest()
+	}
+}
+
+// queue sends a project notification, or holds it for the digest while a
+// digest window is open
+func (n *Notifier) queue(notif Notification) {
+	n.digestMu.Lock()
+	now := time.Now()
+	if n.digestWindow <= 0 || notif.Urgent {
+		n.digestMu.Unlock()
+		n.send(notif)
+		return
+	}
+	if now.After(n.digestUntil) && len(n.pending) == 0 {
+		// The first notification opens the window and is sent as it is
+		n.digestUntil = now.Add(n.digestWindow)
+		n.digestMu.Unlock()
+		n.send(notif)
+		return
+	}
+	n.pending = append(n.pending, notif)
+	if len(n.pending) == 1 {
+		time.AfterFunc(n.digestUntil.Sub(now), n.flushDigest)
+	}
+	n.digestMu.Unlock()
+}
+
+// flushDigest sends the notifications held during a digest window: each
+// backend gets the one it would have received, or a summary of several.
+// A new window opens so a steady stream yields one digest per window.
+func (n *Notifier) flushDigest() {
+	n.digestMu.Lock()
+	pending := n.pending
+	n.pending = nil
+	if len(pending) > 0 {
+		n.digestUntil = time.Now().Add(n.digestWindow)
+	}
+	n.digestMu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	n.mu.RLock()
+	enabled, desktop, routes := n.enabled, n.desktop, n.routes
+	n.mu.RUnlock()
+	if !enabled {
+		return
+	}
+	if desktop {
+		go deliver(desktopBackend{}, digestOf(pending))
+	}
+	for _, r := range routes {
+		var matched []Notification
+		for _, notif := range pending {
+			if r.matches(notif) {
+				matched = append(matched, notif)
+			}
+		}
+		if len(matched) > 0 {
+			go deliver(r.backend, digestOf(matched))
+		}
+	}
+}
+
+// digestOf sums up notifications, as in "3 completed, 1 waiting approval:
+// api, web, docs". A single notification is returned as it is. The summary
+// has the event of the most important notification, in the order of
+// events, so routing by event and per-event sounds follow it.
+func digestOf(notifs []Notification) Notification {
+	if len(notifs) == 1 {
+		return notifs[0]
+	}
+
+	counts := make(map[Event]int)
+	var projects []string
+	sound := false
+	for _, notif := range notifs {
+		counts[notif.Event]++
+		if !slices.Contains(projects, notif.Project) {
+			projects = append(projects, notif.Project)
+		}
+		sound = sound || notif.Sound
+	}
+
+	var event Event
+	var parts []string
+	for _, e := range events {
+		if counts[e] == 0 {
+			continue
+		}
+		if event == "" {
+			event = e
+		}
+		parts = append(parts, i18n.T("notify.digest."+string(e), strconv.Itoa(counts[e])))
+	}
+	if len(projects) > maxDigestProjects {
+		projects = append(projects[:maxDigestProjects:maxDigestProjects], i18n.T("notify.digest_more", strconv.Itoa(len(projects)-maxDigestProjects)))
+	}
+	separator := i18n.T("notify.digest_separator")
+	return Notification{
+		Event:   event,
+		Title:   notifs[0].Title,
+		Message: i18n.T("notify.digest", strings.Join(parts, separator), strings.Join(projects, separator)),
+		Sound:   sound,
+	}
+}
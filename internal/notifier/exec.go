@@ -0,0 +1,53 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/sho7650/claude-watch-status/internal/config"
+)
+
+func init() {
+	Register("exec", newExecBackend)
+}
+
+// execBackend runs a program for each notification, to ring a tmux bell,
+// switch home-automation lights or run any script. The notification is
+// passed in CWS_EVENT, CWS_PROJECT, CWS_STATE, CWS_TOOL, CWS_SESSION,
+// CWS_TITLE and CWS_MESSAGE.
+type execBackend struct {
+	argv []string
+}
+
+func newExecBackend(cfg config.NotifierConfig) (Backend, error) {
+	if len(cfg.Command) == 0 || cfg.Command[0] == "" {
+		return nil, fmt.Errorf("exec: command is required")
+	}
+	return execBackend{argv: cfg.Command}, nil
+}
+
+func (execBackend) Name() string {
+	return "exec"
+}
+
+func (e execBackend) Send(ctx context.Context, n Notification) error {
+	cmd := exec.CommandContext(ctx, e.argv[0], e.argv[1:]...)
+	cmd.Env = append(os.Environ(),
+		"CWS_EVENT="+string(n.Event),
+		"CWS_PROJECT="+n.Project,
+		"CWS_STATE="+n.State,
+		"CWS_TOOL="+n.Tool,
+		"CWS_SESSION="+n.SessionID,
+		"CWS_TITLE="+n.Title,
+		"CWS_MESSAGE="+n.Message,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/sho7650/claude-watch-status/internal/config"
+)
+
+// Without "on", exec commands run for approvals and completions only, not
+// on every state change
+func TestExecRouteDefaultEvents(t *testing.T) {
+	r, err := newRoute(config.NotifierConfig{Type: "exec", Command: []string{"true"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		event Event
+		want  bool
+	}{
+		{EventWaitingApproval, true},
+		{EventCompleted, true},
+		{EventInterrupted, false},
+		{EventSessionStart, false},
+		{EventSessionEnd, false},
+		{EventUnattendedPermissions, false},
+		{EventDaemonStopped, false},
+	} {
+		if got := r.matches(Notification{Event: tt.event, Project: "p", State: "running: Bash"}); got != tt.want {
+			t.Errorf("exec route matches %s = %v, want %v", tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestExecRouteConfiguredEvents(t *testing.T) {
+	r, err := newRoute(config.NotifierConfig{Type: "exec", Command: []string{"true"}, On: []string{"session_start"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.matches(Notification{Event: EventSessionStart, Project: "p"}) {
+		t.Error("configured event not matched")
+	}
+	if r.matches(Notification{Event: EventCompleted, Project: "p"}) {
+		t.Error("default event matched although \"on\" is configured")
+	}
+
+	// Other backends still receive every event by default
+	r, err = newRoute(config.NotifierConfig{Type: "webhook", URL: "http://127.0.0.1:1/hook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.matches(Notification{Event: EventSessionStart, Project: "p"}) {
+		t.Error("webhook route does not receive all events")
+	}
+}
@@ -1,67 +1,295 @@
 package notifier
 
 import (
-	"runtime"
+	"context"
+	"fmt"
+	"sync"
+	"time"
 
-	"github.com/gen2brain/beeep"
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/i18n"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/state"
 )
 
-// Notifier handles desktop notifications
+// sendTimeout bounds how long a backend may take to deliver a notification
+const sendTimeout = 30 * time.Second
+
+// Notifier turns status changes into notifications and fans them out to
+// the built-in desktop backend and the backends configured in the
+// "notifiers" section
 type Notifier struct {
-	enabled bool
+	mu                 sync.RWMutex // guards enabled, desktop and routes, which a config reload changes
+	enabled            bool
+	interruptedEnabled bool
+	desktop            bool // built-in desktop backend, unless replaced by a configured one
+	routes             []route
+	tierFor            func(projectName string) config.Tier
+	projectEnabled     func(projectName string) bool
+
+	digestMu     sync.Mutex // guards the digest fields below
+	digestWindow time.Duration
+	digestUntil  time.Time      // end of the open digest window
+	pending      []Notification // held until the window ends
 }
 
-// New creates a new Notifier
+// New creates a new Notifier sending desktop notifications
 func New() *Notifier {
 	return &Notifier{
-		enabled: true,
+		enabled:            true,
+		interruptedEnabled: true,
+		desktop:            true,
 	}
 }
 
 // SetEnabled enables or disables notifications
 func (n *Notifier) SetEnabled(enabled bool) {
+	n.mu.Lock()
 	n.enabled = enabled
+	n.mu.Unlock()
 }
 
-// Notify sends a desktop notification
-func (n *Notifier) Notify(title, message string) error {
-	if !n.enabled {
-		return nil
+// SetInterruptedEnabled enables or disables notifications for interruptions
+func (n *Notifier) SetInterruptedEnabled(enabled bool) {
+	n.mu.Lock()
+	n.interruptedEnabled = enabled
+	n.mu.Unlock()
+}
+
+// SetDesktopEnabled enables or disables the built-in desktop backend
+func (n *Notifier) SetDesktopEnabled(enabled bool) {
+	n.mu.Lock()
+	n.desktop = enabled
+	n.mu.Unlock()
+}
+
+// Configure replaces the configured backends. A "desktop" entry replaces
+// the built-in desktop backend, e.g. to limit it to some events.
+// An error names the first invalid entry.
+func (n *Notifier) Configure(cfgs []config.NotifierConfig) error {
+	routes := make([]route, 0, len(cfgs))
+	replacesDesktop := false
+	for i, c := range cfgs {
+		r, err := newRoute(c)
+		if err != nil {
+			return fmt.Errorf("notifiers[%d]: %w", i, err)
+		}
+		routes = append(routes, r)
+		replacesDesktop = replacesDesktop || c.Type == desktopType
+	}
+	n.mu.Lock()
+	n.routes = routes
+	if replacesDesktop {
+		n.desktop = false
 	}
+	n.mu.Unlock()
+	return nil
+}
+
+// Validate checks the "notifiers" config section without keeping the backends
+func Validate(cfgs []config.NotifierConfig) error {
+	return New().Configure(cfgs)
+}
+
+// Len returns the number of configured backends
+func (n *Notifier) Len() int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return len(n.routes)
+}
 
-	// Use beeep for cross-platform notifications
-	return beeep.Notify(title, message, "")
+// DesktopEnabled reports whether desktop notifications are sent, built
+// in or configured
+func (n *Notifier) DesktopEnabled() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.desktop {
+		return true
+	}
+	for _, r := range n.routes {
+		if r.backend.Name() == desktopType {
+			return true
+		}
+	}
+	return false
 }
 
-// NotifyWithSound sends a desktop notification with sound (if supported)
-func (n *Notifier) NotifyWithSound(title, message string) error {
+// backends returns the backends receiving a notification, none while
+// notifications are disabled
+func (n *Notifier) backends(notif Notification) []Backend {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
 	if !n.enabled {
 		return nil
 	}
+	var backends []Backend
+	if n.desktop {
+		backends = append(backends, desktopBackend{})
+	}
+	for _, r := range n.routes {
+		if r.matches(notif) {
+			backends = append(backends, r.backend)
+		}
+	}
+	return backends
+}
+
+// send hands a notification to every matching backend in the background,
+// so a slow webhook or command does not hold up status updates
+func (n *Notifier) send(notif Notification) {
+	for _, b := range n.backends(notif) {
+		go deliver(b, notif)
+	}
+}
+
+// sendAndWait hands a notification to every matching backend and waits
+// for them, for notifications sent right before the daemon exits
+func (n *Notifier) sendAndWait(notif Notification) {
+	var wg sync.WaitGroup
+	for _, b := range n.backends(notif) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			deliver(b, notif)
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver sends a notification through one backend, logging failures
+func deliver(b Backend, notif Notification) {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	if err := b.Send(ctx, notif); err != nil {
+		logging.Logger().Warn("notification failed", "backend", b.Name(), "event", notif.Event, "project", notif.Project, "error", err)
+	}
+}
+
+// SetTierFunc sets the function used to look up a project's tier for alert routing
+func (n *Notifier) SetTierFunc(fn func(projectName string) config.Tier) {
+	n.tierFor = fn
+}
+
+func (n *Notifier) tier(projectName string) config.Tier {
+	if n.tierFor == nil {
+		return config.TierNormal
+	}
+	return n.tierFor(projectName)
+}
+
+// SetProjectEnabledFunc sets the function used to check whether
+// notifications are enabled for a project
+func (n *Notifier) SetProjectEnabledFunc(fn func(projectName string) bool) {
+	n.projectEnabled = fn
+}
+
+// muted reports whether notifications for a project are suppressed,
+// either explicitly or because it is in the background tier
+func (n *Notifier) muted(projectName string) bool {
+	if n.projectEnabled != nil && !n.projectEnabled(projectName) {
+		return true
+	}
+	return n.tier(projectName) == config.TierBackground
+}
+
+// projectNotification describes a project's status change
+func projectNotification(event Event, status state.ProjectStatus, message string, sound bool) Notification {
+	tool := status.ToolName
+	if tool == "" {
+		tool = state.RunningTool(status.State)
+	}
+	return Notification{
+		Event:     event,
+		Project:   status.Name,
+		State:     status.State,
+		Tool:      tool,
+		SessionID: status.SessionID,
+		Title:     "Claude Code",
+		Message:   message,
+		Sound:     sound,
+	}
+}
 
-	// beeep.Alert includes sound on supported platforms
-	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
-		return beeep.Alert(title, message, "")
+// stateMessage is the message of a notification about a project's state,
+// in the configured language
+func stateMessage(project, stateText string) string {
+	return i18n.T("notify.state", project, i18n.State(stateText))
+}
+
+// project sends a notification about a project unless it is muted
+func (n *Notifier) project(event Event, status state.ProjectStatus, message string, sound bool) {
+	if n.muted(status.Name) {
+		return
 	}
-	return beeep.Notify(title, message, "")
+	n.queue(projectNotification(event, status, message, sound))
 }
 
-// NotifyWaitingApproval sends a notification for waiting approval status
-func (n *Notifier) NotifyWaitingApproval(projectName string) error {
-	return n.NotifyWithSound("Claude Code", projectName+": waiting approval")
+// NotifyWaitingApproval sends a notification for waiting approval status.
+// Critical projects get an additional audible beep; background projects
+// are shown on the dashboard only.
+func (n *Notifier) NotifyWaitingApproval(status state.ProjectStatus) {
+	if n.muted(status.Name) {
+		return
+	}
+	message := stateMessage(status.Name, "waiting approval")
+	if status.Request != "" {
+		message = i18n.T("notify.waiting_request", status.Name, status.Request)
+	}
+	notif := projectNotification(EventWaitingApproval, status, message, true)
+	if n.tier(status.Name) == config.TierCritical {
+		notif.Message = "‼️ " + notif.Message
+		notif.Urgent = true
+	}
+	n.queue(notif)
 }
 
 // NotifyCompleted sends a notification for completed status
-func (n *Notifier) NotifyCompleted(projectName string) error {
-	return n.NotifyWithSound("Claude Code", projectName+": completed")
+func (n *Notifier) NotifyCompleted(status state.ProjectStatus) {
+	n.project(EventCompleted, status, stateMessage(status.Name, "completed"), true)
+}
+
+// NotifyInterrupted sends a notification for interrupted status
+func (n *Notifier) NotifyInterrupted(status state.ProjectStatus) {
+	if !n.interruptedEnabled {
+		return
+	}
+	n.project(EventInterrupted, status, stateMessage(status.Name, "interrupted"), false)
 }
 
 // NotifySessionStart sends a notification for session start
-func (n *Notifier) NotifySessionStart(projectName string) error {
-	return n.Notify("Claude Code", projectName+": session started")
+func (n *Notifier) NotifySessionStart(status state.ProjectStatus) {
+	n.project(EventSessionStart, status, stateMessage(status.Name, "session started"), false)
 }
 
 // NotifySessionEnd sends a notification for session end
-func (n *Notifier) NotifySessionEnd(projectName string) error {
-	return n.Notify("Claude Code", projectName+": session ended")
+func (n *Notifier) NotifySessionEnd(status state.ProjectStatus) {
+	n.project(EventSessionEnd, status, stateMessage(status.Name, "session ended"), false)
+}
+
+// NotifyUnattendedPermissions warns that a session runs tools without
+// approval prompts (--dangerously-skip-permissions)
+func (n *Notifier) NotifyUnattendedPermissions(status state.ProjectStatus) {
+	n.project(EventUnattendedPermissions, status, i18n.T("notify.unattended", status.Name), true)
+}
+
+// NotifyDaemonStopped sends a notification that the daemon has stopped,
+// waiting until it is delivered. Per-project muting does not apply,
+// since it concerns the daemon itself.
+func (n *Notifier) NotifyDaemonStopped(reason string) {
+	n.sendAndWait(Notification{
+		Event:   EventDaemonStopped,
+		Title:   appName,
+		Message: i18n.T("notify.daemon_stopped", reason),
+		Sound:   true,
+	})
+}
+
+// NotifyWatcherFailed sends a notification that session logs are no longer watched
+func (n *Notifier) NotifyWatcherFailed() {
+	n.send(Notification{
+		Event:   EventWatcherFailed,
+		Title:   appName,
+		Message: i18n.T("notify.watcher_failed"),
+		Sound:   true,
+	})
 }
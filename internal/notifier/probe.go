@@ -0,0 +1,85 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/config"
+)
+
+// ProbeResult is whether the HTTP endpoint of a configured backend answered
+type ProbeResult struct {
+	Index  int    // position in the notifiers section
+	Type   string // backend type
+	Host   string // host of the endpoint; the full URL may carry a secret
+	Status int    // HTTP status answered, 0 if none
+	Err    error  // why the endpoint is unreachable, nil if it answered
+}
+
+// probeURL returns the endpoint a backend sends notifications to over
+// HTTP, "" for backends that do not
+func probeURL(cfg config.NotifierConfig) string {
+	switch cfg.Type {
+	case "webhook", "slack":
+		return cfg.URL
+	case "ntfy":
+		if cfg.URL == "" {
+			return defaultNtfyServer
+		}
+		return cfg.URL
+	case "pushover":
+		return pushoverAPI
+	default:
+		return ""
+	}
+}
+
+// Probe sends a HEAD request to the endpoint of every configured webhook,
+// slack, ntfy and pushover backend, each within timeout. Any HTTP answer
+// counts as reachable: the request carries no notification, so endpoints
+// may well refuse it. No notification is sent.
+func Probe(ctx context.Context, cfgs []config.NotifierConfig, timeout time.Duration) []ProbeResult {
+	var results []ProbeResult
+	for i, cfg := range cfgs {
+		if endpoint := probeURL(cfg); endpoint != "" {
+			results = append(results, ProbeResult{Index: i, Type: cfg.Type, Host: endpoint})
+		}
+	}
+
+	client := &http.Client{Timeout: timeout}
+	var wg sync.WaitGroup
+	for i := range results {
+		r := &results[i]
+		endpoint := r.Host
+		if u, err := url.Parse(endpoint); err == nil {
+			r.Host = u.Host
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+			if err != nil {
+				r.Err = errors.New("invalid URL")
+				return
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				// *url.Error repeats the URL, secrets included
+				var uerr *url.Error
+				if errors.As(err, &uerr) {
+					err = uerr.Err
+				}
+				r.Err = err
+				return
+			}
+			resp.Body.Close()
+			r.Status = resp.StatusCode
+		}()
+	}
+	wg.Wait()
+	return results
+}
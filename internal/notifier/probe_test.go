@@ -0,0 +1,71 @@
+package notifier
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/config"
+)
+
+func TestProbe(t *testing.T) {
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer srv.Close()
+
+	// A port nothing listens on
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := "http://" + ln.Addr().String() + "/hook"
+	ln.Close()
+
+	results := Probe(context.Background(), []config.NotifierConfig{
+		{Type: "desktop"},
+		{Type: "webhook", URL: srv.URL + "/hook?secret=x"},
+		{Type: "exec", Command: []string{"true"}},
+		{Type: "slack", URL: closed},
+	}, 2*time.Second)
+
+	if len(results) != 2 {
+		t.Fatalf("Probe() = %d results, want 2 (webhook, slack): %+v", len(results), results)
+	}
+	if r := results[0]; r.Index != 1 || r.Err != nil || r.Status != http.StatusMethodNotAllowed {
+		t.Errorf("webhook probe = %+v, want reachable with 405", r)
+	}
+	if r := results[1]; r.Index != 3 || r.Err == nil || r.Status != 0 {
+		t.Errorf("slack probe = %+v, want unreachable", r)
+	} else if strings.Contains(r.Err.Error(), "/hook") {
+		t.Errorf("slack probe error %q repeats the URL", r.Err)
+	}
+	if host := results[0].Host; host != srv.Listener.Addr().String() {
+		t.Errorf("Host = %q, want the host only", host)
+	}
+	if len(methods) != 1 || methods[0] != http.MethodHead {
+		t.Errorf("requests = %v, want one HEAD", methods)
+	}
+}
+
+func TestProbeURL(t *testing.T) {
+	for _, tt := range []struct {
+		cfg  config.NotifierConfig
+		want string
+	}{
+		{config.NotifierConfig{Type: "ntfy", Topic: "t"}, defaultNtfyServer},
+		{config.NotifierConfig{Type: "ntfy", URL: "https://ntfy.example", Topic: "t"}, "https://ntfy.example"},
+		{config.NotifierConfig{Type: "pushover"}, pushoverAPI},
+		{config.NotifierConfig{Type: "sound"}, ""},
+	} {
+		if got := probeURL(tt.cfg); got != tt.want {
+			t.Errorf("probeURL(%s) = %q, want %q", tt.cfg.Type, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sho7650/claude-watch-status/internal/config"
+)
+
+func init() {
+	Register("ntfy", newNtfyBackend)
+	Register("pushover", newPushoverBackend)
+}
+
+// Push notification services, which reach the user's phone away from the
+// desk. Their messages carry the project name and state only: details
+// such as the command waiting approval are not sent to third-party servers.
+const (
+	defaultNtfyServer = "https://ntfy.sh"
+	pushoverAPI       = "https://api.pushover.net/1/messages.json" // https://pushover.net/api
+)
+
+// expand resolves a $VAR or ${VAR} value from the environment, so
+// secrets need not be written into the config file
+func expand(value string) string {
+	if strings.HasPrefix(value, "$") {
+		return os.ExpandEnv(value)
+	}
+	return value
+}
+
+// pushMessage is the message of a push notification. The message of an
+// approval wait may name the command, so the state is sent in its place.
+func pushMessage(n Notification) string {
+	if n.Event == EventWaitingApproval && n.Project != "" {
+		return stateMessage(n.Project, n.State)
+	}
+	return n.Message
+}
+
+// pushPriority reports whether a notification is sent with high
+// priority: approval waits and the daemon's own failures
+func pushPriority(n Notification) bool {
+	switch n.Event {
+	case EventWaitingApproval, EventDaemonStopped, EventWatcherFailed:
+		return true
+	}
+	return false
+}
+
+// ntfyBackend publishes messages to an ntfy topic (https://docs.ntfy.sh/publish/)
+type ntfyBackend struct {
+	url   string // server URL with the topic appended
+	topic string
+	token string // optional access token for protected topics
+}
+
+func newNtfyBackend(cfg config.NotifierConfig) (Backend, error) {
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("ntfy: topic is required")
+	}
+	if strings.ContainsAny(cfg.Topic, "/?#") {
+		return nil, fmt.Errorf("ntfy: invalid topic %q", cfg.Topic)
+	}
+	server := cfg.URL
+	if server == "" {
+		server = defaultNtfyServer
+	}
+	if err := parseURL("ntfy", server); err != nil {
+		return nil, err
+	}
+	return &ntfyBackend{
+		url:   strings.TrimSuffix(server, "/") + "/" + url.PathEscape(cfg.Topic),
+		topic: cfg.Topic,
+		token: expand(cfg.Token),
+	}, nil
+}
+
+func (b *ntfyBackend) Name() string {
+	return "ntfy:" + b.topic
+}
+
+func (b *ntfyBackend) Send(ctx context.Context, n Notification) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, strings.NewReader(pushMessage(n)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", n.Title)
+	req.Header.Set("Tags", ntfyTag(n.Event))
+	switch {
+	case n.Urgent:
+		req.Header.Set("Priority", "urgent")
+	case pushPriority(n):
+		req.Header.Set("Priority", "high")
+	}
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
+
+// ntfyTag returns the emoji short code ntfy shows for an event
+func ntfyTag(event Event) string {
+	switch event {
+	case EventWaitingApproval, EventUnattendedPermissions:
+		return "warning"
+	case EventCompleted:
+		return "white_check_mark"
+	case EventInterrupted:
+		return "stop_sign"
+	case EventDaemonStopped, EventWatcherFailed:
+		return "x"
+	default:
+		return "robot"
+	}
+}
+
+// pushoverBackend sends messages to a Pushover user or group
+type pushoverBackend struct {
+	token string // application token
+	user  string // user or group key
+}
+
+func newPushoverBackend(cfg config.NotifierConfig) (Backend, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("pushover: token is required")
+	}
+	if cfg.User == "" {
+		return nil, fmt.Errorf("pushover: user is required")
+	}
+	return &pushoverBackend{token: expand(cfg.Token), user: expand(cfg.User)}, nil
+}
+
+func (b *pushoverBackend) Name() string {
+	return "pushover"
+}
+
+func (b *pushoverBackend) Send(ctx context.Context, n Notification) error {
+	// Emergency priority (2) requires acknowledging on the phone and
+	// repeats until then; high priority bypasses quiet hours instead
+	priority := 0
+	if n.Urgent || pushPriority(n) {
+		priority = 1
+	}
+	form := url.Values{
+		"token":    {b.token},
+		"user":     {b.user},
+		"title":    {n.Title},
+		"message":  {pushMessage(n)},
+		"priority": {strconv.Itoa(priority)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pushoverAPI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
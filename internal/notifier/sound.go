@@ -0,0 +1,136 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/gen2brain/beeep"
+	"github.com/sho7650/claude-watch-status/internal/config"
+)
+
+func init() {
+	Register("sound", newSoundBackend)
+}
+
+// soundBackend plays a sound file, or beeps without one. Events with a
+// sound of their own in "sounds" play that instead.
+type soundBackend struct {
+	file   string
+	sounds map[Event]string
+}
+
+func newSoundBackend(cfg config.NotifierConfig) (Backend, error) {
+	sounds, err := parseSounds(cfg.Sounds)
+	if err != nil {
+		return nil, err
+	}
+	return soundBackend{file: cfg.File, sounds: sounds}, nil
+}
+
+func (soundBackend) Name() string {
+	return "sound"
+}
+
+func (s soundBackend) Send(ctx context.Context, n Notification) error {
+	sound := s.sounds[n.Event]
+	if sound == "" {
+		sound = s.file
+	}
+	if sound == "" {
+		return beeep.Beep(beeep.DefaultFreq, beeep.DefaultDuration)
+	}
+	return playSound(ctx, sound)
+}
+
+// parseSounds checks the events of a "sounds" setting
+func parseSounds(sounds map[string]string) (map[Event]string, error) {
+	if len(sounds) == 0 {
+		return nil, nil
+	}
+	parsed := make(map[Event]string, len(sounds))
+	for name, sound := range sounds {
+		e, ok := ParseEvent(name)
+		if !ok {
+			return nil, fmt.Errorf("sounds: %w", unknownEventError(name))
+		}
+		if sound == "" {
+			return nil, fmt.Errorf("sounds: no sound for %s", name)
+		}
+		parsed[e] = sound
+	}
+	return parsed, nil
+}
+
+// playSound plays a sound file, or a system sound given by name: "Glass"
+// on macOS, "complete" or another freedesktop sound on Linux, "Asterisk"
+// or a file in %WINDIR%\Media on Windows. It returns when the sound ends.
+func playSound(ctx context.Context, sound string) error {
+	named := !strings.ContainsAny(sound, `/\`) && filepath.Ext(sound) == ""
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		if named {
+			sound = macSound(sound)
+		}
+		cmd = exec.CommandContext(ctx, "afplay", sound)
+	case "windows":
+		if named && windowsSystemSounds[sound] {
+			// Play is asynchronous; wait so the sound is not cut off
+			cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command",
+				"[System.Media.SystemSounds]::"+sound+".Play(); Start-Sleep -Milliseconds 1000")
+			break
+		}
+		if named {
+			sound = filepath.Join(os.Getenv("WINDIR"), "Media", sound+".wav")
+		}
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command",
+			"(New-Object Media.SoundPlayer $args[0]).PlaySync()", sound)
+	default:
+		if named {
+			if _, err := exec.LookPath("canberra-gtk-play"); err == nil {
+				cmd = exec.CommandContext(ctx, "canberra-gtk-play", "-i", sound)
+				break
+			}
+			sound = filepath.Join("/usr/share/sounds/freedesktop/stereo", sound+".oga")
+		}
+		player := "paplay"
+		if _, err := exec.LookPath(player); err != nil {
+			player = "aplay"
+		}
+		cmd = exec.CommandContext(ctx, player, sound)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return err
+	}
+	return nil
+}
+
+// macSound returns the file of a named macOS sound, preferring the
+// user's own sounds
+func macSound(name string) string {
+	if home, err := os.UserHomeDir(); err == nil {
+		file := filepath.Join(home, "Library", "Sounds", name+".aiff")
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+	}
+	return filepath.Join("/System/Library/Sounds", name+".aiff")
+}
+
+// windowsSystemSounds are the sounds of System.Media.SystemSounds
+var windowsSystemSounds = map[string]bool{
+	"Asterisk":    true,
+	"Beep":        true,
+	"Exclamation": true,
+	"Hand":        true,
+	"Question":    true,
+}
@@ -0,0 +1,135 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/config"
+)
+
+func init() {
+	Register("webhook", newWebhookBackend)
+	Register("slack", newSlackBackend)
+}
+
+// WebhookPayload is the JSON body a webhook backend posts
+type WebhookPayload struct {
+	Event     Event     `json:"event"`
+	Project   string    `json:"project,omitempty"`
+	State     string    `json:"state,omitempty"`
+	Tool      string    `json:"tool,omitempty"`
+	SessionID string    `json:"session_id,omitempty"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Urgent    bool      `json:"urgent,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// webhookBackend posts notifications as JSON to a URL
+type webhookBackend struct {
+	name    string
+	url     string
+	headers map[string]string
+	body    func(n Notification) any
+}
+
+// parseURL checks that a backend URL is an absolute http(s) URL
+func parseURL(typ, raw string) error {
+	if raw == "" {
+		return fmt.Errorf("%s: url is required", typ)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s: invalid url %q", typ, raw)
+	}
+	return nil
+}
+
+func newWebhookBackend(cfg config.NotifierConfig) (Backend, error) {
+	if err := parseURL("webhook", cfg.URL); err != nil {
+		return nil, err
+	}
+	return &webhookBackend{
+		name:    "webhook",
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		body: func(n Notification) any {
+			return WebhookPayload{
+				Event:     n.Event,
+				Project:   n.Project,
+				State:     n.State,
+				Tool:      n.Tool,
+				SessionID: n.SessionID,
+				Title:     n.Title,
+				Message:   n.Message,
+				Urgent:    n.Urgent,
+				Time:      time.Now(),
+			}
+		},
+	}, nil
+}
+
+// newSlackBackend posts to a Slack incoming webhook
+// (https://api.slack.com/messaging/webhooks)
+func newSlackBackend(cfg config.NotifierConfig) (Backend, error) {
+	if err := parseURL("slack", cfg.URL); err != nil {
+		return nil, err
+	}
+	return &webhookBackend{
+		name: "slack",
+		url:  cfg.URL,
+		body: func(n Notification) any {
+			text := "*" + n.Title + "*\n" + n.Message
+			if n.Urgent {
+				text = "<!channel> " + text
+			}
+			return map[string]string{"text": text}
+		},
+	}, nil
+}
+
+func (w *webhookBackend) Name() string {
+	return w.name
+}
+
+func (w *webhookBackend) Send(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(w.body(n))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
+
+// checkResponse turns a non-2xx response into an error including the
+// start of its body
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	var buf [256]byte
+	m, _ := resp.Body.Read(buf[:])
+	if msg := strings.TrimSpace(string(buf[:m])); msg != "" {
+		return fmt.Errorf("%s: %s", resp.Status, msg)
+	}
+	return fmt.Errorf("%s", resp.Status)
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/sho7650/claude-watch-status/internal/redact"
+)
+
+// MaxDetailLength bounds tool details, in characters
+const MaxDetailLength = 80
+
+// toolDetailKeys lists the tool_input field that describes what each tool
+// is working on
+var toolDetailKeys = map[string]string{
+	"Bash":         "command",
+	"Read":         "file_path",
+	"Write":        "file_path",
+	"Edit":         "file_path",
+	"MultiEdit":    "file_path",
+	"NotebookEdit": "notebook_path",
+	"WebFetch":     "url",
+	"WebSearch":    "query",
+	"Grep":         "pattern",
+	"Glob":         "pattern",
+	"Task":         "description",
+}
+
+// pathDetailTools are tools whose detail is a file path; long paths keep
+// their end (the file name) instead of their start
+var pathDetailTools = map[string]bool{
+	"Read": true, "Write": true, "Edit": true, "MultiEdit": true, "NotebookEdit": true,
+}
+
+// ToolDetail returns what a tool call is working on (file path, command,
+// URL, ...), shortened and with secrets redacted. Returns "" for tools
+// without a known detail.
+func ToolDetail(toolName string, input map[string]interface{}) string {
+	key, ok := toolDetailKeys[toolName]
+	if !ok {
+		return ""
+	}
+	value, _ := input[key].(string)
+	if value == "" {
+		return ""
+	}
+
+	value = redact.String(value)
+	if pathDetailTools[toolName] {
+		return truncateLeft(value, MaxDetailLength)
+	}
+	// Multi-line commands show their first line
+	if line, _, more := strings.Cut(strings.TrimSpace(value), "\n"); more {
+		value = line + " …"
+	}
+	return truncateRight(value, MaxDetailLength)
+}
+
+// toolDetailJSON returns the detail of a tool call from its raw tool_use input
+func toolDetailJSON(toolName string, raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var input map[string]interface{}
+	if err := json.Unmarshal(raw, &input); err != nil {
+		return ""
+	}
+	return ToolDetail(toolName, input)
+}
+
+// truncateRight shortens s to max characters, keeping its start
+func truncateRight(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "…"
+}
+
+// truncateLeft shortens s to max characters, keeping its end
+func truncateLeft(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return "…" + string(r[len(r)-max+1:])
+}
@@ -1,7 +1,12 @@
 package parser
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 )
@@ -105,21 +110,86 @@ type Entry struct {
 	UUID       string    `json:"uuid"`
 	ParentUUID string    `json:"parentUuid,omitempty"`
 	Timestamp  string    `json:"timestamp"`
+	CWD        string    `json:"cwd,omitempty"`
+
+	// Session environment, on user entries of newer Claude Code versions
+	PermissionMode string `json:"permissionMode,omitempty"`
+
+	// API request of an assistant entry; entries of the same message share it
+	RequestID string `json:"requestId,omitempty"`
+	// Duration of a turn, on system entries of newer Claude Code versions
+	DurationMs int64 `json:"durationMs,omitempty"`
+
+	// Subagent entries written to the parent session log
+	IsSidechain bool   `json:"isSidechain,omitempty"`
+	AgentID     string `json:"agentId,omitempty"`
 }
 
 // Message represents the message content
 type Message struct {
+	ID         string    `json:"id,omitempty"`    // assistant messages span several entries
+	Model      string    `json:"model,omitempty"` // assistant messages
 	StopReason *string   `json:"stop_reason"`
 	Content    []Content `json:"content"`
+	Usage      *Usage    `json:"usage,omitempty"` // assistant messages
+}
+
+// Usage is the token usage of an API request. Each entry of a message
+// repeats it, so sum it once per message ID.
+type Usage struct {
+	InputTokens              int    `json:"input_tokens"`
+	OutputTokens             int    `json:"output_tokens"`
+	CacheCreationInputTokens int    `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int    `json:"cache_read_input_tokens"`
+	ServiceTier              string `json:"service_tier,omitempty"`
+}
+
+// TotalInput returns the input tokens of a request, including cache
+// reads and writes
+func (u Usage) TotalInput() int {
+	return u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
 }
 
 // Content represents message content item
 type Content struct {
-	Type      string `json:"type"`
-	ID        string `json:"id,omitempty"`          // tool_use id
-	Name      string `json:"name,omitempty"`        // for tool_use
-	Text      string `json:"text,omitempty"`        // for text
-	ToolUseID string `json:"tool_use_id,omitempty"` // for tool_result
+	Type      string          `json:"type"`
+	ID        string          `json:"id,omitempty"`          // tool_use id
+	Name      string          `json:"name,omitempty"`        // for tool_use
+	Text      string          `json:"text,omitempty"`        // for text
+	ToolUseID string          `json:"tool_use_id,omitempty"` // for tool_result
+	IsError   bool            `json:"is_error,omitempty"`    // for tool_result
+	Content   json.RawMessage `json:"content,omitempty"`     // for tool_result (string or array)
+	Input     json.RawMessage `json:"input,omitempty"`       // for tool_use
+}
+
+// ResultText returns the text of a tool_result, which may be encoded
+// either as a plain string or as an array of text items
+func (c Content) ResultText() string {
+	if len(c.Content) == 0 {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(c.Content, &text); err == nil {
+		return text
+	}
+
+	var items []Content
+	if err := json.Unmarshal(c.Content, &items); err != nil {
+		return ""
+	}
+	var parts []string
+	for _, item := range items {
+		if item.Text != "" {
+			parts = append(parts, item.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// ToolDetail returns what a tool_use works on; see the ToolDetail function
+func (c Content) ToolDetail() string {
+	return toolDetailJSON(c.Name, c.Input)
 }
 
 // State represents the parsed state from a JSONL entry
@@ -127,6 +197,7 @@ type State struct {
 	Icon        string
 	Text        string
 	ToolName    string
+	Detail      string // what the tool is working on, see ToolDetail
 	Skip        bool
 	IsEstimated bool // true if state detection is based on timeout heuristics
 }
@@ -145,6 +216,229 @@ func ParseEntry(line string) (*Entry, error) {
 	return &entry, nil
 }
 
+// ReadLastEntry reads the last line of a JSONL file and parses it
+func ReadLastEntry(filePath string) (*Entry, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lastLine string
+	scanner := bufio.NewScanner(file)
+	// Use a larger buffer for potentially long lines
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			lastLine = line
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return ParseEntry(lastLine)
+}
+
+// ReadLastEntries reads the last n non-empty lines of a JSONL file and
+// parses them, oldest first. Lines that fail to parse are skipped.
+func ReadLastEntries(filePath string, n int) ([]*Entry, error) {
+	lines, err := ReadLastLines(filePath, n)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(lines))
+	for _, line := range lines {
+		entry, err := ParseEntry(line)
+		if err != nil || entry == nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// ReadLastLines reads the last n non-empty lines of a file, oldest first
+func ReadLastLines(filePath string, n int) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := make([]string, 0, n)
+	scanner := bufio.NewScanner(file)
+	// Use a larger buffer for potentially long lines
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		if len(lines) == n {
+			lines = lines[1:]
+		}
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// ReadEntriesFrom parses the complete lines of a JSONL file from offset
+// on, oldest first, and returns the offset after the last complete line
+// to continue from. An offset inside a line starts at the next one; a
+// file shorter than offset was replaced and is read from the start,
+// reported by restarted. Lines that fail to parse are skipped.
+func ReadEntriesFrom(filePath string, offset int64) (entries []*Entry, next int64, restarted bool, err error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, offset, false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, offset, false, err
+	}
+	if info.Size() < offset {
+		offset, restarted = 0, true
+	}
+	// Read from the byte before offset to tell whether a line starts there
+	start := max(offset-1, 0)
+	if _, err := file.Seek(start, io.SeekStart); err != nil {
+		return nil, offset, restarted, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, offset, restarted, err
+	}
+	if offset > 0 {
+		skip := bytes.IndexByte(data, '\n')
+		if skip < 0 {
+			return nil, offset, restarted, nil
+		}
+		data, start = data[skip+1:], start+int64(skip)+1
+	}
+
+	// A line still being written is left for the next read
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil, start, restarted, nil
+	}
+	for _, line := range strings.Split(string(data[:end]), "\n") {
+		entry, err := ParseEntry(line)
+		if err != nil || entry == nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, start + int64(end) + 1, restarted, nil
+}
+
+// ReadFirstLine reads the first non-empty line of a file
+func ReadFirstLine(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			return line, nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// maxCWDLines bounds the lines ReadCWD reads; the working directory is
+// recorded on the first user entry
+const maxCWDLines = 20
+
+// ReadCWD returns the working directory recorded near the start of a
+// session log, or "" if none is found
+func ReadCWD(filePath string) string {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	for i := 0; i < maxCWDLines && scanner.Scan(); i++ {
+		var entry struct {
+			CWD string `json:"cwd"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil && entry.CWD != "" {
+			return entry.CWD
+		}
+	}
+	return ""
+}
+
+// SubagentLogID returns the agent ID of a subagent session log
+// ({session}/subagents/agent-{id}.jsonl), or false for other files
+func SubagentLogID(filePath string) (string, bool) {
+	if filepath.Base(filepath.Dir(filePath)) != "subagents" {
+		return "", false
+	}
+	name := strings.TrimSuffix(filepath.Base(filePath), ".jsonl")
+	return strings.TrimPrefix(name, "agent-"), true
+}
+
+// ReadFirstPrompt returns the text of the first user message in a session
+// log, e.g. the task prompt of a subagent. The content may be a plain
+// string, which Entry does not decode.
+func ReadFirstPrompt(filePath string) string {
+	line, err := ReadFirstLine(filePath)
+	if err != nil {
+		return ""
+	}
+
+	var entry struct {
+		Type    EntryType `json:"type"`
+		Message struct {
+			Content json.RawMessage `json:"content"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Type != EntryTypeUser {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(entry.Message.Content, &text); err == nil {
+		return text
+	}
+	var items []Content
+	if err := json.Unmarshal(entry.Message.Content, &items); err != nil {
+		return ""
+	}
+	var parts []string
+	for _, item := range items {
+		if item.Type == string(ContentTypeText) {
+			parts = append(parts, item.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
 // ParseState determines the state from a JSONL entry
 func ParseState(entry *Entry) State {
 	if entry == nil {
@@ -171,6 +465,10 @@ func parseUserState(entry *Entry) State {
 		return State{Icon: "👤", Text: "user input"}
 	}
 
+	if IsInterrupted(entry) {
+		return State{Icon: "🛑", Text: "interrupted"}
+	}
+
 	contentType := entry.Message.Content[0].Type
 	if contentType == string(ContentTypeToolResult) {
 		return State{Icon: "⏳", Text: "processing"}
@@ -195,7 +493,7 @@ func parseAssistantState(entry *Entry) State {
 
 	case StopReasonToolUse:
 		toolName := getLastToolName(entry.Message.Content)
-		return State{Icon: "🔧", Text: "running: " + toolName, ToolName: toolName}
+		return State{Icon: "🔧", Text: "running: " + toolName, ToolName: toolName, Detail: getLastToolDetail(entry.Message.Content)}
 
 	case StopReasonEndTurn:
 		return State{Icon: "✅", Text: "completed"}
@@ -232,6 +530,15 @@ func getLastToolName(content []Content) string {
 	return toolName
 }
 
+// getLastToolDetail returns the detail of the last tool_use in content
+func getLastToolDetail(content []Content) string {
+	for i := len(content) - 1; i >= 0; i-- {
+		if content[i].Type == string(ContentTypeToolUse) && content[i].Name != "" {
+			return toolDetailJSON(content[i].Name, content[i].Input)
+		}
+	}
+	return ""
+}
 
 // GetToolUseIDs returns all tool_use IDs from content
 func GetToolUseIDs(content []Content) []string {
@@ -261,7 +568,7 @@ func HasPendingToolUse(entry *Entry) bool {
 		return false
 	}
 	stopReason := getStopReason(entry.Message.StopReason)
-	return stopReason == StopReasonToolUse || 
+	return stopReason == StopReasonToolUse ||
 		(stopReason == StopReasonNull && getContentType(entry.Message.Content) == ContentTypeToolUse)
 }
 
@@ -296,3 +603,42 @@ func IsIdleCompleted(entry *Entry) bool {
 	// text with stop_reason null means likely completed (estimated)
 	return stopReason == StopReasonNull && contentType == ContentTypeText
 }
+
+// InterruptMarker is the text Claude Code records when the user aborts a request
+const InterruptMarker = "[Request interrupted by user"
+
+// IsInterrupted checks if the entry records a user interruption (e.g. Esc pressed),
+// either as an interrupt marker in user text or as an aborted tool call
+func IsInterrupted(entry *Entry) bool {
+	if entry == nil || entry.Type != EntryTypeUser || entry.Message == nil {
+		return false
+	}
+
+	for _, c := range entry.Message.Content {
+		switch c.Type {
+		case string(ContentTypeText):
+			if strings.HasPrefix(c.Text, InterruptMarker) {
+				return true
+			}
+		case string(ContentTypeToolResult):
+			if c.IsError && strings.Contains(c.ResultText(), InterruptMarker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsOrphanedToolUse checks if a tool_use was abandoned: the previous
+// assistant entry requested a tool, but the next user entry contains
+// text instead of the matching tool_result
+func IsOrphanedToolUse(prev, last *Entry) bool {
+	if !HasPendingToolUse(prev) {
+		return false
+	}
+	if last == nil || last.Type != EntryTypeUser || last.Message == nil || len(last.Message.Content) == 0 {
+		return false
+	}
+	return len(GetToolResultIDs(last.Message.Content)) == 0 &&
+		getContentType(last.Message.Content) == ContentTypeText
+}
@@ -0,0 +1,12 @@
+// Package power reports whether the machine is running on battery
+package power
+
+import "errors"
+
+// ErrUnsupported is returned on platforms without power source detection
+var ErrUnsupported = errors.New("power source detection not supported on this platform")
+
+// OnBattery reports whether the machine is currently running on battery power
+func OnBattery() (bool, error) {
+	return onBattery()
+}
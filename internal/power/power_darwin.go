@@ -0,0 +1,17 @@
+//go:build darwin
+
+package power
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// onBattery asks pmset for the current power source
+func onBattery() (bool, error) {
+	out, err := exec.Command("pmset", "-g", "batt").Output()
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(string(out), "'Battery Power'"), nil
+}
@@ -0,0 +1,44 @@
+//go:build linux
+
+package power
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const powerSupplyDir = "/sys/class/power_supply"
+
+// onBattery checks sysfs: any online mains adapter means AC power,
+// otherwise a discharging battery means battery power
+func onBattery() (bool, error) {
+	entries, err := os.ReadDir(powerSupplyDir)
+	if err != nil {
+		return false, err
+	}
+
+	discharging := false
+	for _, entry := range entries {
+		dir := filepath.Join(powerSupplyDir, entry.Name())
+		switch readAttr(dir, "type") {
+		case "Mains", "USB":
+			if readAttr(dir, "online") == "1" {
+				return false, nil
+			}
+		case "Battery":
+			if readAttr(dir, "status") == "Discharging" {
+				discharging = true
+			}
+		}
+	}
+	return discharging, nil
+}
+
+func readAttr(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
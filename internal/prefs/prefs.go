@@ -0,0 +1,188 @@
+// Package prefs keeps settings changed at runtime, such as mutes set
+// through the API, in a small persistent key-value store. They are kept
+// apart from the config file, which only the user edits.
+package prefs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// migrations upgrade the values of older files: migrations[i] turns a
+// version i+1 file into version i+2. Append one whenever the stored form
+// of a value changes.
+var migrations = []func(values map[string]json.RawMessage) error{}
+
+// Version is the format version written by this build
+var Version = len(migrations) + 1
+
+// file is the on-disk format of the store
+type file struct {
+	Version int                        `json:"version"`
+	Values  map[string]json.RawMessage `json:"values"`
+}
+
+// Store is a persistent key-value store of JSON values. Every change is
+// written to disk, synced and atomically renamed into place, so a crash
+// leaves either the old or the new file.
+type Store struct {
+	mu     sync.Mutex
+	path   string
+	values map[string]json.RawMessage
+}
+
+// Open loads the store at path; a missing file is an empty store. Files
+// of an older version are migrated and rewritten. Files written by a
+// newer build are refused rather than overwritten.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, values: make(map[string]json.RawMessage)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid prefs file %s: %w", path, err)
+	}
+	if f.Version > Version {
+		return nil, fmt.Errorf("prefs file %s has version %d, newer than supported (%d)", path, f.Version, Version)
+	}
+	if f.Values != nil {
+		s.values = f.Values
+	}
+	if f.Version < 1 {
+		f.Version = 1
+	}
+	if f.Version == Version {
+		return s, nil
+	}
+
+	for v := f.Version; v < Version; v++ {
+		if err := migrations[v-1](s.values); err != nil {
+			return nil, fmt.Errorf("migrating prefs file %s to version %d: %w", path, v+1, err)
+		}
+	}
+	if err := s.write(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Move moves a store file from an earlier location to path, unless a
+// file is already there. A missing old file is not an error.
+func Move(oldPath, path string) error {
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return err
+	}
+	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if err := os.Rename(oldPath, path); err == nil {
+		return nil
+	}
+
+	// Another file system: copy through a store, written atomically
+	s, err := Open(oldPath)
+	if err != nil {
+		return err
+	}
+	s.path = path
+	if err := s.write(); err != nil {
+		return err
+	}
+	return os.Remove(oldPath)
+}
+
+// Path returns the store file
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Get decodes the value of key into v, reporting whether key is set
+func (s *Store) Get(key string, v any) (bool, error) {
+	s.mu.Lock()
+	raw, ok := s.values[key]
+	s.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return true, fmt.Errorf("invalid prefs value %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// Set stores v under key and writes the store
+func (s *Store) Set(key string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = raw
+	return s.write()
+}
+
+// Delete removes key and writes the store
+func (s *Store) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[key]; !ok {
+		return nil
+	}
+	delete(s.values, key)
+	return s.write()
+}
+
+// write replaces the store file. Caller must hold s.mu or own s.
+func (s *Store) write() error {
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(file{Version: Version, Values: s.values}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".prefs-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	// Sync the directory so the rename survives a crash; not supported
+	// on every platform, and the file itself is already safe
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
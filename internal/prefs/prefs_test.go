@@ -0,0 +1,51 @@
+package prefs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMove(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "data", "prefs.json")
+	path := filepath.Join(dir, "cws", "prefs.json")
+
+	// Nothing to move
+	if err := Move(oldPath, path); err != nil {
+		t.Fatalf("Move() without an old file = %v", err)
+	}
+
+	old, err := Open(oldPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := old.Set("mutes", []string{"api"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Move(oldPath, path); err != nil {
+		t.Fatalf("Move() = %v", err)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("old file still there: %v", err)
+	}
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mutes []string
+	if ok, err := s.Get("mutes", &mutes); !ok || err != nil || len(mutes) != 1 || mutes[0] != "api" {
+		t.Errorf("moved mutes = %v (found %v, %v), want [api]", mutes, ok, err)
+	}
+
+	// A file at the new location wins
+	if err := os.WriteFile(oldPath, []byte(`{"version":1,"values":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Move(oldPath, path); err != nil {
+		t.Fatalf("Move() with both files = %v", err)
+	}
+	if _, err := os.Stat(oldPath); err != nil {
+		t.Errorf("old file removed although the new one exists: %v", err)
+	}
+}
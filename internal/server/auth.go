@@ -0,0 +1,116 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/internal/hooks"
+)
+
+// requireHookToken rejects hook events without a valid shared-secret token.
+// If no token is configured, all events are accepted.
+func (s *Server) requireHookToken(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if s.hookToken == "" {
+			return next(c)
+		}
+		if !hooks.TokensEqual(c.Request().Header.Get(hooks.TokenHeader), s.hookToken) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		}
+		return next(c)
+	}
+}
+
+// requireAPIToken rejects read API requests without a valid bearer token.
+// The token may also be passed as a "token" query parameter, since the
+// browser EventSource API cannot set request headers.
+// If no token is configured, all requests are accepted.
+func (s *Server) requireAPIToken(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if s.apiToken == "" {
+			return next(c)
+		}
+		token := c.QueryParam("token")
+		if auth := c.Request().Header.Get(echo.HeaderAuthorization); strings.HasPrefix(auth, "Bearer ") {
+			token = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if !hooks.TokensEqual(token, s.apiToken) {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		}
+		return next(c)
+	}
+}
+
+// requireTrusted refuses requests that read session transcripts or change
+// the daemon unless the API token authenticates their clients, or they
+// come from a local user: the daemon listens on loopback only, or on all
+// interfaces and the connection comes from loopback. Without a token the
+// Host must be a loopback address too, so pages of other sites cannot
+// reach the daemon through DNS rebinding.
+func (s *Server) requireTrusted(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if s.apiToken != "" {
+			return next(c)
+		}
+		if !isLoopback(s.bindAddr) && !(isUnspecified(s.bindAddr) && loopbackPeer(c.Request())) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "requires an API token (serve --api-token) for clients beyond loopback",
+			})
+		}
+		host := c.Request().Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if !isLoopback(host) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "requires a loopback host name"})
+		}
+		return next(c)
+	}
+}
+
+// isUnspecified reports whether a bind address listens on all interfaces
+func isUnspecified(bind string) bool {
+	if bind == "" {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(bind, "[]"))
+	return ip != nil && ip.IsUnspecified()
+}
+
+// loopbackPeer reports whether the connection of req comes from loopback.
+// The peer address is used, not forwarding headers a client may set.
+func loopbackPeer(req *http.Request) bool {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	return err == nil && isLoopback(host)
+}
+
+// isLoopback reports whether host is a loopback address or localhost
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// sameOrigin refuses cross-origin browser requests, so pages of other
+// sites can neither read the API nor change the daemon with the access
+// of the user's browser to it. Requests without an Origin header, from
+// the CLI, hooks and same-origin GETs, are not affected.
+func sameOrigin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		origin := c.Request().Header.Get(echo.HeaderOrigin)
+		if origin == "" {
+			return next(c)
+		}
+		u, err := url.Parse(origin)
+		if err != nil || !strings.EqualFold(u.Host, c.Request().Host) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "cross-origin request refused"})
+		}
+		return next(c)
+	}
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sho7650/claude-watch-status/internal/engine"
+)
+
+// newTestServer returns a server that is not started, for requests
+// served directly by its router
+func newTestServer(t *testing.T, opts ...Option) *Server {
+	t.Helper()
+	eng := engine.New(t.TempDir())
+	t.Cleanup(func() { eng.Stop() })
+	return New(0, eng, opts...)
+}
+
+func TestRequireTrusted(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		remote string
+		host   string
+		origin string
+		want   int
+	}{
+		{"default bind, local client", nil, "127.0.0.1:50000", "127.0.0.1:10087", "", http.StatusOK},
+		{"default bind, local client by name", nil, "[::1]:50000", "localhost:10087", "", http.StatusOK},
+		{"default bind, Web UI", nil, "127.0.0.1:50000", "localhost:10087", "http://localhost:10087", http.StatusOK},
+		{"default bind, remote client", nil, "192.168.1.20:50000", "192.168.1.10:10087", "", http.StatusForbidden},
+		{"default bind, DNS rebinding", nil, "127.0.0.1:50000", "evil.example:10087", "", http.StatusForbidden},
+		{"loopback bind", []Option{WithBindAddress("127.0.0.1")}, "127.0.0.1:50000", "127.0.0.1:10087", "", http.StatusOK},
+		{"LAN bind, local client", []Option{WithBindAddress("192.168.1.10")}, "192.168.1.10:50000", "192.168.1.10:10087", "", http.StatusForbidden},
+		{"API token, remote client", []Option{WithAPIToken("secret")}, "192.168.1.20:50000", "192.168.1.10:10087", "", http.StatusOK},
+		{"cross-origin page", nil, "127.0.0.1:50000", "127.0.0.1:10087", "http://evil.example", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, tt.opts...)
+			req := httptest.NewRequest(http.MethodPost, "/api/notifications/pause", nil)
+			req.RemoteAddr = tt.remote
+			req.Host = tt.host
+			req.Header.Set("X-Forwarded-For", "127.0.0.1") // never trusted
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if s.apiToken != "" {
+				req.Header.Set("Authorization", "Bearer "+s.apiToken)
+			}
+			rec := httptest.NewRecorder()
+			s.echo.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("POST /api/notifications/pause = %d %s, want %d", rec.Code, rec.Body, tt.want)
+			}
+		})
+	}
+}
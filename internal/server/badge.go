@@ -0,0 +1,113 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// Badge colors, matching the shields.io palette
+const (
+	badgeColorBlue   = "#007ec6"
+	badgeColorYellow = "#dfb317"
+	badgeColorGreen  = "#4c1"
+	badgeColorGrey   = "#9f9f9f"
+	badgeLabel       = "claude"
+)
+
+// ActivitySummary counts projects by current state, plus today's completions
+type ActivitySummary struct {
+	Running        int `json:"running"`
+	Waiting        int `json:"waiting"`
+	CompletedToday int `json:"completed_today"`
+}
+
+// ShieldsEndpoint is the shields.io "endpoint" badge schema
+type ShieldsEndpoint struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+	CacheSeconds  int    `json:"cacheSeconds,omitempty"`
+}
+
+// activitySummary aggregates the current project states and today's statistics
+func (s *Server) activitySummary() ActivitySummary {
+	var summary ActivitySummary
+	for _, status := range s.manager.GetVisible() {
+		switch state.PhaseOf(status.State) {
+		case state.PhaseWorking:
+			summary.Running++
+		case state.PhaseWaiting:
+			summary.Waiting++
+		}
+	}
+	for _, p := range s.stats.Snapshot().Projects {
+		summary.CompletedToday += p.Completions
+	}
+	return summary
+}
+
+// message returns the badge text, e.g. "2 running | 1 waiting | 5 done"
+func (a ActivitySummary) message() string {
+	return fmt.Sprintf("%d running | %d waiting | %d done", a.Running, a.Waiting, a.CompletedToday)
+}
+
+// color returns the badge color: waiting wins over running over done
+func (a ActivitySummary) color() string {
+	switch {
+	case a.Waiting > 0:
+		return badgeColorYellow
+	case a.Running > 0:
+		return badgeColorBlue
+	case a.CompletedToday > 0:
+		return badgeColorGreen
+	default:
+		return badgeColorGrey
+	}
+}
+
+// handleSummaryBadgeSVG returns an SVG badge with aggregate activity counts
+func (s *Server) handleSummaryBadgeSVG(c echo.Context) error {
+	summary := s.activitySummary()
+	c.Response().Header().Set("Cache-Control", "no-cache, max-age=0")
+	return c.Blob(http.StatusOK, "image/svg+xml", []byte(renderBadge(badgeLabel, summary.message(), summary.color())))
+}
+
+// handleSummaryBadgeJSON returns aggregate activity counts in the shields.io
+// endpoint schema, for https://img.shields.io/endpoint?url=...
+func (s *Server) handleSummaryBadgeJSON(c echo.Context) error {
+	summary := s.activitySummary()
+	return c.JSON(http.StatusOK, ShieldsEndpoint{
+		SchemaVersion: 1,
+		Label:         badgeLabel,
+		Message:       summary.message(),
+		Color:         strings.TrimPrefix(summary.color(), "#"),
+		CacheSeconds:  300,
+	})
+}
+
+// renderBadge renders a flat two-part badge in the shields.io style.
+// Text widths are estimated, as there are no font metrics server-side.
+func renderBadge(label, message, color string) string {
+	const charWidth, padding = 7, 10
+	lw := utf8.RuneCountInString(label)*charWidth + padding
+	mw := utf8.RuneCountInString(message)*charWidth + padding
+	label, message = html.EscapeString(label), html.EscapeString(message)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+<title>%[4]s: %[5]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="14">%[4]s</text><text x="%[8]d" y="14">%[5]s</text>
+</g>
+</svg>
+`, lw+mw, lw, mw, label, message, color, lw/2, lw+mw/2)
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// NotificationPreferences controls which status changes carry a
+// notify hint on the SSE stream for browser Notification API alerts
+type NotificationPreferences struct {
+	WaitingApproval bool `json:"waiting_approval"`
+	Completed       bool `json:"completed"`
+	Interrupted     bool `json:"interrupted"`
+}
+
+// notifyPrefs holds the current preferences
+type notifyPrefs struct {
+	mu    sync.RWMutex
+	prefs NotificationPreferences
+}
+
+func newNotifyPrefs() *notifyPrefs {
+	return &notifyPrefs{
+		prefs: NotificationPreferences{
+			WaitingApproval: true,
+			Completed:       true,
+			Interrupted:     true,
+		},
+	}
+}
+
+func (p *notifyPrefs) get() NotificationPreferences {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.prefs
+}
+
+func (p *notifyPrefs) set(prefs NotificationPreferences) {
+	p.mu.Lock()
+	p.prefs = prefs
+	p.mu.Unlock()
+}
+
+// shouldNotify reports whether entering the given phase warrants a browser notification
+func (p *notifyPrefs) shouldNotify(phase state.Phase) bool {
+	prefs := p.get()
+	switch phase {
+	case state.PhaseWaiting:
+		return prefs.WaitingApproval
+	case state.PhaseCompleted:
+		return prefs.Completed
+	case state.PhaseInterrupted:
+		return prefs.Interrupted
+	default:
+		return false
+	}
+}
+
+// handleGetNotificationPrefs returns the browser notification preferences
+func (s *Server) handleGetNotificationPrefs(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.notifyPrefs.get())
+}
+
+// handlePutNotificationPrefs replaces the browser notification preferences
+func (s *Server) handlePutNotificationPrefs(c echo.Context) error {
+	var prefs NotificationPreferences
+	if err := c.Bind(&prefs); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	s.notifyPrefs.set(prefs)
+	s.savePref(prefNotifications, prefs)
+	return c.JSON(http.StatusOK, prefs)
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+)
+
+// DaemonInfo describes the parts of the daemon's configuration the server
+// does not own itself, for the effective configuration summary
+type DaemonInfo struct {
+	ConfigFile   string
+	ProjectsDir  string
+	Sources      []string // names of the running input sources
+	JournalFile  string   // hook event journal, "" if disabled
+	DaemonHealth bool     // daemon health notifications enabled
+}
+
+// EffectiveConfig summarizes the running daemon's configuration.
+// Secrets are only reported as enabled or not.
+type EffectiveConfig struct {
+	Version       string                 `json:"version,omitempty"`
+	ConfigFile    string                 `json:"config_file"`
+	ProjectsDir   string                 `json:"projects_dir"`
+	Port          int                    `json:"port"`
+	BindAddress   string                 `json:"bind_address"`
+	TLS           bool                   `json:"tls"`
+	HookAuth      bool                   `json:"hook_auth"`
+	APIAuth       bool                   `json:"api_auth"`
+	Sources       []string               `json:"sources"`
+	WatchMode     string                 `json:"watch_mode,omitempty"`
+	LowPower      bool                   `json:"low_power"`
+	SSEKeepalive  string                 `json:"sse_keepalive"`
+	Heartbeat     string                 `json:"heartbeat"`
+	HistoryFile   string                 `json:"history_file,omitempty"`
+	PrefsFile     string                 `json:"prefs_file,omitempty"`
+	JournalFile   string                 `json:"journal_file,omitempty"`
+	Notifications EffectiveNotifications `json:"notifications"`
+	LogLevel      string                 `json:"log_level"`
+	Settings      *config.Settings       `json:"settings,omitempty"` // changeable with PUT /api/config
+}
+
+// EffectiveNotifications lists which notification backends are active
+type EffectiveNotifications struct {
+	Desktop               bool `json:"desktop"`
+	DaemonHealth          bool `json:"daemon_health"`
+	UnattendedPermissions bool `json:"unattended_permissions"`
+	Browser               bool `json:"browser"`   // any state enabled for Web UI notifications
+	Notifiers             int  `json:"notifiers"` // configured notification backends
+	Shortcuts             int  `json:"shortcuts"` // configured macOS Shortcuts
+}
+
+// WithDaemonInfo provides the daemon-level settings for GET /api/config
+// and the startup summary
+func WithDaemonInfo(info DaemonInfo) Option {
+	return func(s *Server) {
+		s.info = info
+	}
+}
+
+// WithVersion sets the version reported in the effective configuration
+func WithVersion(version string) Option {
+	return func(s *Server) {
+		s.version = version
+	}
+}
+
+// effectiveConfig returns the current effective configuration
+func (s *Server) effectiveConfig() EffectiveConfig {
+	bind := s.bindAddr
+	if bind == "" {
+		bind = "0.0.0.0"
+	}
+	prefs := s.notifyPrefs.get()
+
+	return EffectiveConfig{
+		Version:      s.version,
+		ConfigFile:   s.info.ConfigFile,
+		ProjectsDir:  s.info.ProjectsDir,
+		Port:         s.port,
+		BindAddress:  bind,
+		TLS:          s.tlsCert != "" && s.tlsKey != "",
+		HookAuth:     s.hookToken != "",
+		APIAuth:      s.apiToken != "",
+		Sources:      s.info.Sources,
+		WatchMode:    s.watchMode(),
+		LowPower:     s.watch.lowPower,
+		SSEKeepalive: s.sseKeepalive.String(),
+		Heartbeat:    s.manager.Heartbeat().String(),
+		HistoryFile:  s.historyPath(),
+		PrefsFile:    s.prefsPath(),
+		JournalFile:  s.info.JournalFile,
+		Notifications: EffectiveNotifications{
+			Desktop:               s.notifier != nil && s.notifier.DesktopEnabled(),
+			DaemonHealth:          s.daemonHealth(),
+			UnattendedPermissions: s.unattended != nil && s.unattended.DesktopEnabled(),
+			Browser:               prefs.WaitingApproval || prefs.Completed || prefs.Interrupted,
+			Notifiers:             s.notifierCount(),
+			Shortcuts:             s.shortcutCount(),
+		},
+		LogLevel: logging.Level().String(),
+		Settings: s.settings(),
+	}
+}
+
+// settings returns the runtime settings of the configuration, nil if it
+// cannot be changed
+func (s *Server) settings() *config.Settings {
+	if s.config == nil {
+		return nil
+	}
+	settings := s.config.Get().Settings()
+	return &settings
+}
+
+// daemonHealth reports whether daemon health notifications are enabled,
+// as of the last config reload
+func (s *Server) daemonHealth() bool {
+	if s.config != nil {
+		return s.config.Get().Notifications.DaemonHealthEnabled()
+	}
+	return s.info.DaemonHealth
+}
+
+// historyPath returns the statistics history file, "" if not persisted
+func (s *Server) historyPath() string {
+	if s.history == nil {
+		return ""
+	}
+	return s.history.Path()
+}
+
+// watchMode returns how session log changes are detected
+func (s *Server) watchMode() string {
+	return string(s.jsonl.WatchMode())
+}
+
+// notifierCount returns the number of configured notification backends
+func (s *Server) notifierCount() int {
+	if s.notifier == nil {
+		return 0
+	}
+	return s.notifier.Len()
+}
+
+// shortcutCount returns the number of configured macOS Shortcuts
+func (s *Server) shortcutCount() int {
+	if s.shortcuts == nil {
+		return 0
+	}
+	return s.shortcuts.Len()
+}
+
+// logEffectiveConfig writes the effective configuration to the log at startup
+func (s *Server) logEffectiveConfig() {
+	cfg := s.effectiveConfig()
+	logging.Logger().Info("effective configuration",
+		"version", cfg.Version,
+		"config_file", cfg.ConfigFile,
+		"projects_dir", cfg.ProjectsDir,
+		"port", cfg.Port,
+		"bind", cfg.BindAddress,
+		"tls", cfg.TLS,
+		"hook_auth", cfg.HookAuth,
+		"api_auth", cfg.APIAuth,
+		"sources", cfg.Sources,
+		"watch_mode", cfg.WatchMode,
+		"low_power", cfg.LowPower,
+		"sse_keepalive", cfg.SSEKeepalive,
+		"heartbeat", cfg.Heartbeat,
+		"history_file", cfg.HistoryFile,
+		"prefs_file", cfg.PrefsFile,
+		"journal_file", cfg.JournalFile,
+		"notify_desktop", cfg.Notifications.Desktop,
+		"notify_daemon_health", cfg.Notifications.DaemonHealth,
+		"notify_unattended_permissions", cfg.Notifications.UnattendedPermissions,
+		"notify_browser", cfg.Notifications.Browser,
+		"notify_notifiers", cfg.Notifications.Notifiers,
+		"notify_shortcuts", cfg.Notifications.Shortcuts,
+		"log_level", cfg.LogLevel,
+	)
+}
+
+// handleGetConfig returns the effective configuration without secrets
+func (s *Server) handleGetConfig(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.effectiveConfig())
+}
@@ -1,48 +1,240 @@
 package server
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/internal/hooks"
+	"github.com/sho7650/claude-watch-status/internal/i18n"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/parser"
 	"github.com/sho7650/claude-watch-status/internal/state"
+	"github.com/sho7650/claude-watch-status/pkg/protocol"
 )
 
 // StatusResponse represents the API response for status
 type StatusResponse struct {
 	Projects []state.ProjectStatus `json:"projects"`
+	Version  uint64                `json:"version,omitempty"`
+}
+
+// StreamUpdate is the data of an update event. Notify is set when
+// the project entered a state the browser should raise a notification for.
+// Cause is set for changes made by idle detection, acknowledgments and
+// mutes.
+type StreamUpdate struct {
+	state.ProjectStatus
+	Notify bool   `json:"notify,omitempty"`
+	Cause  string `json:"cause,omitempty"` // "idle_approval", "idle_completed", "acknowledged" or "muted"
+}
+
+// streamEventTypes are the event types a stream can be filtered by
+var streamEventTypes = map[string]bool{
+	"update":         true, // status changes from sources
+	"idle_approval":  true, // idle detection: estimated waiting approval
+	"idle_completed": true, // idle detection: estimated completion
+
+	state.EventAcknowledged:    true, // a user saw the project's state
+	state.EventMuted:           true, // notifications muted or unmuted
+	state.EventSessionRemoved:  true, // session logs deleted
+	protocol.TypeNotifications: true, // notifications paused or resumed
+}
+
+// streamFilter selects the projects and event types a stream client
+// receives; empty sets select everything
+type streamFilter struct {
+	projects map[string]bool
+	types    map[string]bool
+	inactive bool // include hidden projects in the init snapshot
+}
+
+// parseStreamFilter reads ?project= and ?types= from a stream request.
+// Both accept comma-separated lists and may be repeated.
+func parseStreamFilter(c echo.Context) (streamFilter, error) {
+	query := c.QueryParams()
+	f := streamFilter{projects: queryList(query["project"]), inactive: includeInactive(c)}
+	f.types = queryList(query["types"])
+	for t := range f.types {
+		if !streamEventTypes[t] {
+			return f, fmt.Errorf("unknown event type %q (want update, idle_approval, idle_completed or session_removed)", t)
+		}
+	}
+	return f, nil
+}
+
+// queryList splits comma-separated query values into a set
+func queryList(values []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				set[item] = true
+			}
+		}
+	}
+	return set
+}
+
+func (f streamFilter) matchProject(name string) bool {
+	return len(f.projects) == 0 || f.projects[name]
+}
+
+func (f streamFilter) matchType(eventType string) bool {
+	return len(f.types) == 0 || f.types[eventType]
 }
 
 // handleGetStatus returns the current status of all projects
 func (s *Server) handleGetStatus(c echo.Context) error {
-	statuses := s.manager.GetAll()
+	statuses := s.manager.GetVisible()
+	if includeInactive(c) {
+		statuses = s.manager.GetAll()
+	}
 	return c.JSON(http.StatusOK, StatusResponse{Projects: statuses})
 }
 
-// handleHealth returns server health status
+// handleHealth returns server health status and the idle checker's tick mode
 func (s *Server) handleHealth(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+	mode, interval := s.engine.TickMode()
+	return c.JSON(http.StatusOK, map[string]string{
+		"status":         "ok",
+		"tick_mode":      string(mode),
+		"tick_interval":  interval.String(),
+		"dropped_events": strconv.FormatUint(s.manager.DroppedEvents(), 10),
+
+		"hooks_rejected_rate":    strconv.FormatUint(s.hookLimits.rate.Load(), 10),
+		"hooks_rejected_size":    strconv.FormatUint(s.hookLimits.size.Load(), 10),
+		"hooks_rejected_invalid": strconv.FormatUint(s.hookLimits.invalid.Load(), 10),
+	})
+}
+
+// handleEventSchema serves the JSON Schema of the event envelope
+func (s *Server) handleEventSchema(c echo.Context) error {
+	return c.Blob(http.StatusOK, "application/schema+json", protocol.SchemaV1JSON)
+}
+
+// handleOpenAPI serves the OpenAPI document of the HTTP API
+func (s *Server) handleOpenAPI(c echo.Context) error {
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, protocol.OpenAPIJSON)
+}
+
+// i18nResponse is the response of GET /api/i18n
+type i18nResponse struct {
+	Language string            `json:"language"`
+	Messages map[string]string `json:"messages"`
+}
+
+// handleI18n serves the messages of the configured language for the Web UI
+func (s *Server) handleI18n(c echo.Context) error {
+	lang := i18n.Lang()
+	return c.JSON(http.StatusOK, i18nResponse{Language: lang, Messages: i18n.Messages(lang)})
 }
 
-// handleSSE handles Server-Sent Events for real-time updates
+// handleSSE handles Server-Sent Events for real-time updates. The init
+// snapshot and updates can be limited to some projects (?project=a,b) and
+// updates to some event types (?types=update,idle_approval). Every event
+// carries its ID; a client reconnecting with Last-Event-ID receives the
+// events it missed instead of init, if they are still kept.
 func (s *Server) handleSSE(c echo.Context) error {
+	filter, err := parseStreamFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
 	c.Response().Header().Set("Content-Type", "text/event-stream")
 	c.Response().Header().Set("Cache-Control", "no-cache")
 	c.Response().Header().Set("Connection", "keep-alive")
-	c.Response().Header().Set("Access-Control-Allow-Origin", "*")
 
-	// Subscribe to status events
-	eventCh := s.manager.Subscribe()
+	w := c.Response()
+
+	// Track last state per project so notify hints fire only on transitions
+	lastState := make(map[string]string)
+	sendUpdate := func(statusEvent state.StatusEvent) {
+		project := statusEvent.Project
+		if statusEvent.Type == state.EventSessionRemoved {
+			if statusEvent.ProjectRemoved {
+				delete(lastState, project.Name)
+			}
+			if filter.matchProject(project.Name) && filter.matchType(statusEvent.Type) {
+				writeEvent(w, statusEvent.ID, protocol.TypeSessionRemoved, protocol.SessionRemoved{
+					Project:        project.Name,
+					SessionID:      project.SessionID,
+					ProjectRemoved: statusEvent.ProjectRemoved,
+				})
+			}
+			return
+		}
+		if !filter.matchProject(project.Name) || !filter.matchType(statusEvent.Type) {
+			lastState[project.Name] = project.State
+			return
+		}
+		update := StreamUpdate{ProjectStatus: project}
+		if statusEvent.Type != "update" {
+			update.Cause = statusEvent.Type
+		}
+		if phase := state.PhaseOf(project.State); lastState[project.Name] != project.State && !s.silenced(project.Name, phase) {
+			update.Notify = s.notifyPrefs.shouldNotify(phase)
+		}
+		lastState[project.Name] = project.State
+		writeEvent(w, statusEvent.ID, protocol.TypeUpdate, update)
+	}
+	sendInit := func(statuses []state.ProjectStatus, version uint64) {
+		clear(lastState)
+		snapshot := make([]state.ProjectStatus, 0, len(statuses))
+		for _, status := range statuses {
+			lastState[status.Name] = status.State
+			if filter.matchProject(status.Name) && (filter.inactive || !s.manager.Hidden(status.Name)) {
+				snapshot = append(snapshot, status)
+			}
+		}
+		writeEvent(w, version, protocol.TypeInit, StatusResponse{Projects: snapshot, Version: version})
+	}
+
+	// Subscribe and replay or snapshot atomically so no update is lost
+	// between them; later events up to version are already sent
+	var eventCh chan state.StatusEvent
+	var version uint64
+	resumed := false
+	if since, ok := lastEventID(c); ok {
+		var missed []state.StatusEvent
+		eventCh, missed, resumed = s.manager.SubscribeSince(since)
+		if resumed {
+			version = since
+			for _, statusEvent := range missed {
+				sendUpdate(statusEvent)
+				version = statusEvent.ID
+			}
+		} else {
+			s.manager.Unsubscribe(eventCh)
+		}
+	}
+	if !resumed {
+		var statuses []state.ProjectStatus
+		eventCh, statuses, version = s.manager.SubscribeWithSnapshot()
+		sendInit(statuses, version)
+	}
 	defer s.manager.Unsubscribe(eventCh)
 
-	// Send initial state
-	statuses := s.manager.GetAll()
-	initialData, _ := json.Marshal(StatusResponse{Projects: statuses})
-	fmt.Fprintf(c.Response(), "event: init\ndata: %s\n\n", initialData)
-	c.Response().Flush()
+	// Every client learns whether notifications are paused, and of changes
+	pauseCh := s.pause.subscribe()
+	defer s.pause.unsubscribe(pauseCh)
+	if filter.matchType(protocol.TypeNotifications) {
+		writeUnnumberedEvent(w, protocol.TypeNotifications, s.pause.get())
+	}
+	w.Flush()
+
+	var keepalive <-chan time.Time
+	if s.sseKeepalive > 0 {
+		ticker := time.NewTicker(s.sseKeepalive)
+		defer ticker.Stop()
+		keepalive = ticker.C
+	}
 
 	// Stream updates
 	for {
@@ -50,18 +242,41 @@ func (s *Server) handleSSE(c echo.Context) error {
 		case <-c.Request().Context().Done():
 			return nil
 
-		case event, ok := <-eventCh:
+		// Streams end first when the daemon stops, so shutdown need not
+		// wait for clients to disconnect
+		case <-s.done:
+			return nil
+
+		case <-keepalive:
+			// Comment lines keep proxies from closing an idle connection
+			fmt.Fprint(w, ": keepalive\n\n")
+			w.Flush()
+
+		case pause := <-pauseCh:
+			if filter.matchType(protocol.TypeNotifications) {
+				writeUnnumberedEvent(w, protocol.TypeNotifications, pause)
+				w.Flush()
+			}
+
+		case statusEvent, ok := <-eventCh:
 			if !ok {
 				return nil
 			}
 
-			data, err := json.Marshal(event.Project)
-			if err != nil {
+			// Already included in the init snapshot or the replay
+			if statusEvent.ID <= version {
 				continue
 			}
-
-			fmt.Fprintf(c.Response(), "event: update\ndata: %s\n\n", data)
-			c.Response().Flush()
+			// This stream fell behind and missed events: start the client
+			// over from the current statuses
+			if statusEvent.Type == state.EventResync {
+				version = statusEvent.ID
+				sendInit(statusEvent.Snapshot, version)
+				w.Flush()
+				continue
+			}
+			sendUpdate(statusEvent)
+			w.Flush()
 		}
 	}
 }
@@ -71,9 +286,27 @@ type HookEventRequest struct {
 	SessionID     string                 `json:"session_id"`
 	HookEventName string                 `json:"hook_event_name"`
 	ToolName      string                 `json:"tool_name,omitempty"`
+	ToolUseID     string                 `json:"tool_use_id,omitempty"`
 	ToolInput     map[string]interface{} `json:"tool_input,omitempty"`
 	ToolResult    *ToolResult            `json:"tool_result,omitempty"`
 	CWD           string                 `json:"cwd"`
+
+	// Session environment: permission_mode is sent with every event,
+	// model with SessionStart (a name, or an object with an id)
+	PermissionMode string      `json:"permission_mode,omitempty"`
+	Model          interface{} `json:"model,omitempty"`
+
+	// Stop / SubagentStop fields
+	TranscriptPath string `json:"transcript_path,omitempty"`
+	StopHookActive bool   `json:"stop_hook_active,omitempty"`
+	AgentID        string `json:"agent_id,omitempty"` // SubagentStop, newer Claude Code
+
+	// SessionEnd fields
+	Reason string `json:"reason,omitempty"`
+
+	// Notification fields
+	Message          string `json:"message,omitempty"`
+	NotificationType string `json:"notification_type,omitempty"` // newer Claude Code
 }
 
 // ToolResult represents the result of a tool execution
@@ -85,9 +318,22 @@ type ToolResult struct {
 
 // handleHooksEvent handles incoming hook events from Claude Code
 func (s *Server) handleHooksEvent(c echo.Context) error {
-	var req HookEventRequest
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	req, schema, err := decodeHookEvent(c.Request().Body)
+	if err != nil {
+		return s.rejectHookBind(c, err)
+	}
+	if err := validateHookEvent(req); err != nil {
+		return s.rejectHookInvalid(c, err)
+	}
+	if !s.allowHook(c, req) {
+		return s.rejectHookRate(c)
+	}
+	// Events of newer Claude Code versions have no state to show yet
+	if !knownHookEvent(req.HookEventName) {
+		if hookNotes.first("event:" + req.HookEventName) {
+			logging.Logger().Info("unrecognized hook event ignored", "event", req.HookEventName, "schema", schema)
+		}
+		return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
 	}
 
 	// Extract project name from CWD
@@ -95,19 +341,48 @@ func (s *Server) handleHooksEvent(c echo.Context) error {
 
 	// Convert hook event to state
 	icon, stateText := convertHookEventToState(req.HookEventName, req.ToolName)
+	toolName := req.ToolName
+	logPath := sessionLogPath(s.info.ProjectsDir, req.TranscriptPath)
+	switch strings.ToLower(req.HookEventName) {
+	case "stop":
+		icon, stateText = convertStopEventToState(req, logPath)
+	case "notification":
+		icon, stateText, toolName = convertNotificationToState(req)
+	}
 
 	// Update state manager
 	event := state.HookEvent{
-		SessionID:     req.SessionID,
-		HookEventName: req.HookEventName,
-		ToolName:      req.ToolName,
-		CWD:           req.CWD,
-		ProjectName:   projectName,
-		Icon:          icon,
-		State:         stateText,
+		SessionID:      req.SessionID,
+		HookEventName:  req.HookEventName,
+		ToolName:       toolName,
+		ToolUseID:      req.ToolUseID,
+		Detail:         toolDetail(req),
+		CWD:            req.CWD,
+		ProjectName:    projectName,
+		TranscriptPath: logPath,
+		Icon:           icon,
+		State:          stateText,
+		Environment:    hookEnvironment(req),
+	}
+	event.Environment.Terminal = hookTerminal(c.Request().Header.Get(hooks.TerminalHeader))
+
+	event.Subagent = subagentUpdate(req)
+	if strings.EqualFold(req.HookEventName, "subagentstop") {
+		// Only the subagent finished; the parent keeps its state
+		event.Icon, event.State = "", ""
+	}
+
+	// Events replayed from the hook-relay spool carry their original time
+	if t, err := time.Parse(time.RFC3339Nano, c.Request().Header.Get(hooks.EventTimeHeader)); err == nil {
+		event.Time = t
 	}
 
-	s.manager.UpdateFromHook(event)
+	logging.Logger().Debug("hook event received",
+		"event", req.HookEventName, "project", projectName, "session", req.SessionID, "tool", req.ToolName, "schema", schema)
+
+	if s.hooks.Submit(event) == nil {
+		logging.Logger().Debug("hook event did not change the status", "project", projectName, "state", stateText)
+	}
 
 	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
 }
@@ -116,13 +391,29 @@ func (s *Server) handleHooksEvent(c echo.Context) error {
 func extractProjectNameFromCWD(cwd string) string {
 	// Try to extract meaningful project name from path
 	// e.g., /Users/user/projects/myproject -> myproject
+	// (C:\Users\user\projects\myproject on Windows)
 	base := filepath.Base(cwd)
-	if base == "" || base == "." || base == "/" {
+	if base == "" || base == "." || base == string(filepath.Separator) {
 		return "unknown"
 	}
 	return base
 }
 
+// sessionLogPath returns the session log a hook event names as its
+// transcript_path, or "" unless it is an absolute path of a .jsonl file
+// below the projects directory: the daemon reads the file, and any
+// client may send hook events
+func sessionLogPath(projectsDir, transcriptPath string) string {
+	if projectsDir == "" || !filepath.IsAbs(transcriptPath) || filepath.Ext(transcriptPath) != ".jsonl" {
+		return ""
+	}
+	rel, err := filepath.Rel(filepath.Clean(projectsDir), filepath.Clean(transcriptPath))
+	if err != nil || !filepath.IsLocal(rel) {
+		return ""
+	}
+	return filepath.Clean(transcriptPath)
+}
+
 // convertHookEventToState converts hook event to icon and state text
 func convertHookEventToState(hookEvent, toolName string) (icon, stateText string) {
 	switch strings.ToLower(hookEvent) {
@@ -130,6 +421,9 @@ func convertHookEventToState(hookEvent, toolName string) (icon, stateText string
 		return "👤", "session started"
 	case "sessionend":
 		return "💤", "session ended"
+	case "userpromptsubmit":
+		// Fires as soon as the prompt is submitted, before it reaches the log
+		return "👤", "processing prompt"
 	case "pretooluse":
 		// PreToolUse fires AFTER approval, so tool is now running
 		if toolName != "" {
@@ -138,9 +432,163 @@ func convertHookEventToState(hookEvent, toolName string) (icon, stateText string
 		return "🔧", "running tool"
 	case "posttooluse":
 		return "⏳", "processing"
+	case "permissionrequest":
+		// Fires when the permission dialog is shown, so the wait is certain
+		return "⏸️", "waiting approval"
 	case "stop":
 		return "✅", "completed"
 	default:
-		return "🔄", hookEvent
+		// Notification and SubagentStop are converted by their own rules
+		return "", ""
+	}
+}
+
+// hookEnvironment returns the session environment reported by a hook event.
+// Without a reported permission mode, SessionStart falls back to the
+// defaultMode of the Claude Code settings that apply to the session.
+func hookEnvironment(req HookEventRequest) state.Environment {
+	env := state.EnvironmentFromTool(req.ToolName)
+	env.PermissionMode = req.PermissionMode
+	if env.PermissionMode == "" && strings.EqualFold(req.HookEventName, "sessionstart") {
+		env.PermissionMode = hooks.DefaultPermissionMode(req.CWD)
+	}
+	switch model := req.Model.(type) {
+	case string:
+		env.Model = model
+	case map[string]interface{}:
+		env.Model, _ = model["id"].(string)
+	}
+	return env
+}
+
+// terminalFields are the patterns values of the hook relay's terminal
+// header must match. Values end up as arguments of tmux, osascript and
+// others when a terminal is raised, so anything unexpected is dropped.
+var terminalFields = map[string]*regexp.Regexp{
+	"program":       regexp.MustCompile(`^[A-Za-z0-9._+-]{1,64}$`),
+	"tty":           regexp.MustCompile(`^/dev/[A-Za-z0-9/]{1,32}$`),
+	"tmux_pane":     regexp.MustCompile(`^%[0-9]{1,9}$`),
+	"tmux_socket":   regexp.MustCompile(`^/[A-Za-z0-9._/-]{1,255}$`),
+	"iterm_session": regexp.MustCompile(`^[A-Fa-f0-9-]{36}$`),
+	"wezterm_pane":  regexp.MustCompile(`^[0-9]{1,9}$`),
+	"kitty_window":  regexp.MustCompile(`^[0-9]{1,9}$`),
+	"x_window":      regexp.MustCompile(`^[0-9]{1,10}$`),
+}
+
+// hookTerminal returns the terminal described by the hook relay's terminal
+// header, or nil without a usable one
+func hookTerminal(header string) *state.Terminal {
+	if header == "" {
+		return nil
+	}
+	values, err := url.ParseQuery(header)
+	if err != nil {
+		return nil
+	}
+	field := func(key string) string {
+		if v := values.Get(key); terminalFields[key].MatchString(v) {
+			return v
+		}
+		return ""
 	}
+	t := state.Terminal{
+		Program:      field("program"),
+		TTY:          field("tty"),
+		TmuxPane:     field("tmux_pane"),
+		TmuxSocket:   field("tmux_socket"),
+		ITermSession: field("iterm_session"),
+		WezTermPane:  field("wezterm_pane"),
+		KittyWindow:  field("kitty_window"),
+		XWindow:      field("x_window"),
+	}
+	if t == (state.Terminal{}) {
+		return nil
+	}
+	return &t
+}
+
+// toolDetail returns what a tool about to run or awaiting approval works on
+func toolDetail(req HookEventRequest) string {
+	switch strings.ToLower(req.HookEventName) {
+	case "pretooluse", "permissionrequest":
+		return parser.ToolDetail(req.ToolName, req.ToolInput)
+	}
+	return ""
+}
+
+// permissionMessagePrefix starts Notification messages for permission prompts
+const permissionMessagePrefix = "Claude needs your permission to use "
+
+// convertNotificationToState converts a Notification hook event. Permission
+// prompts and MCP input dialogs mean Claude is definitely waiting on the
+// user; other notifications (e.g. "waiting for your input" after a
+// completed turn) return an empty state and leave the project unchanged.
+// The tool name is taken from the message, as the payload has no tool_name.
+func convertNotificationToState(req HookEventRequest) (icon, stateText, toolName string) {
+	switch req.NotificationType {
+	case "permission_prompt", "elicitation_dialog":
+	case "":
+		// Older Claude Code sends only the message
+		if !strings.HasPrefix(req.Message, permissionMessagePrefix) {
+			return "", "", ""
+		}
+	default:
+		return "", "", ""
+	}
+	if tool, ok := strings.CutPrefix(req.Message, permissionMessagePrefix); ok {
+		toolName = strings.TrimSpace(tool)
+	}
+	return "⏸️", "waiting approval", toolName
+}
+
+// subagentUpdate returns the subagent change described by a hook event:
+// Task tool calls start and finish subagents, SubagentStop finishes one
+func subagentUpdate(req HookEventRequest) *state.SubagentUpdate {
+	hookEvent := strings.ToLower(req.HookEventName)
+	if hookEvent == "subagentstop" {
+		return &state.SubagentUpdate{AgentID: req.AgentID, Icon: "✅", State: "completed"}
+	}
+	if req.ToolName != "Task" && req.ToolName != "Agent" {
+		return nil
+	}
+
+	input := func(key string) string {
+		v, _ := req.ToolInput[key].(string)
+		return v
+	}
+	update := &state.SubagentUpdate{
+		ToolUseID:   req.ToolUseID,
+		Prompt:      input("prompt"),
+		Description: input("description"),
+		Type:        input("subagent_type"),
+	}
+	switch hookEvent {
+	case "pretooluse":
+		update.Icon, update.State = "🤖", "started"
+	case "posttooluse":
+		update.Icon, update.State = "✅", "completed"
+	default:
+		return nil
+	}
+	return update
+}
+
+// convertStopEventToState inspects the full Stop payload and the session
+// log it names, as checked by sessionLogPath, to distinguish normal
+// completion from interruptions and stop-hook continuations
+func convertStopEventToState(req HookEventRequest, logPath string) (icon, stateText string) {
+	// stop_hook_active means Claude is already continuing because of a
+	// previous Stop hook, so the turn has not really finished
+	if req.StopHookActive {
+		return "🔄", "continuing"
+	}
+
+	if logPath != "" {
+		entry, err := parser.ReadLastEntry(logPath)
+		if err == nil && parser.IsInterrupted(entry) {
+			return "🛑", "interrupted"
+		}
+	}
+
+	return "✅", "completed"
 }
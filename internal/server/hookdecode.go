@@ -0,0 +1,134 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/sho7650/claude-watch-status/internal/hooks"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+)
+
+// Hook payload schema versions. Claude Code adds fields to its hook
+// payloads without versioning them, so the version of a payload is the
+// newest one among its fields.
+const (
+	hookSchemaV1 = 1 // session_id, hook_event_name, tool_*, cwd, message, reason
+	hookSchemaV2 = 2 // adds permission_mode, transcript_path, model, tool_use_id, ...
+)
+
+// hookField is a field of hook payloads: the schema version introducing
+// it and where it is decoded to; nil for fields understood but not used
+type hookField struct {
+	since  int
+	target any
+}
+
+// hookFields returns the fields of hook payloads decoded into req
+func hookFields(req *HookEventRequest) map[string]hookField {
+	return map[string]hookField{
+		"session_id":       {hookSchemaV1, &req.SessionID},
+		"hook_event_name":  {hookSchemaV1, &req.HookEventName},
+		"tool_name":        {hookSchemaV1, &req.ToolName},
+		"tool_input":       {hookSchemaV1, &req.ToolInput},
+		"tool_result":      {hookSchemaV1, &req.ToolResult},
+		"cwd":              {hookSchemaV1, &req.CWD},
+		"stop_hook_active": {hookSchemaV1, &req.StopHookActive},
+		"reason":           {hookSchemaV1, &req.Reason},
+		"message":          {hookSchemaV1, &req.Message},
+
+		"permission_mode":   {hookSchemaV2, &req.PermissionMode},
+		"transcript_path":   {hookSchemaV2, &req.TranscriptPath},
+		"model":             {hookSchemaV2, &req.Model},
+		"tool_use_id":       {hookSchemaV2, &req.ToolUseID},
+		"agent_id":          {hookSchemaV2, &req.AgentID},
+		"notification_type": {hookSchemaV2, &req.NotificationType},
+		"tool_response":     {hookSchemaV2, nil},
+		"prompt":            {hookSchemaV2, nil},
+		"source":            {hookSchemaV2, nil},
+	}
+}
+
+// requiredHookFields fail an event when they cannot be decoded; other
+// fields of an unexpected type are skipped
+var requiredHookFields = []string{"session_id", "hook_event_name"}
+
+// decodeHookEvent decodes a hook payload of any schema version and
+// returns it with its version. Unknown fields are ignored, and known
+// ones of an unexpected type skipped, so payloads of newer Claude Code
+// versions still update the status.
+func decodeHookEvent(body io.Reader) (HookEventRequest, int, error) {
+	var req HookEventRequest
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return req, 0, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return req, 0, err
+	}
+
+	version := hookSchemaV1
+	fields := hookFields(&req)
+	for name, value := range raw {
+		field, ok := fields[name]
+		if !ok {
+			if hookNotes.first("field:" + name) {
+				logging.Logger().Debug("unknown hook event field ignored", "field", name)
+			}
+			continue
+		}
+		version = max(version, field.since)
+		if field.target == nil {
+			continue
+		}
+		if err := json.Unmarshal(value, field.target); err != nil {
+			for _, required := range requiredHookFields {
+				if name == required {
+					return req, 0, errors.New(name + " is not a string")
+				}
+			}
+			logging.Logger().Debug("hook event field of an unexpected type skipped", "field", name, "error", err)
+		}
+	}
+	return req, version, nil
+}
+
+// knownHookEvent reports whether the daemon understands a hook event:
+// the events init registers hooks for
+func knownHookEvent(name string) bool {
+	for _, event := range hooks.CWSHookEvents {
+		if strings.EqualFold(event, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxHookNotes bounds the unknown names logged, as any client may send them
+const maxHookNotes = 100
+
+// hookNoteLog remembers the unknown hook event names and payload fields
+// logged, so each is logged once
+type hookNoteLog struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+var hookNotes hookNoteLog
+
+// first reports whether key is seen for the first time and to be logged
+func (l *hookNoteLog) first(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.seen[key] || len(l.seen) >= maxHookNotes {
+		return false
+	}
+	if l.seen == nil {
+		l.seen = make(map[string]bool)
+	}
+	l.seen[key] = true
+	return true
+}
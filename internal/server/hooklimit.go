@@ -0,0 +1,143 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+)
+
+// Limits on hook events, so a hook script stuck in a loop cannot flood
+// the daemon. Claude Code sends a few events per tool call; a session
+// exceeding the rate for long is misbehaving.
+const (
+	maxHookBody   = 1 << 20 // bytes; tool inputs are large, but not this large
+	hookRate      = 50      // events per second per session
+	hookBurst     = 200
+	hookAddrRate  = 500 // events per second per client address, for all its sessions
+	hookAddrBurst = 2000
+	maxHookName   = 64   // bytes of hook_event_name
+	maxHookID     = 256  // bytes of session_id, tool_name and tool_use_id
+	maxHookPath   = 4096 // bytes of cwd and transcript_path
+	hookRetryIn   = "1"  // Retry-After seconds of rate limited events
+	hookLimitTTL  = 3 * time.Minute
+)
+
+// hookLimits limits the rate of hook events per session and per client
+// address and counts the events rejected, for /health
+type hookLimits struct {
+	store   *middleware.RateLimiterMemoryStore // per session
+	addrs   *middleware.RateLimiterMemoryStore // per client address
+	rate    atomic.Uint64                      // over the rate limit (429)
+	size    atomic.Uint64                      // body over maxHookBody (413)
+	invalid atomic.Uint64                      // malformed or failing validation (400)
+}
+
+func newHookLimits() *hookLimits {
+	return &hookLimits{
+		store: middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+			Rate:      hookRate,
+			Burst:     hookBurst,
+			ExpiresIn: hookLimitTTL,
+		}),
+		addrs: middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+			Rate:      hookAddrRate,
+			Burst:     hookAddrBurst,
+			ExpiresIn: hookLimitTTL,
+		}),
+	}
+}
+
+// limitHookBody rejects hook events with a body over maxHookBody: at once
+// by Content-Length, or when reading the body reaches the limit
+func (s *Server) limitHookBody(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		if req.ContentLength > maxHookBody {
+			return s.rejectHookSize(c)
+		}
+		req.Body = http.MaxBytesReader(c.Response(), req.Body, maxHookBody)
+		return next(c)
+	}
+}
+
+func (s *Server) rejectHookSize(c echo.Context) error {
+	if s.hookLimits.size.Add(1) == 1 {
+		logging.Logger().Warn("hook event body too large, rejected", "limit", maxHookBody, "remote", c.RealIP())
+	}
+	return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "hook event too large"})
+}
+
+// rejectHookBind answers a hook event that could not be decoded
+func (s *Server) rejectHookBind(c echo.Context, err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return s.rejectHookSize(c)
+	}
+	return s.rejectHookInvalid(c, fmt.Errorf("invalid request: %w", err))
+}
+
+func (s *Server) rejectHookInvalid(c echo.Context, err error) error {
+	s.hookLimits.invalid.Add(1)
+	logging.Logger().Debug("invalid hook event rejected", "error", err, "remote", c.RealIP())
+	return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+}
+
+// allowHook reports whether a hook event is within the rate limits of
+// its session and of its client address. Session IDs are chosen by the
+// client, so the address limit also holds for clients rotating them.
+func (s *Server) allowHook(c echo.Context, req HookEventRequest) bool {
+	source := "addr:" + c.RealIP()
+	ok, _ := s.hookLimits.addrs.Allow(c.RealIP())
+	if ok && req.SessionID != "" {
+		source = "session:" + req.SessionID
+		ok, _ = s.hookLimits.store.Allow(req.SessionID)
+	}
+	if ok {
+		return true
+	}
+	// Logged for the first of every 1000, a flood must not flood the log
+	if rejected := s.hookLimits.rate.Add(1); rejected%1000 == 1 {
+		logging.Logger().Warn("hook events over the rate limit, rejected",
+			"source", source, "limit", hookRate, "rejected", rejected)
+	}
+	return false
+}
+
+func (s *Server) rejectHookRate(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderRetryAfter, hookRetryIn)
+	return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "too many hook events"})
+}
+
+// validateHookEvent checks the fields a hook event is stored and shown by
+func validateHookEvent(req HookEventRequest) error {
+	if req.HookEventName == "" {
+		return errors.New("hook_event_name is required")
+	}
+	for _, field := range []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"hook_event_name", req.HookEventName, maxHookName},
+		{"session_id", req.SessionID, maxHookID},
+		{"tool_name", req.ToolName, maxHookID},
+		{"tool_use_id", req.ToolUseID, maxHookID},
+		{"cwd", req.CWD, maxHookPath},
+		{"transcript_path", req.TranscriptPath, maxHookPath},
+	} {
+		if len(field.value) > field.max {
+			return fmt.Errorf("%s longer than %d bytes", field.name, field.max)
+		}
+		if strings.ContainsRune(field.value, 0) {
+			return fmt.Errorf("%s contains a NUL byte", field.name)
+		}
+	}
+	return nil
+}
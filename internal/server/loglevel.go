@@ -0,0 +1,35 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+)
+
+// LogLevelRequest represents a request to change the log level
+type LogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// handleGetLogLevel returns the current log level
+func (s *Server) handleGetLogLevel(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"level": logging.Level().String()})
+}
+
+// handleSetLogLevel changes the log level without restarting the daemon
+func (s *Server) handleSetLogLevel(c echo.Context) error {
+	var req LogLevelRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	level, err := logging.ParseLevel(req.Level)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	logging.SetLevel(level)
+	logging.Logger().Info("log level changed", "level", level.String(), "via", "api")
+	return c.JSON(http.StatusOK, map[string]string{"level": level.String()})
+}
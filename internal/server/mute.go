@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/pkg/protocol"
+)
+
+// mutes returns the active mutes, which the state manager keeps
+func (s *Server) mutes() []protocol.Mute {
+	active := s.manager.Mutes()
+	mutes := make([]protocol.Mute, 0, len(active))
+	for _, m := range active {
+		mutes = append(mutes, protocol.Mute(m))
+	}
+	return mutes
+}
+
+// handleGetMutes returns the muted projects
+func (s *Server) handleGetMutes(c echo.Context) error {
+	return c.JSON(http.StatusOK, protocol.MutesResponse{Mutes: s.mutes()})
+}
+
+// handleMuteProject silences the desktop and browser notifications of a
+// project, for a duration or until unmuted. Projects need not be known
+// yet, so a project can be muted before its session starts.
+func (s *Server) handleMuteProject(c echo.Context) error {
+	name, err := url.PathUnescape(c.Param("name"))
+	if err != nil || name == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid project name"})
+	}
+	var req protocol.MuteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	var until time.Time
+	if req.Duration != "" {
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil || d <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid duration"})
+		}
+		until = time.Now().Add(d)
+	}
+	s.manager.Mute(name, until)
+	s.savePref(prefMutes, s.mutes())
+	return c.JSON(http.StatusOK, protocol.Mute{Project: name, Until: until})
+}
+
+// handleUnmuteProject restores the notifications of a project
+func (s *Server) handleUnmuteProject(c echo.Context) error {
+	name, err := url.PathUnescape(c.Param("name"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid project name"})
+	}
+	if !s.manager.Unmute(name) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "project is not muted"})
+	}
+	s.savePref(prefMutes, s.mutes())
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,13 @@
+package server
+
+import (
+	"github.com/sho7650/claude-watch-status/internal/notifier"
+)
+
+// WithNotifier enables desktop and configured notifications from the
+// daemon, sent by its engine for the projects that are not silenced
+func WithNotifier(n *notifier.Notifier) Option {
+	return func(s *Server) {
+		s.notifier = n
+	}
+}
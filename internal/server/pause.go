@@ -0,0 +1,144 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/internal/state"
+	"github.com/sho7650/claude-watch-status/pkg/protocol"
+)
+
+// notifyPause silences all notifications, for a duration or until
+// resumed, and tells event stream clients whenever that changes
+type notifyPause struct {
+	mu        sync.Mutex
+	current   protocol.NotificationPause
+	expiry    *time.Timer // resumes a timed pause
+	listeners map[chan protocol.NotificationPause]bool
+}
+
+func newNotifyPause() *notifyPause {
+	return &notifyPause{listeners: make(map[chan protocol.NotificationPause]bool)}
+}
+
+// get returns whether notifications are paused
+func (p *notifyPause) get() protocol.NotificationPause {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.current
+}
+
+// active reports whether notifications are paused
+func (p *notifyPause) active() bool {
+	return p.get().Paused
+}
+
+// pause silences notifications until the given time (zero = until resumed)
+func (p *notifyPause) pause(until time.Time) protocol.NotificationPause {
+	return p.set(protocol.NotificationPause{Paused: true, Until: until})
+}
+
+// resume restores notifications
+func (p *notifyPause) resume() protocol.NotificationPause {
+	return p.set(protocol.NotificationPause{})
+}
+
+func (p *notifyPause) set(pause protocol.NotificationPause) protocol.NotificationPause {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.expiry != nil {
+		p.expiry.Stop()
+		p.expiry = nil
+	}
+	p.current = pause
+	if pause.Paused && !pause.Until.IsZero() {
+		var timer *time.Timer
+		timer = time.AfterFunc(time.Until(pause.Until), func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			// Replaced by another pause or resume meanwhile
+			if p.expiry != timer {
+				return
+			}
+			p.expiry = nil
+			p.current = protocol.NotificationPause{}
+			p.broadcast()
+		})
+		p.expiry = timer
+	}
+	p.broadcast()
+	return pause
+}
+
+// broadcast hands the current state to every listener, replacing a
+// state it has not received yet. Caller must hold p.mu.
+func (p *notifyPause) broadcast() {
+	for ch := range p.listeners {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- p.current
+	}
+}
+
+// subscribe returns a channel receiving the state whenever it changes
+func (p *notifyPause) subscribe() chan protocol.NotificationPause {
+	ch := make(chan protocol.NotificationPause, 1)
+	p.mu.Lock()
+	p.listeners[ch] = true
+	p.mu.Unlock()
+	return ch
+}
+
+func (p *notifyPause) unsubscribe(ch chan protocol.NotificationPause) {
+	p.mu.Lock()
+	delete(p.listeners, ch)
+	p.mu.Unlock()
+}
+
+// silenced reports whether notifications of a project entering a phase
+// are paused, muted or fall in the configured quiet hours
+func (s *Server) silenced(project string, phase state.Phase) bool {
+	return s.pause.active() || s.manager.Muted(project) || s.quiet(phase)
+}
+
+// quiet reports whether the configured quiet hours suppress a
+// notification about a project entering phase now
+func (s *Server) quiet(phase state.Phase) bool {
+	if s.config == nil {
+		return false
+	}
+	return s.config.Get().Notifications.QuietHours.Silences(time.Now(), phase == state.PhaseWaiting)
+}
+
+// handleGetNotificationPause returns whether notifications are paused
+func (s *Server) handleGetNotificationPause(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.pause.get())
+}
+
+// handlePauseNotifications silences all desktop, browser and configured
+// notifiers and Shortcuts, for ?duration= or until resumed
+func (s *Server) handlePauseNotifications(c echo.Context) error {
+	var until time.Time
+	if v := c.QueryParam("duration"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid duration"})
+		}
+		until = time.Now().Add(d)
+	}
+	pause := s.pause.pause(until)
+	s.savePref(prefPause, pause)
+	return c.JSON(http.StatusOK, pause)
+}
+
+// handleResumeNotifications ends a notification pause
+func (s *Server) handleResumeNotifications(c echo.Context) error {
+	pause := s.pause.resume()
+	s.savePref(prefPause, pause)
+	return c.JSON(http.StatusOK, pause)
+}
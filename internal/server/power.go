@@ -0,0 +1,133 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/internal/engine"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/power"
+	"github.com/sho7650/claude-watch-status/internal/source"
+)
+
+// Low-power settings applied while running on battery with --low-power
+const (
+	powerCheckInterval   = 30 * time.Second
+	lowPowerIdleInterval = 30 * time.Second
+	lowPowerDebounce     = 5 * time.Second
+)
+
+// WatchStatus reports the current watching mode
+type WatchStatus struct {
+	Paused       bool   `json:"paused"`
+	LowPower     bool   `json:"low_power"`  // --low-power enabled
+	OnBattery    bool   `json:"on_battery"` // low-power settings in effect
+	IdleInterval string `json:"idle_interval"`
+	Debounce     string `json:"debounce"`
+}
+
+// watchMode holds the adjustable watching settings
+type watchMode struct {
+	mu           sync.RWMutex
+	lowPower     bool
+	onBattery    bool
+	idleInterval time.Duration
+}
+
+// WithLowPower lengthens idle-check intervals and debounces session log
+// reads while the machine runs on battery
+func WithLowPower(enabled bool) Option {
+	return func(s *Server) {
+		s.watch.lowPower = enabled
+	}
+}
+
+// idleInterval returns the current idle check interval
+func (s *Server) idleInterval() time.Duration {
+	s.watch.mu.RLock()
+	defer s.watch.mu.RUnlock()
+	return s.watch.idleInterval
+}
+
+// watchPaused reports whether watching is paused via the API
+func (s *Server) watchPaused() bool {
+	return s.jsonl.Paused()
+}
+
+// runPowerMonitor switches between normal and low-power settings as the
+// machine moves between AC and battery power
+func (s *Server) runPowerMonitor() {
+	ticker := time.NewTicker(powerCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		onBattery, err := power.OnBattery()
+		if err != nil {
+			logging.Logger().Warn("low-power mode disabled", "error", err)
+			return
+		}
+		s.setOnBattery(onBattery)
+
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setOnBattery applies low-power settings on battery and restores them on AC
+func (s *Server) setOnBattery(onBattery bool) {
+	s.watch.mu.Lock()
+	changed := s.watch.onBattery != onBattery
+	s.watch.onBattery = onBattery
+	if onBattery {
+		s.watch.idleInterval = lowPowerIdleInterval
+	} else {
+		s.watch.idleInterval = engine.IdleCheckInterval
+	}
+	s.watch.mu.Unlock()
+
+	if onBattery {
+		s.jsonl.SetDebounce(lowPowerDebounce)
+	} else {
+		s.jsonl.SetDebounce(source.DefaultDebounce)
+	}
+	if changed {
+		logging.Logger().Info("power source changed", "on_battery", onBattery)
+	}
+}
+
+func (s *Server) watchStatus() WatchStatus {
+	s.watch.mu.RLock()
+	defer s.watch.mu.RUnlock()
+
+	return WatchStatus{
+		Paused:       s.watchPaused(),
+		LowPower:     s.watch.lowPower,
+		OnBattery:    s.watch.onBattery,
+		IdleInterval: s.watch.idleInterval.String(),
+		Debounce:     s.jsonl.Debounce().String(),
+	}
+}
+
+// handleGetWatch returns the current watching mode
+func (s *Server) handleGetWatch(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.watchStatus())
+}
+
+// handlePauseWatch stops session log reads and idle checks until resumed
+func (s *Server) handlePauseWatch(c echo.Context) error {
+	s.jsonl.Pause()
+	logging.Logger().Info("watching paused", "via", "api")
+	return c.JSON(http.StatusOK, s.watchStatus())
+}
+
+// handleResumeWatch applies changes collected while paused and resumes watching
+func (s *Server) handleResumeWatch(c echo.Context) error {
+	s.jsonl.Resume()
+	logging.Logger().Info("watching resumed", "via", "api")
+	return c.JSON(http.StatusOK, s.watchStatus())
+}
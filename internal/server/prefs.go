@@ -0,0 +1,74 @@
+package server
+
+import (
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/prefs"
+	"github.com/sho7650/claude-watch-status/pkg/protocol"
+)
+
+// Keys of the runtime preferences kept in the prefs store
+const (
+	prefMutes         = "mutes"              // []protocol.Mute
+	prefNotifications = "notifications"      // NotificationPreferences
+	prefPause         = "notification_pause" // protocol.NotificationPause
+)
+
+// WithPrefs keeps mutes, browser notification preferences and the
+// notification pause set through the API in store, restoring them from
+// it now
+func WithPrefs(store *prefs.Store) Option {
+	return func(s *Server) {
+		s.prefs = store
+		s.loadPrefs()
+	}
+}
+
+// loadPrefs restores the runtime preferences, skipping expired mutes and
+// pauses
+func (s *Server) loadPrefs() {
+	var mutes []protocol.Mute
+	if _, err := s.prefs.Get(prefMutes, &mutes); err != nil {
+		logging.Logger().Warn("stored mutes ignored", "path", s.prefs.Path(), "error", err)
+	}
+	now := time.Now()
+	for _, m := range mutes {
+		if m.Until.IsZero() || m.Until.After(now) {
+			s.manager.Mute(m.Project, m.Until)
+		}
+	}
+
+	var notify NotificationPreferences
+	if ok, err := s.prefs.Get(prefNotifications, &notify); err != nil {
+		logging.Logger().Warn("stored notification preferences ignored", "path", s.prefs.Path(), "error", err)
+	} else if ok {
+		s.notifyPrefs.set(notify)
+	}
+
+	var pause protocol.NotificationPause
+	if _, err := s.prefs.Get(prefPause, &pause); err != nil {
+		logging.Logger().Warn("stored notification pause ignored", "path", s.prefs.Path(), "error", err)
+	} else if pause.Paused && (pause.Until.IsZero() || pause.Until.After(now)) {
+		s.pause.pause(pause.Until)
+	}
+}
+
+// savePref stores a runtime preference, if a prefs store is set. Failures
+// are logged: the change still applies until the daemon restarts.
+func (s *Server) savePref(key string, v any) {
+	if s.prefs == nil {
+		return
+	}
+	if err := s.prefs.Set(key, v); err != nil {
+		logging.Logger().Warn("preference not saved", "key", key, "path", s.prefs.Path(), "error", err)
+	}
+}
+
+// prefsPath returns the prefs store file, "" if not persisted
+func (s *Server) prefsPath() string {
+	if s.prefs == nil {
+		return ""
+	}
+	return s.prefs.Path()
+}
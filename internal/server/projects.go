@@ -0,0 +1,105 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/internal/focus"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// ProjectsResponse represents the API response for the project list
+type ProjectsResponse struct {
+	Projects []state.ProjectInfo `json:"projects"`
+}
+
+// SessionsResponse represents the API response for a project's sessions
+type SessionsResponse struct {
+	Project  string              `json:"project"`
+	Sessions []state.SessionInfo `json:"sessions"`
+}
+
+// handleGetProjects returns the metadata of all projects, hidden ones
+// with ?include_inactive=true
+func (s *Server) handleGetProjects(c echo.Context) error {
+	return c.JSON(http.StatusOK, ProjectsResponse{Projects: s.manager.Projects(includeInactive(c))})
+}
+
+// includeInactive reports whether a request asks for projects hidden
+// after a long time without activity
+func includeInactive(c echo.Context) bool {
+	include, _ := strconv.ParseBool(c.QueryParam("include_inactive"))
+	return include
+}
+
+// handleGetProject returns the metadata of one project
+func (s *Server) handleGetProject(c echo.Context) error {
+	name, err := url.PathUnescape(c.Param("name"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid project name"})
+	}
+	info := s.manager.Project(name)
+	if info == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "unknown project"})
+	}
+	return c.JSON(http.StatusOK, info)
+}
+
+// handleGetProjectSessions returns the sessions seen in one project
+func (s *Server) handleGetProjectSessions(c echo.Context) error {
+	name, err := url.PathUnescape(c.Param("name"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid project name"})
+	}
+	sessions := s.manager.Sessions(name)
+	if sessions == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "unknown project"})
+	}
+	return c.JSON(http.StatusOK, SessionsResponse{Project: name, Sessions: sessions})
+}
+
+// handleAcknowledgeProject marks a project's waiting, completed,
+// interrupted or error state as seen, clearing its highlight in every UI
+func (s *Server) handleAcknowledgeProject(c echo.Context) error {
+	name, err := url.PathUnescape(c.Param("name"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid project name"})
+	}
+	if s.manager.Get(name) == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "unknown project"})
+	}
+	status, ok := s.manager.Acknowledge(name)
+	if !ok {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "project state needs no acknowledgment"})
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// handleFocusProject raises the terminal window of a project's session on
+// the daemon's host, so a prompt waiting for approval can be answered
+func (s *Server) handleFocusProject(c echo.Context) error {
+	name, err := url.PathUnescape(c.Param("name"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid project name"})
+	}
+	status := s.manager.Get(name)
+	if status == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "unknown project"})
+	}
+	var terminal *state.Terminal
+	if status.Environment != nil {
+		terminal = status.Environment.Terminal
+	}
+	if err := focus.Raise(c.Request().Context(), terminal); err != nil {
+		if errors.Is(err, focus.ErrUnknownTerminal) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		logging.Logger().Warn("failed to raise terminal", "project", name, "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/logging"
+)
+
+// pruneInterval is how often projects past the retention TTL are deleted
+const pruneInterval = 10 * time.Minute
+
+// runPruner periodically forgets projects without activity for longer than
+// the configured retention
+func (s *Server) runPruner() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if names := s.manager.Prune(); len(names) > 0 {
+				logging.Logger().Info("pruned inactive projects", "projects", names)
+			}
+		}
+	}
+}
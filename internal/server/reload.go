@@ -0,0 +1,181 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+)
+
+// configReloadDelay lets an editor finish writing the config file before
+// it is reloaded
+const configReloadDelay = 500 * time.Millisecond
+
+// WithConfig reloads the configuration when its file changes and on
+// POST /api/config/reload
+func WithConfig(live *config.Live) Option {
+	return func(s *Server) {
+		s.config = live
+	}
+}
+
+// reloadConfig reloads the configuration, logging the outcome
+func (s *Server) reloadConfig(via string) (config.ReloadResult, error) {
+	result, err := s.config.Reload()
+	if err != nil {
+		logging.Logger().Warn("config not reloaded, keeping the current one", "via", via, "error", err)
+		return result, err
+	}
+	logging.Logger().Info("config reloaded", "via", via, "changed", result.Changed)
+	if len(result.RestartRequired) > 0 {
+		logging.Logger().Warn("config changes take effect after a restart", "sections", result.RestartRequired)
+	}
+	return result, nil
+}
+
+// runConfigWatcher reloads the configuration after its file changed.
+// The directory is watched, so files replaced by editors and symlinked
+// config files keep being followed. While the directory does not exist,
+// its nearest existing parent is watched until it is created.
+func (s *Server) runConfigWatcher() {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		logging.Logger().Warn("config file not watched", "error", err)
+		return
+	}
+	defer w.Close()
+	path := filepath.Clean(s.config.Path())
+	dir := filepath.Dir(path)
+	watched, err := watchNearest(w, dir)
+	if err != nil {
+		logging.Logger().Warn("config file not watched", "path", path, "error", err)
+		return
+	}
+	if watched != dir {
+		logging.Logger().Debug("config directory missing, watching its parent", "dir", dir, "watching", watched)
+	}
+
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-s.done:
+			return
+		case event, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			name := filepath.Clean(event.Name)
+			if name == path && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				reload = time.After(configReloadDelay)
+			}
+			// The directory on the way to the config file was created, or
+			// the watched one removed: watch the nearest existing one again
+			created := watched != dir && event.Op&fsnotify.Create != 0 && isParentDir(name, dir)
+			removed := name == watched && event.Op&(fsnotify.Remove|fsnotify.Rename) != 0
+			if created || removed {
+				w.Remove(watched)
+				if watched, err = watchNearest(w, dir); err != nil {
+					logging.Logger().Warn("config file no longer watched", "path", path, "error", err)
+					return
+				}
+				if _, err := os.Stat(path); created && watched == dir && err == nil {
+					reload = time.After(configReloadDelay)
+				}
+			}
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			logging.Logger().Warn("config watcher error", "error", err)
+		case <-reload:
+			reload = nil
+			s.reloadConfig("file")
+		}
+	}
+}
+
+// watchNearest adds a watch on dir, or on its nearest existing parent if
+// it does not exist, and returns the directory watched
+func watchNearest(w *fsnotify.Watcher, dir string) (string, error) {
+	for {
+		err := w.Add(dir)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return dir, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", err
+		}
+		dir = parent
+	}
+}
+
+// isParentDir reports whether parent is dir or one of its parents
+func isParentDir(parent, dir string) bool {
+	rel, err := filepath.Rel(parent, dir)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// handleReloadConfig reloads the configuration file and reports the
+// changed sections; an invalid file is rejected and the current
+// configuration kept
+func (s *Server) handleReloadConfig(c echo.Context) error {
+	if s.config == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "config reload is not available"})
+	}
+	result, err := s.reloadConfig("api")
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// handlePutConfig changes runtime settings: it writes them to the config
+// file, keeping its comments, and reloads it. Unknown or invalid settings
+// are rejected and the file left unchanged.
+func (s *Server) handlePutConfig(c echo.Context) error {
+	if s.config == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "config changes are not available"})
+	}
+	var update config.SettingsUpdate
+	dec := json.NewDecoder(c.Request().Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&update); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request: " + err.Error()})
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	path := s.config.Path()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		data, err = []byte(config.GenerateTemplate(config.DefaultConfig())), nil
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	updated, err := config.UpdateSettings(data, update)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+	// Checked before writing, so a rejected change leaves the file alone
+	if err := s.config.Check(updated); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+	if err := config.WriteFile(path, updated); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if _, err := s.reloadConfig("api"); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, s.effectiveConfig())
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/internal/transcript"
+)
+
+const (
+	// defaultSearchLimit is the number of matches returned without ?limit=
+	defaultSearchLimit = 100
+	// maxSearchLimit bounds ?limit=, since a search reads every session log
+	maxSearchLimit = 1000
+)
+
+// WithProjectNameFunc names the projects found by GET /api/search as
+// configured, fn mapping a project path to its name ("" to keep the name
+// derived from the log directory)
+func WithProjectNameFunc(fn func(dir string) string) Option {
+	return func(s *Server) {
+		s.nameFor = fn
+	}
+}
+
+// handleSearch searches the session logs for ?q=, optionally limited to
+// ?project= and to entries within ?since= (a duration such as 24h)
+func (s *Server) handleSearch(c echo.Context) error {
+	q := transcript.Query{
+		Text:    strings.TrimSpace(c.QueryParam("q")),
+		Project: c.QueryParam("project"),
+		Limit:   defaultSearchLimit,
+	}
+	if q.Text == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "missing query (q)"})
+	}
+	if v := c.QueryParam("since"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid since duration"})
+		}
+		q.Since = time.Now().Add(-d)
+	}
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxSearchLimit {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit (1-" + strconv.Itoa(maxSearchLimit) + ")"})
+		}
+		q.Limit = n
+	}
+
+	result, err := transcript.Search(s.info.ProjectsDir, s.nameFor, q)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, result)
+}
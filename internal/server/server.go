@@ -1,40 +1,153 @@
 package server
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/engine"
+	"github.com/sho7650/claude-watch-status/internal/notifier"
+	"github.com/sho7650/claude-watch-status/internal/prefs"
+	"github.com/sho7650/claude-watch-status/internal/shortcuts"
+	"github.com/sho7650/claude-watch-status/internal/source"
 	"github.com/sho7650/claude-watch-status/internal/state"
+	"github.com/sho7650/claude-watch-status/internal/stats"
+	"github.com/sho7650/claude-watch-status/pkg/protocol"
 )
 
 //go:embed static
 var staticFS embed.FS
 
+// shutdownTimeout bounds how long Stop waits for requests in flight
+const shutdownTimeout = 5 * time.Second
+
 // Server represents the HTTP server
 type Server struct {
-	echo    *echo.Echo
-	port    int
-	manager *state.Manager
+	echo      *echo.Echo
+	port      int
+	bindAddr  string
+	tlsCert   string
+	tlsKey    string
+	engine    *engine.Engine
+	manager   *state.Manager
+	hookToken string
+	apiToken  string
+	notifier  *notifier.Notifier
+	hooks     *source.HooksSource
+	jsonl     *source.JSONLSource
+	watch     watchMode
+
+	ctx      context.Context // done when the server stops
+	done     <-chan struct{} // ctx.Done()
+	stop     context.CancelFunc
+	tasks    sync.WaitGroup // background tasks started by Start
+	stopOnce sync.Once
+
+	notifyPrefs  *notifyPrefs
+	pause        *notifyPause
+	stats        *stats.Collector
+	hookLimits   *hookLimits
+	history      *stats.HistoryStore // nil = statistics are not persisted
+	prefs        *prefs.Store        // nil = runtime preferences are not persisted
+	unattended   *notifier.Notifier  // nil = no unattended permissions warnings
+	shortcuts    *shortcuts.Bridge   // nil = no macOS Shortcuts
+	sseKeepalive time.Duration       // 0 = no keepalive comments
+	info         DaemonInfo
+	config       *config.Live            // nil = the configuration is not reloaded
+	configMu     sync.Mutex              // serializes changes to the config file
+	nameFor      func(dir string) string // configured project names for search, may be nil
+	version      string
+}
+
+// Option is a function that modifies the server
+type Option func(*Server)
+
+// WithHookToken requires hook events to carry the shared-secret token
+func WithHookToken(token string) Option {
+	return func(s *Server) {
+		s.hookToken = token
+	}
+}
+
+// WithAPIToken requires bearer-token authentication on the read API
+func WithAPIToken(token string) Option {
+	return func(s *Server) {
+		s.apiToken = token
+	}
+}
+
+// WithBindAddress sets the interface address to listen on (empty = all interfaces)
+func WithBindAddress(addr string) Option {
+	return func(s *Server) {
+		s.bindAddr = addr
+	}
+}
+
+// WithTLS serves HTTPS using the given certificate and key files
+func WithTLS(certFile, keyFile string) Option {
+	return func(s *Server) {
+		s.tlsCert = certFile
+		s.tlsKey = keyFile
+	}
+}
+
+// WithHooksSource delivers received hook events through src.
+// By default the server creates its own source feeding the manager.
+func WithHooksSource(src *source.HooksSource) Option {
+	return func(s *Server) {
+		s.hooks = src
+	}
 }
 
-// New creates a new Server
-func New(port int, manager *state.Manager) *Server {
+// New creates a new Server showing the state of eng
+func New(port int, eng *engine.Engine, opts ...Option) *Server {
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
 
 	// Middleware
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	e.Use(sameOrigin)
 
 	s := &Server{
 		echo:    e,
 		port:    port,
-		manager: manager,
+		engine:  eng,
+		manager: eng.Manager(),
+		jsonl:   eng.JSONL(),
+
+		notifyPrefs:  newNotifyPrefs(),
+		pause:        newNotifyPause(),
+		stats:        stats.NewCollector(),
+		hookLimits:   newHookLimits(),
+		sseKeepalive: defaultSSEKeepalive,
+	}
+	s.ctx, s.stop = context.WithCancel(context.Background())
+	s.done = s.ctx.Done()
+	s.watch.idleInterval = engine.IdleCheckInterval
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.hooks == nil {
+		s.hooks = source.NewHooks()
+		s.hooks.Start(s.manager)
+	}
+	eng.SetSilenceFunc(s.silenced)
+	eng.SetIdleIntervalFunc(s.idleInterval)
+	s.manager.SetToolRunFunc(s.stats.RecordToolRun)
+	if s.notifier != nil {
+		eng.SetNotifier(s.notifier)
 	}
 
 	s.setupRoutes()
@@ -44,13 +157,52 @@ func New(port int, manager *state.Manager) *Server {
 func (s *Server) setupRoutes() {
 	// API routes
 	api := s.echo.Group("/api")
-	api.GET("/status", s.handleGetStatus)
-	api.GET("/status/stream", s.handleSSE)
-	api.POST("/hooks", s.handleHooksEvent)
+	// Reading transcripts and changing the daemon need more than the read
+	// API: see requireTrusted
+	trusted := []echo.MiddlewareFunc{s.requireAPIToken, s.requireTrusted}
+	api.GET("/status", s.handleGetStatus, s.requireAPIToken)
+	api.GET("/status/stream", s.handleSSE, s.requireAPIToken)
+	api.GET("/projects", s.handleGetProjects, s.requireAPIToken)
+	api.GET("/projects/:name", s.handleGetProject, s.requireAPIToken)
+	api.GET("/projects/:name/sessions", s.handleGetProjectSessions, s.requireAPIToken)
+	api.GET("/projects/:name/trace", s.handleProjectTrace, trusted...)
+	api.GET("/sessions/:id/transitions", s.handleGetSessionTransitions, s.requireAPIToken)
+	api.GET("/search", s.handleSearch, trusted...)
+	api.POST("/projects/:name/ack", s.handleAcknowledgeProject, trusted...)
+	api.POST("/projects/:name/focus", s.handleFocusProject, trusted...)
+	api.POST("/projects/:name/mute", s.handleMuteProject, trusted...)
+	api.DELETE("/projects/:name/mute", s.handleUnmuteProject, trusted...)
+	api.GET("/mutes", s.handleGetMutes, s.requireAPIToken)
+	api.POST("/hooks", s.handleHooksEvent, s.requireHookToken, s.limitHookBody)
+	api.GET("/stats", s.handleGetStats, s.requireAPIToken)
+	api.GET("/notifications", s.handleGetNotificationPrefs, s.requireAPIToken)
+	api.PUT("/notifications", s.handlePutNotificationPrefs, trusted...)
+	api.GET("/notifications/pause", s.handleGetNotificationPause, s.requireAPIToken)
+	api.POST("/notifications/pause", s.handlePauseNotifications, trusted...)
+	api.POST("/notifications/resume", s.handleResumeNotifications, trusted...)
+	api.GET("/loglevel", s.handleGetLogLevel, s.requireAPIToken)
+	api.POST("/loglevel", s.handleSetLogLevel, trusted...)
+	api.GET("/config", s.handleGetConfig, s.requireAPIToken)
+	api.PUT("/config", s.handlePutConfig, trusted...)
+	api.POST("/config/reload", s.handleReloadConfig, trusted...)
+	api.GET("/watch", s.handleGetWatch, s.requireAPIToken)
+	api.POST("/watch/pause", s.handlePauseWatch, trusted...)
+	api.POST("/watch/resume", s.handleResumeWatch, trusted...)
+
+	// Badges for embedding in READMEs and dashboards
+	s.echo.GET("/badge/summary.svg", s.handleSummaryBadgeSVG, s.requireAPIToken)
+	s.echo.GET("/badge/summary.json", s.handleSummaryBadgeJSON, s.requireAPIToken)
 
 	// Health check
 	s.echo.GET("/health", s.handleHealth)
 
+	// Published schema of the SSE event envelope and the API description
+	s.echo.GET("/schema/"+protocol.SchemaV1+".json", s.handleEventSchema)
+	api.GET("/openapi.json", s.handleOpenAPI)
+
+	// Web UI messages, which hold nothing about sessions
+	api.GET("/i18n", s.handleI18n)
+
 	// Static files (Web UI)
 	staticContent, err := fs.Sub(staticFS, "static")
 	if err == nil {
@@ -58,16 +210,104 @@ func (s *Server) setupRoutes() {
 	}
 }
 
-// Start starts the HTTP server
+// Run serves until ctx is done or the HTTP server fails, then stops the
+// server; see Stop. It returns nil after a stop through ctx.
+func (s *Server) Run(ctx context.Context) error {
+	// Buffered, so the goroutine ends even when ctx is done first
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Start()
+	}()
+
+	var err error
+	select {
+	case err = <-served:
+	case <-ctx.Done():
+	}
+	if stopErr := s.Stop(); err == nil || errors.Is(err, http.ErrServerClosed) {
+		err = stopErr
+	}
+	return err
+}
+
+// Start starts the HTTP server, and the engine's idle checker and
+// notifications
 func (s *Server) Start() error {
-	addr := fmt.Sprintf(":%d", s.port)
-	fmt.Printf("Starting server on http://127.0.0.1%s\n", addr)
+	s.logEffectiveConfig()
+
+	s.engine.Start()
+	s.background(s.runPruner)
+	if s.config != nil {
+		s.background(s.runConfigWatcher)
+	}
+	if s.history != nil {
+		s.restoreStats()
+		s.background(s.runHistory)
+	}
+	s.background(s.runStats)
+	if s.unattended != nil {
+		s.background(s.runUnattendedWarnings)
+	}
+	if s.shortcuts != nil {
+		s.background(s.runShortcuts)
+	}
+	if s.watch.lowPower {
+		s.background(s.runPowerMonitor)
+	}
+
+	addr := net.JoinHostPort(s.bindAddr, strconv.Itoa(s.port))
+
+	host := s.bindAddr
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	url := "http://" + net.JoinHostPort(host, strconv.Itoa(s.port))
+
+	if s.tlsCert != "" || s.tlsKey != "" {
+		if s.tlsCert == "" || s.tlsKey == "" {
+			return fmt.Errorf("both TLS certificate and key are required")
+		}
+		fmt.Printf("Starting server on https%s\n", strings.TrimPrefix(url, "http"))
+		return s.echo.StartTLS(addr, s.tlsCert, s.tlsKey)
+	}
+
+	fmt.Printf("Starting server on %s\n", url)
 	return s.echo.Start(addr)
 }
 
-// Stop gracefully stops the server
+// background runs a task until the server stops; Stop waits for it
+func (s *Server) background(task func()) {
+	s.tasks.Add(1)
+	go func() {
+		defer s.tasks.Done()
+		task()
+	}()
+}
+
+// runStats feeds status events into the statistics collector
+func (s *Server) runStats() {
+	s.stats.Run(s.manager.SubscribeContext(s.ctx), s.done)
+}
+
+// Stop gracefully stops the server, in order: background tasks and event
+// streams end, requests in flight get shutdownTimeout to finish, and
+// today's statistics are saved once nothing records them anymore. Later
+// calls do nothing.
 func (s *Server) Stop() error {
-	return s.echo.Close()
+	var err error
+	s.stopOnce.Do(func() {
+		s.stop()
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err = s.echo.Shutdown(ctx); err != nil {
+			err = s.echo.Close()
+		}
+		s.tasks.Wait()
+		if s.history != nil {
+			s.saveHistory()
+		}
+	})
+	return err
 }
 
 // GetManager returns the state manager
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/engine"
+)
+
+// startServer runs a server on a free loopback port until the returned
+// stop function is called, which returns the error of Run
+func startServer(t *testing.T) (*Server, string, func() error) {
+	t.Helper()
+	eng := engine.New(t.TempDir())
+	t.Cleanup(func() { eng.Stop() })
+	s := New(0, eng, WithBindAddress("127.0.0.1"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := make(chan error, 1)
+	go func() { ran <- s.Run(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for s.echo.ListenerAddr() == nil {
+		if time.Now().After(deadline) {
+			cancel()
+			t.Fatal("server did not start listening")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return s, "http://" + s.echo.ListenerAddr().String(), func() error {
+		cancel()
+		select {
+		case err := <-ran:
+			return err
+		case <-time.After(shutdownTimeout + 5*time.Second):
+			t.Fatal("Run did not return after its context was done")
+			return nil
+		}
+	}
+}
+
+// Stopping ends event streams before the HTTP server waits for requests
+// in flight, so an open stream does not hold up the shutdown
+func TestRunStopsEventStreamsFirst(t *testing.T) {
+	s, url, stop := startServer(t)
+
+	resp, err := http.Get(url + "/api/status/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if _, err := bufio.NewReader(resp.Body).ReadString('\n'); err != nil {
+		t.Fatalf("reading the stream: %v", err)
+	}
+
+	start := time.Now()
+	if err := stop(); err != nil {
+		t.Errorf("Run() = %v, want nil after a stop through its context", err)
+	}
+	if elapsed := time.Since(start); elapsed >= shutdownTimeout {
+		t.Errorf("stopping took %s, the stream held up the shutdown", elapsed)
+	}
+	if _, err := io.ReadAll(resp.Body); err != nil && err != io.ErrUnexpectedEOF {
+		t.Errorf("stream did not end cleanly: %v", err)
+	}
+
+	// Background tasks have ended; later calls do nothing
+	s.tasks.Wait()
+	if err := s.Stop(); err != nil {
+		t.Errorf("second Stop() = %v", err)
+	}
+}
+
+// Subscriptions tied to the server end when it stops, and closing the
+// manager afterwards, as serve does, does not close them twice
+func TestStopEndsSubscriptionsBeforeManagerClose(t *testing.T) {
+	s, _, stop := startServer(t)
+	events := s.manager.SubscribeContext(s.ctx)
+
+	if err := stop(); err != nil {
+		t.Errorf("Run() = %v", err)
+	}
+	timeout := time.After(5 * time.Second)
+	for open := true; open; {
+		select {
+		case _, open = <-events:
+		case <-timeout:
+			t.Fatal("subscription not closed by Stop")
+		}
+	}
+	s.manager.Close()
+}
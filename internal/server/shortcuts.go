@@ -0,0 +1,23 @@
+package server
+
+import (
+	"github.com/sho7650/claude-watch-status/internal/shortcuts"
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// WithShortcuts runs macOS Shortcuts on status transitions
+func WithShortcuts(b *shortcuts.Bridge) Option {
+	return func(s *Server) {
+		s.shortcuts = b
+	}
+}
+
+// runShortcuts fires the configured Shortcuts when a project enters a new
+// state. Muted projects fire none.
+func (s *Server) runShortcuts() {
+	state.ForEachTransition(s.done, s.manager.SubscribeContext(s.ctx), func(project state.ProjectStatus) {
+		if !s.silenced(project.Name, state.PhaseOf(project.State)) {
+			s.shortcuts.Fire(project)
+		}
+	})
+}
@@ -7,38 +7,569 @@ class ClaudeWatchStatus {
         this.reconnectAttempts = 0;
         this.maxReconnectAttempts = 10;
         this.reconnectDelay = 1000;
+        this.reconnectTimer = null;
+        this.lastEventId = null;
+        this.token = this.loadToken();
+        this.messages = {};
+        // Permission modes with a badge of their own, by their message key
+        this.permissionModes = new Map([['plan', 'plan'], ['acceptEdits', 'accept_edits']]);
 
-        this.init();
+        this.loadMessages().then(() => this.init());
     }
 
     init() {
+        this.setupDisplaySettings();
+        this.setupNotifications();
+        this.setupNotificationPause();
+        this.setupKeyboardNavigation();
+        this.setupAcknowledge();
+        this.setupFocus();
+        this.setupSearch();
+        this.setupDaemonSettings();
+        this.setupElapsedTimers();
+        this.setupLifecycle();
+        this.registerServiceWorker();
         this.connectSSE();
     }
 
+    // Messages come from the daemon in its configured language; without
+    // them the page keeps its English text
+    async loadMessages() {
+        try {
+            const response = await fetch('/api/i18n');
+            if (!response.ok) return;
+            const result = await response.json();
+            this.messages = result.messages;
+            document.documentElement.lang = result.language;
+            this.translatePage();
+        } catch (err) {
+            console.warn('Loading messages failed:', err);
+        }
+    }
+
+    translatePage() {
+        document.querySelectorAll('[data-i18n]').forEach(el => {
+            el.textContent = this.t(el.dataset.i18n);
+        });
+        document.querySelectorAll('[data-i18n-title]').forEach(el => {
+            el.title = this.t(el.dataset.i18nTitle);
+        });
+        document.querySelectorAll('[data-i18n-placeholder]').forEach(el => {
+            el.placeholder = this.t(el.dataset.i18nPlaceholder);
+        });
+        document.querySelectorAll('[data-i18n-label]').forEach(el => {
+            el.setAttribute('aria-label', this.t(el.dataset.i18nLabel));
+        });
+        document.title = this.t('web.title');
+    }
+
+    // t returns a message with each %s replaced by the next argument
+    t(key, ...args) {
+        const message = this.messages[key] ?? key;
+        let i = 0;
+        return message.replace(/%s/g, () => String(args[i++] ?? ''));
+    }
+
+    // State names are translated for display only; state classes are
+    // derived from the English state
+    stateLabel(state) {
+        if (state.startsWith('running: ')) return this.t('state.running_tool', state.slice('running: '.length));
+        return this.messages['state.' + state] ?? state;
+    }
+
+    // The token from ?token= is remembered, so the installed app (which
+    // starts at "/") can still authenticate
+    loadToken() {
+        const token = new URLSearchParams(window.location.search).get('token');
+        if (token) {
+            localStorage.setItem('cws.token', token);
+            return token;
+        }
+        return localStorage.getItem('cws.token');
+    }
+
+    // Mobile browsers drop the SSE connection while the page is in the
+    // background; reconnect as soon as it is visible or back online
+    // instead of waiting out the backoff
+    setupLifecycle() {
+        const resume = () => {
+            if (document.visibilityState !== 'visible') return;
+            if (this.eventSource && this.eventSource.readyState === EventSource.OPEN) return;
+            this.reconnectNow();
+        };
+        document.addEventListener('visibilitychange', resume);
+        window.addEventListener('pageshow', resume);
+        window.addEventListener('online', resume);
+    }
+
+    // Running tools show how long they have run; count up between events
+    setupElapsedTimers() {
+        setInterval(() => {
+            document.querySelectorAll('.project-elapsed').forEach(el => {
+                el.textContent = this.formatElapsed(el.dataset.since);
+            });
+        }, 1000);
+    }
+
+    reconnectNow() {
+        clearTimeout(this.reconnectTimer);
+        this.reconnectTimer = null;
+        this.reconnectAttempts = 0;
+        if (this.eventSource) this.eventSource.close();
+        this.connectSSE();
+    }
+
+    registerServiceWorker() {
+        if (!('serviceWorker' in navigator)) return;
+        navigator.serviceWorker.register('/sw.js').catch(err => {
+            console.warn('Service worker registration failed:', err);
+        });
+    }
+
+    // Display settings are stored locally; without a stored choice they
+    // follow the system contrast and motion preferences
+    setupDisplaySettings() {
+        this.settings = {
+            contrast: this.loadSetting('cws.contrast', '(prefers-contrast: more)', 'high', 'normal'),
+            motion: this.loadSetting('cws.motion', '(prefers-reduced-motion: reduce)', 'reduced', 'full')
+        };
+
+        this.contrastToggle = document.getElementById('contrastToggle');
+        this.motionToggle = document.getElementById('motionToggle');
+        this.contrastToggle.addEventListener('click', () => {
+            this.updateSetting('contrast', this.settings.contrast === 'high' ? 'normal' : 'high');
+        });
+        this.motionToggle.addEventListener('click', () => {
+            this.updateSetting('motion', this.settings.motion === 'reduced' ? 'full' : 'reduced');
+        });
+        this.applyDisplaySettings();
+    }
+
+    loadSetting(key, mediaQuery, onValue, offValue) {
+        const stored = localStorage.getItem(key);
+        if (stored === onValue || stored === offValue) return stored;
+        return window.matchMedia && window.matchMedia(mediaQuery).matches ? onValue : offValue;
+    }
+
+    updateSetting(name, value) {
+        this.settings[name] = value;
+        localStorage.setItem('cws.' + name, value);
+        this.applyDisplaySettings();
+    }
+
+    applyDisplaySettings() {
+        const root = document.documentElement;
+        root.dataset.contrast = this.settings.contrast;
+        root.dataset.motion = this.settings.motion;
+
+        const highContrast = this.settings.contrast === 'high';
+        this.contrastToggle.setAttribute('aria-pressed', String(highContrast));
+        this.contrastToggle.title = this.t(highContrast ? 'web.contrast_on' : 'web.contrast_off');
+
+        const reducedMotion = this.settings.motion === 'reduced';
+        this.motionToggle.setAttribute('aria-pressed', String(reducedMotion));
+        this.motionToggle.title = this.t(reducedMotion ? 'web.motion_on' : 'web.motion_off');
+    }
+
+    // Arrow keys, Home and End move focus between project cards. Only the
+    // focused card is in the tab order (roving tabindex).
+    setupKeyboardNavigation() {
+        this.focusedProject = null;
+        const container = document.getElementById('projects');
+
+        container.addEventListener('keydown', (event) => {
+            const cards = Array.from(container.querySelectorAll('.project-card'));
+            const index = cards.indexOf(document.activeElement);
+            if (index === -1) return;
+
+            let next;
+            switch (event.key) {
+                case 'ArrowDown':
+                case 'ArrowRight':
+                    next = Math.min(index + 1, cards.length - 1);
+                    break;
+                case 'ArrowUp':
+                case 'ArrowLeft':
+                    next = Math.max(index - 1, 0);
+                    break;
+                case 'Home':
+                    next = 0;
+                    break;
+                case 'End':
+                    next = cards.length - 1;
+                    break;
+                default:
+                    return;
+            }
+            event.preventDefault();
+            this.focusCard(cards[next]);
+        });
+
+        container.addEventListener('focusin', (event) => {
+            const card = event.target.closest('.project-card');
+            if (card) this.focusedProject = card.dataset.name;
+        });
+    }
+
+    focusCard(card) {
+        document.querySelectorAll('.project-card[tabindex="0"]').forEach(el => {
+            el.tabIndex = -1;
+        });
+        card.tabIndex = 0;
+        card.focus();
+    }
+
+    // Screen reader announcements; states needing attention interrupt
+    announce(message, urgent) {
+        const region = document.getElementById(urgent ? 'announceAssertive' : 'announcePolite');
+        // Clearing first makes a repeated message announce again
+        region.textContent = '';
+        setTimeout(() => {
+            region.textContent = message;
+        }, 50);
+    }
+
+    setupNotifications() {
+        this.notifyToggle = document.getElementById('notifyToggle');
+        this.notificationsEnabled = 'Notification' in window &&
+            Notification.permission === 'granted' &&
+            localStorage.getItem('cws.notifications') === 'on';
+
+        if (!('Notification' in window)) {
+            this.notifyToggle.hidden = true;
+            return;
+        }
+
+        this.notifyToggle.addEventListener('click', () => this.toggleNotifications());
+        this.updateNotifyToggle();
+    }
+
+    async toggleNotifications() {
+        if (this.notificationsEnabled) {
+            this.notificationsEnabled = false;
+        } else {
+            const permission = await Notification.requestPermission();
+            this.notificationsEnabled = permission === 'granted';
+        }
+        localStorage.setItem('cws.notifications', this.notificationsEnabled ? 'on' : 'off');
+        this.updateNotifyToggle();
+    }
+
+    updateNotifyToggle() {
+        this.notifyToggle.textContent = this.notificationsEnabled ? '🔔' : '🔕';
+        this.notifyToggle.setAttribute('aria-pressed', String(this.notificationsEnabled));
+        this.notifyToggle.title = this.t(this.notificationsEnabled
+            ? 'web.notifications_on'
+            : 'web.notifications_off');
+    }
+
+    // The pause is shared by every UI: the daemon reports it on connect
+    // and whenever it changes
+    setupNotificationPause() {
+        this.pause = { paused: false };
+        this.pauseToggle = document.getElementById('pauseToggle');
+        this.pauseToggle.addEventListener('click', () => {
+            const path = this.pause.paused
+                ? '/api/notifications/resume'
+                : '/api/notifications/pause';
+            const url = this.apiUrl(path);
+            this.post(this.pause.paused ? url : url + (url.includes('?') ? '&' : '?') + 'duration=1h');
+        });
+        this.updatePauseToggle();
+    }
+
+    handleNotificationPause(pause) {
+        this.pause = pause;
+        this.updatePauseToggle();
+    }
+
+    updatePauseToggle() {
+        const paused = this.pause.paused;
+        this.pauseToggle.textContent = paused ? '▶' : '⏸';
+        this.pauseToggle.setAttribute('aria-pressed', String(paused));
+        if (!paused) {
+            this.pauseToggle.title = this.t('web.pause');
+        } else if (this.pause.until) {
+            this.pauseToggle.title = this.t('web.paused_until', this.formatTime(this.pause.until));
+        } else {
+            this.pauseToggle.title = this.t('web.paused');
+        }
+    }
+
+    // Acknowledging a waiting, completed or failed project clears its
+    // highlight in every UI until its state changes
+    setupAcknowledge() {
+        const container = document.getElementById('projects');
+        container.addEventListener('click', (event) => {
+            const button = event.target.closest('.ack-button');
+            if (!button) return;
+            this.acknowledge(button.closest('.project-card').dataset.name);
+        });
+        container.addEventListener('keydown', (event) => {
+            if (event.key !== 'a' || event.ctrlKey || event.metaKey || event.altKey) return;
+            const card = event.target.closest('.project-card');
+            if (!card || !card.querySelector('.ack-button')) return;
+            event.preventDefault();
+            this.acknowledge(card.dataset.name);
+        });
+    }
+
+    acknowledge(name) {
+        this.post(this.apiUrl(`/api/projects/${encodeURIComponent(name)}/ack`));
+    }
+
+    // A project waiting for approval whose terminal the hook relay reported
+    // can be answered from here: the daemon raises the session's terminal
+    setupFocus() {
+        const container = document.getElementById('projects');
+        container.addEventListener('click', (event) => {
+            const button = event.target.closest('.focus-button');
+            if (!button) return;
+            this.focusTerminal(button.closest('.project-card').dataset.name, button);
+        });
+        container.addEventListener('keydown', (event) => {
+            if (event.key !== 'f' || event.ctrlKey || event.metaKey || event.altKey) return;
+            const card = event.target.closest('.project-card');
+            const button = card && card.querySelector('.focus-button');
+            if (!button) return;
+            event.preventDefault();
+            this.focusTerminal(card.dataset.name, button);
+        });
+    }
+
+    async focusTerminal(name, button) {
+        try {
+            const response = await fetch(this.apiUrl(`/api/projects/${encodeURIComponent(name)}/focus`), { method: 'POST' });
+            if (!response.ok) {
+                const body = await response.json().catch(() => ({}));
+                throw new Error(body.error || response.statusText);
+            }
+        } catch (err) {
+            button.classList.add('failed');
+            button.title = this.t('web.focus_failed', err.message);
+        }
+    }
+
+    // Search runs on the daemon over the session logs; matches are shown
+    // newest session first with their project, session and time
+    setupSearch() {
+        this.searchStatus = document.getElementById('searchStatus');
+        this.searchResults = document.getElementById('searchResults');
+        document.getElementById('searchForm').addEventListener('submit', (event) => {
+            event.preventDefault();
+            this.search(
+                document.getElementById('searchQuery').value.trim(),
+                document.getElementById('searchSince').value
+            );
+        });
+    }
+
+    async search(query, since) {
+        if (!query) return;
+        const params = new URLSearchParams({ q: query });
+        if (since) params.set('since', since);
+        const url = this.apiUrl('/api/search');
+
+        this.searchStatus.textContent = this.t('web.searching');
+        this.searchResults.innerHTML = '';
+        try {
+            const response = await fetch(url + (url.includes('?') ? '&' : '?') + params);
+            const result = await response.json();
+            if (!response.ok) throw new Error(result.error || response.statusText);
+            this.renderSearchResults(result, query);
+        } catch (err) {
+            this.searchStatus.textContent = this.t('web.search_failed', err.message);
+        }
+    }
+
+    renderSearchResults(result, query) {
+        const count = result.matches.length;
+        this.searchStatus.textContent = count === 0
+            ? this.t('web.no_matches')
+            : this.t(count === 1 ? 'web.match' : 'web.matches', `${count}${result.truncated ? '+' : ''}`);
+        this.searchResults.innerHTML = result.matches.map(m => {
+            let label = this.t(m.role === 'user' ? 'web.role_user' : 'web.role_claude');
+            if (m.type === 'tool_use') label = this.t('web.role_tool');
+            if (m.type === 'tool_result') label = m.tool ? this.t('web.tool_result', m.tool) : this.t('web.result');
+            return `
+                <li class="search-result">
+                    <div class="search-meta">
+                        <span class="search-project">${this.escapeHtml(m.project)}</span>
+                        <span class="search-session">${this.escapeHtml(m.session_id.slice(0, 8))}</span>
+                        <time datetime="${this.escapeHtml(m.time)}">${new Date(m.time).toLocaleString()}</time>
+                        <span class="search-label ${this.escapeHtml(m.type)}">${this.escapeHtml(label + (m.subagent ? this.t('web.subagent_match') : ''))}</span>
+                    </div>
+                    <p class="search-snippet">${this.highlight(m.snippet, query)}</p>
+                </li>
+            `;
+        }).join('');
+    }
+
+    // highlight escapes text and marks every case-insensitive occurrence
+    // of query
+    highlight(text, query) {
+        const lower = text.toLowerCase();
+        const q = query.toLowerCase();
+        let html = '';
+        let pos = 0;
+        for (let i = lower.indexOf(q); i !== -1 && q; i = lower.indexOf(q, pos)) {
+            html += this.escapeHtml(text.slice(pos, i)) + '<mark>' + this.escapeHtml(text.slice(i, i + q.length)) + '</mark>';
+            pos = i + q.length;
+        }
+        return html + this.escapeHtml(text.slice(pos));
+    }
+
+    // Daemon settings are written to its config file, so they persist
+    // across restarts. The section stays hidden when the daemon cannot
+    // change its configuration.
+    setupDaemonSettings() {
+        this.settingsStatus = document.getElementById('settingsStatus');
+        document.getElementById('settingsForm').addEventListener('submit', (event) => {
+            event.preventDefault();
+            this.saveDaemonSettings();
+        });
+        this.loadDaemonSettings();
+    }
+
+    async loadDaemonSettings() {
+        try {
+            const response = await fetch(this.apiUrl('/api/config'));
+            if (!response.ok) return;
+            const config = await response.json();
+            if (!config.settings) return;
+            this.renderDaemonSettings(config.settings);
+            document.getElementById('settingsSection').hidden = false;
+        } catch (err) {
+            console.warn('Loading daemon settings failed:', err);
+        }
+    }
+
+    renderDaemonSettings(settings) {
+        document.getElementById('settingDesktop').checked = settings.desktop;
+        document.getElementById('settingDaemonHealth').checked = settings.daemon_health;
+        document.getElementById('settingUnattended').checked = settings.unattended_permissions;
+        document.getElementById('settingIdle').value = settings.idle_completed_after;
+        document.getElementById('settingMuted').value = settings.muted_projects.join('\n');
+    }
+
+    async saveDaemonSettings() {
+        const update = {
+            desktop: document.getElementById('settingDesktop').checked,
+            daemon_health: document.getElementById('settingDaemonHealth').checked,
+            unattended_permissions: document.getElementById('settingUnattended').checked,
+            idle_completed_after: document.getElementById('settingIdle').value.trim(),
+            muted_projects: document.getElementById('settingMuted').value
+                .split('\n').map(name => name.trim()).filter(name => name)
+        };
+
+        this.settingsStatus.textContent = this.t('web.saving');
+        try {
+            const response = await fetch(this.apiUrl('/api/config'), {
+                method: 'PUT',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(update)
+            });
+            const result = await response.json();
+            if (!response.ok) throw new Error(result.error || response.statusText);
+            this.renderDaemonSettings(result.settings);
+            this.settingsStatus.textContent = this.t('web.saved');
+        } catch (err) {
+            this.settingsStatus.textContent = this.t('web.not_saved', err.message);
+        }
+    }
+
+    post(url) {
+        fetch(url, { method: 'POST' }).catch(err => {
+            console.warn('Request failed:', err);
+        });
+    }
+
+    needsAcknowledgment(project) {
+        const stateClass = this.getStateClass(project.state);
+        return !project.acknowledged && ['waiting', 'completed', 'interrupted', 'error'].includes(stateClass);
+    }
+
+    showNotification(project) {
+        if (!this.notificationsEnabled) return;
+        new Notification('Claude Code', {
+            body: project.request
+                ? this.t('notify.waiting_request', project.name, project.request)
+                : this.t('notify.state', project.name, this.stateLabel(project.state)),
+            tag: `cws-${project.name}`
+        });
+    }
+
     connectSSE() {
         this.updateConnectionStatus('connecting');
 
-        this.eventSource = new EventSource('/api/status/stream');
+        // After a disconnect, resume from the last event: the daemon sends
+        // the projects that changed meanwhile instead of a full init
+        this.eventSource = new EventSource(this.streamUrl());
 
-        this.eventSource.addEventListener('init', (event) => {
-            const data = JSON.parse(event.data);
-            this.handleInit(data);
+        this.eventSource.onopen = () => {
             this.reconnectAttempts = 0;
             this.updateConnectionStatus('connected');
+        };
+
+        this.eventSource.addEventListener('init', (event) => {
+            const data = this.unwrapEvent(event);
+            if (!data) return;
+            this.lastEventId = event.lastEventId;
+            this.handleInit(data);
         });
 
         this.eventSource.addEventListener('update', (event) => {
-            const project = JSON.parse(event.data);
+            const project = this.unwrapEvent(event);
+            if (!project) return;
+            this.lastEventId = event.lastEventId;
             this.handleUpdate(project);
         });
 
+        this.eventSource.addEventListener('session_removed', (event) => {
+            const removed = this.unwrapEvent(event);
+            if (!removed) return;
+            this.lastEventId = event.lastEventId;
+            this.handleSessionRemoved(removed);
+        });
+
+        // Not numbered: leaves lastEventId alone
+        this.eventSource.addEventListener('notifications', (event) => {
+            const pause = this.unwrapEvent(event);
+            if (!pause) return;
+            this.handleNotificationPause(pause);
+        });
+
         this.eventSource.onerror = () => {
             this.eventSource.close();
             this.updateConnectionStatus('disconnected');
-            this.scheduleReconnect();
+            // Retry from the foreground handler while hidden
+            if (document.visibilityState === 'visible') {
+                this.scheduleReconnect();
+            }
         };
     }
 
+    // Events are wrapped in a versioned envelope; see /schema/cws.event.v1.json
+    unwrapEvent(event) {
+        const envelope = JSON.parse(event.data);
+        if (envelope.schema !== 'cws.event.v1') {
+            console.warn('Unsupported event schema:', envelope.schema);
+            return null;
+        }
+        return envelope.data;
+    }
+
+    streamUrl() {
+        const url = this.apiUrl('/api/status/stream');
+        if (!this.lastEventId) return url;
+        return url + (url.includes('?') ? '&' : '?') + 'last_event_id=' + encodeURIComponent(this.lastEventId);
+    }
+
+    apiUrl(path) {
+        if (!this.token) return path;
+        return path + '?token=' + encodeURIComponent(this.token);
+    }
+
     scheduleReconnect() {
         if (this.reconnectAttempts >= this.maxReconnectAttempts) {
             console.error('Max reconnection attempts reached');
@@ -48,7 +579,8 @@ class ClaudeWatchStatus {
         const delay = this.reconnectDelay * Math.pow(2, this.reconnectAttempts);
         this.reconnectAttempts++;
 
-        setTimeout(() => {
+        this.reconnectTimer = setTimeout(() => {
+            this.reconnectTimer = null;
             this.connectSSE();
         }, delay);
     }
@@ -61,15 +593,16 @@ class ClaudeWatchStatus {
 
         switch (status) {
             case 'connecting':
-                textEl.textContent = 'Connecting...';
+                textEl.textContent = this.t('web.connecting');
                 break;
             case 'connected':
-                textEl.textContent = 'Connected';
+                textEl.textContent = this.t('web.connected');
                 break;
             case 'disconnected':
-                textEl.textContent = 'Disconnected - Reconnecting...';
+                textEl.textContent = this.t('web.disconnected');
                 break;
         }
+        statusEl.querySelector('.status-dot').setAttribute('aria-hidden', 'true');
     }
 
     handleInit(data) {
@@ -85,8 +618,28 @@ class ClaudeWatchStatus {
     }
 
     handleUpdate(project) {
+        // Drop updates that arrive out of order
+        const current = this.projects.get(project.name);
+        if (current && project.seq <= current.seq) return;
+
         this.projects.set(project.name, project);
         this.render();
+
+        if (!current || current.state !== project.state) {
+            const stateClass = this.getStateClass(project.state);
+            const urgent = stateClass === 'waiting' || stateClass === 'error' || stateClass === 'interrupted';
+            this.announce(this.t('notify.state', project.name, this.stateLabel(project.state)), urgent);
+        }
+
+        if (project.notify) {
+            this.showNotification(project);
+        }
+    }
+
+    // Projects go away with the session logs of their last session
+    handleSessionRemoved(removed) {
+        if (!removed.project_removed || !this.projects.delete(removed.project)) return;
+        this.render();
     }
 
     render() {
@@ -95,8 +648,8 @@ class ClaudeWatchStatus {
         if (this.projects.size === 0) {
             container.innerHTML = `
                 <div class="empty-state">
-                    <p>No active projects</p>
-                    <p class="hint">Start a Claude Code session to see status updates</p>
+                    <p>${this.escapeHtml(this.t('web.no_projects'))}</p>
+                    <p class="hint">${this.escapeHtml(this.t('web.no_projects_hint'))}</p>
                 </div>
             `;
             return;
@@ -106,31 +659,167 @@ class ClaudeWatchStatus {
         const sortedProjects = Array.from(this.projects.values())
             .sort((a, b) => new Date(b.updated_at) - new Date(a.updated_at));
 
+        // Re-rendering replaces the cards; keep keyboard focus on the same project
+        const hadFocus = container.contains(document.activeElement);
+        let focusName = this.focusedProject;
+        if (!this.projects.has(focusName)) focusName = sortedProjects[0].name;
+
         container.innerHTML = sortedProjects
-            .map(project => this.renderProjectCard(project))
+            .map(project => this.renderProjectCard(project, project.name === focusName))
             .join('');
+
+        if (hadFocus) {
+            const card = Array.from(container.querySelectorAll('.project-card'))
+                .find(el => el.dataset.name === focusName);
+            if (card) card.focus();
+        }
     }
 
-    renderProjectCard(project) {
+    renderProjectCard(project, focusable) {
         const time = this.formatTime(project.updated_at);
         const stateClass = this.getStateClass(project.state);
         const isProcessing = this.isProcessingState(project.state);
+        const tier = project.tier && project.tier !== 'normal' ? `, ${project.tier}` : '';
+        const subagents = (project.subagents || [])
+            .map(sub => this.t('web.label_subagent', this.subagentLabel(sub), this.stateLabel(sub.state)))
+            .join('');
+        const detail = project.request || project.detail ? ` ${project.request || project.detail}` : '';
+        const permission = this.isDangerous(project.environment) ? this.t('web.label_unattended') : this.modeLabel(project.environment);
+        const acknowledged = project.acknowledged ? this.t('web.label_acknowledged') : '';
+        const elapsed = project.tool_started_at ? this.t('web.label_elapsed', this.formatElapsed(project.tool_started_at)) : '';
+        const updated = this.t('web.label_updated', time, project.source);
+        const label = `${project.name}${tier}${permission}: ${this.stateLabel(project.state)}${elapsed}${acknowledged}${detail}${subagents}${updated}`;
 
         return `
-            <div class="project-card ${isProcessing ? 'processing' : ''} ${stateClass}" data-state="${stateClass}">
-                <div class="project-icon">${project.icon}</div>
+            <div class="project-card ${isProcessing ? 'processing' : ''} ${stateClass} ${project.acknowledged ? 'acknowledged' : ''}" data-state="${stateClass}"
+                 data-name="${this.escapeHtml(project.name)}" role="listitem" tabindex="${focusable ? 0 : -1}"
+                 aria-label="${this.escapeHtml(label)}">
+                <div class="project-icon" aria-hidden="true">${project.icon}</div>
                 <div class="project-info">
-                    <div class="project-name">${this.escapeHtml(project.name)}</div>
-                    <div class="project-state">${this.escapeHtml(project.state)}</div>
+                    <div class="project-name" title="${this.escapeHtml(project.project_path || project.name)}">${this.escapeHtml(project.name)}${this.renderModelBadge(project.environment)}${this.renderTierBadge(project.tier)}${this.renderPermissionBadge(project.environment)}</div>
+                    <div class="project-state">${this.escapeHtml(this.stateLabel(project.state))}${this.renderElapsed(project)}</div>
+                    ${this.renderDetail(project.request || project.detail)}
+                    ${this.renderSubagents(project.subagents)}
+                    ${this.renderLocation(project)}
+                    ${this.renderEnvironment(project.environment)}
                 </div>
                 <div class="project-meta">
                     <div class="project-time">${time}</div>
                     <div class="project-source ${project.source}">${project.source}</div>
+                    ${this.renderFocusButton(project)}
+                    ${this.renderAckButton(project)}
                 </div>
             </div>
         `;
     }
 
+    renderAckButton(project) {
+        if (!this.needsAcknowledgment(project)) return '';
+        return `<button class="ack-button" type="button" tabindex="-1" title="${this.escapeHtml(this.t('web.acknowledge'))}" aria-hidden="true">✓</button>`;
+    }
+
+    // Whether the daemon can raise the terminal, as state.Terminal.Focusable
+    canFocus(env) {
+        const t = env && env.terminal;
+        return !!t && !!(t.tmux_pane || t.iterm_session || t.wezterm_pane || t.kitty_window || t.x_window ||
+            (t.program === 'Apple_Terminal' && t.tty));
+    }
+
+    renderFocusButton(project) {
+        if (this.getStateClass(project.state) !== 'waiting' || !this.canFocus(project.environment)) return '';
+        const name = /[\s'"$`\\]/.test(project.name) ? `'${project.name.replace(/'/g, "'\\''")}'` : project.name;
+        return `<button class="focus-button" type="button" tabindex="-1" title="${this.escapeHtml(this.t('web.focus_title', name))}" aria-hidden="true">↗</button>`;
+    }
+
+    renderElapsed(project) {
+        if (!project.tool_started_at) return '';
+        return ` <span class="project-elapsed" data-since="${this.escapeHtml(project.tool_started_at)}">${this.formatElapsed(project.tool_started_at)}</span>`;
+    }
+
+    // Time since a timestamp to the second, as in "1m23s"
+    formatElapsed(since) {
+        const total = Math.max(0, Math.floor((Date.now() - new Date(since)) / 1000));
+        const h = Math.floor(total / 3600);
+        const m = Math.floor(total / 60) % 60;
+        const s = total % 60;
+        if (h > 0) return `${h}h${String(m).padStart(2, '0')}m`;
+        if (m > 0) return `${m}m${String(s).padStart(2, '0')}s`;
+        return `${s}s`;
+    }
+
+    renderDetail(detail) {
+        if (!detail) return '';
+        return `<div class="project-detail" title="${this.escapeHtml(detail)}">${this.escapeHtml(detail)}</div>`;
+    }
+
+    renderSubagents(subagents) {
+        if (!subagents || subagents.length === 0) return '';
+        const items = subagents.map(sub => `
+            <li class="subagent ${this.getStateClass(sub.state)}">
+                <span class="subagent-icon" aria-hidden="true">${sub.icon}</span>
+                <span class="subagent-name">${this.escapeHtml(this.subagentLabel(sub))}</span>
+                <span class="subagent-state">${this.escapeHtml(this.stateLabel(sub.state))}</span>
+            </li>`).join('');
+        return `<ul class="subagents" aria-hidden="true">${items}</ul>`;
+    }
+
+    subagentLabel(sub) {
+        return sub.description || sub.type || sub.id;
+    }
+
+    isDangerous(env) {
+        return !!env && env.permission_mode === 'bypassPermissions';
+    }
+
+    renderPermissionBadge(env) {
+        if (this.isDangerous(env)) {
+            return ` <span class="project-warning" title="${this.escapeHtml(this.t('web.unattended_title'))}">${this.escapeHtml(this.t('web.unattended'))}</span>`;
+        }
+        const mode = this.permissionModes.get(env && env.permission_mode);
+        if (!mode) return '';
+        return ` <span class="project-mode ${mode}" title="${this.escapeHtml(this.t(`web.${mode}_title`))}">${this.escapeHtml(this.t(`web.${mode}`))}</span>`;
+    }
+
+    modeLabel(env) {
+        const mode = this.permissionModes.get(env && env.permission_mode);
+        return mode ? this.t(`web.label_${mode}`) : '';
+    }
+
+    // The model family badge tells expensive sessions apart at a glance;
+    // its title holds the full model ID
+    renderModelBadge(env) {
+        if (!env || !env.model) return '';
+        const family = ['opus', 'sonnet', 'haiku'].find(f => env.model.includes(f));
+        const label = family || env.model.replace(/^claude-/, '');
+        return ` <span class="project-model ${family || ''}" title="${this.escapeHtml(env.model)}">${this.escapeHtml(label)}</span>`;
+    }
+
+    // The branch tells worktrees of the same repository apart; the full
+    // working directory is in the title
+    renderLocation(project) {
+        if (!project.branch && !project.path) return '';
+        const dir = project.path ? project.path.split(/[\\/]/).filter(Boolean).pop() : '';
+        const text = [project.branch ? `⎇ ${project.branch}` : '', dir].filter(Boolean).join(' · ');
+        return `<div class="project-location" title="${this.escapeHtml(project.path || '')}">${this.escapeHtml(text)}</div>`;
+    }
+
+    renderEnvironment(env) {
+        if (!env) return '';
+        const parts = [];
+        // Modes with a badge are not repeated
+        if (env.permission_mode && env.permission_mode !== 'default' && !this.isDangerous(env) &&
+            !this.permissionModes.has(env.permission_mode)) parts.push(env.permission_mode);
+        if (env.mcp_servers && env.mcp_servers.length > 0) parts.push(`MCP: ${env.mcp_servers.join(', ')}`);
+        if (parts.length === 0) return '';
+        const text = parts.join(' · ');
+        return `<div class="project-env" title="${this.escapeHtml(text)}">${this.escapeHtml(text)}</div>`;
+    }
+
+    renderTierBadge(tier) {
+        if (!tier || tier === 'normal') return '';
+        return ` <span class="project-tier ${this.escapeHtml(tier)}">${this.escapeHtml(tier)}</span>`;
+    }
+
     formatTime(timestamp) {
         const date = new Date(timestamp);
         return date.toLocaleTimeString('en-US', {
@@ -144,7 +833,9 @@ class ClaudeWatchStatus {
     getStateClass(state) {
         if (state.includes('completed')) return 'completed';
         if (state.includes('waiting') || state.includes('approval')) return 'waiting';
+        if (state.includes('interrupted')) return 'interrupted';
         if (state.includes('error') || state.includes('max tokens')) return 'error';
+        if (state === 'inactive') return 'inactive';
         return '';
     }
 
@@ -152,13 +843,15 @@ class ClaudeWatchStatus {
         return state.includes('processing') ||
                state.includes('thinking') ||
                state.includes('running') ||
+               state.includes('continuing') ||
                state.includes('calling');
     }
 
     escapeHtml(text) {
         const div = document.createElement('div');
         div.textContent = text;
-        return div.innerHTML;
+        // innerHTML leaves quotes alone; escape them for use in attributes
+        return div.innerHTML.replace(/"/g, '&quot;').replace(/'/g, '&#39;');
     }
 }
 
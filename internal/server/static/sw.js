@@ -0,0 +1,61 @@
+// Claude Watch Status - service worker
+//
+// Caches the app shell and its messages so the installed app opens without
+// a connection. Status always comes live from the daemon: other API, SSE,
+// badge, schema and health requests are never cached.
+
+const CACHE = 'cws-shell-v1';
+
+const SHELL = [
+    '/',
+    '/css/style.css',
+    '/js/app.js',
+    '/manifest.json',
+    '/api/i18n',
+    '/icons/icon-192.png',
+    '/icons/icon-512.png'
+];
+
+self.addEventListener('install', (event) => {
+    event.waitUntil(
+        caches.open(CACHE)
+            .then(cache => cache.addAll(SHELL))
+            .then(() => self.skipWaiting())
+    );
+});
+
+self.addEventListener('activate', (event) => {
+    event.waitUntil(
+        caches.keys()
+            .then(keys => Promise.all(keys.filter(key => key !== CACHE).map(key => caches.delete(key))))
+            .then(() => self.clients.claim())
+    );
+});
+
+self.addEventListener('fetch', (event) => {
+    const request = event.request;
+    const url = new URL(request.url);
+
+    if (request.method !== 'GET' || url.origin !== self.location.origin || !isShell(url.pathname)) {
+        return;
+    }
+
+    // Network first so a daemon upgrade is picked up; the cache is the fallback
+    event.respondWith(
+        fetch(request)
+            .then(response => {
+                if (response.ok) {
+                    const copy = response.clone();
+                    const key = request.mode === 'navigate' ? '/' : url.pathname;
+                    caches.open(CACHE).then(cache => cache.put(key, copy));
+                }
+                return response;
+            })
+            .catch(() => caches.match(request.mode === 'navigate' ? '/' : url.pathname))
+    );
+});
+
+function isShell(path) {
+    if (path === '/api/i18n') return true;
+    return !path.startsWith('/api/') && !path.startsWith('/badge/') && !path.startsWith('/schema/') && path !== '/health';
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/stats"
+)
+
+// historySaveInterval is how often today's statistics are written to the
+// history file
+const historySaveInterval = 5 * time.Minute
+
+// WithHistory keeps daily statistics in store across restarts
+func WithHistory(store *stats.HistoryStore) Option {
+	return func(s *Server) {
+		s.history = store
+	}
+}
+
+// handleGetStats returns today's per-project activity statistics
+func (s *Server) handleGetStats(c echo.Context) error {
+	return c.JSON(http.StatusOK, s.stats.Snapshot())
+}
+
+// restoreStats continues today's statistics from the history file.
+// Must run before status events are recorded.
+func (s *Server) restoreStats() {
+	hist, err := s.history.Load()
+	if err != nil {
+		logging.Logger().Warn("statistics history not loaded", "path", s.history.Path(), "error", err)
+		return
+	}
+	for _, day := range hist.Days {
+		s.stats.Restore(day)
+	}
+}
+
+// runHistory periodically saves statistics to the history file
+func (s *Server) runHistory() {
+	ticker := time.NewTicker(historySaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			// Stop saves the final statistics
+			return
+		case <-ticker.C:
+			s.saveHistory()
+		}
+	}
+}
+
+// saveHistory writes the finished day, if the day rolled over, and today's
+// statistics so far
+func (s *Server) saveHistory() {
+	snaps := make([]stats.Snapshot, 0, 2)
+	if finished, ok := s.stats.TakeFinishedDay(); ok {
+		snaps = append(snaps, finished)
+	}
+	snaps = append(snaps, s.stats.Snapshot())
+
+	for _, snap := range snaps {
+		if err := s.history.Save(snap); err != nil {
+			logging.Logger().Warn("statistics history not saved", "path", s.history.Path(), "date", snap.Date, "error", err)
+		}
+	}
+}
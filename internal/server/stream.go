@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/pkg/protocol"
+)
+
+// defaultSSEKeepalive is how often idle event streams get a comment line
+const defaultSSEKeepalive = 15 * time.Second
+
+// WithSSEKeepalive sets how often a keepalive comment is sent on idle
+// event streams; 0 disables keepalives
+func WithSSEKeepalive(interval time.Duration) Option {
+	return func(s *Server) {
+		s.sseKeepalive = interval
+	}
+}
+
+// lastEventID returns the ID of the last event a reconnecting client
+// received, from the Last-Event-ID header or, for clients that reconnect
+// by opening a new EventSource, the last_event_id query parameter
+func lastEventID(c echo.Context) (uint64, bool) {
+	raw := c.Request().Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = c.QueryParam("last_event_id")
+	}
+	if raw == "" {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// writeEvent writes an enveloped SSE event with an ID. Reports whether it
+// was written.
+func writeEvent(w io.Writer, id uint64, eventType string, data interface{}) bool {
+	payload, err := json.Marshal(protocol.New(eventType, data))
+	if err != nil {
+		return false
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, eventType, payload)
+	return true
+}
+
+// writeUnnumberedEvent writes an enveloped SSE event without an ID, for
+// state that is not replayed, so the client's last event ID is kept
+func writeUnnumberedEvent(w io.Writer, eventType string, data interface{}) bool {
+	payload, err := json.Marshal(protocol.New(eventType, data))
+	if err != nil {
+		return false
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, payload)
+	return true
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+)
+
+// traceCheckInterval is how often an idle trace stream checks that debug
+// logging is still on
+const traceCheckInterval = 5 * time.Second
+
+// tracingAllowed reports whether project traces may be streamed: they
+// include raw event details, so only while debug logging is on
+func tracingAllowed() bool {
+	return logging.Level() <= slog.LevelDebug
+}
+
+// handleProjectTrace streams, for every event affecting a project, the raw
+// signal, its classification and the state machine decision, to explain
+// why the project shows its state. The stream ends when debug logging is
+// turned off.
+func (s *Server) handleProjectTrace(c echo.Context) error {
+	if !tracingAllowed() {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "tracing requires debug logging (serve --log-level debug, SIGUSR1 or POST /api/loglevel)",
+		})
+	}
+	name := c.Param("name")
+
+	c.Response().Header().Set("Content-Type", "text/event-stream")
+	c.Response().Header().Set("Cache-Control", "no-cache")
+	c.Response().Header().Set("Connection", "keep-alive")
+
+	w := c.Response()
+	traceCh := s.manager.SubscribeTrace()
+	defer s.manager.UnsubscribeTrace(traceCh)
+
+	// Start with the current status, the state the traces explain
+	if info := s.manager.Project(name); info != nil {
+		fmt.Fprintf(w, ": current status %s %s (%s)\n\n", info.Status.Icon, info.Status.State, info.Status.Source)
+	}
+	w.Flush()
+
+	ticker := time.NewTicker(traceCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+
+		case <-s.done:
+			return nil
+
+		case <-ticker.C:
+			if !tracingAllowed() {
+				return nil
+			}
+			fmt.Fprint(w, ": keepalive\n\n")
+			w.Flush()
+
+		case t, ok := <-traceCh:
+			if !ok {
+				return nil
+			}
+			if t.Project != name {
+				continue
+			}
+			if !tracingAllowed() {
+				return nil
+			}
+			payload, err := json.Marshal(t)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: trace\ndata: %s\n\n", payload)
+			w.Flush()
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// Page sizes of the transitions API
+const (
+	defaultTransitionsLimit = 500
+	maxTransitionsLimit     = 1000
+)
+
+// TransitionsResponse represents the API response for a session's
+// transitions
+type TransitionsResponse struct {
+	SessionID   string             `json:"session_id"`
+	Transitions []state.Transition `json:"transitions"`
+	NextCursor  uint64             `json:"next_cursor"` // pass as ?after= to continue
+	More        bool               `json:"more"`
+	Truncated   bool               `json:"truncated"` // transitions were missed; resync from the project status
+}
+
+// handleGetSessionTransitions returns the state transitions of a session
+// after the ?after= cursor, so recorders can replicate them incrementally
+func (s *Server) handleGetSessionTransitions(c echo.Context) error {
+	var after uint64
+	if v := c.QueryParam("after"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid after cursor"})
+		}
+		after = n
+	}
+	limit := defaultTransitionsLimit
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxTransitionsLimit {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 1000"})
+		}
+		limit = n
+	}
+
+	id := c.Param("id")
+	page, ok := s.manager.Transitions(id, after, limit)
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "unknown session"})
+	}
+	return c.JSON(http.StatusOK, TransitionsResponse{
+		SessionID:   id,
+		Transitions: page.Transitions,
+		NextCursor:  page.NextCursor,
+		More:        page.More,
+		Truncated:   page.Truncated,
+	})
+}
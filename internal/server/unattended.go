@@ -0,0 +1,38 @@
+package server
+
+import (
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/notifier"
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// WithUnattendedWarning sends a notification when a session starts
+// running tools without approval prompts
+func WithUnattendedWarning(n *notifier.Notifier) Option {
+	return func(s *Server) {
+		s.unattended = n
+	}
+}
+
+// runUnattendedWarnings notifies once per session when it is first seen
+// with the bypassPermissions permission mode
+func (s *Server) runUnattendedWarnings() {
+	warned := make(map[string]bool)
+
+	state.ForEachUpdate(s.done, s.manager.SubscribeContext(s.ctx), func(event state.StatusEvent) {
+		project := event.Project
+		key := project.Name + "\x00" + project.SessionID
+		if event.Type == state.EventSessionRemoved {
+			delete(warned, key)
+			return
+		}
+		if !project.Environment.Dangerous() || warned[key] {
+			return
+		}
+		warned[key] = true
+
+		logging.Logger().Warn("session running with unattended permissions",
+			"project", project.Name, "session", project.SessionID)
+		s.unattended.NotifyUnattendedPermissions(project)
+	})
+}
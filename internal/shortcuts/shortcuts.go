@@ -0,0 +1,154 @@
+// Package shortcuts runs macOS Shortcuts when projects change state,
+// passing the project and state as input, so automations (flash lights,
+// send a message, ...) can be built in the Shortcuts app without
+// programming.
+package shortcuts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// runTimeout bounds how long a Shortcut may run
+const runTimeout = time.Minute
+
+// Input is the JSON passed to a Shortcut as its input file. In the
+// Shortcuts app, "Get Dictionary from Input" exposes the fields.
+type Input struct {
+	Project   string    `json:"project"`
+	State     string    `json:"state"`
+	Phase     string    `json:"phase"` // waiting, completed, interrupted, ...
+	Icon      string    `json:"icon"`
+	Detail    string    `json:"detail,omitempty"`
+	SessionID string    `json:"session_id,omitempty"`
+	Tier      string    `json:"tier,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// rule is a parsed config.ShortcutConfig
+type rule struct {
+	name     string
+	phases   []state.Phase
+	projects []string // empty = all projects
+}
+
+// Bridge runs the configured Shortcuts on matching transitions
+type Bridge struct {
+	rules []rule
+	run   func(ctx context.Context, name, inputPath string) error
+}
+
+// New parses the configured Shortcuts. An error names the first invalid
+// entry.
+func New(cfgs []config.ShortcutConfig) (*Bridge, error) {
+	b := &Bridge{run: runShortcut}
+	for i, c := range cfgs {
+		if c.Name == "" {
+			return nil, fmt.Errorf("shortcuts[%d]: name is required", i)
+		}
+		if len(c.On) == 0 {
+			return nil, fmt.Errorf("shortcut %q: \"on\" lists no states", c.Name)
+		}
+		r := rule{name: c.Name, projects: c.Projects}
+		for _, name := range c.On {
+			phase, ok := state.ParsePhase(name)
+			if !ok {
+				return nil, fmt.Errorf("shortcut %q: unknown state %q (want started, user_input, working, waiting, completed, interrupted, error or ended)", c.Name, name)
+			}
+			r.phases = append(r.phases, phase)
+		}
+		b.rules = append(b.rules, r)
+	}
+	return b, nil
+}
+
+// Available reports whether Shortcuts can be run: on macOS 12 or later,
+// which ships the shortcuts command
+func Available() bool {
+	if runtime.GOOS != "darwin" {
+		return false
+	}
+	_, err := exec.LookPath("shortcuts")
+	return err == nil
+}
+
+// Len returns the number of configured Shortcuts
+func (b *Bridge) Len() int {
+	return len(b.rules)
+}
+
+// Fire runs, in the background, the Shortcuts configured for the phase
+// the project just entered
+func (b *Bridge) Fire(status state.ProjectStatus) {
+	phase := state.PhaseOf(status.State)
+	var input []byte
+	for _, r := range b.rules {
+		if !slices.Contains(r.phases, phase) {
+			continue
+		}
+		if len(r.projects) > 0 && !slices.Contains(r.projects, status.Name) {
+			continue
+		}
+		if input == nil {
+			var err error
+			input, err = json.Marshal(Input{
+				Project:   status.Name,
+				State:     status.State,
+				Phase:     phase.String(),
+				Icon:      status.Icon,
+				Detail:    status.Detail,
+				SessionID: status.SessionID,
+				Tier:      status.Tier,
+				Time:      status.UpdatedAt,
+			})
+			if err != nil {
+				return
+			}
+		}
+		go b.runWithInput(r.name, status.Name, input)
+	}
+}
+
+// runWithInput writes the input to a temporary file and runs a Shortcut
+func (b *Bridge) runWithInput(name, project string, input []byte) {
+	f, err := os.CreateTemp("", "cws-shortcut-*.json")
+	if err != nil {
+		logging.Logger().Warn("shortcut not run", "shortcut", name, "error", err)
+		return
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(input)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		logging.Logger().Warn("shortcut not run", "shortcut", name, "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+	defer cancel()
+	logging.Logger().Debug("running shortcut", "shortcut", name, "project", project)
+	if err := b.run(ctx, name, f.Name()); err != nil {
+		logging.Logger().Warn("shortcut failed", "shortcut", name, "project", project, "error", err)
+	}
+}
+
+// runShortcut runs a Shortcut with the shortcuts command
+func runShortcut(ctx context.Context, name, inputPath string) error {
+	out, err := exec.CommandContext(ctx, "shortcuts", "run", name, "--input-path", inputPath).CombinedOutput()
+	if err != nil && len(out) > 0 {
+		return fmt.Errorf("%w: %s", err, out)
+	}
+	return err
+}
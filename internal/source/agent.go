@@ -0,0 +1,202 @@
+package source
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// AgentState is a session log normalized into the common status model
+type AgentState struct {
+	ProjectName string
+	SessionID   string
+	Icon        string
+	State       string
+	Detail      string
+	Skip        bool // true if the log has no displayable state yet
+}
+
+// AgentParser normalizes one agent CLI's session logs
+type AgentParser interface {
+	// Name identifies the agent ("codex", ...); used as the status source
+	Name() string
+	// DefaultDir returns the agent's default session log directory
+	DefaultDir() string
+	// Match reports whether a file under the log directory is a session log
+	Match(path string) bool
+	// ParseFile derives the current state from a session log
+	ParseFile(path string) (AgentState, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]AgentParser)
+)
+
+// RegisterParser makes an agent parser available by name.
+// It panics if a parser with the same name is already registered.
+func RegisterParser(p AgentParser) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, dup := registry[p.Name()]; dup {
+		panic("source: RegisterParser called twice for " + p.Name())
+	}
+	registry[p.Name()] = p
+}
+
+// LookupParser returns the registered parser with the given name
+func LookupParser(name string) (AgentParser, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	p, ok := registry[name]
+	return p, ok
+}
+
+// Parsers returns the names of all registered parsers, sorted
+func Parsers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AgentLogSource watches another agent CLI's session logs (recursively)
+// and feeds normalized states to the sink
+type AgentLogSource struct {
+	parser    AgentParser
+	dir       string
+	fsWatcher *fsnotify.Watcher
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewAgentLog creates an AgentLogSource. If dir is empty, the parser's
+// default directory is used.
+func NewAgentLog(parser AgentParser, dir string) *AgentLogSource {
+	if dir == "" {
+		dir = parser.DefaultDir()
+	}
+	return &AgentLogSource{parser: parser, dir: dir, done: make(chan struct{})}
+}
+
+// Name returns the parser name
+func (s *AgentLogSource) Name() string {
+	return s.parser.Name()
+}
+
+// Start watches the log directory tree and forwards changes to sink
+func (s *AgentLogSource) Start(sink Sink) error {
+	if _, err := os.Stat(s.dir); err != nil {
+		return fmt.Errorf("%s log directory: %w", s.parser.Name(), err)
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	s.fsWatcher = fsWatcher
+
+	if err := s.addTree(s.dir); err != nil {
+		fsWatcher.Close()
+		return err
+	}
+
+	s.wg.Add(1)
+	go s.loop(sink)
+	return nil
+}
+
+// Stop stops watching
+func (s *AgentLogSource) Stop() error {
+	if s.fsWatcher == nil {
+		return nil
+	}
+	close(s.done)
+	err := s.fsWatcher.Close()
+	s.wg.Wait()
+	return err
+}
+
+// addTree watches dir and all of its subdirectories
+func (s *AgentLogSource) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			return s.fsWatcher.Add(path)
+		}
+		return nil
+	})
+}
+
+func (s *AgentLogSource) loop(sink Sink) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.done:
+			return
+
+		case event, ok := <-s.fsWatcher.Events:
+			if !ok {
+				return
+			}
+			s.handleEvent(event, sink)
+
+		case err, ok := <-s.fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			logging.Logger().Warn("agent log watcher error", "agent", s.parser.Name(), "error", err)
+		}
+	}
+}
+
+func (s *AgentLogSource) handleEvent(event fsnotify.Event, sink Sink) {
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if err := s.addTree(event.Name); err != nil {
+				logging.Logger().Warn("agent log watcher error", "agent", s.parser.Name(), "error", err)
+			}
+			return
+		}
+	}
+
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+		return
+	}
+	if !s.parser.Match(event.Name) {
+		return
+	}
+
+	agentState, err := s.parser.ParseFile(event.Name)
+	if err != nil {
+		logging.Logger().Debug("agent log parse failed", "agent", s.parser.Name(), "path", event.Name, "error", err)
+		return
+	}
+	if agentState.Skip {
+		return
+	}
+
+	sink.UpdateFromHook(state.HookEvent{
+		SessionID:   agentState.SessionID,
+		ToolName:    agentState.Detail,
+		ProjectName: agentState.ProjectName,
+		Icon:        agentState.Icon,
+		State:       agentState.State,
+		Source:      s.parser.Name(),
+	})
+}
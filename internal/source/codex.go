@@ -0,0 +1,135 @@
+package source
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+)
+
+func init() {
+	RegisterParser(codexParser{})
+}
+
+// codexParser reads OpenAI Codex CLI rollout logs:
+// ~/.codex/sessions/YYYY/MM/DD/rollout-<timestamp>-<id>.jsonl
+type codexParser struct{}
+
+// codexLine is one line of a Codex rollout log
+type codexLine struct {
+	Type    string       `json:"type"`
+	Payload codexPayload `json:"payload"`
+}
+
+type codexPayload struct {
+	Type string `json:"type"`
+	Role string `json:"role,omitempty"`
+	Name string `json:"name,omitempty"`
+	ID   string `json:"id,omitempty"`
+	CWD  string `json:"cwd,omitempty"`
+}
+
+func (codexParser) Name() string {
+	return "codex"
+}
+
+func (codexParser) DefaultDir() string {
+	if home := os.Getenv("CODEX_HOME"); home != "" {
+		return filepath.Join(home, "sessions")
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".codex", "sessions")
+}
+
+func (codexParser) Match(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasPrefix(base, "rollout-") && strings.HasSuffix(base, ".jsonl")
+}
+
+func (p codexParser) ParseFile(path string) (AgentState, error) {
+	result := AgentState{
+		SessionID:   strings.TrimSuffix(filepath.Base(path), ".jsonl"),
+		ProjectName: "codex",
+	}
+
+	// The first line carries session metadata including the working directory
+	if first, err := parser.ReadFirstLine(path); err == nil {
+		var meta codexLine
+		if json.Unmarshal([]byte(first), &meta) == nil && meta.Type == "session_meta" {
+			if meta.Payload.ID != "" {
+				result.SessionID = meta.Payload.ID
+			}
+			if meta.Payload.CWD != "" {
+				result.ProjectName = filepath.Base(meta.Payload.CWD)
+			}
+		}
+	}
+	// Keep Codex sessions distinct from Claude Code sessions in the same directory
+	result.ProjectName += " (codex)"
+
+	lines, err := parser.ReadLastLines(path, 20)
+	if err != nil {
+		return result, err
+	}
+
+	// Walk backwards to the most recent line that carries a state
+	for i := len(lines) - 1; i >= 0; i-- {
+		var line codexLine
+		if err := json.Unmarshal([]byte(lines[i]), &line); err != nil {
+			continue
+		}
+		if icon, text, detail, ok := codexState(line); ok {
+			result.Icon, result.State, result.Detail = icon, text, detail
+			return result, nil
+		}
+	}
+
+	result.Skip = true
+	return result, nil
+}
+
+// codexState maps a rollout line to a status; ok is false for lines
+// without state information (token counts, turn context, ...)
+func codexState(line codexLine) (icon, text, detail string, ok bool) {
+	switch line.Type {
+	case "response_item":
+		switch line.Payload.Type {
+		case "message":
+			if line.Payload.Role == "user" {
+				return "👤", "user input", "", true
+			}
+			return "🤔", "responding", "", true
+		case "reasoning":
+			return "🤔", "thinking", "", true
+		case "function_call", "custom_tool_call", "local_shell_call":
+			name := line.Payload.Name
+			if name == "" {
+				name = "shell"
+			}
+			return "🔧", "running: " + name, name, true
+		case "function_call_output", "custom_tool_call_output":
+			return "⏳", "processing", "", true
+		}
+
+	case "event_msg":
+		switch line.Payload.Type {
+		case "task_started":
+			return "🤔", "thinking", "", true
+		case "user_message":
+			return "👤", "user input", "", true
+		case "agent_reasoning":
+			return "🤔", "thinking", "", true
+		case "agent_message":
+			return "🤔", "responding", "", true
+		case "exec_approval_request", "apply_patch_approval_request":
+			return "⏸️", "waiting approval", "", true
+		case "task_complete":
+			return "✅", "completed", "", true
+		case "turn_aborted":
+			return "🛑", "interrupted", "", true
+		}
+	}
+	return "", "", "", false
+}
@@ -0,0 +1,123 @@
+package source
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+)
+
+func init() {
+	RegisterParser(desktopParser{})
+}
+
+// desktopParser reads Claude Desktop's MCP server logs to show when
+// desktop (claude.ai app) sessions invoke local MCP tools
+type desktopParser struct{}
+
+// desktopLinePattern matches MCP log lines such as:
+// 2025-01-01T12:00:00.000Z [filesystem] [info] Message from client: {...}
+var desktopLinePattern = regexp.MustCompile(`^\S+ \[([^\]]+)\] \[\w+\] Message from (client|server): (\{.*\})\s*$`)
+
+// jsonrpcMessage is the subset of a JSON-RPC message needed for status
+type jsonrpcMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params struct {
+		Name string `json:"name,omitempty"`
+	} `json:"params"`
+	Error *json.RawMessage `json:"error,omitempty"`
+}
+
+// desktopMessage is a JSON-RPC message of an MCP log line
+type desktopMessage struct {
+	jsonrpcMessage
+	server string
+	from   string // "client" or "server"
+}
+
+// callKey identifies the request a message is or answers
+func (m desktopMessage) callKey() string {
+	return m.server + "\x00" + string(m.ID)
+}
+
+func (desktopParser) Name() string {
+	return "desktop"
+}
+
+func (desktopParser) DefaultDir() string {
+	homeDir, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(homeDir, "Library", "Logs", "Claude")
+	case "windows":
+		return filepath.Join(os.Getenv("APPDATA"), "Claude", "logs")
+	default:
+		return filepath.Join(homeDir, ".config", "Claude", "logs")
+	}
+}
+
+func (desktopParser) Match(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasPrefix(base, "mcp") && strings.HasSuffix(base, ".log")
+}
+
+func (desktopParser) ParseFile(path string) (AgentState, error) {
+	result := AgentState{
+		SessionID:   strings.TrimSuffix(filepath.Base(path), ".log"),
+		ProjectName: "Claude Desktop",
+	}
+
+	lines, err := parser.ReadLastLines(path, 50)
+	if err != nil {
+		return result, err
+	}
+
+	var messages []desktopMessage
+	// Request IDs of the tool calls, by server: other responses, such as
+	// to tools/list during a call, do not end it
+	calls := make(map[string]bool)
+	for _, line := range lines {
+		m := desktopLinePattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		msg := desktopMessage{server: m[1], from: m[2]}
+		if err := json.Unmarshal([]byte(m[3]), &msg.jsonrpcMessage); err != nil {
+			continue
+		}
+		if msg.from == "client" && msg.Method == "tools/call" && len(msg.ID) > 0 {
+			calls[msg.callKey()] = true
+		}
+		messages = append(messages, msg)
+	}
+
+	// Walk backwards to the most recent tool call or tool response
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		switch {
+		case msg.from == "client" && msg.Method == "tools/call":
+			tool := fmt.Sprintf("mcp__%s__%s", msg.server, msg.Params.Name)
+			result.ProjectName = msg.server + " (desktop)"
+			result.Icon, result.State, result.Detail = "🔧", "running: "+tool, tool
+			return result, nil
+
+		case msg.from == "server" && msg.Method == "" && len(msg.ID) > 0 && calls[msg.callKey()]:
+			result.ProjectName = msg.server + " (desktop)"
+			if msg.Error != nil {
+				result.Icon, result.State = "⚠️", "tool error"
+			} else {
+				result.Icon, result.State = "✅", "completed"
+			}
+			return result, nil
+		}
+	}
+
+	result.Skip = true
+	return result, nil
+}
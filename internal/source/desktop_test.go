@@ -0,0 +1,51 @@
+package source
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDesktopParseFile(t *testing.T) {
+	const (
+		initialize = `2025-01-01T12:00:00.000Z [fs] [info] Message from client: {"jsonrpc":"2.0","id":0,"method":"initialize","params":{}}`
+		ready      = `2025-01-01T12:00:00.100Z [fs] [info] Message from server: {"jsonrpc":"2.0","id":0,"result":{}}`
+		call       = `2025-01-01T12:00:01.000Z [fs] [info] Message from client: {"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"read_file"}}`
+		list       = `2025-01-01T12:00:01.500Z [fs] [info] Message from client: {"jsonrpc":"2.0","id":2,"method":"tools/list","params":{}}`
+		listed     = `2025-01-01T12:00:01.600Z [fs] [info] Message from server: {"jsonrpc":"2.0","id":2,"result":{"tools":[]}}`
+		answered   = `2025-01-01T12:00:02.000Z [fs] [info] Message from server: {"jsonrpc":"2.0","id":1,"result":{}}`
+		failed     = `2025-01-01T12:00:02.000Z [fs] [info] Message from server: {"jsonrpc":"2.0","id":1,"error":{"code":-1}}`
+	)
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		state string // "" = skipped
+	}{
+		{"handshake only", []string{initialize, ready}, ""},
+		{"call running", []string{initialize, ready, call}, "running: mcp__fs__read_file"},
+		{"other response during a call", []string{initialize, ready, call, list, listed}, "running: mcp__fs__read_file"},
+		{"call answered", []string{initialize, ready, call, list, listed, answered}, "completed"},
+		{"call failed", []string{call, failed}, "tool error"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mcp-server-fs.log")
+			if err := os.WriteFile(path, []byte(strings.Join(tt.lines, "\n")+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := desktopParser{}.ParseFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.state == "" {
+				if !got.Skip {
+					t.Errorf("ParseFile() = %+v, want skipped", got)
+				}
+				return
+			}
+			if got.Skip || got.State != tt.state || got.ProjectName != "fs (desktop)" {
+				t.Errorf("ParseFile() = %+v, want %q of fs (desktop)", got, tt.state)
+			}
+		})
+	}
+}
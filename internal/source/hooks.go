@@ -0,0 +1,77 @@
+package source
+
+import (
+	"sync"
+
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// HooksSource forwards events received from Claude Code hooks
+// (via the daemon's /api/hooks endpoint) to the sink
+type HooksSource struct {
+	mu      sync.RWMutex
+	sink    Sink
+	journal *Journal // nil = events are not journaled
+}
+
+// NewHooks creates a HooksSource
+func NewHooks() *HooksSource {
+	return &HooksSource{}
+}
+
+// Name returns "hooks"
+func (s *HooksSource) Name() string {
+	return "hooks"
+}
+
+// SetJournal makes the source write every event to j before delivering
+// it, and replay the events j holds from the previous run on Start
+func (s *HooksSource) SetJournal(j *Journal) {
+	s.mu.Lock()
+	s.journal = j
+	s.mu.Unlock()
+}
+
+// Start replays the journal, if any, and begins forwarding submitted
+// events to sink
+func (s *HooksSource) Start(sink Sink) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sink = sink
+	if s.journal != nil {
+		if n := s.journal.Replay(sink); n > 0 {
+			logging.Logger().Info("hook events replayed from journal", "events", n, "path", s.journal.Path())
+		}
+	}
+	return nil
+}
+
+// Stop stops forwarding and closes the journal; later submissions are
+// dropped
+func (s *HooksSource) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sink = nil
+	if s.journal != nil {
+		return s.journal.Close()
+	}
+	return nil
+}
+
+// Submit delivers a hook event to the sink.
+// Returns nil if the source is stopped or the sink ignored the event.
+func (s *HooksSource) Submit(event state.HookEvent) *state.ProjectStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.sink == nil {
+		return nil
+	}
+	if s.journal != nil {
+		if err := s.journal.Append(&event); err != nil {
+			logging.Logger().Warn("hook event not journaled", "error", err)
+		}
+	}
+	return s.sink.UpdateFromHook(event)
+}
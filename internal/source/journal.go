@@ -0,0 +1,259 @@
+package source
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// Journal limits: the last maxJournalEntries events are kept, and events
+// older than maxJournalAge are not replayed. The file is compacted when it
+// holds twice as many entries.
+const (
+	maxJournalEntries = 500
+	maxJournalAge     = 24 * time.Hour
+)
+
+// journalRecord is the on-disk form of a hook event
+type journalRecord struct {
+	SessionID     string                `json:"session_id,omitempty"`
+	HookEventName string                `json:"hook_event_name"`
+	ToolName      string                `json:"tool_name,omitempty"`
+	ToolUseID     string                `json:"tool_use_id,omitempty"`
+	Detail        string                `json:"detail,omitempty"`
+	CWD           string                `json:"cwd,omitempty"`
+	Transcript    string                `json:"transcript_path,omitempty"`
+	ProjectName   string                `json:"project"`
+	Icon          string                `json:"icon,omitempty"`
+	State         string                `json:"state,omitempty"`
+	Source        string                `json:"source,omitempty"`
+	Environment   state.Environment     `json:"environment,omitzero"`
+	Time          time.Time             `json:"ts"`
+	Subagent      *state.SubagentUpdate `json:"subagent,omitempty"`
+}
+
+// Journal is a write-ahead log of hook events. Events are appended before
+// they are applied, so the latest states survive a daemon crash or
+// restart and are replayed on the next start.
+type Journal struct {
+	mu      sync.Mutex
+	path    string
+	f       *os.File
+	entries int               // entries in the file
+	pending []state.HookEvent // read at open, not yet replayed
+}
+
+// OpenJournal opens or creates the journal at path and reads the events
+// to replay. Torn or invalid lines, as left by a crash mid-write, are
+// skipped.
+func OpenJournal(path string) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	records, err := readJournal(path)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Journal{path: path}
+	cutoff := time.Now().Add(-maxJournalAge)
+	for _, rec := range records {
+		if rec.Time.After(cutoff) {
+			j.pending = append(j.pending, rec.event())
+		}
+	}
+	// Start from a compact file holding only what is still replayable
+	if err := j.rewrite(records, cutoff); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Path returns the journal file
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Replay applies the events read at open to sink, oldest first, and
+// returns how many were applied. Later calls replay nothing.
+func (j *Journal) Replay(sink Sink) int {
+	j.mu.Lock()
+	pending := j.pending
+	j.pending = nil
+	j.mu.Unlock()
+
+	applied := 0
+	for _, event := range pending {
+		if sink.UpdateFromHook(event) != nil {
+			applied++
+		}
+	}
+	return applied
+}
+
+// Append writes an event to the journal and syncs it to disk. A zero
+// event time is set to now, so a replayed event keeps its original time.
+func (j *Journal) Append(event *state.HookEvent) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	line, err := json.Marshal(recordOf(*event))
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return fmt.Errorf("journal closed")
+	}
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := j.f.Sync(); err != nil {
+		return err
+	}
+	j.entries++
+
+	if j.entries >= 2*maxJournalEntries {
+		records, err := readJournal(j.path)
+		if err != nil {
+			return err
+		}
+		return j.rewrite(records, time.Now().Add(-maxJournalAge))
+	}
+	return nil
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return nil
+	}
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
+
+// rewrite replaces the journal with the last maxJournalEntries records
+// newer than cutoff and reopens it for appending. Caller must hold j.mu
+// or own j exclusively.
+func (j *Journal) rewrite(records []journalRecord, cutoff time.Time) error {
+	if len(records) > maxJournalEntries {
+		records = records[len(records)-maxJournalEntries:]
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), ".journal-*")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	entries := 0
+	for _, rec := range records {
+		if !rec.Time.After(cutoff) {
+			continue
+		}
+		line, err := json.Marshal(rec)
+		if err != nil {
+			continue
+		}
+		w.Write(append(line, '\n'))
+		entries++
+	}
+	err = w.Flush()
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), j.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if j.f != nil {
+		j.f.Close()
+	}
+	f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		j.f = nil
+		return err
+	}
+	j.f, j.entries = f, entries
+	return nil
+}
+
+// readJournal reads the valid records of a journal file, oldest first
+func readJournal(path string) ([]journalRecord, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []journalRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec journalRecord
+		if json.Unmarshal(scanner.Bytes(), &rec) != nil || rec.ProjectName == "" {
+			continue
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+func recordOf(e state.HookEvent) journalRecord {
+	return journalRecord{
+		SessionID:     e.SessionID,
+		HookEventName: e.HookEventName,
+		ToolName:      e.ToolName,
+		ToolUseID:     e.ToolUseID,
+		Detail:        e.Detail,
+		CWD:           e.CWD,
+		Transcript:    e.TranscriptPath,
+		ProjectName:   e.ProjectName,
+		Icon:          e.Icon,
+		State:         e.State,
+		Source:        e.Source,
+		Environment:   e.Environment,
+		Time:          e.Time,
+		Subagent:      e.Subagent,
+	}
+}
+
+func (r journalRecord) event() state.HookEvent {
+	return state.HookEvent{
+		SessionID:      r.SessionID,
+		HookEventName:  r.HookEventName,
+		ToolName:       r.ToolName,
+		ToolUseID:      r.ToolUseID,
+		Detail:         r.Detail,
+		CWD:            r.CWD,
+		TranscriptPath: r.Transcript,
+		ProjectName:    r.ProjectName,
+		Icon:           r.Icon,
+		State:          r.State,
+		Source:         r.Source,
+		Environment:    r.Environment,
+		Time:           r.Time,
+		Subagent:       r.Subagent,
+	}
+}
@@ -0,0 +1,254 @@
+package source
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/watcher"
+)
+
+// Watcher errors beyond maxWatcherErrors within watcherErrorWindow are
+// treated as a failed watcher
+const (
+	maxWatcherErrors   = 5
+	watcherErrorWindow = time.Minute
+)
+
+// DefaultDebounce is the debounce interval outside low-power mode
+const DefaultDebounce = watcher.CoalesceWindow
+
+// JSONLSource watches Claude Code session logs in a projects directory
+type JSONLSource struct {
+	projectsDir string
+	watchMode   watcher.Mode
+	watcher     watcher.Watcher
+	wg          sync.WaitGroup
+	stopping    chan struct{}
+	onFailure   func(err error)
+	failOnce    sync.Once
+
+	// Pausing and debouncing defer log re-reads to save I/O on battery
+	mu       sync.Mutex
+	paused   bool
+	debounce time.Duration
+	wake     chan struct{}
+}
+
+// NewJSONL creates a JSONLSource for the given projects directory
+func NewJSONL(projectsDir string) *JSONLSource {
+	return &JSONLSource{
+		projectsDir: projectsDir,
+		watchMode:   watcher.ModeAuto,
+		stopping:    make(chan struct{}),
+		debounce:    DefaultDebounce,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Pause stops reading session logs. Changes are remembered and the latest
+// write per file is applied on Resume.
+func (s *JSONLSource) Pause() {
+	s.mu.Lock()
+	s.paused = true
+	s.mu.Unlock()
+}
+
+// Resume applies changes collected while paused and resumes reading
+func (s *JSONLSource) Resume() {
+	s.mu.Lock()
+	s.paused = false
+	s.mu.Unlock()
+	s.signal()
+}
+
+// Paused reports whether the source is paused
+func (s *JSONLSource) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// SetDebounce coalesces writes to the same file within d of the first
+// into a single re-read (0 = read on every write). The default is
+// DefaultDebounce.
+func (s *JSONLSource) SetDebounce(d time.Duration) {
+	s.mu.Lock()
+	s.debounce = d
+	s.mu.Unlock()
+	s.signal()
+}
+
+// Debounce returns the current debounce interval
+func (s *JSONLSource) Debounce() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.debounce
+}
+
+// signal wakes the forwarding loop after a setting change
+func (s *JSONLSource) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// SetWatchMode selects how log changes are detected; call before Start
+func (s *JSONLSource) SetWatchMode(mode watcher.Mode) {
+	s.watchMode = mode
+}
+
+// WatchMode returns how log changes are detected: after Start, the mode
+// auto resolved to
+func (s *JSONLSource) WatchMode() watcher.Mode {
+	if s.watcher != nil {
+		return s.watcher.Mode()
+	}
+	return s.watchMode
+}
+
+// SetFailureFunc sets a function called once if the file watcher dies or
+// keeps failing, after which no more status updates arrive from this source
+func (s *JSONLSource) SetFailureFunc(fn func(err error)) {
+	s.onFailure = fn
+}
+
+// fail reports a watcher failure, unless the source is being stopped
+func (s *JSONLSource) fail(err error) {
+	select {
+	case <-s.stopping:
+		return
+	default:
+	}
+	s.failOnce.Do(func() {
+		logging.Logger().Error("jsonl watcher failed", "error", err)
+		if s.onFailure != nil {
+			s.onFailure(err)
+		}
+	})
+}
+
+// Name returns "jsonl"
+func (s *JSONLSource) Name() string {
+	return "jsonl"
+}
+
+// Start starts the file watcher and forwards file changes to sink
+func (s *JSONLSource) Start(sink Sink) error {
+	w, err := watcher.New(s.projectsDir, s.watchMode)
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	if err := w.Start(); err != nil {
+		return fmt.Errorf("failed to start watcher: %w", err)
+	}
+	s.watcher = w
+
+	// Seed the states of existing sessions instead of waiting for writes
+	seeded := 0
+	for _, event := range w.Latest() {
+		if status, _ := sink.Seed(event.ProjectName, event.SessionID, event.Path); status != nil {
+			seeded++
+		}
+	}
+	logging.Logger().Debug("seeded project states from session logs", "projects", seeded)
+
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		s.forward(w.Events(), sink)
+		s.fail(fmt.Errorf("watcher stopped unexpectedly"))
+	}()
+	go func() {
+		defer s.wg.Done()
+		var recent []time.Time
+		for err := range w.Errors() {
+			logging.Logger().Warn("watcher error", "error", err)
+
+			now := time.Now()
+			recent = append(recent, now)
+			for len(recent) > 0 && now.Sub(recent[0]) > watcherErrorWindow {
+				recent = recent[1:]
+			}
+			if len(recent) > maxWatcherErrors {
+				s.fail(fmt.Errorf("%d watcher errors within %s, last: %w", len(recent), watcherErrorWindow, err))
+			}
+		}
+	}()
+
+	return nil
+}
+
+// forward delivers file changes to sink, holding them back while paused
+// and coalescing them per file while a debounce interval is set
+func (s *JSONLSource) forward(events <-chan watcher.Event, sink Sink) {
+	pending := watcher.NewCoalescer()
+	var flush <-chan time.Time
+
+	apply := func(event watcher.Event) {
+		if event.Removed {
+			logging.Logger().Debug("jsonl removed", "project", event.ProjectName, "path", event.Path)
+			sink.RemoveLog(event.Path)
+			return
+		}
+		logging.Logger().Debug("jsonl write", "project", event.ProjectName, "session", event.SessionID)
+		sink.Update(event.ProjectName, event.SessionID, event.Path)
+	}
+	// applyPending applies the files whose debounce interval has passed,
+	// or all of them, and waits for the next
+	applyPending := func(all bool) {
+		for _, event := range pending.Take(all) {
+			apply(event)
+		}
+		flush = nil
+		if next := pending.Next(); !next.IsZero() {
+			flush = time.After(time.Until(next))
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			s.mu.Lock()
+			paused, debounce := s.paused, s.debounce
+			s.mu.Unlock()
+
+			if !paused && debounce == 0 {
+				apply(event)
+				continue
+			}
+			pending.Add(event, debounce)
+			if !paused && flush == nil {
+				flush = time.After(debounce)
+			}
+
+		case <-flush:
+			if !s.Paused() {
+				applyPending(false)
+			} else {
+				flush = nil
+			}
+
+		case <-s.wake:
+			if !s.Paused() {
+				applyPending(true)
+			}
+		}
+	}
+}
+
+// Stop stops the file watcher
+func (s *JSONLSource) Stop() error {
+	if s.watcher == nil {
+		return nil
+	}
+	close(s.stopping)
+	err := s.watcher.Stop()
+	s.wg.Wait()
+	return err
+}
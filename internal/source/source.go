@@ -0,0 +1,31 @@
+package source
+
+import "github.com/sho7650/claude-watch-status/internal/state"
+
+// Sink receives status inputs from sources. *state.Manager implements Sink.
+type Sink interface {
+	// Update applies a change to a session log file
+	Update(projectName, sessionID, filePath string) (*state.ProjectStatus, error)
+	// Seed applies a session log found at startup, marking the project
+	// inactive if the log is old
+	Seed(projectName, sessionID, filePath string) (*state.ProjectStatus, error)
+	// UpdateFromHook applies an already-classified hook event
+	UpdateFromHook(event state.HookEvent) *state.ProjectStatus
+	// RemoveLog forgets the sessions of a deleted session log or project
+	// directory
+	RemoveLog(filePath string) []state.StatusEvent
+}
+
+// Source produces status inputs and pushes them into a Sink.
+//
+// Lifecycle: Start is called once and must return after the source is
+// ready (initial scans done, goroutines launched). Stop releases all
+// resources; no calls into the sink happen after Stop returns.
+type Source interface {
+	// Name identifies the source in logs and status ("jsonl", "hooks", ...)
+	Name() string
+	// Start begins delivering inputs to sink
+	Start(sink Sink) error
+	// Stop stops delivering inputs and releases resources
+	Stop() error
+}
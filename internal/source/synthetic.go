@@ -0,0 +1,67 @@
+package source
+
+import (
+	"sync"
+
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// SyntheticSource delivers programmatically generated inputs.
+// It drives the pipeline without a real filesystem or hook script,
+// for tests, demos and adapters for other agents.
+type SyntheticSource struct {
+	name string
+	mu   sync.RWMutex
+	sink Sink
+}
+
+// NewSynthetic creates a SyntheticSource with the given name
+func NewSynthetic(name string) *SyntheticSource {
+	return &SyntheticSource{name: name}
+}
+
+// Name returns the name given to NewSynthetic
+func (s *SyntheticSource) Name() string {
+	return s.name
+}
+
+// Start begins forwarding emitted inputs to sink
+func (s *SyntheticSource) Start(sink Sink) error {
+	s.mu.Lock()
+	s.sink = sink
+	s.mu.Unlock()
+	return nil
+}
+
+// Stop stops forwarding; later emissions are dropped
+func (s *SyntheticSource) Stop() error {
+	s.mu.Lock()
+	s.sink = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// EmitState delivers a fully-classified state change for a project
+func (s *SyntheticSource) EmitState(event state.HookEvent) *state.ProjectStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.sink == nil {
+		return nil
+	}
+	if event.Source == "" {
+		event.Source = s.name
+	}
+	return s.sink.UpdateFromHook(event)
+}
+
+// EmitFile delivers a session log change, as if the file had been written
+func (s *SyntheticSource) EmitFile(projectName, sessionID, filePath string) (*state.ProjectStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.sink == nil {
+		return nil, nil
+	}
+	return s.sink.Update(projectName, sessionID, filePath)
+}
@@ -0,0 +1,94 @@
+package source
+
+import (
+	"testing"
+
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// recordingSink records the inputs it receives instead of reading session logs
+type recordingSink struct {
+	files []string
+	hooks []state.HookEvent
+}
+
+func (s *recordingSink) Update(projectName, sessionID, filePath string) (*state.ProjectStatus, error) {
+	s.files = append(s.files, filePath)
+	return &state.ProjectStatus{Name: projectName, SessionID: sessionID, FilePath: filePath}, nil
+}
+
+func (s *recordingSink) Seed(projectName, sessionID, filePath string) (*state.ProjectStatus, error) {
+	return s.Update(projectName, sessionID, filePath)
+}
+
+func (s *recordingSink) UpdateFromHook(event state.HookEvent) *state.ProjectStatus {
+	s.hooks = append(s.hooks, event)
+	return &state.ProjectStatus{Name: event.ProjectName, State: event.State, Source: event.Source}
+}
+
+func (s *recordingSink) RemoveLog(filePath string) []state.StatusEvent {
+	return nil
+}
+
+func TestSyntheticSourceLifecycle(t *testing.T) {
+	src := NewSynthetic("demo")
+	if got := src.Name(); got != "demo" {
+		t.Errorf("Name() = %q, want demo", got)
+	}
+
+	// Nothing is delivered before Start
+	if status := src.EmitState(state.HookEvent{ProjectName: "p", State: "thinking"}); status != nil {
+		t.Errorf("EmitState before Start = %+v, want nil", status)
+	}
+
+	sink := &recordingSink{}
+	if err := src.Start(sink); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	status := src.EmitState(state.HookEvent{ProjectName: "p", State: "thinking"})
+	if status == nil || status.Source != "demo" {
+		t.Errorf("EmitState() = %+v, want source demo", status)
+	}
+	src.EmitState(state.HookEvent{ProjectName: "p", State: "completed", Source: "hooks"})
+	if len(sink.hooks) != 2 || sink.hooks[1].Source != "hooks" {
+		t.Errorf("hook events = %+v, want the explicit source kept", sink.hooks)
+	}
+
+	status, err := src.EmitFile("p", "s1", "/nonexistent/s1.jsonl")
+	if err != nil || status == nil || status.FilePath != "/nonexistent/s1.jsonl" {
+		t.Errorf("EmitFile() = %+v, %v", status, err)
+	}
+
+	if err := src.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if status := src.EmitState(state.HookEvent{ProjectName: "p", State: "thinking"}); status != nil {
+		t.Errorf("EmitState after Stop = %+v, want nil", status)
+	}
+	if status, err := src.EmitFile("p", "s1", "/nonexistent/s1.jsonl"); status != nil || err != nil {
+		t.Errorf("EmitFile after Stop = %+v, %v, want nil", status, err)
+	}
+	if len(sink.hooks) != 2 || len(sink.files) != 1 {
+		t.Errorf("sink got %d hook events and %d files after Stop, want 2 and 1", len(sink.hooks), len(sink.files))
+	}
+}
+
+// SyntheticSource drives a real state manager without session logs
+func TestSyntheticSourceDrivesManager(t *testing.T) {
+	manager := state.NewManager()
+	src := NewSynthetic("synthetic")
+	if err := src.Start(manager); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer src.Stop()
+
+	src.EmitState(state.HookEvent{SessionID: "s1", ProjectName: "demo", Icon: "🔧", State: "running: Bash"})
+	status := manager.Get("demo")
+	if status == nil {
+		t.Fatal("project not created")
+	}
+	if status.State != "running: Bash" || status.Source != "synthetic" || status.SessionID != "s1" {
+		t.Errorf("status = %q from %q (session %q), want running: Bash from synthetic (session s1)",
+			status.State, status.Source, status.SessionID)
+	}
+}
@@ -0,0 +1,40 @@
+package state
+
+// EventAcknowledged is the type of events reporting that a user saw a
+// project's state; the status is unchanged except for Acknowledged
+const EventAcknowledged = "acknowledged"
+
+// Acknowledgeable reports whether a phase asks for the user's attention,
+// so that it can be acknowledged
+func (p Phase) Acknowledgeable() bool {
+	switch p {
+	case PhaseWaiting, PhaseCompleted, PhaseInterrupted, PhaseError:
+		return true
+	default:
+		return false
+	}
+}
+
+// Acknowledge marks a project's waiting, completed, interrupted or error
+// state as seen, so UIs stop highlighting it until the state changes.
+// Reports false if the project is unknown or in no such state.
+func (m *Manager) Acknowledge(projectName string) (*ProjectStatus, bool) {
+	m.mu.Lock()
+	cur := m.projects[projectName]
+	if cur == nil || !PhaseOf(cur.State).Acknowledgeable() {
+		m.mu.Unlock()
+		return nil, false
+	}
+	if cur.Acknowledged {
+		m.mu.Unlock()
+		return cur, true
+	}
+
+	acked := *cur
+	acked.Acknowledged = true
+	acked.Seq++
+	m.version++
+	m.projects[projectName] = &acked
+	m.publish(m.record(StatusEvent{Project: acked, Type: EventAcknowledged}))
+	return &acked, true
+}
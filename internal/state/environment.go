@@ -0,0 +1,154 @@
+package state
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+)
+
+// PermissionBypass is the permission mode of sessions started with
+// --dangerously-skip-permissions: tools run without approval prompts
+const PermissionBypass = "bypassPermissions"
+
+// PermissionPlan is the permission mode of plan mode: Claude plans
+// without editing files or running tools that change anything
+const PermissionPlan = "plan"
+
+// PermissionAcceptEdits is the permission mode accepting file edits
+// without approval prompts; other tools still ask
+const PermissionAcceptEdits = "acceptEdits"
+
+// maxMCPServers bounds the MCP servers listed per session
+const maxMCPServers = 20
+
+// Environment describes how a session runs. It is gathered from hook
+// payloads and session log entries as they arrive.
+type Environment struct {
+	Model          string   `json:"model,omitempty"`
+	PermissionMode string   `json:"permission_mode,omitempty"` // default, acceptEdits, plan, bypassPermissions
+	MCPServers     []string `json:"mcp_servers,omitempty"`     // MCP servers whose tools were used
+
+	// Terminal is where the session runs, reported by the hook relay
+	Terminal *Terminal `json:"terminal,omitempty"`
+}
+
+// Terminal tells which terminal window a session runs in, so UIs can
+// raise it. Every field is optional.
+type Terminal struct {
+	Program      string `json:"program,omitempty"`       // TERM_PROGRAM: iTerm.app, Apple_Terminal, WezTerm, tmux, ...
+	TTY          string `json:"tty,omitempty"`           // e.g. /dev/ttys003
+	TmuxPane     string `json:"tmux_pane,omitempty"`     // e.g. %3
+	TmuxSocket   string `json:"tmux_socket,omitempty"`   // socket of the tmux server
+	ITermSession string `json:"iterm_session,omitempty"` // iTerm2 session unique ID
+	WezTermPane  string `json:"wezterm_pane,omitempty"`  // WezTerm pane ID
+	KittyWindow  string `json:"kitty_window,omitempty"`  // kitty window ID
+	XWindow      string `json:"x_window,omitempty"`      // X11 window ID (WINDOWID), for wmctrl
+}
+
+// Focusable reports whether anything is known that can raise the
+// terminal: a tmux pane, a terminal's own session ID, an X11 window or a
+// Terminal.app tty
+func (t *Terminal) Focusable() bool {
+	return t != nil && (t.TmuxPane != "" || t.ITermSession != "" || t.WezTermPane != "" || t.KittyWindow != "" || t.XWindow != "" ||
+		(t.Program == "Apple_Terminal" && t.TTY != ""))
+}
+
+// Dangerous reports whether the session runs tools without approval
+func (e *Environment) Dangerous() bool {
+	return e != nil && e.PermissionMode == PermissionBypass
+}
+
+// ModelFamily returns the short name of the session's model — opus,
+// sonnet or haiku — or the model ID without its "claude-" prefix for
+// other models, "" if unknown
+func (e *Environment) ModelFamily() string {
+	if e == nil || e.Model == "" {
+		return ""
+	}
+	for _, family := range []string{"opus", "sonnet", "haiku"} {
+		if strings.Contains(e.Model, family) {
+			return family
+		}
+	}
+	return strings.TrimPrefix(e.Model, "claude-")
+}
+
+// empty reports whether nothing is known
+func (e Environment) empty() bool {
+	return e.Model == "" && e.PermissionMode == "" && len(e.MCPServers) == 0 && e.Terminal == nil
+}
+
+// with returns a copy of e updated by newer information: a reported
+// model, permission mode or terminal replaces the previous one, MCP
+// servers add up.
+// e may be nil; the result never shares memory with e.
+func (e *Environment) with(update Environment) *Environment {
+	next := &Environment{}
+	if e != nil {
+		*next = *e
+		next.MCPServers = slices.Clone(e.MCPServers)
+	}
+	if update.Model != "" {
+		next.Model = update.Model
+	}
+	if update.PermissionMode != "" {
+		next.PermissionMode = update.PermissionMode
+	}
+	if update.Terminal != nil {
+		t := *update.Terminal
+		next.Terminal = &t
+	}
+	for _, server := range update.MCPServers {
+		if !slices.Contains(next.MCPServers, server) && len(next.MCPServers) < maxMCPServers {
+			next.MCPServers = append(next.MCPServers, server)
+		}
+	}
+	slices.Sort(next.MCPServers)
+	return next
+}
+
+// equal reports whether e and o describe the same environment
+func (e *Environment) equal(o *Environment) bool {
+	if e == nil || o == nil {
+		return e == o
+	}
+	return e.Model == o.Model && e.PermissionMode == o.PermissionMode && slices.Equal(e.MCPServers, o.MCPServers) &&
+		(e.Terminal == nil) == (o.Terminal == nil) && (e.Terminal == nil || *e.Terminal == *o.Terminal)
+}
+
+// MCPServer returns the server of an MCP tool (mcp__<server>__<tool>), or ""
+func MCPServer(toolName string) string {
+	rest, ok := strings.CutPrefix(toolName, "mcp__")
+	if !ok {
+		return ""
+	}
+	server, _, _ := strings.Cut(rest, "__")
+	return server
+}
+
+// EnvironmentFromTool returns the environment revealed by a tool call
+func EnvironmentFromTool(toolName string) Environment {
+	if server := MCPServer(toolName); server != "" {
+		return Environment{MCPServers: []string{server}}
+	}
+	return Environment{}
+}
+
+// entryEnvironment returns the environment recorded in a session log entry
+func entryEnvironment(entry *parser.Entry) Environment {
+	env := Environment{PermissionMode: entry.PermissionMode}
+	if entry.Message == nil {
+		return env
+	}
+	// Synthetic messages (API errors, interruptions) have no real model
+	if !strings.HasPrefix(entry.Message.Model, "<") {
+		env.Model = entry.Message.Model
+	}
+	for _, c := range entry.Message.Content {
+		if server := MCPServer(c.Name); c.Type == "tool_use" && server != "" {
+			env.MCPServers = append(env.MCPServers, server)
+		}
+	}
+	return env
+}
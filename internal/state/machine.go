@@ -0,0 +1,148 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Phase is a coarse classification of a project state used for transition rules
+type Phase int
+
+const (
+	PhaseUnknown Phase = iota
+	PhaseStarted
+	PhaseUserInput
+	PhaseWorking
+	PhaseWaiting
+	PhaseCompleted
+	PhaseInterrupted
+	PhaseError
+	PhaseEnded
+)
+
+// String returns the phase name
+func (p Phase) String() string {
+	switch p {
+	case PhaseStarted:
+		return "started"
+	case PhaseUserInput:
+		return "user_input"
+	case PhaseWorking:
+		return "working"
+	case PhaseWaiting:
+		return "waiting"
+	case PhaseCompleted:
+		return "completed"
+	case PhaseInterrupted:
+		return "interrupted"
+	case PhaseError:
+		return "error"
+	case PhaseEnded:
+		return "ended"
+	default:
+		return "unknown"
+	}
+}
+
+// ParsePhase returns the phase with the given name (see Phase.String)
+func ParsePhase(name string) (Phase, bool) {
+	for p := PhaseStarted; p <= PhaseEnded; p++ {
+		if p.String() == name {
+			return p, true
+		}
+	}
+	return PhaseUnknown, false
+}
+
+// PhaseOf classifies a state text into a phase
+func PhaseOf(state string) Phase {
+	switch {
+	case state == "session started":
+		return PhaseStarted
+	case state == "session ended":
+		return PhaseEnded
+	case state == "user input", state == "processing prompt":
+		return PhaseUserInput
+	case state == "waiting approval":
+		return PhaseWaiting
+	case state == "completed":
+		return PhaseCompleted
+	case state == "interrupted":
+		return PhaseInterrupted
+	case state == "max tokens", state == "tool error":
+		return PhaseError
+	case state == "processing", state == "thinking", state == "responding",
+		state == "calling tool", state == "continuing", strings.HasPrefix(state, "running"):
+		return PhaseWorking
+	default:
+		return PhaseUnknown
+	}
+}
+
+// RunningTool returns the tool named by a "running: <tool>" state, or ""
+func RunningTool(state string) string {
+	tool, _ := strings.CutPrefix(state, "running: ")
+	if tool == state {
+		return ""
+	}
+	return tool
+}
+
+// sourcePriority ranks status sources; higher values are more authoritative
+func sourcePriority(source string) int {
+	switch source {
+	case "hooks":
+		return 2
+	case "jsonl":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// lowerPriorityTransitions lists the phases a less authoritative source may
+// move a project into, keyed by the phase set by the more authoritative one.
+// Hooks report precise lifecycle events, so JSONL may only advance a hooks
+// state where hooks have no signal of their own (e.g. a new user prompt).
+var lowerPriorityTransitions = map[Phase][]Phase{
+	PhaseStarted:     {PhaseUserInput, PhaseWorking},
+	PhaseUserInput:   {PhaseWorking, PhaseCompleted, PhaseInterrupted, PhaseError},
+	PhaseWorking:     {PhaseUserInput, PhaseWorking, PhaseWaiting, PhaseCompleted, PhaseInterrupted, PhaseError},
+	PhaseWaiting:     {PhaseUserInput, PhaseWorking, PhaseCompleted, PhaseInterrupted, PhaseError},
+	PhaseCompleted:   {PhaseUserInput},
+	PhaseInterrupted: {PhaseUserInput},
+	PhaseError:       {PhaseUserInput},
+	PhaseEnded:       {PhaseUserInput},
+	PhaseUnknown:     {PhaseUserInput, PhaseWorking, PhaseWaiting, PhaseCompleted, PhaseInterrupted, PhaseError},
+}
+
+// checkTransition reports whether next may replace cur as a project's
+// status, and the rule that decided, for traces.
+//
+// Rules:
+//  1. Events never replace a status that was caused by a newer event.
+//  2. Events from an equally or more authoritative source always apply.
+//  3. Events from a less authoritative source apply only if the
+//     transition is listed in lowerPriorityTransitions.
+func checkTransition(cur, next *ProjectStatus) (bool, string) {
+	if cur == nil {
+		return true, "first status of the project"
+	}
+
+	if !cur.EventTime.IsZero() && !next.EventTime.IsZero() && next.EventTime.Before(cur.EventTime) {
+		return false, fmt.Sprintf("event at %s is older than the current status (%s)",
+			next.EventTime.Format(traceTimeFormat), cur.EventTime.Format(traceTimeFormat))
+	}
+
+	if sourcePriority(next.Source) >= sourcePriority(cur.Source) {
+		return true, fmt.Sprintf("%s is at least as authoritative as %s", next.Source, cur.Source)
+	}
+
+	from, to := PhaseOf(cur.State), PhaseOf(next.State)
+	for _, allowed := range lowerPriorityTransitions[from] {
+		if allowed == to {
+			return true, fmt.Sprintf("%s may move a %s status from %s to %s", next.Source, cur.Source, from, to)
+		}
+	}
+	return false, fmt.Sprintf("%s may not move a %s status from %s to %s", next.Source, cur.Source, from, to)
+}
@@ -0,0 +1,74 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSourcePriority(t *testing.T) {
+	if !(sourcePriority("hooks") > sourcePriority("jsonl")) {
+		t.Errorf("hooks (%d) must outrank jsonl (%d)", sourcePriority("hooks"), sourcePriority("jsonl"))
+	}
+	if !(sourcePriority("jsonl") > sourcePriority("")) {
+		t.Errorf("jsonl (%d) must outrank unknown sources (%d)", sourcePriority("jsonl"), sourcePriority(""))
+	}
+}
+
+func TestCheckTransition(t *testing.T) {
+	t0 := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	status := func(source, state string, at time.Time) *ProjectStatus {
+		return &ProjectStatus{Source: source, State: state, EventTime: at}
+	}
+
+	tests := []struct {
+		name string
+		cur  *ProjectStatus
+		next *ProjectStatus
+		want bool
+	}{
+		{"first status", nil, status("jsonl", "thinking", t0), true},
+		{"older event", status("jsonl", "thinking", t0), status("jsonl", "responding", t0.Add(-time.Second)), false},
+		{"older event from hooks", status("jsonl", "thinking", t0), status("hooks", "waiting approval", t0.Add(-time.Second)), false},
+		{"no event times", status("hooks", "waiting approval", time.Time{}), status("hooks", "running: Bash", time.Time{}), true},
+
+		// Equally or more authoritative sources always apply
+		{"hooks over jsonl", status("jsonl", "completed", t0), status("hooks", "running: Bash", t0), true},
+		{"hooks over hooks", status("hooks", "completed", t0), status("hooks", "session ended", t0), true},
+		{"jsonl over jsonl", status("jsonl", "completed", t0), status("jsonl", "interrupted", t0), true},
+		{"jsonl over unknown source", status("", "completed", t0), status("jsonl", "thinking", t0), true},
+
+		// jsonl may only advance hooks states along lowerPriorityTransitions
+		{"jsonl new prompt after hooks completed", status("hooks", "completed", t0), status("jsonl", "user input", t0), true},
+		{"jsonl working after hooks completed", status("hooks", "completed", t0), status("jsonl", "thinking", t0), false},
+		{"jsonl tool after hooks waiting", status("hooks", "waiting approval", t0), status("jsonl", "running: Bash", t0), true},
+		{"jsonl completed after hooks working", status("hooks", "running: Edit", t0), status("jsonl", "completed", t0), true},
+		{"jsonl waiting after hooks working", status("hooks", "running: Edit", t0), status("jsonl", "waiting approval", t0), true},
+		{"jsonl waiting after hooks prompt", status("hooks", "processing prompt", t0), status("jsonl", "waiting approval", t0), false},
+		{"jsonl started after hooks working", status("hooks", "thinking", t0), status("jsonl", "session started", t0), false},
+		{"jsonl working after hooks ended", status("hooks", "session ended", t0), status("jsonl", "responding", t0), false},
+		{"jsonl prompt after hooks ended", status("hooks", "session ended", t0), status("jsonl", "user input", t0), true},
+		{"jsonl ended after hooks interrupted", status("hooks", "interrupted", t0), status("jsonl", "session ended", t0), false},
+		{"jsonl error after hooks unknown", status("hooks", "idle", t0), status("jsonl", "tool error", t0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := checkTransition(tt.cur, tt.next)
+			if got != tt.want {
+				t.Errorf("checkTransition() = %v (%s), want %v", got, reason, tt.want)
+			}
+			if reason == "" {
+				t.Error("checkTransition() gave no reason")
+			}
+		})
+	}
+}
+
+// Every phase has an entry, so a less authoritative source is never
+// locked out of a project by a phase added without one
+func TestLowerPriorityTransitionsCoverPhases(t *testing.T) {
+	for p := PhaseUnknown; p <= PhaseEnded; p++ {
+		if _, ok := lowerPriorityTransitions[p]; !ok {
+			t.Errorf("no lower priority transitions from %s", p)
+		}
+	}
+}
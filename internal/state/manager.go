@@ -1,62 +1,217 @@
 package state
 
 import (
-	"bufio"
+	"context"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/logging"
 	"github.com/sho7650/claude-watch-status/internal/parser"
 )
 
 // ProjectStatus represents the current status of a project
 type ProjectStatus struct {
-	Name        string    `json:"name"`
-	Icon        string    `json:"icon"`
-	State       string    `json:"state"`
-	Detail      string    `json:"detail,omitempty"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	SessionID   string    `json:"session_id,omitempty"`
-	Source      string    `json:"source"` // "hooks" or "jsonl"
-	FilePath    string    `json:"-"`
-	FileTime    time.Time `json:"-"`
-	ToolName    string    `json:"-"` // Current tool name for timeout calculation
-	IsEstimated bool      `json:"-"` // true if state is based on timeout heuristics
+	Name         string           `json:"name"`
+	Icon         string           `json:"icon"`
+	State        string           `json:"state"`
+	Detail       string           `json:"detail,omitempty"`  // What the current tool works on: file path, command, URL, ...
+	Request      string           `json:"request,omitempty"` // What a project waiting for approval asks for: "Bash: rm -rf build/"
+	UpdatedAt    time.Time        `json:"updated_at"`
+	ReceivedAt   time.Time        `json:"received_at"` // When the event was observed, for latency metrics
+	SessionID    string           `json:"session_id,omitempty"`
+	Source       string           `json:"source"` // "hooks" or "jsonl"
+	Tier         string           `json:"tier,omitempty"`
+	Seq          uint64           `json:"seq"`                    // Per-project sequence number, incremented on every change
+	Subagents    []SubagentStatus `json:"subagents,omitempty"`    // Task subagents of the current turn
+	Environment  *Environment     `json:"environment,omitempty"`  // Model, permission mode and MCP servers of the session
+	Acknowledged bool             `json:"acknowledged,omitempty"` // A user saw the current state; cleared when it changes
+	Muted        bool             `json:"muted,omitempty"`        // Notifications are silenced; the state still updates
+	MutedUntil   time.Time        `json:"muted_until,omitzero"`   // When the mute ends; zero while muted until unmuted
+	ProjectPath  string           `json:"project_path,omitempty"` // Directory the name was derived from, telling same-named projects apart
+	Path         string           `json:"path,omitempty"`         // Working directory of the session
+	Branch       string           `json:"branch,omitempty"`       // Git branch (or detached commit) checked out there
+	FilePath     string           `json:"-"`
+	FileTime     time.Time        `json:"-"`
+	EventTime    time.Time        `json:"-"` // When the underlying event happened, for ordering
+	ToolName     string           `json:"-"` // Current tool name for timeout calculation
+	IsEstimated  bool             `json:"-"` // true if state is based on timeout heuristics
+
+	// ToolStartedAt is when the tool the state shows running started
+	ToolStartedAt time.Time `json:"tool_started_at,omitzero"`
 }
 
 // StatusEvent represents a status change event
 type StatusEvent struct {
 	Project ProjectStatus
-	Type    string // "update", "idle_approval", "idle_completed"
+	Type    string // "update", "idle_approval", "idle_completed", EventAcknowledged, EventMuted, EventSessionRemoved, EventResync
+	ID      uint64 // Manager version after this change; increases with every event
+
+	// ProjectRemoved is set on EventSessionRemoved when the project went
+	// with its last session
+	ProjectRemoved bool
+
+	// Snapshot and Dropped are set on EventResync: the status of every
+	// project, and how many events the subscriber missed
+	Snapshot []ProjectStatus
+	Dropped  uint64
 }
 
+// EventSessionRemoved is the type of events reporting that a session was
+// forgotten: its log was deleted, or its project pruned after a long
+// time without activity. Project carries the project name and the
+// session ID only; the project status itself did not change.
+const EventSessionRemoved = "session_removed"
+
+// maxReplayEvents bounds the events kept for stream clients that reconnect
+const maxReplayEvents = 256
+
 // Manager manages the state of all projects
 type Manager struct {
 	projects  map[string]*ProjectStatus
+	meta      map[string]*projectMeta // paths and sessions, guarded by mu
 	mu        sync.RWMutex
-	listeners []chan StatusEvent
+	listeners []*subscriber
 	listMu    sync.RWMutex
+	resyncing bool          // a resync is scheduled, guarded by listMu
+	closed    bool          // Close was called, guarded by listMu
+	dropped   atomic.Uint64 // events subscribers missed
+	tracers   []chan Trace
+	traceMu   sync.RWMutex
+	version   uint64 // incremented on every change, guarded by mu
+
+	replay      []StatusEvent // the last maxReplayEvents events, guarded by mu
+	replayFloor uint64        // ID of the last event dropped from replay, guarded by mu
+	started     uint64        // first version of this run
+
+	transitions map[string]*transitionLog // keyed by session ID, guarded by mu
+
+	hideAfter   time.Duration // inactive projects are hidden after this, guarded by mu
+	deleteAfter time.Duration // and forgotten after this, guarded by mu
+
+	heartbeat time.Duration        // republish unchanged statuses this often, guarded by mu
+	emitted   map[string]time.Time // when each project's last event was published, guarded by mu
+	branches  map[string]gitBranch // git branch per working directory, guarded by mu
+
+	names   projectNames // unique names of project directories, guarded by namesMu
+	namesMu sync.Mutex
+
+	tierFor  func(projectName string) config.Tier
+	groupFor func(projectName string) string
+	nameFor  func(dir string) string
+
+	pendingTools map[string]pendingTool // started tool calls by tool_use id, guarded by mu
+	calls        toolCalls              // unanswered tool calls of session logs, has its own lock
+	onToolRun    func(run ToolRun)      // receives measured tool calls, guarded by mu
+
+	mutes map[string]time.Time // muted projects and when each mute ends (zero = never), guarded by mu
+
+	lastActivity time.Time     // last accepted source update, guarded by mu
+	activity     chan struct{} // signalled on accepted source updates
 }
 
 // NewManager creates a new state manager
 func NewManager() *Manager {
+	// Event IDs start at the startup time, so IDs a client kept from a
+	// previous daemon run are never mistaken for recent ones
+	start := uint64(time.Now().UnixMicro())
 	return &Manager{
-		projects:  make(map[string]*ProjectStatus),
-		listeners: make([]chan StatusEvent, 0),
+		projects:    make(map[string]*ProjectStatus),
+		meta:        make(map[string]*projectMeta),
+		listeners:   make([]*subscriber, 0),
+		activity:    make(chan struct{}, 1),
+		version:     start,
+		replayFloor: start,
+		started:     start,
+		transitions: make(map[string]*transitionLog),
+		emitted:     make(map[string]time.Time),
+		branches:    make(map[string]gitBranch),
+		names:       newProjectNames(),
+		mutes:       make(map[string]time.Time),
+
+		pendingTools: make(map[string]pendingTool),
 	}
 }
 
+// SetTierFunc sets the function used to tag project statuses with their tier
+func (m *Manager) SetTierFunc(fn func(projectName string) config.Tier) {
+	m.tierFor = fn
+}
+
+// SetGroupFunc sets the function used to look up a project's group
+func (m *Manager) SetGroupFunc(fn func(projectName string) string) {
+	m.groupFor = fn
+}
+
+// SetProjectNameFunc sets the function mapping a session's working
+// directory to a configured project name; "" keeps the name the source
+// derived from the directory
+func (m *Manager) SetProjectNameFunc(fn func(dir string) string) {
+	m.nameFor = fn
+}
+
+func (m *Manager) tier(projectName string) string {
+	if m.tierFor == nil {
+		return string(config.TierNormal)
+	}
+	return string(m.tierFor(projectName))
+}
+
 // Update updates the status for a project from a JSONL file change
 func (m *Manager) Update(projectName, sessionID, filePath string) (*ProjectStatus, error) {
-	entry, err := readLastEntry(filePath)
+	if agentID, ok := parser.SubagentLogID(filePath); ok {
+		return m.updateSubagentLog(projectName, sessionID, agentID, filePath)
+	}
+
+	// Read the last two entries so abandoned tool calls can be detected
+	entries, err := parser.ReadLastEntries(filePath, 2)
 	if err != nil {
 		return nil, err
 	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
 
+	entry := entries[len(entries)-1]
+	projectName = m.logProjectName(projectName, filePath, entry.CWD)
 	state := parser.ParseState(entry)
+	var signal string
+	if m.tracing() {
+		signal = entrySignal(entry)
+	}
 	if state.Skip {
+		if signal != "" {
+			m.trace(Trace{Project: projectName, SessionID: sessionID, Source: "jsonl", Signal: signal,
+				Decision: TraceSkipped, Reason: "entry does not change the status"})
+		}
 		return nil, nil
 	}
+	calls, err := m.calls.read(filePath)
+	if err != nil {
+		// Without the log's tool calls, the previous entry tells whether
+		// this one abandons a call
+		calls.abandoned = len(entries) == 2 && parser.IsOrphanedToolUse(entries[0], entry)
+	}
+	if calls.abandoned {
+		state = parser.State{Icon: "🛑", Text: "interrupted"}
+		signal += " (after an unanswered tool call)"
+	}
+	// Claude Code runs the calls of a turn in order: after a result, or
+	// with several calls requested, the first unanswered one is current
+	if call := calls.oldest; calls.pending {
+		switch {
+		case state.Text == "processing" || RunningTool(state.Text) != "":
+			state = parser.State{Icon: "🔧", Text: "running: " + call.name, ToolName: call.name, Detail: call.detail}
+		case state.Text == "calling tool":
+			state.ToolName, state.Detail = call.name, call.detail
+		}
+	}
 
 	// Get file modification time
 	info, err := os.Stat(filePath)
@@ -64,44 +219,265 @@ func (m *Manager) Update(projectName, sessionID, filePath string) (*ProjectStatu
 		return nil, err
 	}
 
+	receivedAt := time.Now()
+	eventTime := entryTime(entry, info.ModTime(), receivedAt)
+
+	// Subagent entries in the parent log update the subagent, not the parent
+	if entry.IsSidechain {
+		agentID := entry.AgentID
+		if agentID == "" {
+			agentID = "sidechain"
+		}
+		if state.Text == "user input" {
+			state = parser.State{Icon: "⏳", Text: "processing"}
+		}
+		u := SubagentUpdate{AgentID: agentID, Icon: state.Icon, State: state.Text, Time: eventTime}
+		status := m.commitSubagent(projectName, sessionID, u)
+		if signal != "" {
+			m.traceSubagent(projectName, sessionID, "jsonl", signal, u, status)
+		}
+		return status, nil
+	}
+
 	m.mu.Lock()
 	status := &ProjectStatus{
 		Name:        projectName,
 		Icon:        state.Icon,
 		State:       state.Text,
-		Detail:      state.ToolName,
-		UpdatedAt:   time.Now(),
+		Detail:      state.Detail,
+		UpdatedAt:   eventTime,
+		ReceivedAt:  receivedAt,
 		SessionID:   sessionID,
 		Source:      "jsonl",
+		Tier:        m.tier(projectName),
 		FilePath:    filePath,
 		FileTime:    info.ModTime(),
+		EventTime:   eventTime,
 		ToolName:    state.ToolName,
 		IsEstimated: state.IsEstimated,
 	}
+	env := entryEnvironment(entry)
+	if env.PermissionMode == "" {
+		env.PermissionMode = calls.mode
+	}
+	m.observeSession(projectName, entry.CWD, env, status)
+	cur := m.projects[projectName]
+	status.ToolStartedAt = toolStartedAt(cur, status, false)
+	ok, reason := checkTransition(cur, status)
+	if signal != "" {
+		result := status
+		if !ok {
+			result = cur
+		}
+		m.traceStatus(status, cur, result, signal, ok, reason)
+	}
+	if !ok {
+		m.mu.Unlock()
+		logging.Logger().Debug("jsonl update rejected by state machine",
+			"project", projectName, "from", cur.State, "from_source", cur.Source, "to", status.State,
+			"out_of_order", status.EventTime.Before(cur.EventTime))
+		return nil, nil
+	}
+	status.Seq = nextSeq(cur)
+	status.Subagents = carrySubagents(cur, status)
+	status.Request = pendingRequest(status)
+	m.applyMute(status)
 	m.projects[projectName] = status
+	m.markActivity(receivedAt)
+	if m.repeats(cur, status) {
+		// Stored for idle detection, which needs the newer times and
+		// sequence number, but subscribers would see nothing new
+		status.Acknowledged = cur.Acknowledged
+		m.mu.Unlock()
+		return nil, nil
+	}
+	m.version++
+	m.publish(m.record(StatusEvent{Project: *status, Type: "update"}))
+	return status, nil
+}
+
+// maxClockSkew bounds how far in the future an entry timestamp may be
+// before it is treated as clock skew and replaced with the receipt time
+const maxClockSkew = 2 * time.Second
+
+// inactiveAge is how long a session log found at startup may have been
+// unchanged before its project is seeded as inactive
+const inactiveAge = activeSessionWindow
+
+// Seed applies the latest session log of a project found at startup. A
+// log unchanged for longer than inactiveAge marks the project "inactive"
+// instead: its last state, e.g. waiting approval, is long over.
+func (m *Manager) Seed(projectName, sessionID, filePath string) (*ProjectStatus, error) {
+	status, err := m.Update(projectName, sessionID, filePath)
+	if err != nil || status == nil || time.Since(status.FileTime) < inactiveAge {
+		return status, err
+	}
+
+	m.mu.Lock()
+	cur := m.projects[status.Name]
+	// A newer event arrived meanwhile
+	if cur != status {
+		m.mu.Unlock()
+		return status, nil
+	}
+	inactive := *cur
+	inactive.Icon, inactive.State, inactive.Detail, inactive.Request = "💤", "inactive", "", ""
+	inactive.Subagents = nil
+	inactive.Acknowledged = false
+	inactive.Seq++
+	m.version++
+	m.projects[status.Name] = &inactive
+	m.publish(m.record(StatusEvent{Project: inactive, Type: "update"}))
+	return &inactive, nil
+}
+
+// entryTime returns when a JSONL entry happened: its own timestamp if
+// present and plausible, otherwise the file modification time
+func entryTime(entry *parser.Entry, modTime, receivedAt time.Time) time.Time {
+	t := modTime
+	if ts, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
+		t = ts.Local()
+	}
+	if t.After(receivedAt.Add(maxClockSkew)) {
+		return receivedAt
+	}
+	return t
+}
+
+// SetHeartbeat makes a source update that repeats a project's status
+// publish an event anyway once interval has passed since the project's
+// last event; 0, the default, never publishes repeats
+func (m *Manager) SetHeartbeat(interval time.Duration) {
+	m.mu.Lock()
+	m.heartbeat = interval
 	m.mu.Unlock()
+}
 
-	m.notify(StatusEvent{Project: *status, Type: "update"})
-	return status, nil
+// Heartbeat returns the interval set by SetHeartbeat
+func (m *Manager) Heartbeat() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.heartbeat
+}
+
+// repeats reports whether status only repeats cur, so subscribers would
+// see nothing new, and no heartbeat is due. Caller must hold m.mu.
+func (m *Manager) repeats(cur, status *ProjectStatus) bool {
+	if cur == nil || cur.Icon != status.Icon || cur.State != status.State || cur.Detail != status.Detail ||
+		cur.SessionID != status.SessionID || cur.Source != status.Source || cur.Tier != status.Tier ||
+		cur.IsEstimated != status.IsEstimated || len(cur.Subagents) != len(status.Subagents) ||
+		cur.Path != status.Path || cur.Branch != status.Branch || !cur.Environment.equal(status.Environment) ||
+		!cur.ToolStartedAt.Equal(status.ToolStartedAt) {
+		return false
+	}
+	return m.heartbeat <= 0 || time.Since(m.emitted[status.Name]) < m.heartbeat
 }
 
-// UpdateFromHook updates the status from a hooks event
+// nextSeq returns the sequence number for the change following cur
+func nextSeq(cur *ProjectStatus) uint64 {
+	if cur == nil {
+		return 1
+	}
+	return cur.Seq + 1
+}
+
+// UpdateFromHook updates the status from a hooks event (or another
+// source delivering already-classified states)
 func (m *Manager) UpdateFromHook(event HookEvent) *ProjectStatus {
+	event.ProjectName = m.hookProjectName(event)
+	if event.SessionID == "" && event.TranscriptPath != "" {
+		event.SessionID = strings.TrimSuffix(filepath.Base(event.TranscriptPath), ".jsonl")
+	}
+
+	source := event.Source
+	if source == "" {
+		source = "hooks"
+	}
+
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
+	// Subagent-only events (SubagentStop) leave the parent state alone
+	if event.State == "" {
+		if event.Subagent == nil {
+			m.mu.Unlock()
+			return nil
+		}
+		status := m.applySubagent(event.ProjectName, event.SessionID, *event.Subagent)
+		if m.tracing() {
+			m.traceSubagent(event.ProjectName, event.SessionID, source, hookSignal(event), *event.Subagent, status)
+		}
+		if status == nil {
+			m.mu.Unlock()
+			return nil
+		}
+		m.publish(m.record(StatusEvent{Project: *status, Type: "update"}))
+		return status
+	}
+
+	now := time.Now()
+	eventTime := now
+	if !event.Time.IsZero() && event.Time.Before(now) {
+		eventTime = event.Time
+	}
 	status := &ProjectStatus{
-		Name:      event.ProjectName,
-		Icon:      event.Icon,
-		State:     event.State,
-		Detail:    event.ToolName,
-		UpdatedAt: time.Now(),
-		SessionID: event.SessionID,
-		Source:    "hooks",
+		Name:       event.ProjectName,
+		Icon:       event.Icon,
+		State:      event.State,
+		Detail:     event.Detail,
+		UpdatedAt:  eventTime,
+		ReceivedAt: now,
+		SessionID:  event.SessionID,
+		Source:     source,
+		Tier:       m.tier(event.ProjectName),
+		EventTime:  eventTime,
+		ToolName:   event.ToolName,
+		FilePath:   event.TranscriptPath,
+	}
+	m.observeSession(event.ProjectName, event.CWD, event.Environment, status)
+	m.trackTool(event, eventTime)
+	cur := m.projects[event.ProjectName]
+	inheritRequest(cur, status)
+	status.ToolStartedAt = toolStartedAt(cur, status, isPreToolUse(event))
+	ok, reason := checkTransition(cur, status)
+	if m.tracing() {
+		result := status
+		if !ok {
+			result = cur
+		}
+		m.traceStatus(status, cur, result, hookSignal(event), ok, reason)
+	}
+	if !ok {
+		logging.Logger().Debug("hook update rejected by state machine",
+			"project", event.ProjectName, "from", cur.State, "from_source", cur.Source, "to", status.State,
+			"out_of_order", status.EventTime.Before(cur.EventTime))
+		status = nil
+	} else {
+		status.Seq = nextSeq(cur)
+		status.Subagents = carrySubagents(cur, status)
+		status.Request = pendingRequest(status)
+		m.applyMute(status)
+		m.projects[event.ProjectName] = status
+		m.markActivity(now)
+		if m.repeats(cur, status) {
+			status.Acknowledged = cur.Acknowledged
+			status = nil
+		} else {
+			m.version++
+		}
 	}
-	m.projects[event.ProjectName] = status
 
-	m.notify(StatusEvent{Project: *status, Type: "update"})
+	// Task tool events also start or finish a subagent
+	if event.Subagent != nil {
+		if updated := m.applySubagent(event.ProjectName, event.SessionID, *event.Subagent); updated != nil {
+			status = updated
+		}
+	}
+
+	if status == nil {
+		m.mu.Unlock()
+		return nil
+	}
+	m.publish(m.record(StatusEvent{Project: *status, Type: "update"}))
 	return status
 }
 
@@ -110,10 +486,24 @@ type HookEvent struct {
 	SessionID     string `json:"session_id"`
 	HookEventName string `json:"hook_event_name"`
 	ToolName      string `json:"tool_name,omitempty"`
+	ToolUseID     string `json:"tool_use_id,omitempty"` // pairs PreToolUse with PostToolUse
+	Detail        string `json:"-"`                     // see ProjectStatus.Detail
 	CWD           string `json:"cwd"`
 	ProjectName   string `json:"-"`
-	Icon          string `json:"-"`
-	State         string `json:"-"`
+
+	// TranscriptPath is the session log of the event's session, if
+	// reported; it joins the hooks and session log views of the session
+	TranscriptPath string `json:"transcript_path,omitempty"`
+
+	Icon        string      `json:"-"`
+	State       string      `json:"-"`
+	Source      string      `json:"-"` // defaults to "hooks"
+	Environment Environment `json:"-"`
+	Time        time.Time   `json:"-"` // when the event happened; zero = when received
+
+	// Subagent is applied after the parent state; an event with an empty
+	// State only updates the subagent
+	Subagent *SubagentUpdate `json:"-"`
 }
 
 // Get returns the status for a specific project
@@ -138,22 +528,103 @@ func (m *Manager) GetAll() []ProjectStatus {
 	return statuses
 }
 
-// Subscribe creates a new subscription channel for status events
+// Subscribe creates a new subscription channel for status events. A
+// subscriber whose channel fills up misses events; once it has room
+// again it receives an EventResync with the current statuses instead.
 func (m *Manager) Subscribe() chan StatusEvent {
-	ch := make(chan StatusEvent, 100)
+	ch := make(chan StatusEvent, subscriberBuffer)
 	m.listMu.Lock()
-	m.listeners = append(m.listeners, ch)
-	m.listMu.Unlock()
+	defer m.listMu.Unlock()
+	if m.closed {
+		close(ch)
+		return ch
+	}
+	m.listeners = append(m.listeners, &subscriber{ch: ch})
 	return ch
 }
 
+// SubscribeContext subscribes like Subscribe until ctx is done; the
+// channel is closed then, ending loops over it
+func (m *Manager) SubscribeContext(ctx context.Context) chan StatusEvent {
+	ch := m.Subscribe()
+	context.AfterFunc(ctx, func() { m.Unsubscribe(ch) })
+	return ch
+}
+
+// Close ends every subscription, closing the channels, and refuses new
+// ones with a closed channel. Sources should be stopped before: later
+// changes reach no subscriber.
+func (m *Manager) Close() {
+	m.listMu.Lock()
+	defer m.listMu.Unlock()
+	m.closed = true
+	for _, sub := range m.listeners {
+		close(sub.ch)
+	}
+	m.listeners = nil
+}
+
+// SubscribeWithSnapshot atomically subscribes to status events and captures
+// a snapshot of all projects. Events with an ID less than or equal to
+// the returned version are already reflected in the snapshot and should be
+// skipped; all later events are guaranteed to be delivered to the channel.
+func (m *Manager) SubscribeWithSnapshot() (chan StatusEvent, []ProjectStatus, uint64) {
+	// Holding mu blocks changes, so no change can fall between the
+	// snapshot and the subscription
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ch := m.Subscribe()
+	statuses := make([]ProjectStatus, 0, len(m.projects))
+	for _, status := range m.projects {
+		statuses = append(statuses, *status)
+	}
+	return ch, statuses, m.version
+}
+
+// SubscribeSince atomically subscribes to status events and returns the
+// events after the given ID, oldest first. ok is false if some of them
+// are no longer kept (or the ID is unknown), in which case the caller
+// should unsubscribe and start over from a snapshot. Events with an ID
+// less than or equal to the last returned one should be skipped.
+func (m *Manager) SubscribeSince(id uint64) (ch chan StatusEvent, missed []StatusEvent, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ch = m.Subscribe()
+	if id < m.replayFloor || id > m.version {
+		return ch, nil, false
+	}
+	i := sort.Search(len(m.replay), func(i int) bool { return m.replay[i].ID > id })
+	missed = append([]StatusEvent(nil), m.replay[i:]...)
+	return ch, missed, true
+}
+
+// record stamps an event with the current version and keeps it for
+// replay and in the session's transitions. Caller must hold m.mu.
+func (m *Manager) record(event StatusEvent) StatusEvent {
+	event.ID = m.version
+	if len(m.replay) == maxReplayEvents {
+		m.replayFloor = m.replay[0].ID
+		m.replay = append(m.replay[:0], m.replay[1:]...)
+	}
+	m.replay = append(m.replay, event)
+	m.recordTransition(event)
+	if event.ProjectRemoved {
+		delete(m.emitted, event.Project.Name)
+	} else {
+		m.emitted[event.Project.Name] = time.Now()
+	}
+	return event
+}
+
 // Unsubscribe removes a subscription channel
 func (m *Manager) Unsubscribe(ch chan StatusEvent) {
 	m.listMu.Lock()
 	defer m.listMu.Unlock()
 
 	for i, listener := range m.listeners {
-		if listener == ch {
+		if listener.ch == ch {
 			m.listeners = append(m.listeners[:i], m.listeners[i+1:]...)
 			close(ch)
 			return
@@ -161,15 +632,37 @@ func (m *Manager) Unsubscribe(ch chan StatusEvent) {
 	}
 }
 
+// publish releases m.mu, which the caller holds for writing, and
+// delivers the events it recorded under it to every subscriber. listMu
+// is taken before m.mu is released, so concurrent changes reach
+// subscribers in the order of their IDs.
+func (m *Manager) publish(events ...StatusEvent) {
+	m.listMu.Lock()
+	m.mu.Unlock()
+	defer m.listMu.Unlock()
+
+	for _, event := range events {
+		m.notify(event)
+	}
+}
+
+// notify delivers an event to every subscriber. Subscribers that fell
+// behind miss it; resync catches them up. Caller must hold listMu.
 func (m *Manager) notify(event StatusEvent) {
-	m.listMu.RLock()
-	defer m.listMu.RUnlock()
-
-	for _, ch := range m.listeners {
-		select {
-		case ch <- event:
-		default:
-			// Channel full, skip
+	for _, sub := range m.listeners {
+		if sub.dropped == 0 {
+			select {
+			case sub.ch <- event:
+				continue
+			default:
+				logging.Logger().Warn("status subscriber fell behind, resyncing it", "buffer", cap(sub.ch))
+			}
+		}
+		sub.dropped++
+		m.dropped.Add(1)
+		if !m.resyncing {
+			m.resyncing = true
+			time.AfterFunc(resyncRetry, m.resync)
 		}
 	}
 }
@@ -184,16 +677,22 @@ func (m *Manager) CheckIdleProjects(idleThreshold time.Duration) []StatusEvent {
 	now := time.Now()
 
 	for _, status := range m.projects {
+		// The parent waits on its subagents; they are not idle
+		if hasRunningSubagent(status) {
+			continue
+		}
+
 		// For hooks-based status, only check processing state for idle detection
 		// Other hooks states (running, completed, etc.) are accurate and don't need idle checks
 		if status.Source == "hooks" {
-			if status.State != "processing" {
+			// Without approval prompts, a quiet tool is still running
+			if status.State != "processing" || status.Environment.Dangerous() {
 				continue
 			}
 			// Use tool-specific timeout for hooks-based status
 			toolTimeout := parser.ToolTimeout(status.ToolName)
 			idle := now.Sub(status.UpdatedAt)
-			
+
 			// Skip if not yet past tool-specific threshold
 			if idle < toolTimeout {
 				continue
@@ -202,7 +701,7 @@ func (m *Manager) CheckIdleProjects(idleThreshold time.Duration) []StatusEvent {
 			if idle > parser.MaxIdleThreshold {
 				continue
 			}
-			
+
 			// Processing state that's been idle = estimated waiting approval
 			events = append(events, StatusEvent{
 				Project: ProjectStatus{
@@ -212,6 +711,11 @@ func (m *Manager) CheckIdleProjects(idleThreshold time.Duration) []StatusEvent {
 					UpdatedAt:   now,
 					SessionID:   status.SessionID,
 					Source:      "hooks",
+					Tier:        status.Tier,
+					Seq:         status.Seq,
+					FilePath:    status.FilePath,
+					FileTime:    status.FileTime,
+					EventTime:   status.EventTime,
 					IsEstimated: true,
 				},
 				Type: "idle_approval",
@@ -221,23 +725,40 @@ func (m *Manager) CheckIdleProjects(idleThreshold time.Duration) []StatusEvent {
 
 		// JSONL-based status: use FileTime for idle detection
 		idle := now.Sub(status.FileTime)
-		
+
+		// Nothing to detect past the max threshold; skip the re-read
+		if idle > parser.MaxIdleThreshold {
+			continue
+		}
+
 		// Re-read the file to check current state
-		entry, err := readLastEntry(status.FilePath)
+		entry, err := parser.ReadLastEntry(status.FilePath)
 		if err != nil {
 			continue
 		}
 
-		if parser.IsIdleWaitingApproval(entry) {
-			// Get tool name for timeout calculation
-			toolName := ""
-			if entry.Message != nil {
+		// An unanswered call waits for approval, also when results of
+		// other calls of the turn came after it
+		calls, err := m.calls.read(status.FilePath)
+		if err != nil {
+			calls.pending = parser.IsIdleWaitingApproval(entry)
+			if calls.pending && entry.Message != nil {
 				for _, c := range entry.Message.Content {
 					if c.Type == "tool_use" && c.Name != "" {
-						toolName = c.Name
+						calls.oldest.name = c.Name
 					}
 				}
 			}
+		}
+
+		if calls.pending {
+			// Without approval prompts, an unanswered call is still running
+			if status.Environment.Dangerous() {
+				continue
+			}
+
+			// Get tool name for timeout calculation
+			toolName := calls.oldest.name
 
 			toolTimeout := parser.ToolTimeout(toolName)
 
@@ -245,11 +766,6 @@ func (m *Manager) CheckIdleProjects(idleThreshold time.Duration) []StatusEvent {
 			if idle < toolTimeout {
 				continue
 			}
-			// Skip if way past max threshold
-			if idle > parser.MaxIdleThreshold {
-				continue
-			}
-			
 			// Determine if this is a confident or estimated detection
 			// Confident: past tool timeout AND tool is known short-running
 			// Estimated: past tool timeout BUT tool could still be running
@@ -258,7 +774,7 @@ func (m *Manager) CheckIdleProjects(idleThreshold time.Duration) []StatusEvent {
 			if isEstimated {
 				icon = "❓"
 			}
-			
+
 			events = append(events, StatusEvent{
 				Project: ProjectStatus{
 					Name:        status.Name,
@@ -267,6 +783,11 @@ func (m *Manager) CheckIdleProjects(idleThreshold time.Duration) []StatusEvent {
 					UpdatedAt:   now,
 					SessionID:   status.SessionID,
 					Source:      "jsonl",
+					Tier:        status.Tier,
+					Seq:         status.Seq,
+					FilePath:    status.FilePath,
+					FileTime:    status.FileTime,
+					EventTime:   status.EventTime,
 					ToolName:    toolName,
 					IsEstimated: isEstimated,
 				},
@@ -277,10 +798,7 @@ func (m *Manager) CheckIdleProjects(idleThreshold time.Duration) []StatusEvent {
 			if idle < idleThreshold {
 				continue
 			}
-			if idle > parser.MaxIdleThreshold {
-				continue
-			}
-			
+
 			// Completion is always estimated since we can't detect end_turn
 			events = append(events, StatusEvent{
 				Project: ProjectStatus{
@@ -290,6 +808,11 @@ func (m *Manager) CheckIdleProjects(idleThreshold time.Duration) []StatusEvent {
 					UpdatedAt:   now,
 					SessionID:   status.SessionID,
 					Source:      "jsonl",
+					Tier:        status.Tier,
+					Seq:         status.Seq,
+					FilePath:    status.FilePath,
+					FileTime:    status.FileTime,
+					EventTime:   status.EventTime,
 					IsEstimated: true,
 				},
 				Type: "idle_completed",
@@ -300,42 +823,59 @@ func (m *Manager) CheckIdleProjects(idleThreshold time.Duration) []StatusEvent {
 	return events
 }
 
-// MarkIdle updates a project's status to an idle state
-func (m *Manager) MarkIdle(projectName string, icon, state string, isEstimated bool) {
+// MarkIdle updates a project's status to an idle state and notifies subscribers
+// with an "idle_approval" or "idle_completed" event.
+// seq is the sequence number the idle check was based on; if the project has
+// changed since then, the idle state is stale and is dropped. Reports whether
+// the idle state was applied.
+func (m *Manager) MarkIdle(projectName string, seq uint64, icon, state string, isEstimated bool) bool {
 	m.mu.Lock()
-	if status, ok := m.projects[projectName]; ok {
+	status, ok := m.projects[projectName]
+	var from string
+	if m.tracing() && ok {
+		from = describeStatus(status)
+		t := Trace{Project: projectName, SessionID: status.SessionID, Source: "idle",
+			Signal: "no activity since " + status.EventTime.Format(traceTimeFormat),
+			Parsed: icon + " " + state, Phase: PhaseOf(state).String(), From: from}
+		if status.Seq != seq {
+			t.Decision, t.Reason, t.State = TraceRejected, "project changed since the idle check", from
+		} else {
+			t.Decision, t.Reason = TraceApplied, "idle detection"
+			if isEstimated {
+				t.Reason += " (estimated)"
+			}
+			t.State = fmt.Sprintf("%s %s (%s)", icon, state, status.Source)
+		}
+		m.trace(t)
+	}
+	if ok && status.Seq != seq {
+		logging.Logger().Debug("idle update dropped, project changed since check",
+			"project", projectName, "checked_seq", seq, "current_seq", status.Seq)
+		ok = false
+	}
+	if ok {
 		status.Icon = icon
 		status.State = state
+		// Only a session log tells which tool call an idle session waits
+		// on; after hook events the last tool is one that already ran
+		status.Request = ""
+		if status.Source == "jsonl" {
+			status.Request = pendingRequest(status)
+		}
 		status.UpdatedAt = time.Now()
 		status.IsEstimated = isEstimated
+		status.Acknowledged = false
+		status.Seq++
 	}
-	m.mu.Unlock()
-}
-
-// readLastEntry reads the last line of a JSONL file and parses it
-func readLastEntry(filePath string) (*parser.Entry, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var lastLine string
-	scanner := bufio.NewScanner(file)
-	// Use a larger buffer for potentially long lines
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line != "" {
-			lastLine = line
-		}
+	if !ok {
+		m.mu.Unlock()
+		return false
 	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	m.version++
+	event := StatusEvent{Project: *status, Type: "idle_completed"}
+	if PhaseOf(state) == PhaseWaiting {
+		event.Type = "idle_approval"
 	}
-
-	return parser.ParseEntry(lastLine)
+	m.publish(m.record(event))
+	return true
 }
@@ -0,0 +1,121 @@
+package state
+
+import (
+	"sort"
+	"time"
+)
+
+// EventMuted is the type of events reporting that a project's
+// notifications were muted or unmuted, or its mute ran out; the status
+// is unchanged except for Muted and MutedUntil
+const EventMuted = "muted"
+
+// Mute silences the notifications of a project until a time
+type Mute struct {
+	Project string    `json:"project"`
+	Until   time.Time `json:"until,omitzero"` // zero = until unmuted
+}
+
+// Mute silences the notifications of a project until a time (zero =
+// until unmuted). Muted projects keep updating their state. Projects need
+// not be known yet, so a project can be muted before its session starts.
+func (m *Manager) Mute(projectName string, until time.Time) {
+	m.mu.Lock()
+	m.mutes[projectName] = until
+	m.publishMute(projectName)
+}
+
+// Unmute restores the notifications of a project, reporting whether it
+// was muted
+func (m *Manager) Unmute(projectName string) bool {
+	m.mu.Lock()
+	if _, ok := m.mutes[projectName]; !ok {
+		m.mu.Unlock()
+		return false
+	}
+	delete(m.mutes, projectName)
+	m.publishMute(projectName)
+	return true
+}
+
+// Muted reports whether notifications of a project are silenced
+func (m *Manager) Muted(projectName string) bool {
+	m.mu.RLock()
+	until, ok := m.mutes[projectName]
+	m.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	if until.IsZero() || time.Now().Before(until) {
+		return true
+	}
+	m.expireMutes()
+	return false
+}
+
+// Mutes returns the active mutes, sorted by project
+func (m *Manager) Mutes() []Mute {
+	m.expireMutes()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	mutes := make([]Mute, 0, len(m.mutes))
+	for project, until := range m.mutes {
+		mutes = append(mutes, Mute{Project: project, Until: until})
+	}
+	sort.Slice(mutes, func(i, j int) bool { return mutes[i].Project < mutes[j].Project })
+	return mutes
+}
+
+// expireMutes drops the mutes that ran out, publishing the statuses that
+// are no longer muted
+func (m *Manager) expireMutes() {
+	m.mu.Lock()
+	now := time.Now()
+	var events []StatusEvent
+	for project, until := range m.mutes {
+		if until.IsZero() || now.Before(until) {
+			continue
+		}
+		delete(m.mutes, project)
+		if event, ok := m.muteEvent(project); ok {
+			events = append(events, event)
+		}
+	}
+	m.publish(events...)
+}
+
+// publishMute publishes the status of a project whose mute changed, if
+// the project is known. Caller must hold m.mu, which is released.
+func (m *Manager) publishMute(projectName string) {
+	if event, ok := m.muteEvent(projectName); ok {
+		m.publish(event)
+		return
+	}
+	m.mu.Unlock()
+}
+
+// muteEvent applies the mute of a project to its status and records the
+// change. Reports false if the project is unknown or its status already
+// shows the mute. Caller must hold m.mu.
+func (m *Manager) muteEvent(projectName string) (StatusEvent, bool) {
+	cur := m.projects[projectName]
+	if cur == nil {
+		return StatusEvent{}, false
+	}
+	muted := *cur
+	m.applyMute(&muted)
+	if muted.Muted == cur.Muted && muted.MutedUntil.Equal(cur.MutedUntil) {
+		return StatusEvent{}, false
+	}
+	muted.Seq++
+	m.version++
+	m.projects[projectName] = &muted
+	return m.record(StatusEvent{Project: muted, Type: EventMuted}), true
+}
+
+// applyMute shows the mute of a project in a new status. Caller must hold
+// m.mu.
+func (m *Manager) applyMute(status *ProjectStatus) {
+	until, ok := m.mutes[status.Name]
+	status.Muted, status.MutedUntil = ok, until
+}
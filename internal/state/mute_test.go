@@ -0,0 +1,54 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMute(t *testing.T) {
+	m := NewManager()
+	ch := m.Subscribe()
+	defer m.Unsubscribe(ch)
+
+	// Muted before its session starts
+	m.Mute("api", time.Time{})
+	status := m.UpdateFromHook(HookEvent{SessionID: "s1", ProjectName: "api", Icon: "💭", State: "thinking"})
+	if status == nil || !status.Muted || !status.MutedUntil.IsZero() {
+		t.Fatalf("status after update = %+v, want muted until unmuted", status)
+	}
+	if event := <-ch; event.Type != "update" || !event.Project.Muted {
+		t.Errorf("update event = %s %+v, want muted status", event.Type, event.Project)
+	}
+
+	if !m.Unmute("api") {
+		t.Fatal("Unmute() = false, want true")
+	}
+	if event := <-ch; event.Type != EventMuted || event.Project.Muted || event.Project.State != "thinking" {
+		t.Errorf("unmute event = %s %+v, want unmuted thinking status", event.Type, event.Project)
+	}
+	if m.Muted("api") || m.Get("api").Muted {
+		t.Error("project still muted after Unmute")
+	}
+	if m.Unmute("api") {
+		t.Error("second Unmute() = true, want false")
+	}
+
+	// A mute that runs out clears the status
+	m.Mute("api", time.Now().Add(20*time.Millisecond))
+	if event := <-ch; event.Type != EventMuted || !event.Project.Muted || event.Project.MutedUntil.IsZero() {
+		t.Errorf("mute event = %s %+v, want muted with an end", event.Type, event.Project)
+	}
+	if got := m.Mutes(); len(got) != 1 || got[0].Project != "api" {
+		t.Errorf("Mutes() = %+v, want api", got)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if m.Muted("api") {
+		t.Error("Muted() = true after the mute ended")
+	}
+	if event := <-ch; event.Type != EventMuted || event.Project.Muted {
+		t.Errorf("expiry event = %s %+v, want unmuted status", event.Type, event.Project)
+	}
+	if got := m.Mutes(); len(got) != 0 {
+		t.Errorf("Mutes() = %+v, want none", got)
+	}
+}
@@ -0,0 +1,167 @@
+package state
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+)
+
+// projectNames gives every project directory a name of its own. Names
+// derived from directories are short ("api"); when two directories share
+// one, the later gets a hint of its parent directories ("api (beta)").
+type projectNames struct {
+	roots   map[string]string // project directory -> name
+	owners  map[string]string // name -> project directory
+	logCWD  map[string]string // session log -> working directory, for entries without one
+	logName map[string]string // session log -> project name, for hook events naming the log
+}
+
+func newProjectNames() projectNames {
+	return projectNames{
+		roots:   make(map[string]string),
+		owners:  make(map[string]string),
+		logCWD:  make(map[string]string),
+		logName: make(map[string]string),
+	}
+}
+
+// projectName returns the name of the project a session working in dir
+// belongs to: the configured name for dir, else name, made unique if
+// another directory already goes by it
+func (m *Manager) projectName(name, dir string) string {
+	if dir == "" {
+		return name
+	}
+	if m.nameFor != nil {
+		if configured := m.nameFor(dir); configured != "" {
+			return configured
+		}
+	}
+
+	m.namesMu.Lock()
+	defer m.namesMu.Unlock()
+	return m.names.unique(name, projectRoot(name, dir))
+}
+
+// logProjectName returns the project name for an entry of a session log.
+// Entries without a working directory use the one last seen in the log,
+// or recorded at its start.
+func (m *Manager) logProjectName(name, filePath, cwd string) string {
+	m.namesMu.Lock()
+	if cwd != "" {
+		m.names.logCWD[filePath] = cwd
+	} else if known, ok := m.names.logCWD[filePath]; ok {
+		cwd = known
+	} else {
+		cwd = parser.ReadCWD(filePath)
+		m.names.logCWD[filePath] = cwd
+	}
+	m.namesMu.Unlock()
+	name = m.projectName(name, cwd)
+
+	m.namesMu.Lock()
+	m.names.logName[filePath] = name
+	m.namesMu.Unlock()
+	return name
+}
+
+// hookProjectName returns the project name for a hook event. An event
+// naming its session log (transcript_path) joins the project the log's
+// entries went to, or else the one of the directory the session started
+// in, so hooks and session logs agree even after the session changed
+// into another directory. Other events are named by their directory.
+func (m *Manager) hookProjectName(event HookEvent) string {
+	logPath := event.TranscriptPath
+	if logPath == "" {
+		return m.projectName(event.ProjectName, event.CWD)
+	}
+	m.namesMu.Lock()
+	name, ok := m.names.logName[logPath]
+	m.namesMu.Unlock()
+	if ok {
+		return name
+	}
+
+	// Not written yet, e.g. at SessionStart: the session is still in the
+	// directory it started in
+	start := parser.ReadCWD(logPath)
+	if start == "" {
+		return m.projectName(event.ProjectName, event.CWD)
+	}
+	name = m.projectName(filepath.Base(start), start)
+	m.namesMu.Lock()
+	m.names.logName[logPath] = name
+	m.namesMu.Unlock()
+	return name
+}
+
+// forgetLog drops what is remembered about a removed session log
+func (m *Manager) forgetLog(filePath string) {
+	m.namesMu.Lock()
+	defer m.namesMu.Unlock()
+	prefix := filePath + string(filepath.Separator)
+	for path := range m.names.logCWD {
+		if path == filePath || strings.HasPrefix(path, prefix) {
+			delete(m.names.logCWD, path)
+		}
+	}
+	for path := range m.names.logName {
+		if path == filePath || strings.HasPrefix(path, prefix) {
+			delete(m.names.logName, path)
+		}
+	}
+}
+
+// ProjectPath returns the directory a project's name was derived from,
+// or "" for configured names and projects without a known directory
+func (m *Manager) ProjectPath(projectName string) string {
+	m.namesMu.Lock()
+	defer m.namesMu.Unlock()
+	return m.names.owners[projectName]
+}
+
+// unique returns the name of the project in root, claiming name, or name
+// with a hint of root's parent directories if another directory has it
+func (n projectNames) unique(name, root string) string {
+	if claimed, ok := n.roots[root]; ok {
+		return claimed
+	}
+	candidate := name
+	hint, parent := "", filepath.Dir(root)
+	for {
+		if owner, taken := n.owners[candidate]; !taken || owner == root {
+			break
+		}
+		if filepath.Dir(parent) == parent {
+			// No parent directory left to tell them apart
+			candidate = name + " (" + root + ")"
+			break
+		}
+		if hint == "" {
+			hint = filepath.Base(parent)
+		} else {
+			hint = filepath.Base(parent) + "/" + hint
+		}
+		candidate = name + " (" + hint + ")"
+		parent = filepath.Dir(parent)
+	}
+	n.roots[root] = candidate
+	n.owners[candidate] = root
+	return candidate
+}
+
+// projectRoot returns the directory a project name was derived from: the
+// nearest of dir and its parents named name, so sessions that changed
+// into a subdirectory stay in their project, else dir itself
+func projectRoot(name, dir string) string {
+	dir = filepath.Clean(dir)
+	for d := dir; ; d = filepath.Dir(d) {
+		if filepath.Base(d) == name {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return dir
+		}
+	}
+}
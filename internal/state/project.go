@@ -0,0 +1,287 @@
+package state
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/gitinfo"
+	"github.com/sho7650/claude-watch-status/internal/logging"
+	"github.com/sho7650/claude-watch-status/internal/parser"
+)
+
+// maxSessions bounds the sessions remembered per project
+const maxSessions = 20
+
+// activeSessionWindow is how long a session counts as active after its
+// last activity, unless it ended
+const activeSessionWindow = 30 * time.Minute
+
+// SessionInfo describes a session seen in a project
+type SessionInfo struct {
+	ID           string    `json:"id"`
+	Source       string    `json:"source"`
+	LogPath      string    `json:"log_path,omitempty"` // session log file, for JSONL sessions
+	Path         string    `json:"path,omitempty"`     // working directory
+	Branch       string    `json:"branch,omitempty"`   // git branch checked out there
+	Icon         string    `json:"icon"`
+	State        string    `json:"state"` // last state reported for this session
+	StartedAt    time.Time `json:"started_at"`
+	LastActivity time.Time `json:"last_activity"`
+	Ended        bool      `json:"ended,omitempty"`
+	Active       bool      `json:"active"` // not ended and recently active
+
+	Environment *Environment `json:"environment,omitempty"`
+}
+
+// ProjectInfo is the metadata of a project: where it lives, its sessions
+// and its current status
+type ProjectInfo struct {
+	Name           string        `json:"name"`
+	Path           string        `json:"path,omitempty"` // working directory, when reported
+	Tier           string        `json:"tier,omitempty"`
+	Group          string        `json:"group,omitempty"`
+	LastActivity   time.Time     `json:"last_activity"`
+	Hidden         bool          `json:"hidden,omitempty"` // inactive past the hide TTL
+	Sessions       int           `json:"sessions"`
+	ActiveSessions int           `json:"active_sessions"`
+	Status         ProjectStatus `json:"status"`
+}
+
+// projectMeta holds what is known about a project beyond its status
+type projectMeta struct {
+	path     string
+	pathRead bool // path was looked up in a session log
+	sessions map[string]*SessionInfo
+}
+
+// observeSession records activity and environment of a session, whether
+// or not it changes the project status, and tags status with the session
+// environment. Caller must hold m.mu.
+func (m *Manager) observeSession(projectName, cwd string, env Environment, status *ProjectStatus) {
+	meta := m.meta[projectName]
+	if meta == nil {
+		meta = &projectMeta{sessions: make(map[string]*SessionInfo)}
+		m.meta[projectName] = meta
+	}
+	if cwd != "" {
+		meta.path = cwd
+	} else if meta.path == "" && !meta.pathRead && status.FilePath != "" {
+		// The last entry may carry no cwd, e.g. a summary at startup
+		meta.path = parser.ReadCWD(status.FilePath)
+		meta.pathRead = true
+	}
+	status.ProjectPath = m.ProjectPath(projectName)
+	if status.SessionID == "" {
+		m.locate(status, meta.path)
+		return
+	}
+
+	sess := meta.sessions[status.SessionID]
+	if sess == nil {
+		sess = &SessionInfo{ID: status.SessionID, StartedAt: status.EventTime}
+		meta.sessions[status.SessionID] = sess
+		trimSessions(meta.sessions)
+	}
+	// Out-of-order events only count as activity
+	if !status.EventTime.Before(sess.LastActivity) {
+		sess.Source = status.Source
+		sess.Icon, sess.State = status.Icon, status.State
+		sess.LastActivity = status.EventTime
+		sess.Ended = PhaseOf(status.State) == PhaseEnded
+	}
+	if status.FilePath != "" {
+		sess.LogPath = status.FilePath
+	}
+	if cwd != "" || sess.Path == "" {
+		sess.Path = meta.path
+	}
+	m.locate(status, sess.Path)
+	sess.Branch = status.Branch
+	if status.EventTime.Before(sess.StartedAt) {
+		sess.StartedAt = status.EventTime
+	}
+	// Copy on write: published statuses share the environment
+	if !env.empty() {
+		sess.Environment = sess.Environment.with(env)
+	}
+	status.Environment = sess.Environment
+}
+
+// branchCheckInterval is how long a working directory's git branch is
+// cached before HEAD is read again
+const branchCheckInterval = 10 * time.Second
+
+// gitBranch is the cached git branch of a working directory
+type gitBranch struct {
+	name    string
+	checked time.Time
+}
+
+// locate tags status with its working directory and the git branch
+// checked out there. Caller must hold m.mu.
+func (m *Manager) locate(status *ProjectStatus, path string) {
+	status.Path = path
+	if path == "" {
+		status.Branch = ""
+		return
+	}
+	b, ok := m.branches[path]
+	if !ok || time.Since(b.checked) >= branchCheckInterval {
+		b = gitBranch{name: gitinfo.Branch(path), checked: time.Now()}
+		m.branches[path] = b
+	}
+	status.Branch = b.name
+}
+
+// trimSessions drops the least recently active sessions beyond maxSessions
+func trimSessions(sessions map[string]*SessionInfo) {
+	for len(sessions) > maxSessions {
+		var oldest *SessionInfo
+		for _, sess := range sessions {
+			if oldest == nil || sess.LastActivity.Before(oldest.LastActivity) {
+				oldest = sess
+			}
+		}
+		delete(sessions, oldest.ID)
+	}
+}
+
+// RemoveLog forgets the sessions whose log is filePath, or lies below it
+// for a removed project directory, and publishes an EventSessionRemoved
+// for each. A project without sessions left is removed as well; otherwise
+// its status stays until the next update. Returns the published events.
+func (m *Manager) RemoveLog(filePath string) []StatusEvent {
+	m.forgetLog(filePath)
+	m.calls.forget(filePath)
+	m.mu.Lock()
+
+	var events []StatusEvent
+	prefix := filePath + string(filepath.Separator)
+	for name, meta := range m.meta {
+		var removed []string
+		for id, sess := range meta.sessions {
+			if sess.LogPath != "" && (sess.LogPath == filePath || strings.HasPrefix(sess.LogPath, prefix)) {
+				removed = append(removed, id)
+				delete(meta.sessions, id)
+			}
+		}
+		if len(removed) == 0 {
+			continue
+		}
+		slices.Sort(removed)
+
+		status := m.projects[name]
+		dropProject := len(meta.sessions) == 0
+		for i, id := range removed {
+			event := StatusEvent{
+				Project:        ProjectStatus{Name: name, SessionID: id, Source: "jsonl", UpdatedAt: time.Now()},
+				Type:           EventSessionRemoved,
+				ProjectRemoved: dropProject && i == len(removed)-1,
+			}
+			if status != nil {
+				event.Project.Tier = status.Tier
+			}
+			m.version++
+			events = append(events, m.record(event))
+		}
+		if dropProject {
+			delete(m.projects, name)
+			delete(m.meta, name)
+		}
+	}
+	m.publish(events...)
+
+	for _, event := range events {
+		logging.Logger().Debug("session removed", "project", event.Project.Name,
+			"session", event.Project.SessionID, "project_removed", event.ProjectRemoved)
+	}
+	return events
+}
+
+// Projects returns the metadata of all projects, sorted by name. Hidden
+// projects are only included if includeHidden is set.
+func (m *Manager) Projects(includeHidden bool) []ProjectInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	infos := make([]ProjectInfo, 0, len(m.projects))
+	for name := range m.projects {
+		if includeHidden || !m.hidden(name, now) {
+			infos = append(infos, m.projectInfo(name, now))
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Project returns the metadata of a project, or nil if it is unknown
+func (m *Manager) Project(projectName string) *ProjectInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.projects[projectName] == nil {
+		return nil
+	}
+	info := m.projectInfo(projectName, time.Now())
+	return &info
+}
+
+// Sessions returns the sessions seen in a project, most recently active
+// first, or nil if the project is unknown
+func (m *Manager) Sessions(projectName string) []SessionInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.projects[projectName] == nil {
+		return nil
+	}
+	return m.sessionList(projectName, time.Now())
+}
+
+// projectInfo builds the metadata of a known project. Caller must hold m.mu.
+func (m *Manager) projectInfo(projectName string, now time.Time) ProjectInfo {
+	status := m.projects[projectName]
+	info := ProjectInfo{
+		Name:         projectName,
+		Tier:         status.Tier,
+		LastActivity: status.UpdatedAt,
+		Status:       *status,
+	}
+	if meta := m.meta[projectName]; meta != nil {
+		info.Path = meta.path
+	}
+	if m.groupFor != nil {
+		info.Group = m.groupFor(projectName)
+	}
+	info.Hidden = m.hidden(projectName, now)
+	for _, sess := range m.sessionList(projectName, now) {
+		info.Sessions++
+		if sess.Active {
+			info.ActiveSessions++
+		}
+		if sess.LastActivity.After(info.LastActivity) {
+			info.LastActivity = sess.LastActivity
+		}
+	}
+	return info
+}
+
+// sessionList copies a project's sessions. Caller must hold m.mu.
+func (m *Manager) sessionList(projectName string, now time.Time) []SessionInfo {
+	meta := m.meta[projectName]
+	if meta == nil {
+		return []SessionInfo{}
+	}
+	sessions := make([]SessionInfo, 0, len(meta.sessions))
+	for _, sess := range meta.sessions {
+		s := *sess
+		s.Active = !s.Ended && now.Sub(s.LastActivity) < activeSessionWindow
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastActivity.After(sessions[j].LastActivity) })
+	return sessions
+}
@@ -0,0 +1,32 @@
+package state
+
+// pendingRequest describes what a project waiting for approval asks
+// permission for: the tool and what it works on, as in "Bash: rm -rf
+// build/". It is empty in other states.
+func pendingRequest(status *ProjectStatus) string {
+	if PhaseOf(status.State) != PhaseWaiting {
+		return ""
+	}
+	switch {
+	case status.ToolName != "" && status.Detail != "":
+		return status.ToolName + ": " + status.Detail
+	case status.ToolName != "":
+		return status.ToolName
+	default:
+		return status.Detail
+	}
+}
+
+// inheritRequest completes a waiting-approval status that does not say
+// what the tool works on, such as one from a permission_prompt
+// Notification, with the detail of the tool call it follows in the same
+// session
+func inheritRequest(cur, status *ProjectStatus) {
+	if cur == nil || status.Detail != "" || PhaseOf(status.State) != PhaseWaiting || cur.SessionID != status.SessionID {
+		return
+	}
+	if status.ToolName != "" && status.ToolName != cur.ToolName {
+		return
+	}
+	status.ToolName, status.Detail = cur.ToolName, cur.Detail
+}
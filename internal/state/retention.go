@@ -0,0 +1,103 @@
+package state
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/logging"
+)
+
+// SetRetention sets how long projects without activity stay visible and
+// how long they are kept; 0 disables hiding or deleting
+func (m *Manager) SetRetention(hideAfter, deleteAfter time.Duration) {
+	m.mu.Lock()
+	m.hideAfter, m.deleteAfter = hideAfter, deleteAfter
+	m.mu.Unlock()
+}
+
+// lastActive returns when a project was last active: its last status
+// change or session activity. Caller must hold m.mu.
+func (m *Manager) lastActive(projectName string) time.Time {
+	var last time.Time
+	if status := m.projects[projectName]; status != nil {
+		last = status.UpdatedAt
+	}
+	if meta := m.meta[projectName]; meta != nil {
+		for _, sess := range meta.sessions {
+			if sess.LastActivity.After(last) {
+				last = sess.LastActivity
+			}
+		}
+	}
+	return last
+}
+
+// hidden reports whether a project has been inactive past the hide TTL.
+// Caller must hold m.mu.
+func (m *Manager) hidden(projectName string, now time.Time) bool {
+	return m.hideAfter > 0 && now.Sub(m.lastActive(projectName)) > m.hideAfter
+}
+
+// Hidden reports whether a project has been inactive for longer than the
+// hide TTL. Hidden projects are left out of dashboards and, unless
+// asked for, API listings; any activity shows them again.
+func (m *Manager) Hidden(projectName string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.hidden(projectName, time.Now())
+}
+
+// GetVisible returns the statuses of projects that are not hidden
+func (m *Manager) GetVisible() []ProjectStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	statuses := make([]ProjectStatus, 0, len(m.projects))
+	for name, status := range m.projects {
+		if !m.hidden(name, now) {
+			statuses = append(statuses, *status)
+		}
+	}
+	return statuses
+}
+
+// Prune forgets projects inactive for longer than the delete TTL,
+// publishing an EventSessionRemoved with ProjectRemoved for each, and
+// returns their names
+func (m *Manager) Prune() []string {
+	m.mu.Lock()
+	if m.deleteAfter <= 0 {
+		m.mu.Unlock()
+		return nil
+	}
+
+	now := time.Now()
+	var names []string
+	for name := range m.projects {
+		if now.Sub(m.lastActive(name)) > m.deleteAfter {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	events := make([]StatusEvent, 0, len(names))
+	for _, name := range names {
+		status := m.projects[name]
+		delete(m.projects, name)
+		delete(m.meta, name)
+		m.version++
+		events = append(events, m.record(StatusEvent{
+			Project: ProjectStatus{Name: name, SessionID: status.SessionID, Source: status.Source,
+				Tier: status.Tier, UpdatedAt: now},
+			Type:           EventSessionRemoved,
+			ProjectRemoved: true,
+		}))
+	}
+	m.publish(events...)
+
+	for _, event := range events {
+		logging.Logger().Debug("project pruned", "project", event.Project.Name)
+	}
+	return names
+}
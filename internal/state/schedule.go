@@ -0,0 +1,64 @@
+package state
+
+import (
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+)
+
+// TickMode describes how often idle checks run, based on recent activity
+type TickMode string
+
+const (
+	// TickFast checks every FastTickInterval while projects are active
+	TickFast TickMode = "fast"
+	// TickSlow backs off after QuietPeriod without activity
+	TickSlow TickMode = "slow"
+	// TickDormant skips checks: every project has been quiet for longer
+	// than parser.MaxIdleThreshold, so no idle transition is possible
+	TickDormant TickMode = "dormant"
+)
+
+// Idle check intervals per tick mode
+const (
+	FastTickInterval    = 5 * time.Second
+	SlowTickInterval    = 30 * time.Second
+	DormantTickInterval = 60 * time.Second
+	QuietPeriod         = 3 * time.Minute
+)
+
+// TickModeFor returns the tick mode and interval for the time since the
+// last status change
+func TickModeFor(sinceActivity time.Duration) (TickMode, time.Duration) {
+	switch {
+	case sinceActivity < QuietPeriod:
+		return TickFast, FastTickInterval
+	case sinceActivity <= parser.MaxIdleThreshold:
+		return TickSlow, SlowTickInterval
+	default:
+		return TickDormant, DormantTickInterval
+	}
+}
+
+// LastActivity returns when a status last changed from a source event.
+// Idle marks do not count. Zero if nothing has changed yet.
+func (m *Manager) LastActivity() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastActivity
+}
+
+// Activity returns a channel that receives after status changes, to wake a
+// backed-off idle scheduler. Signals are coalesced; use a single receiver.
+func (m *Manager) Activity() <-chan struct{} {
+	return m.activity
+}
+
+// markActivity records a status change. Caller must hold m.mu.
+func (m *Manager) markActivity(at time.Time) {
+	m.lastActivity = at
+	select {
+	case m.activity <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,270 @@
+package state
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+	"github.com/sho7650/claude-watch-status/internal/redact"
+)
+
+// maxSubagents bounds the subagent statuses kept per project
+const maxSubagents = 10
+
+// promptKeyLength is how much of a prompt is compared to match hook and
+// session log updates for the same subagent
+const promptKeyLength = 200
+
+// SubagentStatus is the status of a subagent spawned by the Task tool,
+// shown nested under its parent project
+type SubagentStatus struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description,omitempty"`
+	Type        string    `json:"type,omitempty"` // subagent_type, e.g. "general-purpose"
+	Icon        string    `json:"icon"`
+	State       string    `json:"state"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	toolUseID string // Task tool_use ID, from hooks
+	agentID   string // agent ID, from the subagent session log
+	prompt    string // prompt prefix
+}
+
+// Running reports whether the subagent has not finished yet
+func (s SubagentStatus) Running() bool {
+	switch PhaseOf(s.State) {
+	case PhaseCompleted, PhaseInterrupted, PhaseError, PhaseEnded:
+		return false
+	}
+	return true
+}
+
+// SubagentUpdate is a change to a subagent. ToolUseID, AgentID and Prompt
+// identify it; an update without any of them applies to the oldest
+// running subagent.
+type SubagentUpdate struct {
+	ToolUseID   string
+	AgentID     string
+	Prompt      string
+	Description string
+	Type        string
+	Icon        string
+	State       string
+	Time        time.Time
+}
+
+// promptKey normalizes a prompt for matching
+func promptKey(prompt string) string {
+	prompt = strings.TrimSpace(prompt)
+	if len(prompt) > promptKeyLength {
+		prompt = prompt[:promptKeyLength]
+	}
+	return prompt
+}
+
+// promptSummary returns the first line of a prompt, shortened and
+// redacted, for subagents known only from their session log
+func promptSummary(prompt string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(prompt), "\n")
+	line = redact.String(line)
+	if r := []rune(line); len(r) > 40 {
+		line = string(r[:39]) + "…"
+	}
+	return line
+}
+
+// matches reports whether an update refers to this subagent
+func (s SubagentStatus) matches(u SubagentUpdate) bool {
+	switch {
+	case u.ToolUseID != "" && s.toolUseID == u.ToolUseID:
+		return true
+	case u.AgentID != "" && s.agentID == u.AgentID:
+		return true
+	case u.Prompt != "" && s.prompt == promptKey(u.Prompt):
+		// Only merge hook and log views, never two subagents from one source
+		return (u.AgentID == "" || s.agentID == "") && (u.ToolUseID == "" || s.toolUseID == "")
+	}
+	return false
+}
+
+// applySubagent applies a subagent change to a project, returning the
+// updated status, or nil if the project is unknown, in another session,
+// or the update matches nothing and would not start a subagent.
+// Caller must hold m.mu and publish the result.
+func (m *Manager) applySubagent(projectName, sessionID string, u SubagentUpdate) *ProjectStatus {
+	cur := m.projects[projectName]
+	if cur == nil || (sessionID != "" && cur.SessionID != "" && cur.SessionID != sessionID) {
+		return nil
+	}
+	if u.Time.IsZero() {
+		u.Time = time.Now()
+	}
+
+	// Copy on write: published statuses share the slice
+	subs := append([]SubagentStatus(nil), cur.Subagents...)
+
+	idx := -1
+	for i := range subs {
+		if subs[i].matches(u) {
+			idx = i
+			break
+		}
+	}
+	identified := u.ToolUseID != "" || u.AgentID != "" || u.Prompt != ""
+	if idx == -1 && !identified {
+		for i := range subs {
+			if subs[i].Running() {
+				idx = i
+				break
+			}
+		}
+	}
+	if idx == -1 {
+		if !identified {
+			return nil
+		}
+		id := u.ToolUseID
+		if id == "" {
+			id = u.AgentID
+		}
+		if id == "" {
+			id = strconv.Itoa(len(subs) + 1)
+		}
+		subs = append(subs, SubagentStatus{ID: id})
+		idx = len(subs) - 1
+	}
+
+	sub := &subs[idx]
+	if u.ToolUseID != "" {
+		sub.toolUseID = u.ToolUseID
+	}
+	if u.AgentID != "" {
+		sub.agentID = u.AgentID
+	}
+	if u.Prompt != "" && sub.prompt == "" {
+		sub.prompt = promptKey(u.Prompt)
+	}
+	if u.Description != "" {
+		sub.Description = redact.String(u.Description)
+	} else if sub.Description == "" && u.Prompt != "" {
+		sub.Description = promptSummary(u.Prompt)
+	}
+	if u.Type != "" {
+		sub.Type = u.Type
+	}
+	// A finished subagent stays finished; late log writes only update details
+	if sub.State == "" || sub.Running() {
+		sub.Icon, sub.State = u.Icon, u.State
+	}
+	sub.UpdatedAt = u.Time
+
+	status := *cur
+	status.Subagents = trimSubagents(subs)
+	status.Seq = nextSeq(cur)
+	m.version++
+	m.projects[projectName] = &status
+	m.markActivity(time.Now())
+	return &status
+}
+
+// trimSubagents drops the oldest finished subagents beyond maxSubagents
+func trimSubagents(subs []SubagentStatus) []SubagentStatus {
+	for len(subs) > maxSubagents {
+		drop := 0
+		for i, sub := range subs {
+			if !sub.Running() {
+				drop = i
+				break
+			}
+		}
+		subs = append(subs[:drop], subs[drop+1:]...)
+	}
+	return subs
+}
+
+// carrySubagents keeps a project's subagents across parent updates within
+// a turn. They are cleared when the session changes or the turn ends.
+func carrySubagents(cur, next *ProjectStatus) []SubagentStatus {
+	if cur == nil || cur.SessionID != next.SessionID {
+		return nil
+	}
+	switch PhaseOf(next.State) {
+	case PhaseStarted, PhaseUserInput, PhaseCompleted, PhaseInterrupted, PhaseEnded:
+		return nil
+	}
+	return cur.Subagents
+}
+
+// hasRunningSubagent reports whether any subagent of a project is working
+func hasRunningSubagent(status *ProjectStatus) bool {
+	for _, sub := range status.Subagents {
+		if sub.Running() {
+			return true
+		}
+	}
+	return false
+}
+
+// updateSubagentLog applies a change to a subagent session log
+func (m *Manager) updateSubagentLog(projectName, sessionID, agentID, filePath string) (*ProjectStatus, error) {
+	entry, err := parser.ReadLastEntry(filePath)
+	if err != nil {
+		return nil, err
+	}
+	st := parser.ParseState(entry)
+	if st.Skip {
+		return nil, nil
+	}
+	projectName = m.logProjectName(projectName, filePath, entry.CWD)
+	// The first entry is the task prompt; the subagent is working on it
+	if st.Text == "user input" {
+		st = parser.State{Icon: "⏳", Text: "processing"}
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	u := SubagentUpdate{
+		AgentID: agentID,
+		Icon:    st.Icon,
+		State:   st.Text,
+		Time:    entryTime(entry, info.ModTime(), time.Now()),
+	}
+
+	// Read the prompt once, to match the subagent started by a hook
+	m.mu.RLock()
+	known := false
+	if cur := m.projects[projectName]; cur != nil {
+		for _, sub := range cur.Subagents {
+			if sub.agentID == agentID {
+				known = true
+				break
+			}
+		}
+	}
+	m.mu.RUnlock()
+	if !known {
+		u.Prompt = parser.ReadFirstPrompt(filePath)
+	}
+
+	status := m.commitSubagent(projectName, sessionID, u)
+	if m.tracing() {
+		m.traceSubagent(projectName, sessionID, "jsonl", entrySignal(entry)+" in subagent log", u, status)
+	}
+	return status, nil
+}
+
+// commitSubagent applies and publishes a subagent change
+func (m *Manager) commitSubagent(projectName, sessionID string, u SubagentUpdate) *ProjectStatus {
+	m.mu.Lock()
+	status := m.applySubagent(projectName, sessionID, u)
+	if status == nil {
+		m.mu.Unlock()
+		return nil
+	}
+	m.publish(m.record(StatusEvent{Project: *status, Type: "update"}))
+	return status
+}
@@ -0,0 +1,122 @@
+package state
+
+import (
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/logging"
+)
+
+// EventResync is the type of events replacing the events a subscriber
+// missed while its channel was full: Snapshot holds the current status
+// of every project, Dropped how many events were missed
+const EventResync = "resync"
+
+// subscriberBuffer is how many events a subscriber may fall behind
+// before it misses some
+const subscriberBuffer = 100
+
+// resyncRetry is how often a subscriber that fell behind is offered a
+// snapshot until its channel has room for it
+const resyncRetry = 100 * time.Millisecond
+
+// subscriber is a subscription channel and what it missed
+type subscriber struct {
+	ch      chan StatusEvent
+	dropped uint64 // events missed since the last resync, guarded by listMu
+}
+
+// DroppedEvents returns how many events subscribers missed because they
+// fell behind; each was caught up with an EventResync since
+func (m *Manager) DroppedEvents() uint64 {
+	return m.dropped.Load()
+}
+
+// resync sends the subscribers that missed events a snapshot in their
+// place, and tries again later for those still without room
+func (m *Manager) resync() {
+	// Holding mu keeps the snapshot current until it is delivered; later
+	// changes are notified after it
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	m.listMu.Lock()
+	defer m.listMu.Unlock()
+
+	m.resyncing = false
+	var snapshot []ProjectStatus
+	for _, sub := range m.listeners {
+		if sub.dropped == 0 {
+			continue
+		}
+		if snapshot == nil {
+			snapshot = make([]ProjectStatus, 0, len(m.projects))
+			for _, status := range m.projects {
+				snapshot = append(snapshot, *status)
+			}
+		}
+		// Subscribers share the snapshot; it must not be modified
+		select {
+		case sub.ch <- StatusEvent{Type: EventResync, ID: m.version, Snapshot: snapshot, Dropped: sub.dropped}:
+			logging.Logger().Debug("status subscriber resynced", "dropped", sub.dropped)
+			sub.dropped = 0
+		default:
+			if !m.resyncing {
+				m.resyncing = true
+				time.AfterFunc(resyncRetry, m.resync)
+			}
+		}
+	}
+}
+
+// Updates returns the events a subscriber applies for e: e itself, or
+// for an EventResync an "update" per project of the snapshot, each
+// project's current status standing in for the events missed
+func (e StatusEvent) Updates() []StatusEvent {
+	if e.Type != EventResync {
+		return []StatusEvent{e}
+	}
+	events := make([]StatusEvent, len(e.Snapshot))
+	for i, status := range e.Snapshot {
+		events[i] = StatusEvent{Project: status, Type: "update", ID: e.ID}
+	}
+	return events
+}
+
+// ForEachUpdate calls fn for every event received on ch until ch is
+// closed or done is; a nil done never is. A resync stands in for missed events with the
+// current statuses, as Updates returns them.
+func ForEachUpdate(done <-chan struct{}, ch <-chan StatusEvent, fn func(event StatusEvent)) {
+	for {
+		select {
+		case <-done:
+			return
+		case received, ok := <-ch:
+			if !ok {
+				return
+			}
+			for _, event := range received.Updates() {
+				fn(event)
+			}
+		}
+	}
+}
+
+// ForEachTransition calls fn for every project entering a new state, as
+// received on ch until ch is closed or done is. Repeated updates with the
+// same state are skipped, and removed projects forgotten.
+func ForEachTransition(done <-chan struct{}, ch <-chan StatusEvent, fn func(project ProjectStatus)) {
+	lastState := make(map[string]string)
+	ForEachUpdate(done, ch, func(event StatusEvent) {
+		project := event.Project
+		if event.Type == EventSessionRemoved {
+			if event.ProjectRemoved {
+				delete(lastState, project.Name)
+			}
+			return
+		}
+		if lastState[project.Name] == project.State {
+			return
+		}
+		lastState[project.Name] = project.State
+		fn(project)
+	})
+}
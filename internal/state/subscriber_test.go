@@ -0,0 +1,126 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// publishUntil changes the state of a project in a loop until stop is closed
+func publishUntil(m *Manager, project string, stop <-chan struct{}) {
+	states := []string{"thinking", "responding"}
+	for i := 0; ; i++ {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		m.UpdateFromHook(HookEvent{SessionID: project, ProjectName: project, Icon: "💭", State: states[i%len(states)]})
+	}
+}
+
+// waitClosed fails the test unless ch is closed within a few seconds,
+// draining what is left in it
+func waitClosed(t *testing.T, ch <-chan StatusEvent) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("subscription channel not closed")
+		}
+	}
+}
+
+// Cancelling subscriptions while changes are delivered to them must
+// neither send on a closed channel nor leave the channel open. Run with
+// -race.
+func TestSubscribeContextCancelDuringPublish(t *testing.T) {
+	m := NewManager()
+	stop := make(chan struct{})
+	var publishers sync.WaitGroup
+	for p := range 4 {
+		publishers.Add(1)
+		go func() {
+			defer publishers.Done()
+			publishUntil(m, fmt.Sprintf("p%d", p), stop)
+		}()
+	}
+	defer func() {
+		close(stop)
+		publishers.Wait()
+	}()
+
+	for i := range 50 {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := m.SubscribeContext(ctx)
+		if i%2 == 0 {
+			// Read some events first; odd subscribers never read, fall
+			// behind and are cancelled with a resync pending
+			for range 3 {
+				<-ch
+			}
+		}
+		cancel()
+		waitClosed(t, ch)
+	}
+}
+
+// Close ends every subscription; a context cancelled later, a new
+// subscription and further changes are then harmless
+func TestCloseEndsSubscriptions(t *testing.T) {
+	m := NewManager()
+	plain := m.Subscribe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tied := m.SubscribeContext(ctx)
+
+	m.UpdateFromHook(HookEvent{SessionID: "s", ProjectName: "p", Icon: "💭", State: "thinking"})
+	m.Close()
+	waitClosed(t, plain)
+	waitClosed(t, tied)
+
+	cancel()
+	m.Unsubscribe(plain)
+	late := m.Subscribe()
+	waitClosed(t, late)
+	if status := m.UpdateFromHook(HookEvent{SessionID: "s", ProjectName: "p", Icon: "💬", State: "responding"}); status == nil {
+		t.Error("change after Close not applied")
+	}
+}
+
+// Changes made concurrently reach subscribers in the order of their IDs
+func TestPublishOrder(t *testing.T) {
+	m := NewManager()
+	ch := m.Subscribe()
+	defer m.Unsubscribe(ch)
+
+	const publishers, changes = 4, 20 // within the subscriber buffer
+	var wg sync.WaitGroup
+	for p := range publishers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			states := []string{"thinking", "responding"}
+			for i := range changes {
+				m.UpdateFromHook(HookEvent{SessionID: "s", ProjectName: fmt.Sprintf("p%d", p), Icon: "💭", State: states[i%2]})
+			}
+		}()
+	}
+	wg.Wait()
+
+	var last uint64
+	for range publishers * changes {
+		event := <-ch
+		if event.ID <= last {
+			t.Fatalf("event %d delivered after event %d", event.ID, last)
+		}
+		last = event.ID
+	}
+}
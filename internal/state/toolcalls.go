@@ -0,0 +1,173 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+)
+
+// maxTrackedLogs bounds the session logs whose tool calls are followed;
+// the one read least recently is dropped first
+const maxTrackedLogs = 256
+
+// maxPendingCalls bounds the unanswered tool calls kept per session log
+const maxPendingCalls = 64
+
+// tailBytes is how much of a session log is read when it is first seen;
+// calls still waiting for their result are near its end
+const tailBytes = 1 << 20
+
+// toolCall is a tool_use of a session log without its tool_result yet
+type toolCall struct {
+	id     string
+	name   string
+	detail string
+}
+
+// logCalls follows the tool calls of one session log
+type logCalls struct {
+	offset    int64      // where the next read continues
+	message   string     // ID of the assistant message the calls belong to
+	pending   []toolCall // unanswered tool calls, oldest first
+	abandoned bool       // the last entry is a prompt that left calls unanswered
+	mode      string     // permission mode of the latest prompt
+	readAt    time.Time
+}
+
+// callState is what the tool calls of a session log tell after a read
+type callState struct {
+	oldest    toolCall // the first unanswered call, if pending
+	pending   bool
+	abandoned bool   // the last entry is a prompt that left calls unanswered
+	mode      string // permission mode of the latest prompt, "" if none was read
+}
+
+// toolCalls follows the tool calls of session logs across entries, and
+// the permission mode they run in. The last entry alone misreads
+// multi-tool turns: a result answering one call leaves the others
+// pending, and results may arrive after other content.
+type toolCalls struct {
+	mu   sync.Mutex
+	logs map[string]*logCalls // by session log path
+}
+
+// read applies the entries appended to a session log since the last read
+// and returns what its tool calls tell
+func (t *toolCalls) read(filePath string) (callState, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	l, ok := t.logs[filePath]
+	if !ok {
+		l = &logCalls{}
+		if info, err := os.Stat(filePath); err == nil {
+			l.offset = max(info.Size()-tailBytes, 0)
+		}
+	}
+	entries, next, restarted, err := parser.ReadEntriesFrom(filePath, l.offset)
+	if err != nil {
+		return callState{}, err
+	}
+	if !ok {
+		if t.logs == nil {
+			t.logs = make(map[string]*logCalls)
+		}
+		if len(t.logs) >= maxTrackedLogs {
+			t.dropOldest()
+		}
+		t.logs[filePath] = l
+	}
+	if restarted {
+		l.message, l.pending, l.abandoned, l.mode = "", nil, false, ""
+	}
+	l.offset, l.readAt = next, time.Now()
+	for _, entry := range entries {
+		l.apply(entry)
+	}
+
+	s := callState{pending: len(l.pending) > 0, abandoned: l.abandoned, mode: l.mode}
+	if s.pending {
+		s.oldest = l.pending[0]
+	}
+	return s, nil
+}
+
+// apply adds the tool calls of an assistant entry and removes the ones a
+// user entry answers
+func (l *logCalls) apply(entry *parser.Entry) {
+	// Subagent calls are answered within the subagent
+	if entry.IsSidechain {
+		return
+	}
+	// Only prompts carry the mode; it holds for the entries after them
+	if entry.PermissionMode != "" {
+		l.mode = entry.PermissionMode
+	}
+	if entry.Message == nil {
+		return
+	}
+	switch entry.Type {
+	case parser.EntryTypeAssistant:
+		l.abandoned = false
+		// Claude continues only once every call of a message has its
+		// result, so a new message leaves none of the earlier ones pending
+		if id := entry.Message.ID; id != "" && id != l.message {
+			l.message, l.pending = id, nil
+		}
+		for _, c := range entry.Message.Content {
+			if c.Type == string(parser.ContentTypeToolUse) && c.ID != "" {
+				l.pending = append(l.pending, toolCall{id: c.ID, name: c.Name, detail: c.ToolDetail()})
+			}
+		}
+		if over := len(l.pending) - maxPendingCalls; over > 0 {
+			l.pending = l.pending[over:]
+		}
+
+	case parser.EntryTypeUser:
+		answered := parser.GetToolResultIDs(entry.Message.Content)
+		if len(answered) == 0 {
+			// A prompt instead of the results abandons the calls
+			l.abandoned = len(l.pending) > 0 && len(entry.Message.Content) > 0 &&
+				entry.Message.Content[0].Type == string(parser.ContentTypeText)
+			if l.abandoned {
+				l.pending = nil
+			}
+			return
+		}
+		l.abandoned = false
+		l.pending = slices.DeleteFunc(l.pending, func(call toolCall) bool {
+			return slices.Contains(answered, call.id)
+		})
+	}
+}
+
+// dropOldest forgets the session log read least recently. Caller must
+// hold t.mu.
+func (t *toolCalls) dropOldest() {
+	var oldest string
+	var oldestAt time.Time
+	for path, l := range t.logs {
+		if oldest == "" || l.readAt.Before(oldestAt) {
+			oldest, oldestAt = path, l.readAt
+		}
+	}
+	delete(t.logs, oldest)
+}
+
+// forget drops the tool calls of a removed session log, or of the logs
+// below it for a removed project directory
+func (t *toolCalls) forget(filePath string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prefix := filePath + string(filepath.Separator)
+	for path := range t.logs {
+		if path == filePath || strings.HasPrefix(path, prefix) {
+			delete(t.logs, path)
+		}
+	}
+}
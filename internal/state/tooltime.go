@@ -0,0 +1,104 @@
+package state
+
+import (
+	"strings"
+	"time"
+)
+
+// maxPendingTools bounds the tool calls waiting for their PostToolUse
+// hook; older ones are dropped when hooks get lost
+const maxPendingTools = 256
+
+// ToolRun is a tool call measured from its PreToolUse to its PostToolUse
+// hook. PreToolUse fires after approval, so waiting for it is not counted.
+type ToolRun struct {
+	Project   string
+	SessionID string
+	ID        string // tool_use id
+	Tool      string
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+// pendingTool is a tool call that started and has not finished yet
+type pendingTool struct {
+	project   string
+	sessionID string
+	tool      string
+	startedAt time.Time
+}
+
+// SetToolRunFunc sets the function receiving every measured tool call
+func (m *Manager) SetToolRunFunc(fn func(run ToolRun)) {
+	m.mu.Lock()
+	m.onToolRun = fn
+	m.mu.Unlock()
+}
+
+// trackTool records the start of a tool call on PreToolUse and reports
+// its duration on the matching PostToolUse. Caller must hold m.mu.
+func (m *Manager) trackTool(event HookEvent, at time.Time) {
+	if event.ToolUseID == "" {
+		return
+	}
+	switch {
+	case isPreToolUse(event):
+		if len(m.pendingTools) >= maxPendingTools {
+			m.dropOldestTool()
+		}
+		m.pendingTools[event.ToolUseID] = pendingTool{
+			project:   event.ProjectName,
+			sessionID: event.SessionID,
+			tool:      event.ToolName,
+			startedAt: at,
+		}
+	case strings.EqualFold(event.HookEventName, "posttooluse"):
+		start, ok := m.pendingTools[event.ToolUseID]
+		if !ok {
+			return
+		}
+		delete(m.pendingTools, event.ToolUseID)
+		if m.onToolRun == nil || at.Before(start.startedAt) {
+			return
+		}
+		m.onToolRun(ToolRun{
+			Project:   start.project,
+			SessionID: start.sessionID,
+			ID:        event.ToolUseID,
+			Tool:      start.tool,
+			StartedAt: start.startedAt,
+			Duration:  at.Sub(start.startedAt),
+		})
+	}
+}
+
+// isPreToolUse reports whether a hook event announces a tool call
+func isPreToolUse(event HookEvent) bool {
+	return strings.EqualFold(event.HookEventName, "pretooluse")
+}
+
+// dropOldestTool forgets the tool call that started first. Caller must
+// hold m.mu.
+func (m *Manager) dropOldestTool() {
+	var oldest string
+	var oldestAt time.Time
+	for id, p := range m.pendingTools {
+		if oldest == "" || p.startedAt.Before(oldestAt) {
+			oldest, oldestAt = id, p.startedAt
+		}
+	}
+	delete(m.pendingTools, oldest)
+}
+
+// toolStartedAt returns when the tool a status shows running started: on
+// PreToolUse, or for session logs when the project started showing it.
+// It is zero for states other than running a tool.
+func toolStartedAt(cur, status *ProjectStatus, preToolUse bool) time.Time {
+	if RunningTool(status.State) == "" {
+		return time.Time{}
+	}
+	if !preToolUse && cur != nil && cur.State == status.State && cur.SessionID == status.SessionID && !cur.ToolStartedAt.IsZero() {
+		return cur.ToolStartedAt
+	}
+	return status.EventTime
+}
@@ -0,0 +1,168 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+)
+
+// traceTimeFormat shows event times in traces to the millisecond
+const traceTimeFormat = "15:04:05.000"
+
+// Trace decisions
+const (
+	TraceApplied  = "applied"  // the event became the project status
+	TraceRejected = "rejected" // the state machine kept the current status
+	TraceSkipped  = "skipped"  // the event carries no status
+	TraceSubagent = "subagent" // the event updated a subagent only
+)
+
+// Trace explains how one incoming event affected a project: what arrived,
+// how it was classified and what the state machine decided
+type Trace struct {
+	Time      time.Time `json:"time"`
+	Project   string    `json:"project"`
+	SessionID string    `json:"session_id,omitempty"`
+	Source    string    `json:"source"`           // jsonl, hooks, idle, ...
+	Signal    string    `json:"signal"`           // the raw event, summarized
+	Parsed    string    `json:"parsed,omitempty"` // the state it was classified as
+	Phase     string    `json:"phase,omitempty"`  // phase of the parsed state
+	Decision  string    `json:"decision"`
+	Reason    string    `json:"reason"`
+	From      string    `json:"from,omitempty"`  // status before the event
+	State     string    `json:"state,omitempty"` // status after the event
+}
+
+// SubscribeTrace creates a channel receiving a Trace for every event.
+// Traces are only built while someone is subscribed.
+func (m *Manager) SubscribeTrace() chan Trace {
+	ch := make(chan Trace, 100)
+	m.traceMu.Lock()
+	m.tracers = append(m.tracers, ch)
+	m.traceMu.Unlock()
+	return ch
+}
+
+// UnsubscribeTrace removes a trace channel
+func (m *Manager) UnsubscribeTrace(ch chan Trace) {
+	m.traceMu.Lock()
+	defer m.traceMu.Unlock()
+
+	for i, tracer := range m.tracers {
+		if tracer == ch {
+			m.tracers = append(m.tracers[:i], m.tracers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+// tracing reports whether anyone receives traces
+func (m *Manager) tracing() bool {
+	m.traceMu.RLock()
+	defer m.traceMu.RUnlock()
+	return len(m.tracers) > 0
+}
+
+// trace delivers t to the trace subscribers, dropping it for slow ones
+func (m *Manager) trace(t Trace) {
+	m.traceMu.RLock()
+	defer m.traceMu.RUnlock()
+
+	if t.Time.IsZero() {
+		t.Time = time.Now()
+	}
+	for _, ch := range m.tracers {
+		select {
+		case ch <- t:
+		default:
+		}
+	}
+}
+
+// traceStatus traces a status update and the state machine decision. cur
+// and result are the project statuses before and after (may be nil).
+func (m *Manager) traceStatus(next, cur, result *ProjectStatus, signal string, applied bool, reason string) {
+	decision := TraceApplied
+	if !applied {
+		decision = TraceRejected
+	}
+	m.trace(Trace{
+		Project:   next.Name,
+		SessionID: next.SessionID,
+		Source:    next.Source,
+		Signal:    signal,
+		Parsed:    next.Icon + " " + next.State,
+		Phase:     PhaseOf(next.State).String(),
+		Decision:  decision,
+		Reason:    reason,
+		From:      describeStatus(cur),
+		State:     describeStatus(result),
+	})
+}
+
+// traceSubagent traces an event that only updates a subagent
+func (m *Manager) traceSubagent(projectName, sessionID, source, signal string, u SubagentUpdate, result *ProjectStatus) {
+	reason := fmt.Sprintf("subagent %s is %s", u.AgentID, u.State)
+	if result == nil {
+		reason += "; no matching subagent of the current turn"
+	}
+	m.trace(Trace{
+		Project:   projectName,
+		SessionID: sessionID,
+		Source:    source,
+		Signal:    signal,
+		Parsed:    strings.TrimSpace(u.Icon + " " + u.State),
+		Phase:     PhaseOf(u.State).String(),
+		Decision:  TraceSubagent,
+		Reason:    reason,
+		State:     describeStatus(result),
+	})
+}
+
+// describeStatus formats a status for a trace, "" for none
+func describeStatus(s *ProjectStatus) string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %s (%s)", s.Icon, s.State, s.Source)
+}
+
+// entrySignal summarizes a session log entry: its type, stop reason and
+// content blocks
+func entrySignal(entry *parser.Entry) string {
+	parts := []string{string(entry.Type)}
+	if entry.IsSidechain {
+		parts = append(parts, "sidechain")
+	}
+	if entry.Message != nil {
+		if entry.Message.StopReason != nil {
+			parts = append(parts, "stop_reason="+*entry.Message.StopReason)
+		}
+		for _, c := range entry.Message.Content {
+			switch {
+			case c.Type == "tool_use":
+				parts = append(parts, "tool_use("+c.Name+")")
+			case c.Type == "tool_result" && c.IsError:
+				parts = append(parts, "tool_result(error)")
+			default:
+				parts = append(parts, c.Type)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// hookSignal summarizes a hook event
+func hookSignal(event HookEvent) string {
+	name := event.HookEventName
+	if name == "" {
+		name = "event"
+	}
+	if event.ToolName != "" {
+		name += " tool=" + event.ToolName
+	}
+	return name
+}
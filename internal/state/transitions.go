@@ -0,0 +1,130 @@
+package state
+
+import (
+	"sort"
+	"time"
+)
+
+// Bounds of the kept transitions: per session, and sessions in total
+const (
+	maxSessionTransitions = 1000
+	maxTransitionSessions = 200
+)
+
+// Transition is a state change of a session. Transitions are only ever
+// appended; once reported, a transition does not change.
+type Transition struct {
+	Cursor    uint64    `json:"cursor"` // increases with every transition of the daemon
+	Project   string    `json:"project"`
+	Icon      string    `json:"icon"`
+	State     string    `json:"state"`
+	Phase     string    `json:"phase"`
+	Source    string    `json:"source"`
+	Cause     string    `json:"cause,omitempty"` // idle_approval or idle_completed for idle detection
+	Estimated bool      `json:"estimated,omitempty"`
+	At        time.Time `json:"at"` // when the change happened
+}
+
+// transitionLog holds the transitions of a session
+type transitionLog struct {
+	entries []Transition
+	floor   uint64 // cursor of the last dropped transition, 0 = none dropped
+}
+
+// TransitionPage is a part of a session's transitions
+type TransitionPage struct {
+	Transitions []Transition
+	NextCursor  uint64 // cursor to continue after; the given one if nothing is new
+	More        bool   // more transitions follow NextCursor
+	Truncated   bool   // transitions after the given cursor are no longer kept
+}
+
+// recordTransition appends the state change of a published event to its
+// session's transitions. Caller must hold m.mu.
+func (m *Manager) recordTransition(event StatusEvent) {
+	status := event.Project
+	if status.SessionID == "" || event.Type == EventSessionRemoved {
+		return
+	}
+
+	log := m.transitions[status.SessionID]
+	if log == nil {
+		log = &transitionLog{}
+		m.transitions[status.SessionID] = log
+		m.trimTransitionLogs()
+	}
+	// Subagent and detail changes keep the session state
+	if n := len(log.entries); n > 0 {
+		last := log.entries[n-1]
+		if last.State == status.State && last.Icon == status.Icon && last.Project == status.Name {
+			return
+		}
+	}
+
+	t := Transition{
+		Cursor:    event.ID,
+		Project:   status.Name,
+		Icon:      status.Icon,
+		State:     status.State,
+		Phase:     PhaseOf(status.State).String(),
+		Source:    status.Source,
+		Estimated: status.IsEstimated,
+		At:        status.UpdatedAt,
+	}
+	if event.Type != "update" {
+		t.Cause = event.Type
+	}
+	if len(log.entries) == maxSessionTransitions {
+		log.floor = log.entries[0].Cursor
+		log.entries = append(log.entries[:0], log.entries[1:]...)
+	}
+	log.entries = append(log.entries, t)
+}
+
+// trimTransitionLogs drops the transitions of the least recently changed
+// sessions beyond maxTransitionSessions. Caller must hold m.mu.
+func (m *Manager) trimTransitionLogs() {
+	for len(m.transitions) > maxTransitionSessions {
+		var oldestID string
+		var oldest uint64
+		for id, log := range m.transitions {
+			var last uint64
+			if n := len(log.entries); n > 0 {
+				last = log.entries[n-1].Cursor
+			}
+			if oldestID == "" || last < oldest {
+				oldestID, oldest = id, last
+			}
+		}
+		delete(m.transitions, oldestID)
+	}
+}
+
+// Transitions returns up to limit transitions of a session after the
+// given cursor (0 = from the first kept one), oldest first, or false if
+// the session is unknown. A cursor from before the daemon started, or one
+// whose successors were dropped, yields Truncated.
+func (m *Manager) Transitions(sessionID string, after uint64, limit int) (TransitionPage, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	log := m.transitions[sessionID]
+	if log == nil {
+		return TransitionPage{}, false
+	}
+
+	page := TransitionPage{
+		NextCursor: after,
+		Truncated:  after < log.floor || (after != 0 && after < m.started),
+	}
+	i := sort.Search(len(log.entries), func(i int) bool { return log.entries[i].Cursor > after })
+	rest := log.entries[i:]
+	if len(rest) > limit {
+		rest, page.More = rest[:limit], true
+	}
+	page.Transitions = append([]Transition{}, rest...)
+	if len(rest) > 0 {
+		page.NextCursor = rest[len(rest)-1].Cursor
+	}
+	return page, true
+}
@@ -0,0 +1,228 @@
+package stats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// HistoryVersion is the format version of history files and exports
+const HistoryVersion = 1
+
+// Export formats
+const (
+	FormatJSON = "json"
+	FormatZstd = "zstd" // zstd-compressed JSON
+)
+
+// zstdMagic starts every zstd frame
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// History holds daily statistics, one snapshot per day sorted by date
+type History struct {
+	Version int        `json:"version"`
+	Days    []Snapshot `json:"days"`
+}
+
+// merge adds the projects of snap to the history. Records for a day and
+// project already present are replaced if overwrite is set and kept
+// otherwise. Returns the number of records added or replaced.
+func (h *History) merge(snap Snapshot, overwrite bool) int {
+	if len(snap.Projects) == 0 {
+		return 0
+	}
+	idx := sort.Search(len(h.Days), func(i int) bool { return h.Days[i].Date >= snap.Date })
+	if idx == len(h.Days) || h.Days[idx].Date != snap.Date {
+		h.Days = append(h.Days, Snapshot{})
+		copy(h.Days[idx+1:], h.Days[idx:])
+		h.Days[idx] = Snapshot{Date: snap.Date}
+	}
+	day := &h.Days[idx]
+
+	changed := 0
+	for _, p := range snap.Projects {
+		i := sort.Search(len(day.Projects), func(i int) bool { return day.Projects[i].Name >= p.Name })
+		switch {
+		case i < len(day.Projects) && day.Projects[i].Name == p.Name:
+			if !overwrite {
+				continue
+			}
+			day.Projects[i] = p
+		default:
+			day.Projects = append(day.Projects, ProjectStats{})
+			copy(day.Projects[i+1:], day.Projects[i:])
+			day.Projects[i] = p
+		}
+		changed++
+	}
+	return changed
+}
+
+// HistoryStore keeps daily statistics in a JSON file. Every change reads
+// the file first, so the daemon and import commands can share it.
+type HistoryStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewHistoryStore creates a store backed by path
+func NewHistoryStore(path string) *HistoryStore {
+	return &HistoryStore{path: path}
+}
+
+// Path returns the history file
+func (s *HistoryStore) Path() string {
+	return s.path
+}
+
+// Load reads the history; a missing file is an empty history
+func (s *HistoryStore) Load() (*History, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Save records a day's statistics, replacing earlier records of the same
+// day and project
+func (s *HistoryStore) Save(snap Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hist, err := s.load()
+	if err != nil {
+		return err
+	}
+	if hist.merge(snap, true) == 0 {
+		return nil
+	}
+	return s.write(hist)
+}
+
+// Import merges an exported history into the store. Existing records for
+// the same day and project are kept unless overwrite is set. Returns the
+// number of records imported.
+func (s *HistoryStore) Import(imported *History, overwrite bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hist, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, day := range imported.Days {
+		n += hist.merge(day, overwrite)
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return n, s.write(hist)
+}
+
+// load reads the history file. Caller must hold s.mu.
+func (s *HistoryStore) load() (*History, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return &History{Version: HistoryVersion}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hist, err := ReadHistory(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid history file %s: %w", s.path, err)
+	}
+	return hist, nil
+}
+
+// write replaces the history file. Caller must hold s.mu.
+func (s *HistoryStore) write(hist *History) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(hist, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write via rename so readers never see a partial file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// WriteHistory writes a history export in the given format
+func WriteHistory(w io.Writer, hist *History, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(hist)
+
+	case FormatZstd:
+		zw, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+		if err != nil {
+			return err
+		}
+		if err := json.NewEncoder(zw).Encode(hist); err != nil {
+			zw.Close()
+			return err
+		}
+		return zw.Close()
+
+	default:
+		return fmt.Errorf("unknown format %q (want %s or %s)", format, FormatJSON, FormatZstd)
+	}
+}
+
+// ReadHistory reads a history export, detecting zstd compression
+func ReadHistory(r io.Reader) (*History, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(len(zstdMagic)); bytes.Equal(magic, zstdMagic) {
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	} else {
+		r = br
+	}
+
+	var hist History
+	if err := json.NewDecoder(r).Decode(&hist); err != nil {
+		return nil, err
+	}
+	if hist.Version > HistoryVersion {
+		return nil, fmt.Errorf("history version %d is newer than supported version %d", hist.Version, HistoryVersion)
+	}
+	hist.Version = HistoryVersion
+	for _, day := range hist.Days {
+		if _, err := time.Parse("2006-01-02", day.Date); err != nil {
+			return nil, fmt.Errorf("invalid date %q", day.Date)
+		}
+	}
+
+	sort.Slice(hist.Days, func(i, j int) bool { return hist.Days[i].Date < hist.Days[j].Date })
+	for _, day := range hist.Days {
+		sort.Slice(day.Projects, func(i, j int) bool { return day.Projects[i].Name < day.Projects[j].Name })
+	}
+	return &hist, nil
+}
@@ -0,0 +1,306 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// ProjectStats holds today's activity aggregates for a project
+type ProjectStats struct {
+	Name              string         `json:"name"`
+	ActiveSeconds     float64        `json:"active_seconds"`
+	ToolCalls         map[string]int `json:"tool_calls"`
+	ApprovalsWaited   int            `json:"approvals_waited"`
+	Completions       int            `json:"completions"`
+	AvgResponseMillis int64          `json:"avg_response_latency_ms"`
+	Responses         int            `json:"responses"` // responses averaged in AvgResponseMillis
+	// ToolTimes holds the measured run time of tool calls, from hook
+	// PreToolUse/PostToolUse pairs
+	ToolTimes map[string]ToolTime `json:"tool_times,omitempty"`
+}
+
+// ToolTime aggregates the measured run time of a tool's calls
+type ToolTime struct {
+	Runs         int     `json:"runs"`
+	TotalSeconds float64 `json:"total_seconds"`
+	MaxSeconds   float64 `json:"max_seconds"`
+}
+
+// Snapshot is a point-in-time view of all project statistics
+type Snapshot struct {
+	Date     string         `json:"date"`
+	Projects []ProjectStats `json:"projects"`
+}
+
+// projectAccumulator tracks running aggregates for one project
+type projectAccumulator struct {
+	lastPhase   state.Phase
+	lastState   string
+	lastAt      time.Time
+	active      time.Duration
+	toolCalls   map[string]int
+	approvals   int
+	completions int
+	promptAt    time.Time // when the pending user input arrived
+	latencySum  time.Duration
+	latencyRuns int
+	toolTimes   map[string]ToolTime
+}
+
+// Collector computes activity statistics from the status event stream.
+// Statistics cover the current local day and reset at midnight.
+type Collector struct {
+	mu       sync.RWMutex
+	day      string
+	projects map[string]*projectAccumulator
+	finished *Snapshot // the previous day, until taken for the history
+	now      func() time.Time
+}
+
+// NewCollector creates a new Collector
+func NewCollector() *Collector {
+	return &Collector{
+		projects: make(map[string]*projectAccumulator),
+		now:      time.Now,
+	}
+}
+
+// Run consumes events until the channel closes or done is closed
+func (c *Collector) Run(events <-chan state.StatusEvent, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case received, ok := <-events:
+			if !ok {
+				return
+			}
+			for _, event := range received.Updates() {
+				// Removals carry no state
+				if event.Type == state.EventSessionRemoved {
+					continue
+				}
+				c.Record(event.Project)
+			}
+		}
+	}
+}
+
+// Record folds a status change into the statistics
+func (c *Collector) Record(status state.ProjectStatus) {
+	at := status.UpdatedAt
+	if at.IsZero() {
+		at = c.now()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rollover(at)
+
+	acc := c.project(status.Name)
+
+	phase := state.PhaseOf(status.State)
+
+	// Time spent working since the previous event counts as active
+	if acc.lastPhase == state.PhaseWorking && !acc.lastAt.IsZero() && at.After(acc.lastAt) {
+		acc.active += at.Sub(acc.lastAt)
+	}
+
+	changed := status.State != acc.lastState
+
+	if changed && strings.HasPrefix(status.State, "running") {
+		tool := state.RunningTool(status.State)
+		if tool == "" {
+			tool = "unknown"
+		}
+		acc.toolCalls[tool]++
+	}
+
+	if changed && phase == state.PhaseWaiting {
+		acc.approvals++
+	}
+
+	if changed && phase == state.PhaseCompleted {
+		acc.completions++
+	}
+
+	// Response latency: from user input to the first sign of work
+	if phase == state.PhaseUserInput {
+		if acc.promptAt.IsZero() {
+			acc.promptAt = at
+		}
+	} else if !acc.promptAt.IsZero() && phase == state.PhaseWorking {
+		acc.latencySum += at.Sub(acc.promptAt)
+		acc.latencyRuns++
+		acc.promptAt = time.Time{}
+	}
+
+	acc.lastPhase = phase
+	acc.lastState = status.State
+	acc.lastAt = at
+}
+
+// RecordToolRun adds a measured tool call to the statistics
+func (c *Collector) RecordToolRun(run state.ToolRun) {
+	at := run.StartedAt.Add(run.Duration)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rollover(at)
+
+	acc := c.project(run.Project)
+	tool := run.Tool
+	if tool == "" {
+		tool = "unknown"
+	}
+	t := acc.toolTimes[tool]
+	t.Runs++
+	t.TotalSeconds += run.Duration.Seconds()
+	t.MaxSeconds = max(t.MaxSeconds, run.Duration.Seconds())
+	acc.toolTimes[tool] = t
+}
+
+// project returns the accumulator of a project, creating it if needed.
+// Caller must hold c.mu.
+func (c *Collector) project(name string) *projectAccumulator {
+	acc, ok := c.projects[name]
+	if !ok {
+		acc = &projectAccumulator{
+			toolCalls: make(map[string]int),
+			toolTimes: make(map[string]ToolTime),
+		}
+		c.projects[name] = acc
+	}
+	return acc
+}
+
+// rollover resets statistics when the local day changes, keeping the
+// finished day for TakeFinishedDay
+func (c *Collector) rollover(at time.Time) {
+	day := at.Local().Format("2006-01-02")
+	if day != c.day {
+		if c.day != "" && len(c.projects) > 0 {
+			// Working time of the finished day ends at midnight
+			local := at.Local()
+			midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local)
+			finished := c.snapshot(midnight)
+			c.finished = &finished
+		}
+		c.day = day
+		c.projects = make(map[string]*projectAccumulator)
+	}
+}
+
+// TakeFinishedDay returns the statistics of the day before the current
+// one, once, after the day has rolled over
+func (c *Collector) TakeFinishedDay() (Snapshot, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.finished == nil {
+		return Snapshot{}, false
+	}
+	snap := *c.finished
+	c.finished = nil
+	return snap, true
+}
+
+// Restore continues counting from saved statistics of the current day, so
+// a restart does not reset today's numbers. Snapshots of other days are
+// ignored.
+func (c *Collector) Restore(snap Snapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	today := c.now().Local().Format("2006-01-02")
+	if snap.Date != today || (c.day != "" && c.day != today) {
+		return
+	}
+	c.day = today
+
+	for _, p := range snap.Projects {
+		if _, ok := c.projects[p.Name]; ok {
+			continue
+		}
+		acc := &projectAccumulator{
+			toolCalls:   make(map[string]int, len(p.ToolCalls)),
+			active:      time.Duration(p.ActiveSeconds * float64(time.Second)),
+			approvals:   p.ApprovalsWaited,
+			completions: p.Completions,
+			latencySum:  time.Duration(p.AvgResponseMillis) * time.Millisecond * time.Duration(p.Responses),
+			latencyRuns: p.Responses,
+			toolTimes:   make(map[string]ToolTime, len(p.ToolTimes)),
+		}
+		for tool, n := range p.ToolCalls {
+			acc.toolCalls[tool] = n
+		}
+		for tool, t := range p.ToolTimes {
+			acc.toolTimes[tool] = t
+		}
+		c.projects[p.Name] = acc
+	}
+}
+
+// Snapshot returns the current statistics, sorted by project name.
+// Active time includes the ongoing working interval up to now.
+func (c *Collector) Snapshot() Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.snapshot(c.now())
+}
+
+// snapshot returns the statistics with working time counted up to now.
+// Caller must hold c.mu.
+func (c *Collector) snapshot(now time.Time) Snapshot {
+	snap := Snapshot{Date: c.day, Projects: make([]ProjectStats, 0, len(c.projects))}
+	if snap.Date == "" {
+		snap.Date = now.Local().Format("2006-01-02")
+	}
+
+	for name, acc := range c.projects {
+		active := acc.active
+		if acc.lastPhase == state.PhaseWorking && now.After(acc.lastAt) {
+			active += now.Sub(acc.lastAt)
+		}
+
+		tools := make(map[string]int, len(acc.toolCalls))
+		for tool, n := range acc.toolCalls {
+			tools[tool] = n
+		}
+
+		var times map[string]ToolTime
+		if len(acc.toolTimes) > 0 {
+			times = make(map[string]ToolTime, len(acc.toolTimes))
+			for tool, t := range acc.toolTimes {
+				times[tool] = t
+			}
+		}
+
+		var avg int64
+		if acc.latencyRuns > 0 {
+			avg = (acc.latencySum / time.Duration(acc.latencyRuns)).Milliseconds()
+		}
+
+		snap.Projects = append(snap.Projects, ProjectStats{
+			Name:              name,
+			ActiveSeconds:     active.Seconds(),
+			ToolCalls:         tools,
+			ApprovalsWaited:   acc.approvals,
+			Completions:       acc.completions,
+			AvgResponseMillis: avg,
+			Responses:         acc.latencyRuns,
+			ToolTimes:         times,
+		})
+	}
+
+	sort.Slice(snap.Projects, func(i, j int) bool {
+		return snap.Projects[i].Name < snap.Projects[j].Name
+	})
+	return snap
+}
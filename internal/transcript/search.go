@@ -0,0 +1,224 @@
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+	"github.com/sho7650/claude-watch-status/internal/redact"
+	"github.com/sho7650/claude-watch-status/internal/watcher"
+)
+
+// snippetContext is the number of characters kept on each side of a match
+const snippetContext = 60
+
+// Query selects the session log entries returned by Search
+type Query struct {
+	Text    string    // matched case-insensitively
+	Project string    // "" = all projects
+	Since   time.Time // zero = all entries
+	Limit   int       // matches returned, 0 = all
+}
+
+// Match is a prompt, reply, tool call or tool result matching a search
+type Match struct {
+	Project   string    `json:"project"`
+	SessionID string    `json:"session_id"`
+	Time      time.Time `json:"time"`
+	Role      string    `json:"role"` // user or assistant
+	Type      string    `json:"type"` // text, tool_use or tool_result
+	Tool      string    `json:"tool,omitempty"`
+	Snippet   string    `json:"snippet"` // the text around the match, redacted
+	Subagent  bool      `json:"subagent,omitempty"`
+	IsError   bool      `json:"is_error,omitempty"`
+}
+
+// Result is the outcome of a search
+type Result struct {
+	Matches   []Match `json:"matches"`
+	Truncated bool    `json:"truncated"` // more entries match than Limit
+}
+
+// sessionLog is a session log to search
+type sessionLog struct {
+	project string
+	path    string
+	modTime time.Time
+}
+
+// Search scans the session logs of a project, or of all projects, for
+// text, tool calls and tool results containing q.Text. Sessions are
+// searched newest first, the entries of a session in order. Secrets are
+// redacted before matching, so they cannot be searched for.
+func Search(projectsDir string, nameFor func(dir string) string, q Query) (Result, error) {
+	result := Result{Matches: []Match{}}
+	query := []rune(lower(strings.TrimSpace(q.Text)))
+	if len(query) == 0 {
+		return result, nil
+	}
+
+	logs, err := searchLogs(projectsDir, nameFor, q)
+	if err != nil {
+		return result, err
+	}
+	for _, l := range logs {
+		session := strings.TrimSuffix(filepath.Base(l.path), ".jsonl")
+		tools := make(map[string]string) // tool_use ID -> tool name, to label results
+		err := Each(l.path, func(entry Entry) {
+			if result.Truncated || (entry.Type != parser.EntryTypeUser && entry.Type != parser.EntryTypeAssistant) {
+				return
+			}
+			ts, _ := time.Parse(time.RFC3339Nano, entry.Timestamp)
+			if !q.Since.IsZero() && ts.Before(q.Since) {
+				return
+			}
+			for _, c := range Contents(entry.Message.Content) {
+				var text, tool string
+				switch c.Type {
+				case string(parser.ContentTypeText):
+					text = c.Text
+				case string(parser.ContentTypeToolUse):
+					tools[c.ID] = c.Name
+					tool = c.Name
+					var buf bytes.Buffer
+					if json.Compact(&buf, c.Input) == nil {
+						text = c.Name + " " + buf.String()
+					}
+				case string(parser.ContentTypeToolResult):
+					tool = tools[c.ToolUseID]
+					text = c.ResultText()
+				default:
+					continue
+				}
+				snippet, ok := matchSnippet(text, query)
+				if !ok {
+					continue
+				}
+				if q.Limit > 0 && len(result.Matches) == q.Limit {
+					result.Truncated = true
+					return
+				}
+				result.Matches = append(result.Matches, Match{
+					Project:   l.project,
+					SessionID: session,
+					Time:      ts,
+					Role:      string(entry.Type),
+					Type:      c.Type,
+					Tool:      tool,
+					Snippet:   snippet,
+					Subagent:  entry.IsSidechain,
+					IsError:   c.IsError,
+				})
+			}
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return result, err
+		}
+		if result.Truncated {
+			break
+		}
+	}
+	return result, nil
+}
+
+// searchLogs returns the session logs a query covers, newest first. Logs
+// last written before q.Since hold no entries after it.
+func searchLogs(projectsDir string, nameFor func(dir string) string, q Query) ([]sessionLog, error) {
+	dirs, err := watcher.ListProjectDirs(projectsDir, nameFor)
+	if err != nil {
+		return nil, err
+	}
+	var logs []sessionLog
+	for _, d := range dirs {
+		if q.Project != "" && d.Project != q.Project {
+			continue
+		}
+		entries, err := os.ReadDir(d.Dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || (!q.Since.IsZero() && info.ModTime().Before(q.Since)) {
+				continue
+			}
+			logs = append(logs, sessionLog{project: d.Project, path: filepath.Join(d.Dir, entry.Name()), modTime: info.ModTime()})
+		}
+	}
+	slices.SortFunc(logs, func(a, b sessionLog) int { return b.modTime.Compare(a.modTime) })
+	return logs, nil
+}
+
+// matchSnippet returns text on one line, redacted and cut to the
+// characters around the first occurrence of query, reporting false if
+// it does not occur
+func matchSnippet(text string, query []rune) (string, bool) {
+	runes := []rune(redact.String(strings.Join(strings.Fields(text), " ")))
+	i := index(runes, query)
+	if i < 0 {
+		return "", false
+	}
+	start, end := max(i-snippetContext, 0), min(i+len(query)+snippetContext, len(runes))
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet, true
+}
+
+// Highlight wraps every case-insensitive occurrence of query in text
+// with mark, e.g. to color matches in a terminal
+func Highlight(text, query string, mark func(string) string) string {
+	q := []rune(lower(strings.TrimSpace(query)))
+	if len(q) == 0 {
+		return text
+	}
+	runes := []rune(text)
+	var b strings.Builder
+	for {
+		i := index(runes, q)
+		if i < 0 {
+			b.WriteString(string(runes))
+			return b.String()
+		}
+		b.WriteString(string(runes[:i]))
+		b.WriteString(mark(string(runes[i : i+len(q)])))
+		runes = runes[i+len(q):]
+	}
+}
+
+// index returns the position of the first case-insensitive occurrence of
+// a lowercased query in runes, or -1. Runes are compared one by one, so
+// positions in runes and their lowercase form agree.
+func index(runes, query []rune) int {
+	for i := 0; i+len(query) <= len(runes); i++ {
+		found := true
+		for j, r := range query {
+			if unicode.ToLower(runes[i+j]) != r {
+				found = false
+				break
+			}
+		}
+		if found {
+			return i
+		}
+	}
+	return -1
+}
+
+// lower lowercases s rune by rune, as index compares
+func lower(s string) string {
+	return strings.Map(unicode.ToLower, s)
+}
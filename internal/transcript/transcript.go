@@ -0,0 +1,76 @@
+// Package transcript reads Claude Code session logs as transcripts of
+// prompts, replies and tool calls, for the tail, sessions and search
+// commands and the search API.
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+)
+
+// Entry is the part of a session log entry shown in transcripts. Unlike
+// parser.Entry, user message content may be a plain string.
+type Entry struct {
+	Type        parser.EntryType `json:"type"`
+	Timestamp   string           `json:"timestamp"`
+	IsSidechain bool             `json:"isSidechain"`
+	Summary     string           `json:"summary"`
+	Message     struct {
+		ID      string          `json:"id"` // assistant messages span several entries
+		Content json.RawMessage `json:"content"`
+		Usage   *parser.Usage   `json:"usage"`
+	} `json:"message"`
+}
+
+// Decode parses a log line, reporting false for lines that are not JSON
+func Decode(line []byte) (Entry, bool) {
+	var entry Entry
+	return entry, json.Unmarshal(line, &entry) == nil
+}
+
+// Contents returns the content items of a message; a plain string is
+// returned as one text item
+func Contents(raw json.RawMessage) []parser.Content {
+	if len(raw) == 0 {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return []parser.Content{{Type: string(parser.ContentTypeText), Text: text}}
+	}
+	var items []parser.Content
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil
+	}
+	return items
+}
+
+// Each calls fn for every entry of a session log
+func Each(path string, fn func(entry Entry)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReaderSize(f, 64*1024)
+	for {
+		line, err := r.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if entry, ok := Decode(line); ok {
+				fn(entry)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
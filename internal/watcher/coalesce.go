@@ -0,0 +1,104 @@
+package watcher
+
+import (
+	"sort"
+	"time"
+)
+
+// CoalesceWindow is how long changes to a session log are collected
+// before it is re-read: an assistant turn writes it dozens of times
+const CoalesceWindow = 100 * time.Millisecond
+
+// Coalescer holds back file events so that a burst of changes to one
+// file is handled once. The first change to a file opens its window;
+// later ones within it replace the held event.
+type Coalescer struct {
+	pending map[string]Event
+	due     map[string]time.Time // when each held file's window closes
+}
+
+// NewCoalescer creates an empty Coalescer
+func NewCoalescer() *Coalescer {
+	return &Coalescer{
+		pending: make(map[string]Event),
+		due:     make(map[string]time.Time),
+	}
+}
+
+// Add holds event until window after the first held change to its file
+func (c *Coalescer) Add(event Event, window time.Duration) {
+	if _, ok := c.pending[event.Path]; !ok {
+		c.due[event.Path] = time.Now().Add(window)
+	}
+	c.pending[event.Path] = event
+}
+
+// Take removes and returns the events whose window has closed, or all
+// held events if all is set, in the order their files first changed
+func (c *Coalescer) Take(all bool) []Event {
+	now := time.Now()
+	var paths []string
+	for path := range c.pending {
+		if all || !c.due[path].After(now) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool { return c.due[paths[i]].Before(c.due[paths[j]]) })
+
+	events := make([]Event, 0, len(paths))
+	for _, path := range paths {
+		events = append(events, c.pending[path])
+		delete(c.pending, path)
+		delete(c.due, path)
+	}
+	return events
+}
+
+// Next returns when the earliest window closes, zero if nothing is held
+func (c *Coalescer) Next() time.Time {
+	var next time.Time
+	for _, due := range c.due {
+		if next.IsZero() || due.Before(next) {
+			next = due
+		}
+	}
+	return next
+}
+
+// Coalesce forwards events, coalescing changes to the same file within
+// window. The returned channel is closed after events is.
+func Coalesce(events <-chan Event, window time.Duration) <-chan Event {
+	out := make(chan Event, cap(events))
+	go func() {
+		defer close(out)
+
+		held := NewCoalescer()
+		var flush <-chan time.Time
+		send := func(all bool) {
+			for _, event := range held.Take(all) {
+				out <- event
+			}
+			flush = nil
+			if next := held.Next(); !next.IsZero() {
+				flush = time.After(time.Until(next))
+			}
+		}
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					send(true)
+					return
+				}
+				held.Add(event, window)
+				if flush == nil {
+					flush = time.After(window)
+				}
+			case <-flush:
+				send(false)
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,245 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// notifyWatcher detects changes through file system notifications
+type notifyWatcher struct {
+	resolver
+
+	fsWatcher *fsnotify.Watcher
+	events    chan Event
+	errors    chan error
+	done      chan struct{}
+	mu        sync.RWMutex
+	watching  map[string]bool
+}
+
+func newNotifyWatcher(projectsDir string) (*notifyWatcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &notifyWatcher{
+		resolver:  newResolver(projectsDir),
+		fsWatcher: fsWatcher,
+		events:    make(chan Event, 100),
+		errors:    make(chan error, 10),
+		done:      make(chan struct{}),
+		watching:  make(map[string]bool),
+	}
+
+	return w, nil
+}
+
+func (w *notifyWatcher) Start() error {
+	// Initial scan of existing directories
+	if err := w.scanDirectories(); err != nil {
+		return err
+	}
+
+	// Watch the projects directory for new project folders
+	if err := w.fsWatcher.Add(w.projectsDir); err != nil {
+		return err
+	}
+
+	go w.watchLoop()
+	return nil
+}
+
+func (w *notifyWatcher) Events() <-chan Event {
+	return w.events
+}
+
+func (w *notifyWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+func (w *notifyWatcher) Stop() error {
+	close(w.done)
+	return w.fsWatcher.Close()
+}
+
+func (w *notifyWatcher) Mode() Mode {
+	return ModeFSNotify
+}
+
+func (w *notifyWatcher) scanDirectories() error {
+	entries, err := os.ReadDir(w.projectsDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			w.watchTree(filepath.Join(w.projectsDir, entry.Name()), 1)
+		}
+	}
+	return nil
+}
+
+// recentSessionAge limits which existing session directories are watched
+// for subagent logs at startup; newer ones are picked up when created
+const recentSessionAge = 24 * time.Hour
+
+// maxWatchDepth bounds how deep directories are watched: project
+// directories are at depth 1, sessions at 2, their subagents at 3, and
+// nested subagent transcripts below
+const maxWatchDepth = 6
+
+// watchTree watches dir, at the given depth below the projects directory,
+// and its subdirectories. Existing session directories are only watched
+// if recently modified.
+func (w *notifyWatcher) watchTree(dir string, depth int) {
+	if err := w.watchDirectory(dir); err != nil {
+		// Failures below project directories are not worth an error each
+		if depth == 1 {
+			w.errors <- err
+		}
+		return
+	}
+	if depth >= maxWatchDepth {
+		return
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if depth == 1 {
+			info, err := entry.Info()
+			if err != nil || time.Since(info.ModTime()) > recentSessionAge {
+				continue
+			}
+		}
+		w.watchTree(filepath.Join(dir, entry.Name()), depth+1)
+	}
+}
+
+// depth returns how deep path is below the projects directory
+func (w *notifyWatcher) depth(path string) int {
+	rel, err := filepath.Rel(w.projectsDir, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+func (w *notifyWatcher) watchDirectory(dirPath string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.watching[dirPath] {
+		return nil
+	}
+
+	if err := w.fsWatcher.Add(dirPath); err != nil {
+		return err
+	}
+	w.watching[dirPath] = true
+	return nil
+}
+
+// unwatchTree stops watching a removed or renamed directory and everything
+// below it, so deleted session directories do not leak watches
+func (w *notifyWatcher) unwatchTree(dirPath string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.watching[dirPath] {
+		return
+	}
+	prefix := dirPath + string(filepath.Separator)
+	for path := range w.watching {
+		if path == dirPath || strings.HasPrefix(path, prefix) {
+			// Fails for directories already gone; their watch went with them
+			w.fsWatcher.Remove(path)
+			delete(w.watching, path)
+		}
+	}
+}
+
+func (w *notifyWatcher) watchLoop() {
+	// Closing the channels lets consumers range over them until Stop
+	defer close(w.errors)
+	defer close(w.events)
+
+	for {
+		select {
+		case <-w.done:
+			return
+
+		case event, ok := <-w.fsWatcher.Events:
+			if !ok {
+				return
+			}
+			w.handleEvent(event)
+
+		case err, ok := <-w.fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			w.errors <- err
+		}
+	}
+}
+
+// catchUpDirectory handles what was created inside a new directory before
+// it was watched (e.g. "mkdir -p {session}/subagents" followed by a write)
+func (w *notifyWatcher) catchUpDirectory(dirPath string) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		w.handleEvent(fsnotify.Event{Name: filepath.Join(dirPath, entry.Name()), Op: fsnotify.Create})
+	}
+}
+
+func (w *notifyWatcher) handleEvent(event fsnotify.Event) {
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		w.unwatchTree(event.Name)
+		// Replaced in place (e.g. an atomic rewrite): nothing was removed
+		if _, err := os.Stat(event.Name); err == nil {
+			return
+		}
+		if ev, ok := w.removal(event.Name); ok {
+			w.events <- ev
+		}
+		return
+	}
+
+	// Handle new directory creation, at any depth within bounds
+	if event.Has(fsnotify.Create) {
+		info, err := os.Stat(event.Name)
+		if err == nil && info.IsDir() {
+			if w.depth(event.Name) > maxWatchDepth {
+				return
+			}
+			if err := w.watchDirectory(event.Name); err != nil {
+				w.errors <- err
+			}
+			w.catchUpDirectory(event.Name)
+			return
+		}
+	}
+
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+		return
+	}
+	if ev, ok := w.event(event.Name); ok {
+		w.events <- ev
+	}
+}
@@ -0,0 +1,30 @@
+package watcher
+
+import "syscall"
+
+// remoteTypes are filesystems that never deliver FSEvents/kqueue events
+// for changes made by other machines
+var remoteTypes = map[string]bool{
+	"nfs":     true,
+	"smbfs":   true,
+	"afpfs":   true,
+	"webdav":  true,
+	"macfuse": true, // sshfs, ...
+	"osxfuse": true,
+}
+
+// remoteFilesystem reports whether dir is on a network filesystem
+func remoteFilesystem(dir string) (string, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return "", false
+	}
+	var name []byte
+	for _, c := range st.Fstypename {
+		if c == 0 {
+			break
+		}
+		name = append(name, byte(c))
+	}
+	return string(name), remoteTypes[string(name)]
+}
@@ -0,0 +1,25 @@
+package watcher
+
+import "syscall"
+
+// remoteMagic maps statfs filesystem types that never deliver inotify
+// events for changes made by other machines or the Windows host
+var remoteMagic = map[uint32]string{
+	0x6969:     "nfs",
+	0x517b:     "smb",
+	0xff534d42: "cifs",
+	0xfe534d42: "smb2",
+	0x65735546: "fuse", // sshfs, rclone, ...
+	0x01021997: "9p",   // WSL 2 drive mounts
+	0x5346414f: "afs",
+}
+
+// remoteFilesystem reports whether dir is on a network filesystem
+func remoteFilesystem(dir string) (string, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return "", false
+	}
+	name, ok := remoteMagic[uint32(st.Type)]
+	return name, ok
+}
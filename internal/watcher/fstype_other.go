@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package watcher
+
+// remoteFilesystem reports whether dir is on a network filesystem; use
+// --watch-mode poll where this cannot be detected
+func remoteFilesystem(dir string) (string, bool) {
+	return "", false
+}
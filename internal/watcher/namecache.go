@@ -0,0 +1,113 @@
+package watcher
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// nameCacheEntry is a resolved project directory
+type nameCacheEntry struct {
+	Name string `json:"name"`
+	Path string `json:"path"` // resolved project path, checked on load
+}
+
+// nameCacheFile is the on-disk format of the project name cache
+type nameCacheFile struct {
+	Projects map[string]nameCacheEntry `json:"projects"` // keyed by encoded directory
+}
+
+// nameCache maps encoded project directories to project names. Names that
+// resolved to an existing directory are persisted, so restarts skip
+// probing candidate paths; an entry is dropped when its directory is gone.
+type nameCache struct {
+	mu      sync.RWMutex
+	path    string // cache file, "" = memory only
+	names   map[string]string
+	entries map[string]nameCacheEntry // persisted entries
+}
+
+// newNameCache creates a cache backed by path, loading existing entries
+func newNameCache(path string) *nameCache {
+	c := &nameCache{
+		path:    path,
+		names:   make(map[string]string),
+		entries: make(map[string]nameCacheEntry),
+	}
+	c.load()
+	return c
+}
+
+// get returns the cached name for an encoded directory
+func (c *nameCache) get(encodedDir string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	name, ok := c.names[encodedDir]
+	return name, ok
+}
+
+// put caches a name, persisting it if it was resolved to a directory
+func (c *nameCache) put(encodedDir, name, path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.names[encodedDir] = name
+	if path == "" {
+		return
+	}
+	c.entries[encodedDir] = nameCacheEntry{Name: name, Path: path}
+	c.save()
+}
+
+// load reads the cache file, skipping entries whose directory is gone
+func (c *nameCache) load() {
+	if c.path == "" {
+		return
+	}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return
+	}
+	var file nameCacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return
+	}
+
+	stale := false
+	for encodedDir, entry := range file.Projects {
+		if info, err := os.Stat(entry.Path); err != nil || !info.IsDir() {
+			stale = true
+			continue
+		}
+		c.entries[encodedDir] = entry
+		c.names[encodedDir] = entry.Name
+	}
+	if stale {
+		c.save()
+	}
+}
+
+// save writes the persisted entries. Errors are ignored: the cache only
+// saves work on the next start. Caller must hold c.mu (or own c).
+func (c *nameCache) save() {
+	if c.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(nameCacheFile{Projects: c.entries}, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return
+	}
+
+	// Write via rename so concurrent instances never read a partial file
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		os.Remove(tmp)
+	}
+}
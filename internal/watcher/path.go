@@ -0,0 +1,29 @@
+package watcher
+
+// unixEncodedRoot splits the root off an encoded project directory of a
+// Unix path. Claude Code replaces "/" with "-", so /Users/me/proj is
+// encoded as "-Users-me-proj".
+func unixEncodedRoot(encodedDir string) (root, rest string) {
+	if encodedDir != "" && encodedDir[0] == '-' {
+		return "/", encodedDir[1:]
+	}
+	return "/", encodedDir
+}
+
+// windowsEncodedRoot splits the root off an encoded project directory of
+// a Windows path. Claude Code replaces ":" and "\" with "-", so
+// C:\Users\me\proj is encoded as "C--Users-me-proj" (and
+// \\server\share\proj as "--server-share-proj").
+func windowsEncodedRoot(encodedDir string) (root, rest string) {
+	if len(encodedDir) >= 3 && isDriveLetter(encodedDir[0]) && encodedDir[1:3] == "--" {
+		return encodedDir[:1] + `:\`, encodedDir[3:]
+	}
+	if len(encodedDir) >= 2 && encodedDir[:2] == "--" {
+		return `\\`, encodedDir[2:]
+	}
+	return `\`, encodedDir
+}
+
+func isDriveLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,77 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWindowsEncodedRoot(t *testing.T) {
+	tests := []struct {
+		encoded  string
+		wantRoot string
+		wantRest string
+	}{
+		{"C--Users-x-proj", `C:\`, "Users-x-proj"},
+		{"d--src-my-app", `d:\`, "src-my-app"},
+		{"Z--", `Z:\`, ""},
+		{"--server-share-proj", `\\`, "server-share-proj"},
+		{"--", `\\`, ""},
+		{"1--Users-x", `\`, "1--Users-x"},         // not a drive letter
+		{"CD--Users-x", `\`, "CD--Users-x"},       // drive letters are one letter
+		{"C-Users-x-proj", `\`, "C-Users-x-proj"}, // no colon encoded
+		{"proj", `\`, "proj"},
+		{"", `\`, ""},
+	}
+	for _, tt := range tests {
+		root, rest := windowsEncodedRoot(tt.encoded)
+		if root != tt.wantRoot || rest != tt.wantRest {
+			t.Errorf("windowsEncodedRoot(%q) = %q, %q; want %q, %q", tt.encoded, root, rest, tt.wantRoot, tt.wantRest)
+		}
+	}
+}
+
+func TestUnixEncodedRoot(t *testing.T) {
+	tests := []struct {
+		encoded  string
+		wantRest string
+	}{
+		{"-Users-x-proj", "Users-x-proj"},
+		{"-home-x-my-app", "home-x-my-app"},
+		{"-", ""},
+		{"C--Users-x-proj", "C--Users-x-proj"}, // a Windows path is no Unix root
+		{"", ""},
+	}
+	for _, tt := range tests {
+		root, rest := unixEncodedRoot(tt.encoded)
+		if root != "/" || rest != tt.wantRest {
+			t.Errorf("unixEncodedRoot(%q) = %q, %q; want \"/\", %q", tt.encoded, root, rest, tt.wantRest)
+		}
+	}
+}
+
+// Names with dashes are told apart from path separators by the
+// directories that exist
+func TestResolveProjectName(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("encodes Unix paths")
+	}
+	dir := filepath.Join(t.TempDir(), "my-app")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	encoded := strings.ReplaceAll(dir, "/", "-")
+
+	name, path := resolveProjectName(encoded)
+	if name != "my-app" || path != dir {
+		t.Errorf("resolveProjectName(%q) = %q, %q; want my-app, %q", encoded, name, path, dir)
+	}
+
+	// Without the directory, everything after the last dash
+	name, path = resolveProjectName("-nonexistent-cws-test-my-app")
+	if name != "app" || path != "" {
+		t.Errorf("resolveProjectName of a missing directory = %q, %q; want app, \"\"", name, path)
+	}
+}
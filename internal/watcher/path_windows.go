@@ -0,0 +1,8 @@
+//go:build windows
+
+package watcher
+
+// encodedRoot splits the root off an encoded project directory
+func encodedRoot(encodedDir string) (root, rest string) {
+	return windowsEncodedRoot(encodedDir)
+}
@@ -0,0 +1,202 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pollInterval is how often a polling watcher scans the projects directory
+const pollInterval = 2 * time.Second
+
+// pollWatcher detects changes by comparing the size and modification time
+// of session and subagent logs between periodic scans; logs missing from a
+// scan are reported as removed. It works where file
+// system notifications never fire: NFS, SSHFS and WSL-mounted home
+// directories.
+type pollWatcher struct {
+	resolver
+
+	events   chan Event
+	errors   chan error
+	done     chan struct{}
+	stopOnce sync.Once
+
+	// files holds the last seen stamp of each log; used only by the
+	// scanning goroutine after Start
+	files map[string]fileStamp
+}
+
+// fileStamp identifies a version of a log file
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+func newPollWatcher(projectsDir string) *pollWatcher {
+	return &pollWatcher{
+		resolver: newResolver(projectsDir),
+		events:   make(chan Event, 100),
+		errors:   make(chan error, 10),
+		done:     make(chan struct{}),
+		files:    make(map[string]fileStamp),
+	}
+}
+
+func (w *pollWatcher) Start() error {
+	// The initial scan records existing logs without reporting them
+	if _, err := os.ReadDir(w.projectsDir); err != nil {
+		return err
+	}
+	w.scan(false)
+
+	go w.pollLoop()
+	return nil
+}
+
+func (w *pollWatcher) Events() <-chan Event {
+	return w.events
+}
+
+func (w *pollWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+func (w *pollWatcher) Stop() error {
+	w.stopOnce.Do(func() { close(w.done) })
+	return nil
+}
+
+func (w *pollWatcher) Mode() Mode {
+	return ModePoll
+}
+
+func (w *pollWatcher) pollLoop() {
+	// Closing the channels lets consumers range over them until Stop
+	defer close(w.errors)
+	defer close(w.events)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			if !w.scan(true) {
+				return
+			}
+		}
+	}
+}
+
+// scan compares the logs on disk with the previous scan, reporting new and
+// changed ones if emit is set. It returns false if the watcher was stopped.
+func (w *pollWatcher) scan(emit bool) bool {
+	projects, err := os.ReadDir(w.projectsDir)
+	if err != nil {
+		return w.send(nil, err)
+	}
+
+	seen := make(map[string]bool, len(w.files))
+	for _, project := range projects {
+		if !project.IsDir() {
+			continue
+		}
+		for _, path := range projectLogs(filepath.Join(w.projectsDir, project.Name())) {
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			seen[path] = true
+			stamp := fileStamp{size: info.Size(), modTime: info.ModTime()}
+			prev, known := w.files[path]
+			w.files[path] = stamp
+			if !emit || (known && prev == stamp) {
+				continue
+			}
+			if ev, ok := w.event(path); ok && !w.send(&ev, nil) {
+				return false
+			}
+		}
+	}
+
+	for path := range w.files {
+		if seen[path] {
+			continue
+		}
+		delete(w.files, path)
+		if ev, ok := w.removal(path); ok && emit && !w.send(&ev, nil) {
+			return false
+		}
+	}
+	return true
+}
+
+// projectLogs lists the session logs of a project directory and the
+// subagent logs of its recent sessions, nested ones included
+func projectLogs(projectDir string) []string {
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		return nil
+	}
+
+	var logs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			if strings.HasSuffix(entry.Name(), ".jsonl") {
+				logs = append(logs, filepath.Join(projectDir, entry.Name()))
+			}
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) > recentSessionAge {
+			continue
+		}
+		logs = append(logs, subagentLogs(filepath.Join(projectDir, entry.Name()), 2)...)
+	}
+	return logs
+}
+
+// subagentLogs lists the logs in subagents directories below dir, which is
+// at the given depth below the projects directory
+func subagentLogs(dir string, depth int) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var logs []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		switch {
+		case entry.IsDir() && depth < maxWatchDepth:
+			logs = append(logs, subagentLogs(path, depth+1)...)
+		case !entry.IsDir() && filepath.Base(dir) == "subagents" && strings.HasSuffix(entry.Name(), ".jsonl"):
+			logs = append(logs, path)
+		}
+	}
+	return logs
+}
+
+// send delivers an event or an error, returning false if the watcher was
+// stopped meanwhile
+func (w *pollWatcher) send(ev *Event, err error) bool {
+	if ev != nil {
+		select {
+		case w.events <- *ev:
+			return true
+		case <-w.done:
+			return false
+		}
+	}
+	select {
+	case w.errors <- err:
+		return true
+	case <-w.done:
+		return false
+	}
+}
@@ -1,12 +1,13 @@
 package watcher
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
-	"sync"
 
-	"github.com/fsnotify/fsnotify"
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/logging"
 )
 
 // Event represents a file change event
@@ -14,220 +15,236 @@ type Event struct {
 	Path        string
 	ProjectName string
 	SessionID   string
+	// Removed reports that the session log at Path, or the project
+	// directory at Path with all its logs, was deleted or renamed away
+	Removed bool
 }
 
 // Watcher watches for JSONL file changes in the projects directory
-type Watcher struct {
-	fsWatcher   *fsnotify.Watcher
-	projectsDir string
-	events      chan Event
-	errors      chan error
-	done        chan struct{}
-	mu          sync.RWMutex
-	watching    map[string]bool
-
-	// Project name cache: encodedDir -> projectName
-	nameCache   map[string]string
-	nameCacheMu sync.RWMutex
+type Watcher interface {
+	// Start begins watching for file changes
+	Start() error
+	// Events returns the channel of file events
+	Events() <-chan Event
+	// Errors returns the channel of errors
+	Errors() <-chan error
+	// Stop stops the watcher
+	Stop() error
+	// Mode returns how changes are detected: ModeFSNotify or ModePoll
+	Mode() Mode
+	// Latest returns an event for the most recently modified session log
+	// of each project, to seed states at startup
+	Latest() []Event
 }
 
-// New creates a new Watcher for the given projects directory
-func New(projectsDir string) (*Watcher, error) {
-	fsWatcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, err
-	}
+// Mode selects how file changes are detected
+type Mode string
 
-	w := &Watcher{
-		fsWatcher:   fsWatcher,
-		projectsDir: projectsDir,
-		events:      make(chan Event, 100),
-		errors:      make(chan error, 10),
-		done:        make(chan struct{}),
-		watching:    make(map[string]bool),
-		nameCache:   make(map[string]string),
-	}
+const (
+	ModeAuto     Mode = "auto"     // fsnotify, polling on network filesystems or if fsnotify fails
+	ModeFSNotify Mode = "fsnotify" // file system notifications
+	ModePoll     Mode = "poll"     // periodic scans, for NFS, SSHFS and WSL mounts
+)
 
-	return w, nil
+// ParseMode parses a --watch-mode value
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(s); mode {
+	case ModeAuto, ModeFSNotify, ModePoll:
+		return mode, nil
+	case "":
+		return ModeAuto, nil
+	default:
+		return "", fmt.Errorf("unknown watch mode %q (want auto, fsnotify or poll)", s)
+	}
 }
 
-// Start begins watching for file changes
-func (w *Watcher) Start() error {
-	// Initial scan of existing directories
-	if err := w.scanDirectories(); err != nil {
-		return err
+// New creates a Watcher for the given projects directory. In ModeAuto,
+// directories on network filesystems, where file system notifications
+// never fire, are polled, as are all directories if fsnotify cannot
+// watch the projects directory.
+func New(projectsDir string, mode Mode) (Watcher, error) {
+	switch mode {
+	case ModePoll:
+		return newPollWatcher(projectsDir), nil
+	case ModeFSNotify:
+		return newNotifyWatcher(projectsDir)
 	}
 
-	// Watch the projects directory for new project folders
-	if err := w.fsWatcher.Add(w.projectsDir); err != nil {
-		return err
+	if fsType, remote := remoteFilesystem(projectsDir); remote {
+		logging.Logger().Info("polling session logs on a network filesystem", "dir", projectsDir, "fs", fsType)
+		return newPollWatcher(projectsDir), nil
 	}
-
-	go w.watchLoop()
-	return nil
-}
-
-// Events returns the channel of file events
-func (w *Watcher) Events() <-chan Event {
-	return w.events
+	w, err := newNotifyWatcher(projectsDir)
+	if err == nil {
+		if err = w.fsWatcher.Add(projectsDir); err == nil {
+			return w, nil
+		}
+		w.fsWatcher.Close()
+	}
+	if _, statErr := os.Stat(projectsDir); statErr != nil {
+		return nil, statErr
+	}
+	logging.Logger().Warn("fsnotify unavailable, polling session logs", "dir", projectsDir, "error", err)
+	return newPollWatcher(projectsDir), nil
 }
 
-// Errors returns the channel of errors
-func (w *Watcher) Errors() <-chan error {
-	return w.errors
+// resolver turns changed log paths into events
+type resolver struct {
+	projectsDir string
+	names       *nameCache
 }
 
-// Stop stops the watcher
-func (w *Watcher) Stop() error {
-	close(w.done)
-	return w.fsWatcher.Close()
+func newResolver(projectsDir string) resolver {
+	return resolver{
+		projectsDir: projectsDir,
+		names:       newNameCache(config.GetNameCachePath()),
+	}
 }
 
-func (w *Watcher) scanDirectories() error {
-	entries, err := os.ReadDir(w.projectsDir)
+// event returns the event for a changed file, if it is a session log
+// ({project}/{session}.jsonl) or a subagent log, which sits in a
+// subagents directory anywhere in the session directory
+// ({project}/{session}/subagents/{agent}.jsonl, or nested deeper)
+func (r *resolver) event(path string) (Event, bool) {
+	if !strings.HasSuffix(path, ".jsonl") {
+		return Event{}, false
+	}
+	rel, err := filepath.Rel(r.projectsDir, path)
 	if err != nil {
-		return err
+		return Event{}, false
 	}
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			dirPath := filepath.Join(w.projectsDir, entry.Name())
-			if err := w.watchDirectory(dirPath); err != nil {
-				w.errors <- err
-			}
-		}
+	parts := strings.Split(rel, string(filepath.Separator))
+	if parts[0] == ".." {
+		return Event{}, false
 	}
-	return nil
-}
 
-func (w *Watcher) watchDirectory(dirPath string) error {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-
-	if w.watching[dirPath] {
-		return nil
+	// Events are reported for the session log the file belongs to;
+	// ignore anything that is neither a session nor a subagent log
+	var logPath string
+	switch {
+	case len(parts) == 2:
+		logPath = path
+	case len(parts) >= 4 && parts[len(parts)-2] == "subagents":
+		logPath = filepath.Join(r.projectsDir, parts[0], parts[1]) + ".jsonl"
+	default:
+		return Event{}, false
 	}
 
-	if err := w.fsWatcher.Add(dirPath); err != nil {
-		return err
-	}
-	w.watching[dirPath] = true
-	return nil
+	return Event{
+		Path:        path,
+		ProjectName: r.extractProjectName(logPath),
+		SessionID:   extractSessionID(logPath),
+	}, true
 }
 
-func (w *Watcher) watchLoop() {
-	for {
-		select {
-		case <-w.done:
-			return
-
-		case event, ok := <-w.fsWatcher.Events:
-			if !ok {
-				return
-			}
-			w.handleEvent(event)
-
-		case err, ok := <-w.fsWatcher.Errors:
-			if !ok {
-				return
-			}
-			w.errors <- err
-		}
+// Latest returns an event for the most recently modified session log of
+// each project directory
+func (r *resolver) Latest() []Event {
+	entries, err := os.ReadDir(r.projectsDir)
+	if err != nil {
+		return nil
 	}
-}
 
-func (w *Watcher) handleEvent(event fsnotify.Event) {
-	// Handle new directory creation
-	if event.Has(fsnotify.Create) {
-		info, err := os.Stat(event.Name)
-		if err == nil && info.IsDir() {
-			if err := w.watchDirectory(event.Name); err != nil {
-				w.errors <- err
-			}
-			return
+	var events []Event
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path, err := GetLatestJSONL(filepath.Join(r.projectsDir, entry.Name()))
+		if err != nil || path == "" {
+			continue
+		}
+		if ev, ok := r.event(path); ok {
+			events = append(events, ev)
 		}
 	}
+	return events
+}
 
-	// Only process JSONL file modifications
-	if !strings.HasSuffix(event.Name, ".jsonl") {
-		return
-	}
-
-	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
-		return
+// removal returns the event for a deleted or renamed path, if it is a
+// session log or a project directory. Removed subagent logs do not end
+// their session and are ignored.
+func (r *resolver) removal(path string) (Event, bool) {
+	rel, err := filepath.Rel(r.projectsDir, path)
+	if err != nil {
+		return Event{}, false
 	}
-
-	projectName := w.extractProjectName(event.Name)
-	sessionID := extractSessionID(event.Name)
-
-	w.events <- Event{
-		Path:        event.Name,
-		ProjectName: projectName,
-		SessionID:   sessionID,
+	parts := strings.Split(rel, string(filepath.Separator))
+	switch {
+	case parts[0] == ".." || parts[0] == ".":
+		return Event{}, false
+	case len(parts) == 1 && !strings.HasSuffix(path, ".jsonl"):
+		return Event{
+			Path:        path,
+			ProjectName: r.extractProjectName(filepath.Join(path, "session.jsonl")),
+			Removed:     true,
+		}, true
+	case len(parts) == 2 && strings.HasSuffix(path, ".jsonl"):
+		return Event{
+			Path:        path,
+			ProjectName: r.extractProjectName(path),
+			SessionID:   extractSessionID(path),
+			Removed:     true,
+		}, true
 	}
+	return Event{}, false
 }
 
 // extractProjectName extracts the project name from the Claude projects path.
 // Path format: ~/.claude/projects/{encoded-path}/{session}.jsonl
 // where {encoded-path} is the original path with "/" replaced by "-"
 // e.g., "-Users-sho-work-claude-watch-status" -> "claude-watch-status"
-func (w *Watcher) extractProjectName(path string) string {
+// (on Windows, "C--Users-sho-work-proj" -> "proj")
+func (r *resolver) extractProjectName(path string) string {
 	dir := filepath.Dir(path)
 	base := filepath.Base(dir)
 
-	// Check cache first
-	w.nameCacheMu.RLock()
-	if cached, ok := w.nameCache[base]; ok {
-		w.nameCacheMu.RUnlock()
+	// Check cache first (persisted across restarts)
+	if cached, ok := r.names.get(base); ok {
 		return cached
 	}
-	w.nameCacheMu.RUnlock()
 
 	// Resolve project name by checking filesystem
-	projectName := resolveProjectName(base)
-
-	// Store in cache
-	w.nameCacheMu.Lock()
-	w.nameCache[base] = projectName
-	w.nameCacheMu.Unlock()
+	projectName, projectPath := resolveProjectName(base)
+	r.names.put(base, projectName, projectPath)
 
 	return projectName
 }
 
 // resolveProjectName resolves the actual project name by checking
 // if the reconstructed path exists on the filesystem.
-// Claude Code encodes paths by replacing "/" with "-", so we need to
-// find where the actual project directory starts.
-func resolveProjectName(encodedDir string) string {
+// Claude Code encodes paths by replacing path separators (and the drive
+// colon on Windows) with "-", so we need to find where the actual
+// project directory starts.
+// Returns the name and the project path ("" if no candidate exists).
+func resolveProjectName(encodedDir string) (string, string) {
 	if len(encodedDir) == 0 {
-		return encodedDir
+		return encodedDir, ""
 	}
 
-	// Remove leading "-" (replacement of leading "/")
-	s := encodedDir
-	if s[0] == '-' {
-		s = s[1:]
-	}
+	// Remove the encoded root ("/" on Unix, "C:\" on Windows)
+	root, s := encodedRoot(encodedDir)
+	sep := string(filepath.Separator)
 
 	// Search from end to find the actual project name
 	// by checking if the reconstructed path exists
 	for i := len(s) - 1; i >= 0; i-- {
 		if s[i] == '-' {
 			projectName := s[i+1:]
-			parentPath := "/" + strings.ReplaceAll(s[:i], "-", "/")
+			parentPath := root + strings.ReplaceAll(s[:i], "-", sep)
 			fullPath := filepath.Join(parentPath, projectName)
 
 			if info, err := os.Stat(fullPath); err == nil && info.IsDir() {
-				return projectName
+				return projectName, fullPath
 			}
 		}
 	}
 
 	// Fallback: return everything after the last dash (legacy behavior)
 	if idx := strings.LastIndex(encodedDir, "-"); idx != -1 {
-		return encodedDir[idx+1:]
+		return encodedDir[idx+1:], ""
 	}
-	return encodedDir
+	return encodedDir, ""
 }
 
 // extractSessionID extracts the session ID from the filename
@@ -236,6 +253,55 @@ func extractSessionID(path string) string {
 	return strings.TrimSuffix(base, ".jsonl")
 }
 
+// ProjectDir is a directory of session logs and the project it belongs to
+type ProjectDir struct {
+	Project string
+	Dir     string
+}
+
+// ListProjectDirs returns the directories under projectsDir holding
+// session logs, with their project names. nameFor maps a project path to
+// a configured project name, "" to keep the name derived from the
+// directory; it may be nil.
+func ListProjectDirs(projectsDir string, nameFor func(dir string) string) ([]ProjectDir, error) {
+	entries, err := os.ReadDir(projectsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []ProjectDir
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name, path := resolveProjectName(entry.Name())
+		if nameFor != nil && path != "" {
+			if configured := nameFor(path); configured != "" {
+				name = configured
+			}
+		}
+		dirs = append(dirs, ProjectDir{Project: name, Dir: filepath.Join(projectsDir, entry.Name())})
+	}
+	return dirs, nil
+}
+
+// ProjectDirs returns the directories under projectsDir holding session
+// logs of a project. A project may have several, e.g. one per worktree.
+func ProjectDirs(projectsDir, project string, nameFor func(dir string) string) ([]string, error) {
+	all, err := ListProjectDirs(projectsDir, nameFor)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	for _, d := range all {
+		if d.Project == project {
+			dirs = append(dirs, d.Dir)
+		}
+	}
+	return dirs, nil
+}
+
 // GetLatestJSONL returns the most recently modified JSONL file in a directory
 func GetLatestJSONL(dirPath string) (string, error) {
 	entries, err := os.ReadDir(dirPath)
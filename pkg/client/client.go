@@ -0,0 +1,476 @@
+// Package client talks to a running claude-watch-status daemon over its
+// HTTP API, as described by the OpenAPI document in protocol.OpenAPIJSON:
+// status snapshots, the event stream, project metadata and history, hook
+// events, mutes and the daemon configuration.
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/pkg/protocol"
+)
+
+// requestTimeout bounds requests other than the event stream
+const requestTimeout = 5 * time.Second
+
+// ErrUnauthorized is returned when the daemon rejects the API token
+var ErrUnauthorized = errors.New("daemon rejected the API token (set CWS_API_TOKEN)")
+
+// Client is a daemon API client
+type Client struct {
+	endpoint  string
+	token     string
+	hookToken string
+	http      *http.Client
+}
+
+// New returns a client for the daemon at endpoint (e.g.
+// http://127.0.0.1:10087). token is the API bearer token, if the daemon
+// requires one.
+func New(endpoint, token string) *Client {
+	return &Client{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		token:    token,
+		http:     &http.Client{},
+	}
+}
+
+// SetHookToken sets the shared secret sent with hook events, if the
+// daemon requires one
+func (c *Client) SetHookToken(token string) {
+	c.hookToken = token
+}
+
+// SetInsecureTLS skips verifying the daemon's TLS certificate, for
+// daemons serving HTTPS with a self-signed one
+func (c *Client) SetInsecureTLS() {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	c.http = &http.Client{Transport: transport}
+}
+
+// Endpoint returns the daemon base URL
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+// Health checks that the daemon is running
+func (c *Client) Health(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/health", nil, nil)
+}
+
+// Status returns the status of all projects
+func (c *Client) Status(ctx context.Context) (*protocol.Snapshot, error) {
+	var snapshot protocol.Snapshot
+	if err := c.do(ctx, http.MethodGet, "/api/status", nil, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Mute silences the notifications of a project for d, or until unmuted
+// if d is 0
+func (c *Client) Mute(ctx context.Context, project string, d time.Duration) (*protocol.Mute, error) {
+	req := protocol.MuteRequest{}
+	if d > 0 {
+		req.Duration = d.String()
+	}
+	var mute protocol.Mute
+	if err := c.do(ctx, http.MethodPost, projectPath(project)+"/mute", req, &mute); err != nil {
+		return nil, err
+	}
+	return &mute, nil
+}
+
+// Unmute restores the notifications of a project
+func (c *Client) Unmute(ctx context.Context, project string) error {
+	return c.do(ctx, http.MethodDelete, projectPath(project)+"/mute", nil, nil)
+}
+
+// Acknowledge marks a project's waiting, completed, interrupted or error
+// state as seen, clearing its highlight in the daemon's UIs
+func (c *Client) Acknowledge(ctx context.Context, project string) (*protocol.ProjectStatus, error) {
+	var status protocol.ProjectStatus
+	if err := c.do(ctx, http.MethodPost, projectPath(project)+"/ack", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Focus raises the terminal window of a project's session on the daemon's
+// host
+func (c *Client) Focus(ctx context.Context, project string) (*protocol.ProjectStatus, error) {
+	var status protocol.ProjectStatus
+	if err := c.do(ctx, http.MethodPost, projectPath(project)+"/focus", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// PauseNotifications silences all notifications for d, or until resumed
+// if d is 0
+func (c *Client) PauseNotifications(ctx context.Context, d time.Duration) (*protocol.NotificationPause, error) {
+	path := "/api/notifications/pause"
+	if d > 0 {
+		path += "?duration=" + url.QueryEscape(d.String())
+	}
+	var pause protocol.NotificationPause
+	if err := c.do(ctx, http.MethodPost, path, nil, &pause); err != nil {
+		return nil, err
+	}
+	return &pause, nil
+}
+
+// ResumeNotifications ends a notification pause
+func (c *Client) ResumeNotifications(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/api/notifications/resume", nil, nil)
+}
+
+// Mutes returns the muted projects
+func (c *Client) Mutes(ctx context.Context) ([]protocol.Mute, error) {
+	var resp protocol.MutesResponse
+	if err := c.do(ctx, http.MethodGet, "/api/mutes", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Mutes, nil
+}
+
+// Projects returns the metadata of all projects, including those hidden
+// after a long time without activity if includeInactive is set
+func (c *Client) Projects(ctx context.Context, includeInactive bool) ([]protocol.ProjectInfo, error) {
+	path := "/api/projects"
+	if includeInactive {
+		path += "?include_inactive=true"
+	}
+	var resp protocol.ProjectsResponse
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Projects, nil
+}
+
+// Project returns the metadata of one project
+func (c *Client) Project(ctx context.Context, project string) (*protocol.ProjectInfo, error) {
+	var info protocol.ProjectInfo
+	if err := c.do(ctx, http.MethodGet, projectPath(project), nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Sessions returns the sessions seen in a project, most recent first
+func (c *Client) Sessions(ctx context.Context, project string) ([]protocol.SessionInfo, error) {
+	var resp protocol.SessionsResponse
+	if err := c.do(ctx, http.MethodGet, projectPath(project)+"/sessions", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Sessions, nil
+}
+
+// Transitions returns up to limit state changes of a session after the
+// cursor after (0 for the first page); limit 0 uses the daemon's default
+func (c *Client) Transitions(ctx context.Context, sessionID string, after uint64, limit int) (*protocol.TransitionsPage, error) {
+	query := url.Values{"after": {strconv.FormatUint(after, 10)}}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var page protocol.TransitionsPage
+	path := "/api/sessions/" + url.PathEscape(sessionID) + "/transitions?" + query.Encode()
+	if err := c.do(ctx, http.MethodGet, path, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Stats returns the activity statistics of the current day
+func (c *Client) Stats(ctx context.Context) (*protocol.Stats, error) {
+	var stats protocol.Stats
+	if err := c.do(ctx, http.MethodGet, "/api/stats", nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// SearchOptions narrows a session log search
+type SearchOptions struct {
+	Project string        // only this project; "" = all
+	Since   time.Duration // only entries this recent; 0 = all
+	Limit   int           // at most this many matches; 0 = the daemon's default
+}
+
+// Search finds session log entries containing text
+func (c *Client) Search(ctx context.Context, text string, opts SearchOptions) (*protocol.SearchResult, error) {
+	query := url.Values{"q": {text}}
+	if opts.Project != "" {
+		query.Set("project", opts.Project)
+	}
+	if opts.Since > 0 {
+		query.Set("since", opts.Since.String())
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	var result protocol.SearchResult
+	if err := c.do(ctx, http.MethodGet, "/api/search?"+query.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SendHookEvent reports a Claude Code hook event, as the hook scripts do
+func (c *Client) SendHookEvent(ctx context.Context, event protocol.HookEvent) error {
+	return c.do(ctx, http.MethodPost, "/api/hooks", event, nil)
+}
+
+// Config returns the effective configuration of the daemon
+func (c *Client) Config(ctx context.Context) (*protocol.Config, error) {
+	var cfg protocol.Config
+	if err := c.do(ctx, http.MethodGet, "/api/config", nil, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// UpdateSettings changes runtime settings; the daemon writes them to its
+// config file and returns the settings now in effect
+func (c *Client) UpdateSettings(ctx context.Context, update protocol.SettingsUpdate) (*protocol.Settings, error) {
+	var cfg protocol.Config
+	if err := c.do(ctx, http.MethodPut, "/api/config", update, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Settings == nil {
+		return nil, errors.New("daemon returned no settings")
+	}
+	return cfg.Settings, nil
+}
+
+// ReloadConfig makes the daemon reload its config file
+func (c *Client) ReloadConfig(ctx context.Context) (*protocol.ReloadResult, error) {
+	var result protocol.ReloadResult
+	if err := c.do(ctx, http.MethodPost, "/api/config/reload", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func projectPath(project string) string {
+	return "/api/projects/" + url.PathEscape(project)
+}
+
+// do sends a request with an optional JSON body and decodes the JSON
+// response into out, if not nil
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := c.newRequest(ctx, method, path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	// The hook secret is only for hook events, which need no API token
+	if c.hookToken != "" && path == "/api/hooks" {
+		req.Header.Set(protocol.HookTokenHeader, c.hookToken)
+	}
+	return req, nil
+}
+
+// checkResponse turns error responses into errors carrying the daemon's
+// error message
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return ErrUnauthorized
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&body) == nil && body.Error != "" {
+		return fmt.Errorf("daemon returned %s: %s", resp.Status, body.Error)
+	}
+	return fmt.Errorf("daemon returned %s", resp.Status)
+}
+
+// StreamOptions selects the events of a stream
+type StreamOptions struct {
+	Projects    []string // only these projects; empty = all
+	Types       []string // only these event types (update, idle_approval, idle_completed, acknowledged, session_removed, notifications); empty = all
+	LastEventID uint64   // resume after this event instead of receiving a snapshot; 0 = snapshot
+}
+
+// Event is an event received from the stream. Exactly one of Snapshot,
+// Update, Removed and Notifications is set. Notifications events carry
+// the ID of the event before them. A Snapshot after the first replaces
+// every status known: the stream fell behind and missed events.
+type Event struct {
+	ID            uint64
+	Type          string // protocol.TypeInit, TypeUpdate, TypeSessionRemoved or TypeNotifications
+	Snapshot      *protocol.Snapshot
+	Update        *protocol.Update
+	Removed       *protocol.SessionRemoved
+	Notifications *protocol.NotificationPause
+}
+
+// Stream connects to the event stream and calls fn for every event until
+// ctx is done, the connection drops or fn returns an error. It returns
+// nil only when ctx is done. Callers that reconnect should pass the ID of
+// the last event received as LastEventID to resume without gaps.
+func (c *Client) Stream(ctx context.Context, opts StreamOptions, fn func(Event) error) error {
+	query := url.Values{}
+	if len(opts.Projects) > 0 {
+		query.Set("project", strings.Join(opts.Projects, ","))
+	}
+	if len(opts.Types) > 0 {
+		query.Set("types", strings.Join(opts.Types, ","))
+	}
+	path := "/api/status/stream"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if opts.LastEventID > 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatUint(opts.LastEventID, 10))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+
+	err = readEvents(resp.Body, fn)
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// readEvents parses a text/event-stream body. Comment lines (keepalives)
+// are skipped and unknown event types ignored.
+func readEvents(r io.Reader, fn func(Event) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var id uint64
+	var data []byte
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) == 0 {
+				continue
+			}
+			ev, err := decodeEvent(id, data)
+			data = data[:0]
+			if err != nil {
+				return err
+			}
+			if ev == nil {
+				continue
+			}
+			if err := fn(*ev); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// comment
+		case strings.HasPrefix(line, "id:"):
+			id, _ = strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "id:")), 10, 64)
+		case strings.HasPrefix(line, "data:"):
+			if len(data) > 0 {
+				data = append(data, '\n')
+			}
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")...)
+		}
+	}
+	return scanner.Err()
+}
+
+// decodeEvent decodes an event payload; nil means an unknown event type
+func decodeEvent(id uint64, payload []byte) (*Event, error) {
+	env, err := protocol.Decode(payload)
+	if err != nil {
+		return nil, err
+	}
+	ev := &Event{ID: id, Type: env.Type}
+	switch env.Type {
+	case protocol.TypeInit:
+		ev.Snapshot = &protocol.Snapshot{}
+		err = json.Unmarshal(env.Data, ev.Snapshot)
+	case protocol.TypeUpdate:
+		ev.Update = &protocol.Update{}
+		err = json.Unmarshal(env.Data, ev.Update)
+	case protocol.TypeSessionRemoved:
+		ev.Removed = &protocol.SessionRemoved{}
+		err = json.Unmarshal(env.Data, ev.Removed)
+	case protocol.TypeNotifications:
+		ev.Notifications = &protocol.NotificationPause{}
+		err = json.Unmarshal(env.Data, ev.Notifications)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s event: %w", env.Type, err)
+	}
+	return ev, nil
+}
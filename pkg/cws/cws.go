@@ -0,0 +1,285 @@
+// Package cws embeds Claude Code status watching in Go programs. A
+// Watcher follows the session logs under ~/.claude/projects like the
+// stream and dashboard modes do, including idle detection, and reports
+// the status of every project:
+//
+//	w, err := cws.New(cws.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	defer w.Stop()
+//	sub := w.Subscribe()
+//	defer sub.Close()
+//	if err := w.Start(); err != nil {
+//		return err
+//	}
+//	for event := range sub.Events() {
+//		fmt.Println(event.Status.Name, event.Status.Icon, event.Status.State)
+//	}
+//
+// Statuses use the wire type of the daemon API, protocol.ProjectStatus.
+// ParseLogLine and ReadLogState classify session log entries without a
+// Watcher. A Watcher sends no notifications.
+package cws
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/config"
+	"github.com/sho7650/claude-watch-status/internal/engine"
+	"github.com/sho7650/claude-watch-status/internal/state"
+	"github.com/sho7650/claude-watch-status/internal/watcher"
+	"github.com/sho7650/claude-watch-status/pkg/protocol"
+)
+
+// Status is the status of a project
+type Status = protocol.ProjectStatus
+
+// Event types
+const (
+	EventUpdate         = "update"         // a session log changed the status
+	EventIdleApproval   = "idle_approval"  // idle detection: estimated waiting approval
+	EventIdleCompleted  = "idle_completed" // idle detection: estimated completion
+	EventMuted          = "muted"          // the project was muted or unmuted, or its mute ended
+	EventSessionRemoved = "session_removed"
+)
+
+// Event is a status change
+type Event struct {
+	Type string
+	// Status is the project's new status. For EventSessionRemoved only
+	// Name and SessionID are set.
+	Status Status
+	// ProjectRemoved is set on EventSessionRemoved when the project went
+	// with its last session
+	ProjectRemoved bool
+}
+
+// Options configures a Watcher
+type Options struct {
+	// ProjectsDir holds the session logs; "" = $CLAUDE_PROJECTS_DIR or
+	// ~/.claude/projects
+	ProjectsDir string
+	// ConfigFile applies project names, tiers, groups, retention and the
+	// idle threshold of a claude-watch-status config file; "" = defaults.
+	// DefaultConfigPath returns the user's config file.
+	ConfigFile string
+	// WatchMode selects how log changes are detected: "auto" (default),
+	// "fsnotify" or "poll"
+	WatchMode string
+}
+
+// Watcher watches session logs and keeps the status of every project
+type Watcher struct {
+	engine *engine.Engine
+	mu     sync.Mutex
+	subs   []*Subscription
+}
+
+// DefaultProjectsDir returns the directory Claude Code writes session
+// logs to
+func DefaultProjectsDir() string {
+	return config.GetProjectsDir()
+}
+
+// DefaultConfigPath returns the path of the user's claude-watch-status
+// config file
+func DefaultConfigPath() string {
+	return config.GetConfigPath()
+}
+
+// New creates a Watcher; Start starts watching
+func New(opts Options) (*Watcher, error) {
+	dir := opts.ProjectsDir
+	if dir == "" {
+		dir = DefaultProjectsDir()
+	}
+	eng := engine.New(dir)
+
+	cfg := config.DefaultConfig()
+	if opts.ConfigFile != "" {
+		var err error
+		if cfg, err = config.Load(opts.ConfigFile); err != nil {
+			return nil, err
+		}
+	}
+	eng.ApplyConfig(cfg)
+
+	if opts.WatchMode != "" {
+		mode, err := watcher.ParseMode(opts.WatchMode)
+		if err != nil {
+			return nil, err
+		}
+		eng.SetWatchMode(mode)
+	}
+	return &Watcher{engine: eng}, nil
+}
+
+// Start reads the latest session log of every project and starts
+// watching. Subscribe before Start to receive the statuses found.
+func (w *Watcher) Start() error {
+	if err := w.engine.Watch(); err != nil {
+		return fmt.Errorf("watching session logs: %w", err)
+	}
+	w.engine.Start()
+	return nil
+}
+
+// Stop stops watching and closes all subscriptions
+func (w *Watcher) Stop() error {
+	err := w.engine.Stop()
+	w.mu.Lock()
+	subs := w.subs
+	w.subs = nil
+	w.mu.Unlock()
+	for _, sub := range subs {
+		sub.Close()
+	}
+	return err
+}
+
+// Statuses returns the status of every project, except those hidden after
+// a long time without activity
+func (w *Watcher) Statuses() []Status {
+	projects := w.engine.Manager().GetVisible()
+	statuses := make([]Status, 0, len(projects))
+	for _, p := range projects {
+		statuses = append(statuses, toStatus(p))
+	}
+	return statuses
+}
+
+// Status returns the status of a project
+func (w *Watcher) Status(project string) (Status, bool) {
+	p := w.engine.Manager().Get(project)
+	if p == nil {
+		return Status{}, false
+	}
+	return toStatus(*p), true
+}
+
+// Mute marks a project as muted for d (0 = until unmuted): its status
+// reports Muted and MutedUntil, for programs that notify on their own.
+// Projects need not be known yet.
+func (w *Watcher) Mute(project string, d time.Duration) {
+	var until time.Time
+	if d > 0 {
+		until = time.Now().Add(d)
+	}
+	w.engine.Manager().Mute(project, until)
+}
+
+// Unmute clears the mute of a project, reporting whether it was muted
+func (w *Watcher) Unmute(project string) bool {
+	return w.engine.Manager().Unmute(project)
+}
+
+// Mutes returns the active mutes, sorted by project
+func (w *Watcher) Mutes() []protocol.Mute {
+	active := w.engine.Manager().Mutes()
+	mutes := make([]protocol.Mute, 0, len(active))
+	for _, m := range active {
+		mutes = append(mutes, protocol.Mute(m))
+	}
+	return mutes
+}
+
+// Subscription delivers status changes until closed
+type Subscription struct {
+	events    chan Event
+	manager   *state.Manager
+	ch        chan state.StatusEvent
+	closeOnce sync.Once
+}
+
+// Subscribe returns a subscription to status changes. Events are dropped
+// while the subscriber falls more than 100 events behind.
+func (w *Watcher) Subscribe() *Subscription {
+	sub := &Subscription{
+		events:  make(chan Event, 100),
+		manager: w.engine.Manager(),
+	}
+	sub.ch = sub.manager.Subscribe()
+	go sub.run()
+
+	w.mu.Lock()
+	w.subs = append(w.subs, sub)
+	w.mu.Unlock()
+	return sub
+}
+
+// Events returns the channel status changes are delivered on; it is
+// closed by Close
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close ends the subscription
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.manager.Unsubscribe(s.ch)
+	})
+}
+
+// run forwards the manager's events until the subscription is closed
+func (s *Subscription) run() {
+	defer close(s.events)
+	state.ForEachUpdate(nil, s.ch, func(event state.StatusEvent) {
+		if event.Type == state.EventAcknowledged {
+			return
+		}
+		select {
+		case s.events <- Event{Type: event.Type, Status: toStatus(event.Project), ProjectRemoved: event.ProjectRemoved}:
+		default:
+			// Subscriber too far behind, skip
+		}
+	})
+}
+
+// toStatus converts a project status to its wire type
+func toStatus(p state.ProjectStatus) Status {
+	status := Status{
+		Name:          p.Name,
+		Icon:          p.Icon,
+		State:         p.State,
+		Detail:        p.Detail,
+		Request:       p.Request,
+		UpdatedAt:     p.UpdatedAt,
+		ReceivedAt:    p.ReceivedAt,
+		SessionID:     p.SessionID,
+		Source:        p.Source,
+		Tier:          p.Tier,
+		Seq:           p.Seq,
+		Acknowledged:  p.Acknowledged,
+		Muted:         p.Muted,
+		MutedUntil:    p.MutedUntil,
+		ProjectPath:   p.ProjectPath,
+		Path:          p.Path,
+		Branch:        p.Branch,
+		ToolStartedAt: p.ToolStartedAt,
+	}
+	for _, sub := range p.Subagents {
+		status.Subagents = append(status.Subagents, protocol.SubagentStatus{
+			ID:          sub.ID,
+			Description: sub.Description,
+			Type:        sub.Type,
+			Icon:        sub.Icon,
+			State:       sub.State,
+			UpdatedAt:   sub.UpdatedAt,
+		})
+	}
+	if p.Environment != nil {
+		status.Environment = &protocol.Environment{
+			Model:          p.Environment.Model,
+			PermissionMode: p.Environment.PermissionMode,
+			MCPServers:     p.Environment.MCPServers,
+		}
+		if t := p.Environment.Terminal; t != nil {
+			terminal := protocol.Terminal(*t)
+			status.Environment.Terminal = &terminal
+		}
+	}
+	return status
+}
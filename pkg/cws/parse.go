@@ -0,0 +1,72 @@
+package cws
+
+import (
+	"time"
+
+	"github.com/sho7650/claude-watch-status/internal/parser"
+	"github.com/sho7650/claude-watch-status/internal/state"
+)
+
+// LogState is the state a session log entry shows
+type LogState struct {
+	Icon   string
+	State  string // e.g. "thinking", "running: Bash", "waiting approval"
+	Phase  string // see PhaseOf
+	Tool   string // tool being called, if any
+	Detail string // what the tool works on: file path, command, URL, ...
+	// Estimated is set when the state is based on timeout heuristics
+	Estimated bool
+}
+
+// ParseLogLine classifies one line of a session log. ok is false for
+// entries that do not change the state, such as summaries.
+func ParseLogLine(line string) (s LogState, ok bool, err error) {
+	entry, err := parser.ParseEntry(line)
+	if err != nil {
+		return LogState{}, false, err
+	}
+	return logState(parser.ParseState(entry))
+}
+
+// ReadLogState returns the state shown by the last entry of a session log
+// file; a tool call followed by a prompt instead of its result shows as
+// interrupted. ok is false if that entry does not change the state.
+func ReadLogState(path string) (s LogState, ok bool, err error) {
+	entries, err := parser.ReadLastEntries(path, 2)
+	if err != nil || len(entries) == 0 {
+		return LogState{}, false, err
+	}
+	last := entries[len(entries)-1]
+	ps := parser.ParseState(last)
+	if !ps.Skip && len(entries) == 2 && parser.IsOrphanedToolUse(entries[0], last) {
+		ps = parser.State{Icon: "🛑", Text: "interrupted"}
+	}
+	return logState(ps)
+}
+
+// logState converts a parsed state
+func logState(ps parser.State) (LogState, bool, error) {
+	if ps.Skip {
+		return LogState{}, false, nil
+	}
+	return LogState{
+		Icon:      ps.Icon,
+		State:     ps.Text,
+		Phase:     PhaseOf(ps.Text),
+		Tool:      ps.ToolName,
+		Detail:    ps.Detail,
+		Estimated: ps.IsEstimated,
+	}, true, nil
+}
+
+// PhaseOf classifies a state into a phase: started, user_input, working,
+// waiting, completed, interrupted, error, ended or unknown
+func PhaseOf(s string) string {
+	return state.PhaseOf(s).String()
+}
+
+// ToolTimeout returns how long a call of a tool may run before a project
+// showing it is taken to wait for approval
+func ToolTimeout(tool string) time.Duration {
+	return parser.ToolTimeout(tool)
+}
@@ -0,0 +1,83 @@
+// Package protocol defines the wire format shared by the daemon and its
+// clients: the versioned envelope that wraps every event, its published
+// JSON Schema, the OpenAPI document of the HTTP API and its payload types
+package protocol
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SchemaV1 identifies the first version of the envelope and its payloads.
+// Fields may be added within a version; removing or changing the meaning
+// of a field requires a new version.
+const SchemaV1 = "cws.event.v1"
+
+// Event types
+const (
+	TypeInit           = "init"            // data: snapshot of all projects; repeated when a stream fell behind
+	TypeUpdate         = "update"          // data: one project's status
+	TypeSessionRemoved = "session_removed" // data: a session whose log was deleted
+	TypeNotifications  = "notifications"   // data: whether notifications are paused
+)
+
+// SchemaV1JSON is the JSON Schema of SchemaV1 envelopes
+//
+//go:embed cws.event.v1.json
+var SchemaV1JSON []byte
+
+// OpenAPIJSON is the OpenAPI document of the daemon's HTTP API
+//
+//go:embed openapi.json
+var OpenAPIJSON []byte
+
+// HookTokenHeader is the HTTP header carrying the shared secret of hook
+// events
+const HookTokenHeader = "X-CWS-Token"
+
+// HookTerminalHeader is the HTTP header describing the terminal a hook
+// event comes from, as URL-encoded Terminal fields such as
+// "program=iTerm.app&tty=/dev/ttys003"
+const HookTerminalHeader = "X-CWS-Terminal"
+
+// Envelope wraps an outbound event with its schema version and type
+type Envelope struct {
+	Schema string      `json:"schema"`
+	Type   string      `json:"type"`
+	Time   time.Time   `json:"ts"`
+	Data   interface{} `json:"data"`
+}
+
+// New wraps data in a SchemaV1 envelope stamped with the current time
+func New(eventType string, data interface{}) Envelope {
+	return Envelope{
+		Schema: SchemaV1,
+		Type:   eventType,
+		Time:   time.Now(),
+		Data:   data,
+	}
+}
+
+// RawEnvelope is a received envelope whose data is decoded once its type
+// is known
+type RawEnvelope struct {
+	Schema string          `json:"schema"`
+	Type   string          `json:"type"`
+	Time   time.Time       `json:"ts"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Decode parses an event payload, rejecting envelopes of another schema
+// version
+func Decode(payload []byte) (RawEnvelope, error) {
+	var env RawEnvelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		return env, err
+	}
+	if env.Schema != SchemaV1 {
+		return env, fmt.Errorf("unsupported event schema %q (want %s)", env.Schema, SchemaV1)
+	}
+	return env, nil
+}
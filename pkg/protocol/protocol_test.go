@@ -0,0 +1,76 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// duplicateKeys returns the paths of keys repeated within an object of
+// doc; JSON parsers keep only the last of them
+func duplicateKeys(doc []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	var duplicates []string
+	var walk func(path string) error
+	walk = func(path string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		delim, ok := tok.(json.Delim)
+		if !ok {
+			return nil
+		}
+		switch delim {
+		case '{':
+			seen := make(map[string]bool)
+			for dec.More() {
+				tok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key := tok.(string)
+				if seen[key] {
+					duplicates = append(duplicates, path+"/"+key)
+				}
+				seen[key] = true
+				if err := walk(path + "/" + key); err != nil {
+					return err
+				}
+			}
+		case '[':
+			for i := 0; dec.More(); i++ {
+				if err := walk(fmt.Sprintf("%s/%d", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+		_, err = dec.Token() // closing delimiter
+		return err
+	}
+	return duplicates, walk("")
+}
+
+func TestDocumentsHaveNoDuplicateKeys(t *testing.T) {
+	for name, doc := range map[string][]byte{"openapi.json": OpenAPIJSON, "cws.event.v1.json": SchemaV1JSON} {
+		duplicates, err := duplicateKeys(doc)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if len(duplicates) > 0 {
+			t.Errorf("%s has duplicate keys: %s", name, strings.Join(duplicates, ", "))
+		}
+	}
+}
+
+func TestDuplicateKeysFound(t *testing.T) {
+	duplicates, err := duplicateKeys([]byte(`{"a": {"403": 1, "b": [{"x": 1, "x": 2}], "403": 2}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(duplicates, ","); got != "/a/b/0/x,/a/403" {
+		t.Errorf("duplicateKeys() = %q", got)
+	}
+}
@@ -0,0 +1,315 @@
+package protocol
+
+import "time"
+
+// ProjectStatus is the current status of a project
+type ProjectStatus struct {
+	Name   string `json:"name"`
+	Icon   string `json:"icon"`
+	State  string `json:"state"`
+	Detail string `json:"detail,omitempty"` // what the current tool works on: file path, command, URL, ...
+	// Request is what a project waiting for approval asks permission
+	// for, as in "Bash: rm -rf build/"
+	Request     string           `json:"request,omitempty"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	ReceivedAt  time.Time        `json:"received_at"`
+	SessionID   string           `json:"session_id,omitempty"`
+	Source      string           `json:"source"` // "hooks", "jsonl" or an agent name
+	Tier        string           `json:"tier,omitempty"`
+	Seq         uint64           `json:"seq"` // per-project sequence number
+	Subagents   []SubagentStatus `json:"subagents,omitempty"`
+	Environment *Environment     `json:"environment,omitempty"`
+	// Acknowledged is set once a user saw the current waiting, completed,
+	// interrupted or error state; UIs stop highlighting it
+	Acknowledged bool `json:"acknowledged,omitempty"`
+	// Muted is set while the project's notifications are silenced; its
+	// state still updates. MutedUntil is when the mute ends, zero while
+	// muted until unmuted.
+	Muted      bool      `json:"muted,omitempty"`
+	MutedUntil time.Time `json:"muted_until,omitzero"`
+	// ProjectPath is the directory the name was derived from; projects in
+	// different directories with the same name get a hint appended, as in
+	// "api (beta)"
+	ProjectPath string `json:"project_path,omitempty"`
+	// Path is the session's working directory and Branch the git branch
+	// (or detached commit) checked out there, telling worktrees apart
+	Path   string `json:"path,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	// ToolStartedAt is when the tool a "running: <tool>" state shows
+	// started; UIs count the elapsed time from it
+	ToolStartedAt time.Time `json:"tool_started_at,omitzero"`
+}
+
+// SubagentStatus is the status of a Task subagent of the current turn
+type SubagentStatus struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description,omitempty"`
+	Type        string    `json:"type,omitempty"`
+	Icon        string    `json:"icon"`
+	State       string    `json:"state"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Environment describes how a session runs
+type Environment struct {
+	Model          string    `json:"model,omitempty"`
+	PermissionMode string    `json:"permission_mode,omitempty"`
+	MCPServers     []string  `json:"mcp_servers,omitempty"`
+	Terminal       *Terminal `json:"terminal,omitempty"`
+}
+
+// Terminal tells which terminal window a session runs in
+type Terminal struct {
+	Program      string `json:"program,omitempty"`
+	TTY          string `json:"tty,omitempty"`
+	TmuxPane     string `json:"tmux_pane,omitempty"`
+	TmuxSocket   string `json:"tmux_socket,omitempty"`
+	ITermSession string `json:"iterm_session,omitempty"`
+	WezTermPane  string `json:"wezterm_pane,omitempty"`
+	KittyWindow  string `json:"kitty_window,omitempty"`
+	XWindow      string `json:"x_window,omitempty"`
+}
+
+// Snapshot is the status of all projects: the data of init events and
+// the /api/status response
+type Snapshot struct {
+	Projects []ProjectStatus `json:"projects"`
+	Version  uint64          `json:"version,omitempty"`
+}
+
+// Update is the data of update events
+type Update struct {
+	ProjectStatus
+	Notify bool   `json:"notify,omitempty"` // the browser should raise a notification
+	Cause  string `json:"cause,omitempty"`  // "idle_approval" or "idle_completed" for idle detection, "acknowledged", "muted"
+}
+
+// SessionRemoved is the data of session_removed events: the session's
+// log was deleted, so the daemon forgot it
+type SessionRemoved struct {
+	Project        string `json:"project"`
+	SessionID      string `json:"session_id"`
+	ProjectRemoved bool   `json:"project_removed,omitempty"` // the project went with its last session
+}
+
+// Mute silences the notifications of a project until a time
+type Mute struct {
+	Project string    `json:"project"`
+	Until   time.Time `json:"until,omitzero"` // zero = until unmuted
+}
+
+// MuteRequest is the body of POST /api/projects/:name/mute
+type MuteRequest struct {
+	Duration string `json:"duration,omitempty"` // Go duration; empty = until unmuted
+}
+
+// NotificationPause is the data of notifications events and the
+// /api/notifications/pause response: whether all notifications are
+// silenced, and until when
+type NotificationPause struct {
+	Paused bool      `json:"paused"`
+	Until  time.Time `json:"until,omitzero"` // zero = until resumed
+}
+
+// MutesResponse is the /api/mutes response
+type MutesResponse struct {
+	Mutes []Mute `json:"mutes"`
+}
+
+// ProjectInfo is the metadata of a project: the /api/projects/:name
+// response and the entries of /api/projects
+type ProjectInfo struct {
+	Name           string        `json:"name"`
+	Path           string        `json:"path,omitempty"` // working directory, when reported
+	Tier           string        `json:"tier,omitempty"`
+	Group          string        `json:"group,omitempty"`
+	LastActivity   time.Time     `json:"last_activity"`
+	Hidden         bool          `json:"hidden,omitempty"` // inactive past the hide TTL
+	Sessions       int           `json:"sessions"`
+	ActiveSessions int           `json:"active_sessions"`
+	Status         ProjectStatus `json:"status"`
+}
+
+// ProjectsResponse is the /api/projects response
+type ProjectsResponse struct {
+	Projects []ProjectInfo `json:"projects"`
+}
+
+// SessionInfo is a session seen in a project
+type SessionInfo struct {
+	ID           string       `json:"id"`
+	Source       string       `json:"source"`
+	LogPath      string       `json:"log_path,omitempty"`
+	Path         string       `json:"path,omitempty"`
+	Branch       string       `json:"branch,omitempty"`
+	Icon         string       `json:"icon"`
+	State        string       `json:"state"`
+	StartedAt    time.Time    `json:"started_at"`
+	LastActivity time.Time    `json:"last_activity"`
+	Ended        bool         `json:"ended,omitempty"`
+	Active       bool         `json:"active"`
+	Environment  *Environment `json:"environment,omitempty"`
+}
+
+// SessionsResponse is the /api/projects/:name/sessions response
+type SessionsResponse struct {
+	Project  string        `json:"project"`
+	Sessions []SessionInfo `json:"sessions"`
+}
+
+// Transition is a state change of a session
+type Transition struct {
+	Cursor    uint64    `json:"cursor"`
+	Project   string    `json:"project"`
+	Icon      string    `json:"icon"`
+	State     string    `json:"state"`
+	Phase     string    `json:"phase"`
+	Source    string    `json:"source"`
+	Cause     string    `json:"cause,omitempty"` // idle_approval or idle_completed for idle detection
+	Estimated bool      `json:"estimated,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// TransitionsPage is the /api/sessions/:id/transitions response
+type TransitionsPage struct {
+	SessionID   string       `json:"session_id"`
+	Transitions []Transition `json:"transitions"`
+	NextCursor  uint64       `json:"next_cursor"` // pass as after to continue
+	More        bool         `json:"more"`
+	Truncated   bool         `json:"truncated"` // transitions were missed; resync from the project status
+}
+
+// Stats is the /api/stats response: activity statistics of the current day
+type Stats struct {
+	Date     string         `json:"date"`
+	Projects []ProjectStats `json:"projects"`
+}
+
+// ProjectStats is the activity of a project during a day
+type ProjectStats struct {
+	Name              string         `json:"name"`
+	ActiveSeconds     float64        `json:"active_seconds"`
+	ToolCalls         map[string]int `json:"tool_calls"`
+	ApprovalsWaited   int            `json:"approvals_waited"`
+	Completions       int            `json:"completions"`
+	AvgResponseMillis int64          `json:"avg_response_latency_ms"`
+	Responses         int            `json:"responses"`
+	// ToolTimes holds the measured run time of tool calls by tool; only
+	// calls reported by PreToolUse/PostToolUse hooks are measured
+	ToolTimes map[string]ToolTime `json:"tool_times,omitempty"`
+}
+
+// ToolTime is the measured run time of a tool's calls
+type ToolTime struct {
+	Runs         int     `json:"runs"`
+	TotalSeconds float64 `json:"total_seconds"`
+	MaxSeconds   float64 `json:"max_seconds"`
+}
+
+// SearchResult is the /api/search response
+type SearchResult struct {
+	Matches   []SearchMatch `json:"matches"`
+	Truncated bool          `json:"truncated"` // more entries match than the limit
+}
+
+// SearchMatch is a session log entry matching a search
+type SearchMatch struct {
+	Project   string    `json:"project"`
+	SessionID string    `json:"session_id"`
+	Time      time.Time `json:"time"`
+	Role      string    `json:"role"` // user or assistant
+	Type      string    `json:"type"` // text, tool_use or tool_result
+	Tool      string    `json:"tool,omitempty"`
+	Snippet   string    `json:"snippet"`
+	Subagent  bool      `json:"subagent,omitempty"`
+	IsError   bool      `json:"is_error,omitempty"`
+}
+
+// HookEvent is the body of POST /api/hooks: the JSON Claude Code passes
+// to hooks. The daemon ignores fields it does not know, so payloads may
+// carry more.
+type HookEvent struct {
+	SessionID        string                 `json:"session_id"`
+	HookEventName    string                 `json:"hook_event_name"`
+	ToolName         string                 `json:"tool_name,omitempty"`
+	ToolUseID        string                 `json:"tool_use_id,omitempty"`
+	ToolInput        map[string]interface{} `json:"tool_input,omitempty"`
+	ToolResult       *HookToolResult        `json:"tool_result,omitempty"`
+	CWD              string                 `json:"cwd"`
+	PermissionMode   string                 `json:"permission_mode,omitempty"`
+	Model            interface{}            `json:"model,omitempty"` // a name, or an object with an id
+	TranscriptPath   string                 `json:"transcript_path,omitempty"`
+	StopHookActive   bool                   `json:"stop_hook_active,omitempty"`
+	AgentID          string                 `json:"agent_id,omitempty"`
+	Reason           string                 `json:"reason,omitempty"`
+	Message          string                 `json:"message,omitempty"`
+	NotificationType string                 `json:"notification_type,omitempty"`
+}
+
+// HookToolResult is the result of a tool execution reported by a hook
+type HookToolResult struct {
+	Success bool   `json:"success"`
+	Output  string `json:"output,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Settings are the settings of the config file a running daemon can
+// change: the settings field of the /api/config response
+type Settings struct {
+	Desktop               bool     `json:"desktop"`
+	DaemonHealth          bool     `json:"daemon_health"`
+	UnattendedPermissions bool     `json:"unattended_permissions"`
+	IdleCompletedAfter    string   `json:"idle_completed_after"`
+	MutedProjects         []string `json:"muted_projects"`
+}
+
+// SettingsUpdate is the body of PUT /api/config; nil fields are left as
+// they are
+type SettingsUpdate struct {
+	Desktop               *bool     `json:"desktop,omitempty"`
+	DaemonHealth          *bool     `json:"daemon_health,omitempty"`
+	UnattendedPermissions *bool     `json:"unattended_permissions,omitempty"`
+	IdleCompletedAfter    *string   `json:"idle_completed_after,omitempty"` // "" = default
+	MutedProjects         *[]string `json:"muted_projects,omitempty"`       // replaces the list
+}
+
+// Config is the /api/config response: the effective configuration of the
+// daemon, secrets reported only as enabled or not
+type Config struct {
+	Version       string              `json:"version,omitempty"`
+	ConfigFile    string              `json:"config_file"`
+	ProjectsDir   string              `json:"projects_dir"`
+	Port          int                 `json:"port"`
+	BindAddress   string              `json:"bind_address"`
+	TLS           bool                `json:"tls"`
+	HookAuth      bool                `json:"hook_auth"`
+	APIAuth       bool                `json:"api_auth"`
+	Sources       []string            `json:"sources"`
+	WatchMode     string              `json:"watch_mode,omitempty"`
+	LowPower      bool                `json:"low_power"`
+	SSEKeepalive  string              `json:"sse_keepalive"`
+	Heartbeat     string              `json:"heartbeat"`
+	HistoryFile   string              `json:"history_file,omitempty"`
+	PrefsFile     string              `json:"prefs_file,omitempty"`
+	JournalFile   string              `json:"journal_file,omitempty"`
+	Notifications ConfigNotifications `json:"notifications"`
+	LogLevel      string              `json:"log_level"`
+	Settings      *Settings           `json:"settings,omitempty"` // nil if the daemon cannot change its configuration
+}
+
+// ConfigNotifications lists which notification backends are active
+type ConfigNotifications struct {
+	Desktop               bool `json:"desktop"`
+	DaemonHealth          bool `json:"daemon_health"`
+	UnattendedPermissions bool `json:"unattended_permissions"`
+	Browser               bool `json:"browser"`
+	Notifiers             int  `json:"notifiers"`
+	Shortcuts             int  `json:"shortcuts"`
+}
+
+// ReloadResult is the /api/config/reload response: the config sections
+// a reload changed
+type ReloadResult struct {
+	Changed         []string `json:"changed"`
+	RestartRequired []string `json:"restart_required,omitempty"` // changed, but only applied on restart
+}